@@ -7,6 +7,8 @@ import (
 
 	"github.com/neflyte/timetracker/cmd/timetracker-gui/cmd"
 	"github.com/neflyte/timetracker/lib/constants"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/ipc"
 	"github.com/neflyte/timetracker/lib/logger"
 	"github.com/neflyte/timetracker/lib/ui/gui"
 	"github.com/neflyte/timetracker/lib/utils"
@@ -122,19 +124,68 @@ func postDoGUI() error {
 	return nil
 }
 
-func doGUI() {
-	app := gui.InitGUI(cmd.AppVersion)
+// guiCommand returns the command that corresponds to the command-line options
+func guiCommand() ipc.Command {
 	switch {
 	case guiCmdOptionStopRunningTask:
-		gui.ShowTimetrackerWindowAndStopRunningTask()
+		return ipc.CommandStopRunningTask
 	case guiCmdOptionShowManageWindow:
-		gui.ShowTimetrackerWindowWithManageWindow()
+		return ipc.CommandManage
+	case guiCmdOptionShowTimesheetsWindow:
+		return ipc.CommandTimesheets
+	case guiCmdOptionShowSettingsWindow:
+		return ipc.CommandSettings
+	case guiCmdOptionShowDaySummary:
+		return ipc.CommandSummary
 	case guiCmdOptionShowAboutWindow:
-		gui.ShowTimetrackerWindowWithAbout()
+		return ipc.CommandAbout
 	case guiCmdOptionShowCreateAndStartDialog:
-		gui.ShowTimetrackerWindowAndShowCreateAndStartDialog()
+		return ipc.CommandCreateAndStart
 	default:
-		gui.ShowTimetrackerWindow()
+		return ipc.CommandShow
+	}
+}
+
+// forwardToRunningGUI asks an already-running GUI to perform the requested command. If there
+// is no running GUI, false is returned.
+func forwardToRunningGUI() bool {
+	log := logger.GetLogger("forwardToRunningGUI")
+	err := ipc.Send(guiCommand())
+	if err != nil {
+		if !errors.As(err, &tterrors.ErrGUINotRunning{}) {
+			log.Err(err).
+				Msg(tterrors.SendIPCRequestError)
+		}
+		return false
+	}
+	log.Debug().
+		Str("command", string(guiCommand())).
+		Msg("forwarded command to the running GUI")
+	return true
+}
+
+func doGUI() {
+	log := logger.GetLogger("doGUI")
+	app := gui.InitGUI(cmd.AppVersion)
+	// Listen for requests from the tray and CLI
+	ipcServer := ipc.NewServer(gui.HandleCommand)
+	err := ipcServer.Start()
+	if err != nil {
+		log.Err(err).
+			Msg(tterrors.StartIPCServerError)
+	} else {
+		defer func() {
+			stopErr := ipcServer.Stop()
+			if stopErr != nil {
+				log.Err(stopErr).
+					Msg("error stopping IPC server")
+			}
+		}()
+	}
+	err = gui.HandleCommand(guiCommand())
+	if err != nil {
+		log.Err(err).
+			Msg("error handling command-line options")
 	}
 	// Start the GUI
 	gui.StartGUI(app)
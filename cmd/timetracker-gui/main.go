@@ -7,33 +7,42 @@ import (
 	"github.com/neflyte/timetracker/cmd/timetracker-gui/cmd"
 	"github.com/neflyte/timetracker/lib/constants"
 	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/models"
 	"github.com/neflyte/timetracker/lib/startup"
 )
 
 const (
-	guiPidfile = "timetracker-gui.pid"
+	guiPidfile = constants.GUIPidfile
 )
 
 var (
 	configFileName                       string
+	profileName                          string
 	logLevel                             string
 	showVersion                          bool
 	consoleLogging                       bool
 	guiCmdOptionStopRunningTask          bool
 	guiCmdOptionShowCreateAndStartDialog bool
 	guiCmdOptionShowManageWindow         bool
+	guiCmdOptionShowTimesheetsWindow     bool
+	guiCmdOptionShowSettingsWindow       bool
+	guiCmdOptionShowDaySummary           bool
 	guiCmdOptionShowAboutWindow          bool
 )
 
 func init() {
-	flag.StringVar(&configFileName, "config", "", "Specify the full path and filename of the database to use")
-	flag.StringVar(&logLevel, "logLevel", constants.DefaultLogLevel, "Specify the logging level")
-	flag.BoolVar(&consoleLogging, "console", false, "Also log messages to the console")
+	flag.StringVar(&configFileName, startup.FlagDatabase, "", "Specify the full path and filename of the database to use")
+	flag.StringVar(&profileName, "profile", "", "Specify the profile whose database is used instead of the selected profile")
+	flag.StringVar(&logLevel, startup.FlagLogLevel, constants.DefaultLogLevel, "Specify the logging level")
+	flag.BoolVar(&consoleLogging, startup.FlagConsole, false, "Also log messages to the console")
 	flag.BoolVar(&showVersion, "version", false, "Display the program version")
 	// GUI flags
 	flag.BoolVar(&guiCmdOptionStopRunningTask, "stop-running-task", false, "Stops the running task, if any")
 	flag.BoolVar(&guiCmdOptionShowCreateAndStartDialog, "create-and-start", false, "Shows the Create and Start New Task dialog")
 	flag.BoolVar(&guiCmdOptionShowManageWindow, "manage", false, "Shows the Manage Window")
+	flag.BoolVar(&guiCmdOptionShowTimesheetsWindow, "timesheets", false, "Shows the Timesheets Window")
+	flag.BoolVar(&guiCmdOptionShowSettingsWindow, "settings", false, "Shows the Settings Window")
+	flag.BoolVar(&guiCmdOptionShowDaySummary, "summary", false, "Shows the Report Window with the report of today")
 	flag.BoolVar(&guiCmdOptionShowAboutWindow, "about", false, "Shows the About Window")
 }
 
@@ -45,9 +54,16 @@ func main() {
 	}
 	startup.SetLogLevel(logLevel)
 	startup.SetConsole(consoleLogging)
+	startup.SetDatabaseFileName(configFileName)
+	startup.SetProfileName(profileName)
+	models.SetAuditSource(models.AuditSourceGUI)
+	startup.LoadConfig(isFlagSet)
 	startup.InitLogger()
 	defer startup.CleanupLogger()
-	startup.SetDatabaseFileName(configFileName)
+	// If the GUI is already running, let it handle the request instead
+	if forwardToRunningGUI() {
+		return
+	}
 	startup.InitDatabase()
 	defer startup.CleanupDatabase()
 	log := logger.GetLogger("main")
@@ -66,3 +82,14 @@ func main() {
 	}()
 	doGUI()
 }
+
+// isFlagSet determines if the named flag was set on the command line
+func isFlagSet(name string) bool {
+	flagSet := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			flagSet = true
+		}
+	})
+	return flagSet
+}
@@ -7,25 +7,28 @@ import (
 	"github.com/neflyte/timetracker/cmd/timetracker-tray/cmd"
 	"github.com/neflyte/timetracker/lib/constants"
 	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/models"
 	"github.com/neflyte/timetracker/lib/startup"
 )
 
 const (
-	trayPidfile = "timetracker-tray.pid"
+	trayPidfile = constants.TrayPidfile
 )
 
 var (
 	configFileName string
+	profileName    string
 	logLevel       string
 	showVersion    bool
 	console        bool
 )
 
 func init() {
-	flag.StringVar(&configFileName, "config", "", "Specify the full path and filename of the database to use")
-	flag.StringVar(&logLevel, "logLevel", constants.DefaultLogLevel, "Specify the logging level")
+	flag.StringVar(&configFileName, startup.FlagDatabase, "", "Specify the full path and filename of the database to use")
+	flag.StringVar(&profileName, "profile", "", "Specify the profile whose database is used instead of the selected profile")
+	flag.StringVar(&logLevel, startup.FlagLogLevel, constants.DefaultLogLevel, "Specify the logging level")
 	flag.BoolVar(&showVersion, "version", false, "Display the program version")
-	flag.BoolVar(&console, "console", false, "Log to the console")
+	flag.BoolVar(&console, startup.FlagConsole, false, "Log to the console")
 }
 
 func main() {
@@ -36,9 +39,12 @@ func main() {
 	}
 	startup.SetLogLevel(logLevel)
 	startup.SetConsole(console)
+	startup.SetDatabaseFileName(configFileName)
+	startup.SetProfileName(profileName)
+	models.SetAuditSource(models.AuditSourceTray)
+	startup.LoadConfig(isFlagSet)
 	startup.InitLogger()
 	defer startup.CleanupLogger()
-	startup.SetDatabaseFileName(configFileName)
 	startup.InitDatabase()
 	defer startup.CleanupDatabase()
 	log := logger.GetLogger("main")
@@ -57,3 +63,14 @@ func main() {
 	}()
 	doTray()
 }
+
+// isFlagSet determines if the named flag was set on the command line
+func isFlagSet(name string) bool {
+	flagSet := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			flagSet = true
+		}
+	})
+	return flagSet
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"github.com/neflyte/timetracker/cmd/timetracker/cmd/backup"
+	"github.com/spf13/cobra"
+)
+
+var (
+	backupCmd = &cobra.Command{
+		Use:   "backup",
+		Short: "Backup operations",
+		Long: "Create backup archives of the database, optionally encrypted with a passphrase, and restore them; " +
+			"list and prune the automatic backups in the backup directory",
+	}
+)
+
+func init() {
+	backupCmd.AddCommand(
+		backup.CreateCmd,
+		backup.RestoreCmd,
+		backup.ListCmd,
+		backup.PruneCmd,
+	)
+}
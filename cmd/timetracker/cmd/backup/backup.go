@@ -0,0 +1,19 @@
+package backup
+
+import (
+	"github.com/neflyte/timetracker/lib/backup"
+	"github.com/spf13/cobra"
+)
+
+// addDirectoryFlag adds the flag of the backup directory to a command
+func addDirectoryFlag(cmd *cobra.Command, directory *string) {
+	cmd.Flags().StringVarP(directory, "directory", "d", "", "The directory of the backups; default is the backup.directory setting")
+}
+
+// backupDirectory returns the directory in the flag, or the directory of automatic backups if the flag is empty
+func backupDirectory(directory string) (string, error) {
+	if directory != "" {
+		return directory, nil
+	}
+	return backup.Directory()
+}
@@ -0,0 +1,65 @@
+package backup
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/neflyte/timetracker/lib/backup"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/ui/cli"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// CreateCmd represents the command to create a backup of the database
+	CreateCmd = &cobra.Command{
+		Use:     "create [file]",
+		Aliases: []string{"c", "new"},
+		Short:   "Create a backup",
+		Long: "Write a consistent snapshot of the database to a backup archive; the default file is named after the " +
+			"database and the time, such as timetracker-<date>-<time>" + backup.FileExtension + ", in the current " +
+			"directory. With --encrypt the archive " +
+			"is encrypted with AES-256-GCM using a key derived from a passphrase, which is prompted for or read from " +
+			cli.PassphraseEnvironmentVariable + ".",
+		Args: cobra.MaximumNArgs(1),
+		RunE: createBackup,
+	}
+	encryptBackup bool
+)
+
+func init() {
+	CreateCmd.Flags().BoolVarP(&encryptBackup, "encrypt", "e", false, "Encrypt the backup with a passphrase")
+}
+
+func createBackup(_ *cobra.Command, args []string) error {
+	log := logger.GetLogger("createBackup")
+	fileName := backup.DefaultFileName(time.Now())
+	if len(args) > 0 {
+		fileName = args[0]
+	}
+	passphrase := ""
+	if encryptBackup {
+		var err error
+		passphrase, err = cli.ReadPassphrase(true)
+		if err != nil {
+			cli.PrintAndLogError(log, err, tterrors.ReadPassphraseError)
+			return err
+		}
+	}
+	manifest, err := backup.Create(fileName, passphrase)
+	if err != nil {
+		cli.PrintAndLogError(log, err, tterrors.CreateBackupError)
+		return err
+	}
+	encrypted := ""
+	if encryptBackup {
+		encrypted = " (encrypted)" // i18n
+	}
+	fmt.Println(
+		color.GreenString("Backed up %d tasks and %d timesheets", manifest.Tasks, manifest.Timesheets),
+		color.WhiteString("to %s%s", fileName, encrypted),
+	) // i18n
+	return nil
+}
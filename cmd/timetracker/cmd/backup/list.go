@@ -0,0 +1,78 @@
+package backup
+
+import (
+	"fmt"
+
+	"github.com/alexeyco/simpletable"
+	"github.com/fatih/color"
+	"github.com/neflyte/timetracker/lib/backup"
+	"github.com/neflyte/timetracker/lib/config"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/ui/cli"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// ListCmd represents the command to list the backups in the backup directory
+	ListCmd = &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls", "l"},
+		Short:   "List backups",
+		Long:    "List the backups of the database in the backup directory, newest first",
+		Args:    cobra.NoArgs,
+		RunE:    listBackups,
+	}
+	listDirectory string
+)
+
+func init() {
+	addDirectoryFlag(ListCmd, &listDirectory)
+}
+
+func listBackups(_ *cobra.Command, _ []string) error {
+	log := logger.GetLogger("listBackups")
+	dir, err := backupDirectory(listDirectory)
+	if err != nil {
+		cli.PrintAndLogError(log, err, tterrors.ListBackupsError)
+		return err
+	}
+	files, err := backup.List(dir)
+	if err != nil {
+		cli.PrintAndLogError(log, err, tterrors.ListBackupsError)
+		return err
+	}
+	if len(files) == 0 {
+		fmt.Println(color.YellowString("No backups in %s", dir)) // i18n
+		return nil
+	}
+	printFiles(files)
+	return nil
+}
+
+// printFiles prints a table of backups
+func printFiles(files []backup.File) {
+	table := simpletable.New()
+	table.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Text: "Created"},
+			{Text: "Reason"},
+			{Text: "Size"},
+			{Text: "File"},
+		},
+	}
+	for _, file := range files {
+		reason := file.Reason
+		if reason == "" {
+			reason = "manual" // i18n
+		}
+		table.Body.Cells = append(table.Body.Cells, []*simpletable.Cell{
+			{Text: file.CreatedAt.Format(config.TimestampFormat())},
+			{Text: reason},
+			{Align: simpletable.AlignRight, Text: fmt.Sprintf("%d", file.Size)},
+			{Text: file.Path},
+		})
+	}
+	table.SetStyle(simpletable.StyleCompactLite)
+	fmt.Println(table.String())
+}
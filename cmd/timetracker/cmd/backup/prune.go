@@ -0,0 +1,73 @@
+package backup
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/neflyte/timetracker/lib/backup"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/ui/cli"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// PruneCmd represents the command to remove old backups from the backup directory
+	PruneCmd = &cobra.Command{
+		Use:   "prune",
+		Short: "Remove old backups",
+		Long: "Remove the backups in the backup directory except the last backup of each of the most recent days, " +
+			"weeks and months, as set by backup.keep-daily, backup.keep-weekly and backup.keep-monthly or the flags. " +
+			"The newest backup is always kept.",
+		Args: cobra.NoArgs,
+		RunE: pruneBackups,
+	}
+	pruneDirectory string
+	pruneDryRun    bool
+	keepDaily      int
+	keepWeekly     int
+	keepMonthly    int
+)
+
+func init() {
+	addDirectoryFlag(PruneCmd, &pruneDirectory)
+	PruneCmd.Flags().BoolVarP(&pruneDryRun, "dry-run", "n", false, "List the backups that would be removed without removing them")
+	PruneCmd.Flags().IntVar(&keepDaily, "keep-daily", 0, "The number of days whose last backup is kept; default is the backup.keep-daily setting")
+	PruneCmd.Flags().IntVar(&keepWeekly, "keep-weekly", 0, "The number of weeks whose last backup is kept; default is the backup.keep-weekly setting")
+	PruneCmd.Flags().IntVar(&keepMonthly, "keep-monthly", 0, "The number of months whose last backup is kept; default is the backup.keep-monthly setting")
+}
+
+func pruneBackups(cmd *cobra.Command, _ []string) error {
+	log := logger.GetLogger("pruneBackups")
+	dir, err := backupDirectory(pruneDirectory)
+	if err != nil {
+		cli.PrintAndLogError(log, err, tterrors.PruneBackupsError)
+		return err
+	}
+	retention := backup.ConfiguredRetention()
+	if cmd.Flags().Changed("keep-daily") {
+		retention.Daily = keepDaily
+	}
+	if cmd.Flags().Changed("keep-weekly") {
+		retention.Weekly = keepWeekly
+	}
+	if cmd.Flags().Changed("keep-monthly") {
+		retention.Monthly = keepMonthly
+	}
+	pruned, err := backup.Prune(dir, retention, pruneDryRun)
+	if err != nil {
+		cli.PrintAndLogError(log, err, tterrors.PruneBackupsError)
+		return err
+	}
+	if len(pruned) == 0 {
+		fmt.Println(color.WhiteString("No backups to remove")) // i18n
+		return nil
+	}
+	printFiles(pruned)
+	if pruneDryRun {
+		fmt.Println(color.YellowString("%d backups would be removed", len(pruned))) // i18n
+		return nil
+	}
+	fmt.Println(color.GreenString("Removed %d backups", len(pruned))) // i18n
+	return nil
+}
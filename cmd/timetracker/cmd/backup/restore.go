@@ -0,0 +1,102 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/alexeyco/simpletable"
+	"github.com/fatih/color"
+	"github.com/neflyte/timetracker/lib/backup"
+	"github.com/neflyte/timetracker/lib/config"
+	"github.com/neflyte/timetracker/lib/database"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/startup"
+	"github.com/neflyte/timetracker/lib/ui/cli"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// RestoreCmd represents the command to restore a backup over the database
+	RestoreCmd = &cobra.Command{
+		Use:     "restore [file]",
+		Aliases: []string{"r"},
+		Short:   "Restore a backup",
+		Long: "Verify a backup archive and replace the database with it; the replaced database is kept with " +
+			backup.PreviousDatabaseSuffix + " appended to its file name. The tray and GUI apps must be quit first. " +
+			"With --dry-run the backup is only verified and its contents are listed.",
+		Args: cobra.ExactArgs(1),
+		RunE: restoreBackup,
+	}
+	dryRun bool
+)
+
+func init() {
+	RestoreCmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "Verify the backup and list its contents without restoring it")
+}
+
+func restoreBackup(_ *cobra.Command, args []string) error {
+	log := logger.GetLogger("restoreBackup")
+	archive, err := backup.Open(args[0], "")
+	if errors.Is(err, tterrors.ErrPassphraseRequired{}) {
+		passphrase, passphraseErr := cli.ReadPassphrase(false)
+		if passphraseErr != nil {
+			cli.PrintAndLogError(log, passphraseErr, tterrors.ReadPassphraseError)
+			return passphraseErr
+		}
+		archive, err = backup.Open(args[0], passphrase)
+	}
+	if err != nil {
+		cli.PrintAndLogError(log, err, tterrors.ReadBackupError)
+		return err
+	}
+	defer archive.Close()
+	printContents(archive)
+	if dryRun {
+		return nil
+	}
+	target := database.FileName()
+	// The database is closed so that it can be replaced
+	startup.CleanupDatabase()
+	previousFile, err := archive.Restore(target)
+	if err != nil {
+		cli.PrintAndLogError(log, err, tterrors.RestoreBackupError)
+		return err
+	}
+	fmt.Println(color.GreenString("Restored"), color.WhiteString("%s", target)) // i18n
+	if previousFile != "" {
+		fmt.Println(color.WhiteString("The previous database was kept as %s", previousFile)) // i18n
+	}
+	return nil
+}
+
+// printContents prints the manifest and the files of a backup
+func printContents(archive *backup.Archive) {
+	manifest := archive.Manifest
+	encrypted := "no" // i18n
+	if archive.Encrypted {
+		encrypted = "yes" // i18n
+	}
+	fmt.Println(color.WhiteString("Created:    %s", manifest.CreatedAt.Local().Format(config.TimestampFormat()))) // i18n
+	fmt.Println(color.WhiteString("Database:   %s", manifest.Database))                                           // i18n
+	fmt.Println(color.WhiteString("Encrypted:  %s", encrypted))                                                   // i18n
+	fmt.Println(color.WhiteString("Tasks:      %d", manifest.Tasks))                                              // i18n
+	fmt.Println(color.WhiteString("Timesheets: %d", manifest.Timesheets))                                         // i18n
+	fmt.Println(color.WhiteString("SHA-256:    %s", manifest.SHA256))                                             // i18n
+	table := simpletable.New()
+	table.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Text: "File"},
+			{Text: "Size"},
+		},
+	}
+	for _, entry := range archive.Entries {
+		table.Body.Cells = append(table.Body.Cells, []*simpletable.Cell{
+			{Text: entry.Name},
+			{Align: simpletable.AlignRight, Text: fmt.Sprintf("%d", entry.Size)},
+		})
+	}
+	table.SetStyle(simpletable.StyleCompactLite)
+	fmt.Println(table.String())
+	fmt.Println(color.GreenString("Integrity verified")) // i18n
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	shellBash       = "bash"
+	shellZsh        = "zsh"
+	shellFish       = "fish"
+	shellPowerShell = "powershell"
+)
+
+var (
+	completionCmd = &cobra.Command{
+		Use:   "completion [bash|zsh|fish|powershell]",
+		Short: "Generate a shell completion script",
+		Long: `Generate a script that completes commands, flags, task synopses and IDs, and date keywords for the specified shell.
+
+Bash:
+  source <(timetracker completion bash)
+  # or, to load completions for every session on Linux:
+  timetracker completion bash > /etc/bash_completion.d/timetracker
+
+Zsh:
+  timetracker completion zsh > "${fpath[1]}/_timetracker"
+
+Fish:
+  timetracker completion fish > ~/.config/fish/completions/timetracker.fish
+
+PowerShell:
+  timetracker completion powershell | Out-String | Invoke-Expression
+  # or, to load completions for every session, add the output to your PowerShell profile
+`,
+		Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		ValidArgs:             []string{shellBash, shellZsh, shellFish, shellPowerShell},
+		DisableFlagsInUseLine: true,
+		RunE:                  generateCompletion,
+	}
+)
+
+func generateCompletion(cmd *cobra.Command, args []string) error {
+	root := cmd.Root()
+	switch args[0] {
+	case shellBash:
+		return root.GenBashCompletionV2(os.Stdout, true)
+	case shellZsh:
+		return root.GenZshCompletion(os.Stdout)
+	case shellFish:
+		return root.GenFishCompletion(os.Stdout, true)
+	default:
+		return root.GenPowerShellCompletionWithDesc(os.Stdout)
+	}
+}
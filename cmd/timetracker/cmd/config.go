@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"github.com/neflyte/timetracker/cmd/timetracker/cmd/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	configCmd = &cobra.Command{
+		Use:     "config",
+		Aliases: []string{"cfg"},
+		Short:   "Configuration operations",
+		Long:    "View and change the settings in the configuration file; environment variables take precedence over the file",
+	}
+)
+
+func init() {
+	configCmd.AddCommand(
+		config.GetCmd,
+		config.SetCmd,
+		config.ListCmd,
+		config.EditCmd,
+	)
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"github.com/fatih/color"
+	ttconfig "github.com/neflyte/timetracker/lib/config"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/ui/cli"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// EditCmd represents the command to open the configuration file in an editor
+	EditCmd = &cobra.Command{
+		Use:     "edit",
+		Aliases: []string{"e"},
+		Short:   "Edit the configuration file",
+		Long:    "Open the configuration file in the editor named by $VISUAL or $EDITOR",
+		RunE:    editConfig,
+	}
+)
+
+func editConfig(_ *cobra.Command, _ []string) error {
+	log := logger.GetLogger("editConfig")
+	fileName, err := ttconfig.ConfigFile()
+	if err != nil {
+		cli.PrintAndLogError(log, err, tterrors.EditConfigError)
+		return err
+	}
+	editorCmd := exec.Command(editor(), fileName)
+	editorCmd.Stdin = os.Stdin
+	editorCmd.Stdout = os.Stdout
+	editorCmd.Stderr = os.Stderr
+	err = editorCmd.Run()
+	if err != nil {
+		cli.PrintAndLogError(log, err, tterrors.EditConfigError)
+		return err
+	}
+	// Make sure the edited file can still be read
+	err = ttconfig.Load()
+	if err != nil {
+		cli.PrintAndLogError(log, err, tterrors.LoadConfigError)
+		return err
+	}
+	fmt.Println(color.WhiteString("Configuration file %s", fileName), color.GreenString("saved")) // i18n
+	return nil
+}
+
+// editor returns the user's preferred editor
+func editor() string {
+	for _, envVar := range []string{"VISUAL", "EDITOR"} {
+		if editorName := os.Getenv(envVar); editorName != "" {
+			return editorName
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return "notepad"
+	}
+	return "vi"
+}
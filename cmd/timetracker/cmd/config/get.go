@@ -0,0 +1,34 @@
+package config
+
+import (
+	"fmt"
+
+	ttconfig "github.com/neflyte/timetracker/lib/config"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/ui/cli"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// GetCmd represents the command to print the value of a setting
+	GetCmd = &cobra.Command{
+		Use:     "get [key]",
+		Aliases: []string{"g"},
+		Short:   "Print the value of a setting",
+		Args:    cobra.ExactArgs(1),
+		RunE:    getSetting,
+	}
+)
+
+func getSetting(_ *cobra.Command, args []string) error {
+	log := logger.GetLogger("getSetting")
+	key := args[0]
+	if _, ok := ttconfig.Lookup(key); !ok {
+		err := tterrors.ErrUnknownConfigKey{Key: key}
+		cli.PrintAndLogError(log, err, tterrors.LoadConfigError)
+		return err
+	}
+	fmt.Println(ttconfig.GetString(key))
+	return nil
+}
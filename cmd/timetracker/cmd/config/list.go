@@ -0,0 +1,44 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/alexeyco/simpletable"
+	ttconfig "github.com/neflyte/timetracker/lib/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// ListCmd represents the command to list the settings
+	ListCmd = &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"l", "ls"},
+		Short:   "List the settings",
+		Long:    "List every setting with its value and where the value comes from (env, file or default)",
+		RunE:    listSettings,
+	}
+)
+
+func listSettings(_ *cobra.Command, _ []string) error {
+	table := simpletable.New()
+	table.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Text: "Key"},
+			{Text: "Value"},
+			{Text: "Source"},
+			{Text: "Description"},
+		},
+	}
+	for _, key := range ttconfig.Keys {
+		rec := []*simpletable.Cell{
+			{Text: key.Name},
+			{Text: ttconfig.GetString(key.Name)},
+			{Text: ttconfig.Source(key.Name)},
+			{Text: key.Description},
+		}
+		table.Body.Cells = append(table.Body.Cells, rec)
+	}
+	table.SetStyle(simpletable.StyleCompactLite)
+	fmt.Println(table.String())
+	return nil
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	ttconfig "github.com/neflyte/timetracker/lib/config"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/ui/cli"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// SetCmd represents the command to save a setting in the configuration file
+	SetCmd = &cobra.Command{
+		Use:     "set [key] [value]",
+		Aliases: []string{"s"},
+		Short:   "Save a setting in the configuration file",
+		Args:    cobra.ExactArgs(2),
+		RunE:    setSetting,
+	}
+)
+
+func setSetting(_ *cobra.Command, args []string) error {
+	log := logger.GetLogger("setSetting")
+	key, value := args[0], args[1]
+	err := ttconfig.Set(key, value)
+	if err != nil {
+		cli.PrintAndLogError(log, err, tterrors.SetConfigError)
+		return err
+	}
+	fmt.Println(color.WhiteString("Setting %s", key), color.GreenString("saved")) // i18n
+	if ttconfig.Source(key) == ttconfig.SourceEnvironment {
+		fmt.Println(color.YellowString("%s is set in the environment and takes precedence", ttconfig.EnvironmentVariable(key))) // i18n
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/ipc"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/ui/cli"
+	"github.com/spf13/cobra"
+)
+
+var (
+	guiCmd = &cobra.Command{
+		Use:   fmt.Sprintf("gui [%s]", strings.Join(guiCommandNames(), "|")),
+		Short: "Show the GUI",
+		Long:  "Ask the running GUI to show a window or dialog, launching the GUI if it is not running",
+		Args: func(cmd *cobra.Command, args []string) error {
+			err := cobra.MaximumNArgs(1)(cmd, args)
+			if err != nil {
+				return err
+			}
+			if len(args) > 0 && !ipc.Command(args[0]).Valid() {
+				return fmt.Errorf("%s: %s", tterrors.UnknownCommandIPCError, args[0])
+			}
+			return nil
+		},
+		ValidArgs: guiCommandNames(),
+		RunE:      showGUI,
+	}
+)
+
+func showGUI(_ *cobra.Command, args []string) error {
+	log := logger.GetLogger("showGUI")
+	command := ipc.CommandShow
+	if len(args) > 0 {
+		command = ipc.Command(args[0])
+	}
+	err := ipc.Show(command)
+	if err != nil {
+		cli.PrintAndLogError(log, err, tterrors.LaunchGUIError)
+		return err
+	}
+	return nil
+}
+
+// guiCommandNames returns the names of the commands that the GUI accepts
+func guiCommandNames() []string {
+	names := make([]string, len(ipc.AllCommands))
+	for idx, command := range ipc.AllCommands {
+		names[idx] = string(command)
+	}
+	return names
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/alexeyco/simpletable"
+	"github.com/fatih/color"
+	"github.com/neflyte/timetracker/lib/config"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/models"
+	"github.com/neflyte/timetracker/lib/ui/cli"
+	"github.com/spf13/cobra"
+)
+
+var (
+	historyCmd = &cobra.Command{
+		Use:   "history [task|timesheet] [id]",
+		Short: "Show the history of a task or timesheet",
+		Long: "Shows each change to a task or timesheet with the old and new values, when it was made, and the app " +
+			"that made it (cli, gui, tray or api). A task can also be specified by its synopsis.",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeHistory,
+		RunE:              history,
+	}
+)
+
+func history(_ *cobra.Command, args []string) error {
+	log := logger.GetLogger("history")
+	recordType := args[0]
+	recordID, err := resolveHistoryRecord(recordType, args[1])
+	if err != nil {
+		cli.PrintAndLogError(log, err, tterrors.LoadHistoryError)
+		return err
+	}
+	changes, err := models.NewAudit().History(recordType, recordID)
+	if err != nil {
+		cli.PrintAndLogError(log, err, tterrors.LoadHistoryError)
+		return err
+	}
+	if len(changes) == 0 {
+		fmt.Println(color.YellowString("No history for %s %d", recordType, recordID)) // i18n
+		return nil
+	}
+	table := simpletable.New()
+	table.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Text: "Time"},
+			{Text: "Source"},
+			{Text: "Action"},
+			{Text: "Field"},
+			{Text: "Old Value"},
+			{Text: "New Value"},
+		},
+	}
+	for _, change := range changes {
+		rec := []*simpletable.Cell{
+			{Text: change.CreatedAt.Format(config.TimestampFormat())},
+			{Text: change.Source},
+			{Text: change.Action},
+			{Text: change.Field},
+			{Text: historyValue(change.OldValue.String)},
+			{Text: historyValue(change.NewValue.String)},
+		}
+		table.Body.Cells = append(table.Body.Cells, rec)
+	}
+	table.SetStyle(simpletable.StyleCompactLite)
+	fmt.Println(table.String())
+	return nil
+}
+
+// completeHistory completes the record type, and then the tasks or the timesheets
+func completeHistory(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return []string{models.AuditRecordTask, models.AuditRecordTimesheet}, cobra.ShellCompDirectiveNoFileComp
+	}
+	switch args[0] {
+	case models.AuditRecordTask:
+		return cli.CompleteTasks(cmd, args[1:], toComplete)
+	case models.AuditRecordTimesheet:
+		return cli.CompleteTimesheetIDs(cmd, args[1:], toComplete)
+	default:
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// resolveHistoryRecord returns the ID of the record in the argument; a task may also be specified by its synopsis
+func resolveHistoryRecord(recordType string, arg string) (uint, error) {
+	recordID, err := strconv.ParseUint(arg, 10, 0)
+	if err == nil || recordType != models.AuditRecordTask {
+		return uint(recordID), err
+	}
+	task, err := cli.ResolveTask(arg)
+	if err != nil {
+		return 0, err
+	}
+	return task.Data().ID, nil
+}
+
+// historyValue formats the times of a change with the timestamp format
+func historyValue(value string) string {
+	timestamp, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value
+	}
+	return timestamp.Local().Format(config.TimestampFormat())
+}
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"github.com/neflyte/timetracker/cmd/timetracker/cmd/profile"
+	"github.com/spf13/cobra"
+)
+
+var (
+	profileCmd = &cobra.Command{
+		Use:     "profile",
+		Aliases: []string{"profiles", "p"},
+		Short:   "Profile operations",
+		Long:    "Manage named profiles that each use a separate database",
+	}
+)
+
+func init() {
+	profileCmd.AddCommand(
+		profile.ListCmd,
+		profile.UseCmd,
+		profile.CreateCmd,
+	)
+}
@@ -0,0 +1,51 @@
+package profile
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/profiles"
+	"github.com/neflyte/timetracker/lib/ui/cli"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// CreateCmd represents the command to create a profile
+	CreateCmd = &cobra.Command{
+		Use:     "create [name]",
+		Aliases: []string{"c", "add"},
+		Short:   "Create a profile",
+		Long:    "Create a named profile with its own database; by default the database is created in the user config directory",
+		Args:    cobra.ExactArgs(1),
+		RunE:    createProfile,
+	}
+	profileDatabase string
+	useNewProfile   bool
+)
+
+func init() {
+	CreateCmd.Flags().StringVarP(&profileDatabase, "database", "d", "", "The full path and filename of the database to use")
+	CreateCmd.Flags().BoolVarP(&useNewProfile, "use", "u", false, "Select the profile after creating it")
+}
+
+func createProfile(_ *cobra.Command, args []string) error {
+	log := logger.GetLogger("createProfile")
+	profile, err := profiles.Create(args[0], profileDatabase)
+	if err != nil {
+		cli.PrintAndLogError(log, err, tterrors.CreateProfileError)
+		return err
+	}
+	fmt.Println(color.WhiteString("Profile %s", profile.Name), color.GreenString("created")) // i18n
+	if !useNewProfile {
+		return nil
+	}
+	err = profiles.Use(profile.Name)
+	if err != nil {
+		cli.PrintAndLogError(log, err, tterrors.UseProfileError)
+		return err
+	}
+	fmt.Println(color.WhiteString("Profile %s", profile.Name), color.GreenString("selected")) // i18n
+	return nil
+}
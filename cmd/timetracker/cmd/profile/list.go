@@ -0,0 +1,64 @@
+package profile
+
+import (
+	"fmt"
+
+	"github.com/alexeyco/simpletable"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/profiles"
+	"github.com/neflyte/timetracker/lib/ui/cli"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// ListCmd represents the command to list profiles
+	ListCmd = &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"l", "ls"},
+		Short:   "List profiles",
+		Long:    "List profiles; the selected profile is marked with *",
+		RunE:    listProfiles,
+	}
+)
+
+func listProfiles(_ *cobra.Command, _ []string) error {
+	log := logger.GetLogger("listProfiles")
+	profileList, err := profiles.List()
+	if err != nil {
+		cli.PrintAndLogError(log, err, tterrors.ListProfileError)
+		return err
+	}
+	current, err := profiles.Current()
+	if err != nil {
+		cli.PrintAndLogError(log, err, tterrors.ListProfileError)
+		return err
+	}
+	table := simpletable.New()
+	table.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Text: ""},
+			{Text: "Name"},
+			{Text: "Database"},
+		},
+	}
+	for _, profile := range profileList {
+		selected := ""
+		if profile.Name == current.Name {
+			selected = "*"
+		}
+		databaseFile := profile.Database
+		if profile.IsDefault() {
+			databaseFile = "(default)" // i18n
+		}
+		rec := []*simpletable.Cell{
+			{Text: selected},
+			{Text: profile.Name},
+			{Text: databaseFile},
+		}
+		table.Body.Cells = append(table.Body.Cells, rec)
+	}
+	table.SetStyle(simpletable.StyleCompactLite)
+	fmt.Println(table.String())
+	return nil
+}
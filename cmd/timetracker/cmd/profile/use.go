@@ -0,0 +1,35 @@
+package profile
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/profiles"
+	"github.com/neflyte/timetracker/lib/ui/cli"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// UseCmd represents the command to select a profile
+	UseCmd = &cobra.Command{
+		Use:     "use [name]",
+		Aliases: []string{"u", "switch"},
+		Short:   "Select a profile",
+		Long:    "Select the profile used by the CLI, tray and GUI when they are not started with --profile; a running tray and GUI switch to it straight away",
+		Args:    cobra.ExactArgs(1),
+		RunE:    useProfile,
+	}
+)
+
+func useProfile(_ *cobra.Command, args []string) error {
+	log := logger.GetLogger("useProfile")
+	err := profiles.Use(args[0])
+	if err != nil {
+		cli.PrintAndLogError(log, err, tterrors.UseProfileError)
+		return err
+	}
+	fmt.Println(color.WhiteString("Profile %s", profiles.NormalizeName(args[0])), color.GreenString("selected")) // i18n
+	return nil
+}
@@ -19,16 +19,18 @@ var (
 		PersistentPostRun: cleanUp,
 	}
 	configFileName string
+	profileName    string
 	logLevel       string
 	consoleLogging bool
 )
 
 func init() {
 	cobra.OnInitialize(initialize)
-	rootCmd.PersistentFlags().StringVarP(&configFileName, "config", "c", "", "Specify the full path and filename of the database to use")
-	rootCmd.PersistentFlags().StringVarP(&logLevel, "logLevel", "l", "info", "Specify the logging level")
-	rootCmd.PersistentFlags().BoolVar(&consoleLogging, "console", false, "Log messages to the console as well as the log file")
-	rootCmd.AddCommand(taskCmd, timesheetCmd, statusCmd)
+	rootCmd.PersistentFlags().StringVarP(&configFileName, startup.FlagDatabase, "c", "", "Specify the full path and filename of the database to use")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "Specify the profile whose database is used instead of the selected profile")
+	rootCmd.PersistentFlags().StringVarP(&logLevel, startup.FlagLogLevel, "l", "info", "Specify the logging level")
+	rootCmd.PersistentFlags().BoolVar(&consoleLogging, startup.FlagConsole, false, "Log messages to the console as well as the log file")
+	rootCmd.AddCommand(taskCmd, timesheetCmd, statusCmd, webhooksCmd, serveCmd, guiCmd, profileCmd, configCmd, completionCmd, tuiCmd, undoCmd, redoCmd, historyCmd, syncCmd, backupCmd)
 	rootCmd.SetVersionTemplate(fmt.Sprintf("timetracker %s\n", AppVersion))
 }
 
@@ -44,8 +46,10 @@ func Execute() {
 func initialize() {
 	startup.SetLogLevel(logLevel)
 	startup.SetConsole(consoleLogging)
-	startup.InitLogger()
 	startup.SetDatabaseFileName(configFileName)
+	startup.SetProfileName(profileName)
+	startup.LoadConfig(rootCmd.PersistentFlags().Changed)
+	startup.InitLogger()
 	startup.InitDatabase()
 }
 
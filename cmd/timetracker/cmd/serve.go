@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/neflyte/timetracker/lib/api"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/models"
+	"github.com/neflyte/timetracker/lib/ui/cli"
+	"github.com/spf13/cobra"
+)
+
+const (
+	serveShutdownTimeout = 5 * time.Second
+)
+
+var (
+	serveCmd = &cobra.Command{
+		Use:   "serve",
+		Short: "Serve a REST API",
+		Long: fmt.Sprintf(
+			"Serve a JSON REST API for tasks, timesheets, status and reports. Clients authenticate with the API token "+
+				"as a Bearer token or the access_token query parameter. The token is read from the --token flag or the "+
+				"%s environment variable; if neither is set, a new token is generated and printed.",
+			api.TokenEnvironmentVariable,
+		),
+		Args: cobra.ExactArgs(0),
+		RunE: serve,
+	}
+	serveListenAddress string
+	serveToken         string
+)
+
+func init() {
+	serveCmd.Flags().StringVar(&serveListenAddress, "listen", api.DefaultListenAddress, "the address and port to listen on")
+	serveCmd.Flags().StringVar(&serveToken, "token", "", "the token that clients must supply")
+}
+
+func serve(cmd *cobra.Command, _ []string) error {
+	log := logger.GetLogger("serve")
+	token := serveToken
+	if !cmd.Flags().Changed("token") {
+		// The environment variable is not the flag default so that the token is not printed by --help
+		token = os.Getenv(api.TokenEnvironmentVariable)
+	}
+	if token == "" {
+		generatedToken, err := api.GenerateToken()
+		if err != nil {
+			cli.PrintAndLogError(log, err, tterrors.StartAPIServerError)
+			return err
+		}
+		token = generatedToken
+		fmt.Println(color.WhiteString("API token:"), color.CyanString(token)) // i18n
+	}
+	// Changes made through the API are audited as such
+	models.SetAuditSource(models.AuditSourceAPI)
+	server := api.NewServer(serveListenAddress, token)
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signalChan)
+	go func() {
+		<-signalChan
+		log.Info().
+			Msg("received signal; shutting down API server")
+		ctx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+		defer cancel()
+		shutdownErr := server.Shutdown(ctx)
+		if shutdownErr != nil {
+			log.Err(shutdownErr).
+				Msg("error shutting down API server")
+		}
+	}()
+	fmt.Println(color.WhiteString("Serving API at"), color.GreenString("http://%s%s", serveListenAddress, api.PathPrefix)) // i18n
+	err := server.ListenAndServe()
+	if err != nil {
+		cli.PrintAndLogError(log, err, tterrors.StartAPIServerError)
+		return err
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/fatih/color"
+	"github.com/neflyte/timetracker/lib/config"
 	"github.com/neflyte/timetracker/lib/constants"
 	"github.com/neflyte/timetracker/lib/logger"
 	"github.com/neflyte/timetracker/lib/models"
@@ -79,7 +80,7 @@ func status(_ *cobra.Command, _ []string) error { //nolint:cyclop
 			sb.WriteString(" " + synString)
 		}
 		if verbose {
-			timeSince := time.Since(timesheet.StartTime).Truncate(time.Second).String()
+			timeSince := config.FormatDuration(time.Since(timesheet.StartTime).Truncate(time.Second))
 			if !noColour {
 				timeSince = color.HiBlueString(timeSince)
 			}
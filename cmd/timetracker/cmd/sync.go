@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"github.com/neflyte/timetracker/cmd/timetracker/cmd/sync"
+	"github.com/spf13/cobra"
+)
+
+var (
+	syncCmd = &cobra.Command{
+		Use:   "sync",
+		Short: "Sync operations",
+		Long: "Exchange changes with other databases through a shared folder, such as a folder synced by a file " +
+			"sharing service. Each database writes its own bundle of changes and reads the bundles of the others.",
+	}
+)
+
+func init() {
+	syncCmd.AddCommand(
+		sync.ExportCmd,
+		sync.ImportCmd,
+	)
+}
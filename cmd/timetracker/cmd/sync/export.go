@@ -0,0 +1,45 @@
+package sync
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/replication"
+	"github.com/neflyte/timetracker/lib/ui/cli"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// ExportCmd represents the command to export the changes of this database to the sync folder
+	ExportCmd = &cobra.Command{
+		Use:     "export",
+		Aliases: []string{"e", "push"},
+		Short:   "Export changes to the sync folder",
+		Long:    "Write the changes made in this database to its bundle in the sync folder",
+		Args:    cobra.NoArgs,
+		RunE:    exportChanges,
+	}
+	exportFolder string
+)
+
+func init() {
+	addFolderFlag(ExportCmd, &exportFolder)
+}
+
+func exportChanges(_ *cobra.Command, _ []string) error {
+	log := logger.GetLogger("exportChanges")
+	folder, err := syncFolder(exportFolder)
+	if err != nil {
+		cli.PrintAndLogError(log, err, tterrors.ExportChangesError)
+		return err
+	}
+	result, err := replication.Export(folder)
+	if err != nil {
+		cli.PrintAndLogError(log, err, tterrors.ExportChangesError)
+		return err
+	}
+	fmt.Println(color.GreenString("Exported %d changes", result.Changes), color.WhiteString("to %s", result.FileName)) // i18n
+	return nil
+}
@@ -0,0 +1,49 @@
+package sync
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/replication"
+	"github.com/neflyte/timetracker/lib/ui/cli"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// ImportCmd represents the command to import the changes of other databases from the sync folder
+	ImportCmd = &cobra.Command{
+		Use:     "import",
+		Aliases: []string{"i", "pull"},
+		Short:   "Import changes from the sync folder",
+		Long: "Read the bundles of the other databases in the sync folder and apply their changes. The latest change " +
+			"to each field wins, and tasks with the same synopsis are merged.",
+		Args: cobra.NoArgs,
+		RunE: importChanges,
+	}
+	importFolder string
+)
+
+func init() {
+	addFolderFlag(ImportCmd, &importFolder)
+}
+
+func importChanges(_ *cobra.Command, _ []string) error {
+	log := logger.GetLogger("importChanges")
+	folder, err := syncFolder(importFolder)
+	if err != nil {
+		cli.PrintAndLogError(log, err, tterrors.ImportChangesError)
+		return err
+	}
+	result, err := replication.Import(folder)
+	if err != nil {
+		cli.PrintAndLogError(log, err, tterrors.ImportChangesError)
+		return err
+	}
+	for _, message := range result.Messages {
+		fmt.Println(message)
+	}
+	fmt.Println(color.GreenString("Received %d changes from %d databases, %d new", result.Received, result.Bundles, result.New)) // i18n
+	return nil
+}
@@ -0,0 +1,25 @@
+package sync
+
+import (
+	"errors"
+
+	"github.com/neflyte/timetracker/lib/config"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/spf13/cobra"
+)
+
+// addFolderFlag adds the flag of the sync folder to a command
+func addFolderFlag(cmd *cobra.Command, folder *string) {
+	cmd.Flags().StringVarP(folder, "folder", "f", "", "The shared folder to exchange changes through; default is the sync.folder setting")
+}
+
+// syncFolder returns the folder in the flag, or the sync.folder setting if the flag is empty
+func syncFolder(folder string) (string, error) {
+	if folder == "" {
+		folder = config.GetString(config.KeySyncFolder)
+	}
+	if folder == "" {
+		return "", errors.New(tterrors.NoSyncFolderError)
+	}
+	return folder, nil
+}
@@ -23,5 +23,7 @@ func init() {
 		task.StartCmd,
 		task.StopCmd,
 		task.SearchCmd,
+		task.PinCmd,
+		task.UnpinCmd,
 	)
 }
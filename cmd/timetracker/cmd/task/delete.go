@@ -14,11 +14,12 @@ import (
 var (
 	// DeleteCmd represents the command to delete a task
 	DeleteCmd = &cobra.Command{
-		Use:     "delete [task id]",
-		Aliases: []string{"d", "rm"},
-		Short:   "Mark a task as deleted",
-		Args:    cobra.ExactArgs(1),
-		RunE:    deleteTask,
+		Use:               "delete [task id]",
+		Aliases:           []string{"d", "rm"},
+		Short:             "Mark a task as deleted",
+		Args:              cobra.ExactArgs(1),
+		RunE:              deleteTask,
+		ValidArgsFunction: cli.CompleteTasks,
 	}
 )
 
@@ -5,7 +5,7 @@ import (
 	"strconv"
 
 	"github.com/alexeyco/simpletable"
-	"github.com/neflyte/timetracker/lib/constants"
+	"github.com/neflyte/timetracker/lib/config"
 	"github.com/neflyte/timetracker/lib/errors"
 	"github.com/neflyte/timetracker/lib/logger"
 	"github.com/neflyte/timetracker/lib/models"
@@ -22,15 +22,35 @@ var (
 		RunE:    listTasks,
 	}
 	listDeletedTasks = false
+	listPinnedTasks  = false
+	listSort         string
 )
 
 func init() {
 	ListCmd.Flags().BoolVarP(&listDeletedTasks, "deleted", "d", false, "Include deleted tasks")
+	ListCmd.Flags().BoolVarP(&listPinnedTasks, "pinned", "p", false, "Only list tasks that are pinned as favorites")
+	ListCmd.Flags().StringVar(&listSort, "sort", "", "Sort the tasks (synopsis, synopsis-desc, recent, total-time, created)")
+	cobra.CheckErr(ListCmd.RegisterFlagCompletionFunc("sort", cli.CompleteTaskSorts))
 }
 
 func listTasks(_ *cobra.Command, _ []string) error {
 	log := logger.GetLogger("listTasks")
-	tasks, err := models.NewTask().LoadAll(listDeletedTasks)
+	taskSort, err := models.ParseTaskSort(listSort)
+	if err != nil {
+		cli.PrintAndLogError(log, err, errors.ListTaskError)
+		return err
+	}
+	var tasks []models.TaskData
+	if listPinnedTasks {
+		tasks, err = models.NewTask().LoadPinned()
+	} else {
+		tasks, err = models.NewTask().LoadAll(listDeletedTasks)
+	}
+	if err != nil {
+		cli.PrintAndLogError(log, err, errors.ListTaskError)
+		return err
+	}
+	err = models.SortTasks(tasks, taskSort)
 	if err != nil {
 		cli.PrintAndLogError(log, err, errors.ListTaskError)
 		return err
@@ -41,6 +61,7 @@ func listTasks(_ *cobra.Command, _ []string) error {
 			{Text: "ID"},
 			{Text: "Synopsis"},
 			{Text: "Description"},
+			{Text: "Pinned"},
 			{Text: "Created At"},
 			{Text: "Updated At"},
 		},
@@ -50,8 +71,9 @@ func listTasks(_ *cobra.Command, _ []string) error {
 			{Text: strconv.Itoa(int(task.ID))},
 			{Text: task.Synopsis},
 			{Text: task.Description},
-			{Text: task.CreatedAt.Format(constants.TimestampLayout)},
-			{Text: task.UpdatedAt.Format(constants.TimestampLayout)},
+			{Text: pinnedDisplay(task.Pinned)},
+			{Text: task.CreatedAt.Format(config.TimestampFormat())},
+			{Text: task.UpdatedAt.Format(config.TimestampFormat())},
 		}
 		table.Body.Cells = append(table.Body.Cells, rec)
 	}
@@ -59,3 +81,11 @@ func listTasks(_ *cobra.Command, _ []string) error {
 	fmt.Println(table.String())
 	return nil
 }
+
+// pinnedDisplay returns the text of the Pinned column
+func pinnedDisplay(pinned bool) string {
+	if pinned {
+		return "yes" // i18n
+	}
+	return ""
+}
@@ -0,0 +1,58 @@
+package task
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/ui/cli"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// PinCmd represents the command to pin a task as a favorite
+	PinCmd = &cobra.Command{
+		Use:               "pin [task id/synopsis]",
+		Short:             "Pin a task to the tray's Favorites menu",
+		Args:              cobra.ExactArgs(1),
+		RunE:              pinTask,
+		ValidArgsFunction: cli.CompleteTasks,
+	}
+	// UnpinCmd represents the command to remove a task from the favorites
+	UnpinCmd = &cobra.Command{
+		Use:               "unpin [task id/synopsis]",
+		Short:             "Remove a task from the tray's Favorites menu",
+		Args:              cobra.ExactArgs(1),
+		RunE:              unpinTask,
+		ValidArgsFunction: cli.CompleteTasks,
+	}
+)
+
+func pinTask(_ *cobra.Command, args []string) error {
+	return setTaskPinned(args[0], true)
+}
+
+func unpinTask(_ *cobra.Command, args []string) error {
+	return setTaskPinned(args[0], false)
+}
+
+func setTaskPinned(arg string, pinned bool) error {
+	log := logger.GetLogger("setTaskPinned")
+	task, err := cli.ResolveTask(arg)
+	if err != nil {
+		cli.PrintAndLogError(log, err, tterrors.LoadTaskError)
+		return err
+	}
+	err = task.SetPinned(pinned)
+	if err != nil {
+		cli.PrintAndLogError(log, err, tterrors.PinTaskError)
+		return err
+	}
+	if pinned {
+		fmt.Println(color.WhiteString("Task %s", task.Data().Synopsis), color.GreenString("pinned")) // i18n
+		return nil
+	}
+	fmt.Println(color.WhiteString("Task %s", task.Data().Synopsis), color.YellowString("unpinned")) // i18n
+	return nil
+}
@@ -1,12 +1,13 @@
 package task
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 
 	"github.com/alexeyco/simpletable"
-	"github.com/neflyte/timetracker/lib/constants"
-	"github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/config"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
 	"github.com/neflyte/timetracker/lib/logger"
 	"github.com/neflyte/timetracker/lib/models"
 	"github.com/neflyte/timetracker/lib/ui/cli"
@@ -18,17 +19,65 @@ var (
 	SearchCmd = &cobra.Command{
 		Use:     "search [search terms]",
 		Aliases: []string{"find"},
-		Short:   "Search for tasks",
-		Args:    cobra.ExactArgs(1),
-		RunE:    searchTask,
+		Short:   "Search for tasks, best match first",
+		Long: `Search the synopsis and description of tasks, best match first.
+
+The search supports phrases ("code review"), prefixes (rev*), boolean operators
+(docs OR review, docs NOT api) and columns (synopsis:docs). Words are matched by
+their stem, so "review" also finds "reviewing". The full-text matches are
+followed by the fuzzy matches, where the letters only have to appear in order,
+so "rvw" finds "review".`,
+		Args: cobra.ExactArgs(1),
+		RunE: searchTask,
 	}
+	searchSort string
 )
 
+func init() {
+	SearchCmd.Flags().StringVar(&searchSort, "sort", "", "Sort the tasks (synopsis, synopsis-desc, recent, total-time, created); best match first by default")
+	cobra.CheckErr(SearchCmd.RegisterFlagCompletionFunc("sort", cli.CompleteTaskSorts))
+}
+
 func searchTask(_ *cobra.Command, args []string) error {
 	log := logger.GetLogger("searchTask")
-	tasks, err := models.NewTask().Search(args[0])
+	taskSort, err := models.ParseTaskSort(searchSort)
+	if err != nil {
+		cli.PrintAndLogError(log, err, tterrors.SearchTaskError)
+		return err
+	}
+	results, err := models.NewTask().FullTextSearch(args[0])
+	if err != nil && !errors.Is(err, tterrors.ErrSearchIndexUnavailable{}) && !errors.As(err, new(tterrors.ErrInvalidSearchQuery)) {
+		cli.PrintAndLogError(log, err, tterrors.SearchTaskError)
+		return err
+	}
+	if err != nil {
+		log.Debug().
+			Err(err).
+			Msg("full-text search is unavailable; using fuzzy search only")
+	}
+	tasks, err := models.NewTask().FuzzySearch(args[0])
+	if err != nil {
+		cli.PrintAndLogError(log, err, tterrors.SearchTaskError)
+		return err
+	}
+	// The fuzzy matches that full-text search did not find follow its results
+	found := make(map[uint]bool, len(results))
+	for _, result := range results {
+		found[result.Task.ID] = true
+	}
+	for idx := range tasks {
+		if found[tasks[idx].ID] {
+			continue
+		}
+		results = append(results, models.TaskSearchResult{
+			Task:     tasks[idx],
+			Synopsis: tasks[idx].Synopsis,
+			Snippet:  tasks[idx].Description,
+		})
+	}
+	results, err = sortSearchResults(results, taskSort)
 	if err != nil {
-		cli.PrintAndLogError(log, err, errors.SearchTaskError)
+		cli.PrintAndLogError(log, err, tterrors.SearchTaskError)
 		return err
 	}
 	table := simpletable.New()
@@ -41,13 +90,13 @@ func searchTask(_ *cobra.Command, args []string) error {
 			{Text: "Updated At"},
 		},
 	}
-	for _, task := range tasks {
+	for _, result := range results {
 		rec := []*simpletable.Cell{
-			{Text: strconv.Itoa(int(task.ID))},
-			{Text: task.Synopsis},
-			{Text: task.Description},
-			{Text: task.CreatedAt.Format(constants.TimestampLayout)},
-			{Text: task.UpdatedAt.Format(constants.TimestampLayout)},
+			{Text: strconv.Itoa(int(result.Task.ID))},
+			{Text: cli.Highlight(result.Synopsis)},
+			{Text: cli.Highlight(result.Snippet)},
+			{Text: result.Task.CreatedAt.Format(config.TimestampFormat())},
+			{Text: result.Task.UpdatedAt.Format(config.TimestampFormat())},
 		}
 		table.Body.Cells = append(table.Body.Cells, rec)
 	}
@@ -55,3 +104,22 @@ func searchTask(_ *cobra.Command, args []string) error {
 	fmt.Println(table.String())
 	return nil
 }
+
+// sortSearchResults sorts the search results by their task
+func sortSearchResults(results []models.TaskSearchResult, taskSort models.TaskSort) ([]models.TaskSearchResult, error) {
+	tasks := make([]models.TaskData, len(results))
+	resultsByID := make(map[uint]models.TaskSearchResult, len(results))
+	for idx, result := range results {
+		tasks[idx] = result.Task
+		resultsByID[result.Task.ID] = result
+	}
+	err := models.SortTasks(tasks, taskSort)
+	if err != nil {
+		return nil, err
+	}
+	sorted := make([]models.TaskSearchResult, len(tasks))
+	for idx, task := range tasks {
+		sorted[idx] = resultsByID[task.ID]
+	}
+	return sorted, nil
+}
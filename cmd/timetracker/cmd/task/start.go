@@ -5,7 +5,7 @@ import (
 	"time"
 
 	"github.com/fatih/color"
-	"github.com/neflyte/timetracker/lib/constants"
+	"github.com/neflyte/timetracker/lib/config"
 	tterrors "github.com/neflyte/timetracker/lib/errors"
 	"github.com/neflyte/timetracker/lib/logger"
 	"github.com/neflyte/timetracker/lib/models"
@@ -16,20 +16,24 @@ import (
 var (
 	// StartCmd represents the command to start a task
 	StartCmd = &cobra.Command{
-		Use:     "start [task id/synopsis]",
-		Aliases: []string{"s"},
-		Short:   "Start a task",
-		Args:    cobra.ExactArgs(1),
-		RunE:    startTask,
+		Use:               "start [task id/synopsis]",
+		Aliases:           []string{"s"},
+		Short:             "Start a task",
+		Args:              cobra.ExactArgs(1),
+		RunE:              startTask,
+		ValidArgsFunction: cli.CompleteTasks,
 	}
+	startNote string
 )
 
+func init() {
+	StartCmd.Flags().StringVarP(&startNote, "note", "n", "", "A note about the work to be done; notes can be found with timesheet search")
+}
+
 func startTask(_ *cobra.Command, args []string) (err error) {
 	log := logger.GetLogger("startTask")
-	taskData := models.NewTask()
-	taskData.Data().ID, taskData.Data().Synopsis = taskData.Resolve(args[0])
 	// Load the task to make sure it exists
-	err = taskData.Load(false)
+	taskData, err := cli.ResolveTask(args[0])
 	if err != nil {
 		cli.PrintAndLogError(log, err, tterrors.LoadTaskError)
 		return err
@@ -48,6 +52,7 @@ func startTask(_ *cobra.Command, args []string) (err error) {
 	timesheet := models.NewTimesheet()
 	timesheet.Data().Task = *taskData.Data()
 	timesheet.Data().StartTime = time.Now()
+	timesheet.Data().Note = startNote
 	err = timesheet.Create()
 	if err != nil {
 		cli.PrintAndLogError(log, err, "%s for task %s", tterrors.CreateTimesheetError, taskdisplay)
@@ -58,7 +63,7 @@ func startTask(_ *cobra.Command, args []string) (err error) {
 		color.CyanString(taskData.Data().Synopsis),
 		color.MagentaString("(%s) ", taskData.Data().Description),
 		color.GreenString("started"),
-		color.WhiteString("at %s", timesheet.Data().StartTime.Format(constants.TimestampLayout)),
+		color.WhiteString("at %s", timesheet.Data().StartTime.Format(config.TimestampFormat())),
 	)
 	return nil
 }
@@ -14,11 +14,12 @@ import (
 var (
 	// UpdateCmd represents the command that updates an existing task
 	UpdateCmd = &cobra.Command{
-		Use:     "update [task id]",
-		Aliases: []string{"u"},
-		Short:   "Update task details",
-		Args:    cobra.ExactArgs(1),
-		RunE:    updateTask,
+		Use:               "update [task id]",
+		Aliases:           []string{"u"},
+		Short:             "Update task details",
+		Args:              cobra.ExactArgs(1),
+		RunE:              updateTask,
+		ValidArgsFunction: cli.CompleteTaskIDs,
 	}
 	updateSynopsis    string
 	updateDescription string
@@ -19,5 +19,6 @@ func init() {
 		timesheet.DumpCmd,
 		timesheet.LastStartedCmd,
 		timesheet.ReportCmd,
+		timesheet.SearchCmd,
 	)
 }
@@ -8,7 +8,7 @@ import (
 
 	"github.com/alexeyco/simpletable"
 	"github.com/fatih/color"
-	"github.com/neflyte/timetracker/lib/constants"
+	"github.com/neflyte/timetracker/lib/config"
 	ttErrors "github.com/neflyte/timetracker/lib/errors"
 	"github.com/neflyte/timetracker/lib/logger"
 	"github.com/neflyte/timetracker/lib/models"
@@ -30,9 +30,11 @@ var (
 )
 
 func init() {
-	DumpCmd.Flags().StringVar(&startDate, "startDate", "", "start date (YYYY-MM-DD)")
-	DumpCmd.Flags().StringVar(&endDate, "endDate", "", "end date (YYYY-MM-DD)")
+	DumpCmd.Flags().StringVar(&startDate, "startDate", "", "start date (YYYY-MM-DD or a keyword such as today or week-start)")
+	DumpCmd.Flags().StringVar(&endDate, "endDate", "", "end date (YYYY-MM-DD or a keyword such as today or week-end)")
 	DumpCmd.Flags().BoolVar(&withDeleted, "deleted", false, "include deleted timesheets")
+	cobra.CheckErr(DumpCmd.RegisterFlagCompletionFunc("startDate", cli.CompleteDates))
+	cobra.CheckErr(DumpCmd.RegisterFlagCompletionFunc("endDate", cli.CompleteDates))
 }
 
 func dumpTimesheets(_ *cobra.Command, _ []string) (err error) {
@@ -42,12 +44,12 @@ func dumpTimesheets(_ *cobra.Command, _ []string) (err error) {
 	}
 	var sheets []models.TimesheetData
 	var dStart, dEnd time.Time
-	dStart, err = time.Parse(constants.TimestampDateLayout, startDate)
+	dStart, err = cli.ParseDate(startDate)
 	if err != nil {
 		cli.PrintAndLogError(log, err, "error parsing %s as a start date", startDate)
 		return err
 	}
-	dEnd, err = time.Parse(constants.TimestampDateLayout, endDate)
+	dEnd, err = cli.ParseDate(endDate)
 	if err != nil {
 		cli.PrintAndLogError(log, err, "error parsing %s as an end date", endDate)
 		return err
@@ -75,12 +77,12 @@ func dumpTimesheets(_ *cobra.Command, _ []string) (err error) {
 		},
 	}
 	for _, sheet := range sheets {
-		starttimedisplay := sheet.StartTime.Format(constants.TimestampLayout)
+		starttimedisplay := sheet.StartTime.Format(config.TimestampFormat())
 		stoptimedisplay := "RUNNING"
 		durationdisplay := "(unknown)"
 		if sheet.StopTime.Valid {
-			stoptimedisplay = sheet.StopTime.Time.Format(constants.TimestampLayout)
-			durationdisplay = sheet.StopTime.Time.Sub(sheet.StartTime).Truncate(time.Second).String()
+			stoptimedisplay = sheet.StopTime.Time.Format(config.TimestampFormat())
+			durationdisplay = config.FormatDuration(sheet.StopTime.Time.Sub(sheet.StartTime).Truncate(time.Second))
 		}
 		rec := []*simpletable.Cell{
 			{Text: strconv.Itoa(int(sheet.ID))},
@@ -29,6 +29,7 @@ var (
 func init() {
 	LastStartedCmd.Flags().UintVar(&taskLimit, "limit", defaultTaskLimit, "the number of tasks to return; must be greater than zero")
 	LastStartedCmd.Flags().StringVar(&outputFormat, "outputFormat", outputFormatText, "output format (text, csv, json, xml; default text)")
+	cobra.CheckErr(LastStartedCmd.RegisterFlagCompletionFunc("outputFormat", completeOutputFormats))
 }
 
 func doLastStarted(_ *cobra.Command, _ []string) (err error) {
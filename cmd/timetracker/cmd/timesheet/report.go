@@ -1,8 +1,6 @@
 package timesheet
 
 import (
-	"encoding/csv"
-	"encoding/xml"
 	"errors"
 	"fmt"
 	"os"
@@ -10,6 +8,8 @@ import (
 	"time"
 
 	"github.com/alexeyco/simpletable"
+	"github.com/jinzhu/now"
+	"github.com/neflyte/timetracker/lib/config"
 	"github.com/neflyte/timetracker/lib/constants"
 	"github.com/neflyte/timetracker/lib/logger"
 	"github.com/neflyte/timetracker/lib/models"
@@ -33,7 +33,6 @@ var (
 		Short:   "Report on tasks completed in the specified time period",
 		RunE:    reportTimesheets,
 	}
-	csvTableHeader     = []string{"task_id", "synopsis", "started_on", "duration"}
 	reportStartDate    string
 	reportEndDate      string
 	exportCSVFile      string
@@ -41,28 +40,46 @@ var (
 )
 
 func init() {
-	ReportCmd.Flags().StringVar(&reportStartDate, "startDate", "", "start date (YYYY-MM-DD)")
-	ReportCmd.Flags().StringVar(&reportEndDate, "endDate", "", "end date (YYYY-MM-DD)")
-	ReportCmd.Flags().BoolVar(&withDeleted, "deleted", false, "include deleted timesheets")
+	ReportCmd.Flags().StringVar(&reportStartDate, "startDate", "", "start date (YYYY-MM-DD or a keyword such as today or week-start)")
+	ReportCmd.Flags().StringVar(&reportEndDate, "endDate", "", "end date (YYYY-MM-DD or a keyword such as today or week-end)")
+	ReportCmd.Flags().BoolVar(&withDeleted, "deleted", false, "include deleted timesheets (default from config)")
 	ReportCmd.Flags().StringVar(&exportCSVFile, "exportCSV", "", "file to export report in CSV format")
-	ReportCmd.Flags().StringVar(&reportOutputFormat, "outputFormat", outputFormatText, "output format (text, csv, json, xml; default text)")
+	ReportCmd.Flags().StringVar(&reportOutputFormat, "outputFormat", outputFormatText, "output format (text, csv, json, xml, markdown, html; default from config)")
+	cobra.CheckErr(ReportCmd.RegisterFlagCompletionFunc("startDate", cli.CompleteDates))
+	cobra.CheckErr(ReportCmd.RegisterFlagCompletionFunc("endDate", cli.CompleteDates))
+	cobra.CheckErr(ReportCmd.RegisterFlagCompletionFunc("outputFormat", completeReportOutputFormats))
 }
 
-func reportTimesheets(_ *cobra.Command, _ []string) (err error) {
+func reportTimesheets(cmd *cobra.Command, _ []string) (err error) {
 	log := logger.GetLogger("reportTimesheets")
-	if reportStartDate == "" || reportEndDate == "" {
-		return errors.New("both start date and end date must be specified")
+	if !cmd.Flags().Changed("deleted") {
+		withDeleted = config.GetBool(config.KeyReportDeleted)
 	}
-	var dStart, dEnd time.Time
-	dStart, err = time.Parse(constants.TimestampDateLayout, reportStartDate)
-	if err != nil {
-		cli.PrintAndLogError(log, err, "error parsing %s as the start date", reportStartDate)
-		return err
+	if !cmd.Flags().Changed("outputFormat") {
+		reportOutputFormat = config.GetString(config.KeyReportOutputFormat)
 	}
-	dEnd, err = time.Parse(constants.TimestampDateLayout, reportEndDate)
-	if err != nil {
-		cli.PrintAndLogError(log, err, "error parsing %s as the end date", reportEndDate)
-		return err
+	var dStart, dEnd time.Time
+	if reportStartDate == "" && reportEndDate == "" {
+		dStart, dEnd, err = defaultReportPeriod()
+		if err != nil {
+			return err
+		}
+		reportStartDate = dStart.Format(constants.TimestampDateLayout)
+		reportEndDate = dEnd.Format(constants.TimestampDateLayout)
+	} else {
+		if reportStartDate == "" || reportEndDate == "" {
+			return errors.New("both start date and end date must be specified")
+		}
+		dStart, err = cli.ParseDate(reportStartDate)
+		if err != nil {
+			cli.PrintAndLogError(log, err, "error parsing %s as the start date", reportStartDate)
+			return err
+		}
+		dEnd, err = cli.ParseDate(reportEndDate)
+		if err != nil {
+			cli.PrintAndLogError(log, err, "error parsing %s as the end date", reportEndDate)
+			return err
+		}
 	}
 	timesheet := models.NewTimesheet()
 	reportData, reportErr := timesheet.TaskReport(dStart, dEnd, withDeleted)
@@ -84,30 +101,44 @@ func reportTimesheets(_ *cobra.Command, _ []string) (err error) {
 	return nil
 }
 
+// defaultReportPeriod returns the start and end dates of the configured report period
+func defaultReportPeriod() (time.Time, time.Time, error) {
+	today := now.BeginningOfDay()
+	switch config.GetString(config.KeyReportPeriod) {
+	case config.ReportPeriodToday:
+		return today, today, nil
+	case config.ReportPeriodWeek:
+		return now.BeginningOfWeek(), now.EndOfWeek(), nil
+	case config.ReportPeriodMonth:
+		return now.BeginningOfMonth(), now.EndOfMonth(), nil
+	default:
+		return time.Time{}, time.Time{}, errors.New("both start date and end date must be specified")
+	}
+}
+
+// completeOutputFormats completes the outputFormat flag
+func completeOutputFormats(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	return []string{outputFormatText, outputFormatCSV, outputFormatJSON, outputFormatXML}, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeReportOutputFormats completes the outputFormat flag of the report command
+func completeReportOutputFormats(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	return append([]string{outputFormatText}, cli.ReportFormats...), cobra.ShellCompDirectiveNoFileComp
+}
+
 func printReport(reportData models.TaskReport, reportFormat string) {
 	log := logger.GetLogger("printReport")
 	// Output using requested format
-	switch reportFormat {
-	case outputFormatText:
+	if reportFormat == outputFormatText {
 		printReportTable(reportData)
-	case outputFormatCSV:
-		cli.PrintCSV(log, reportData)
-	case outputFormatJSON:
-		jsonData := struct {
-			TaskReport []models.TaskReportData `json:"Data"`
-		}{
-			TaskReport: reportData,
-		}
-		cli.PrintJSON(log, jsonData)
-	case outputFormatXML:
-		xmlData := struct {
-			XMLName    xml.Name                `xml:"TaskReport"`
-			TaskReport []models.TaskReportData `xml:"Data"`
-		}{
-			TaskReport: reportData,
-		}
-		cli.PrintXML(log, xmlData)
+		return
 	}
+	reportOut, err := cli.MarshalReport(reportData, reportFormat)
+	if err != nil {
+		log.Err(err).Msgf("unable to marshal report to %s", reportFormat)
+		return
+	}
+	fmt.Println(string(reportOut))
 }
 
 func printReportTable(reportData models.TaskReport) {
@@ -124,8 +155,8 @@ func printReportTable(reportData models.TaskReport) {
 		table.Body.Cells = append(table.Body.Cells, []*simpletable.Cell{
 			{Text: strconv.Itoa(int(reportDataEntry.TaskID))},
 			{Text: reportDataEntry.TaskSynopsis},
-			{Text: reportDataEntry.StartDate.Time.Format(constants.TimestampDateLayout)},
-			{Text: reportDataEntry.Duration().String()},
+			{Text: reportDataEntry.StartDate.Time.Format(config.DateFormat())},
+			{Text: config.FormatDuration(reportDataEntry.Duration())},
 		})
 	}
 	table.SetStyle(simpletable.StyleCompactLite)
@@ -134,33 +165,15 @@ func printReportTable(reportData models.TaskReport) {
 
 func exportToCSV(reportData models.TaskReport, exportFile string) error {
 	log := logger.GetLogger("exportToCSV")
-	outFile, fileErr := os.OpenFile(exportFile, os.O_CREATE|os.O_RDWR|os.O_TRUNC, exportFileMode)
-	if fileErr != nil {
-		cli.PrintAndLogError(log, fileErr, "error opening output file %s", exportFile)
-		return fileErr
-	}
-	defer func() {
-		closeErr := outFile.Close()
-		if closeErr != nil {
-			cli.PrintAndLogError(log, closeErr, "error closing output file %s", exportFile)
-		}
-	}()
-	csvOut := csv.NewWriter(outFile)
-	defer csvOut.Flush()
-	csvData := make([][]string, 0)
-	csvData = append(csvData, csvTableHeader)
-	for _, taskReportData := range reportData {
-		csvData = append(csvData, []string{
-			fmt.Sprintf("%d", taskReportData.TaskID),
-			taskReportData.TaskSynopsis,
-			taskReportData.StartDate.Time.Format(constants.TimestampDateLayout),
-			taskReportData.Duration().String(),
-		})
+	csvOut, err := cli.MarshalReport(reportData, cli.ReportFormatCSV)
+	if err != nil {
+		cli.PrintAndLogError(log, err, "error exporting data to CSV file %s", exportFile)
+		return err
 	}
-	writeErr := csvOut.WriteAll(csvData)
-	if writeErr != nil {
-		cli.PrintAndLogError(log, writeErr, "error exporting data to CSV file %s", exportFile)
-		return writeErr
+	err = os.WriteFile(exportFile, csvOut, exportFileMode)
+	if err != nil {
+		cli.PrintAndLogError(log, err, "error writing output file %s", exportFile)
+		return err
 	}
 	return nil
 }
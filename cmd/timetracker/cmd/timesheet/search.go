@@ -0,0 +1,87 @@
+package timesheet
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/alexeyco/simpletable"
+	"github.com/fatih/color"
+	"github.com/neflyte/timetracker/lib/config"
+	ttErrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/models"
+	"github.com/neflyte/timetracker/lib/ui/cli"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// SearchCmd represents the command that searches the notes of timesheets
+	SearchCmd = &cobra.Command{
+		Use:     "search [search terms]",
+		Aliases: []string{"find"},
+		Short:   "Search the notes of timesheets, best match first",
+		Long: `Search the notes of timesheets, best match first.
+
+The search supports phrases ("code review"), prefixes (rev*) and boolean
+operators (docs OR review, docs NOT api). Words are matched by their stem, so
+"review" also finds "reviewing".`,
+		Args: cobra.ExactArgs(1),
+		RunE: searchTimesheets,
+	}
+)
+
+func searchTimesheets(_ *cobra.Command, args []string) error {
+	log := logger.GetLogger("searchTimesheets")
+	results, err := models.NewTimesheet().FullTextSearch(args[0])
+	if errors.Is(err, ttErrors.ErrSearchIndexUnavailable{}) {
+		log.Debug().
+			Msg("no full-text search index; searching notes with LIKE")
+		var sheets []models.TimesheetData
+		sheets, err = models.NewTimesheet().SearchNotes(args[0])
+		results = make([]models.TimesheetSearchResult, len(sheets))
+		for idx := range sheets {
+			results[idx] = models.TimesheetSearchResult{
+				Timesheet: sheets[idx],
+				Snippet:   sheets[idx].Note,
+			}
+		}
+	}
+	if err != nil {
+		cli.PrintAndLogError(log, err, ttErrors.SearchTimesheetError)
+		return err
+	}
+	table := simpletable.New()
+	table.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Text: "Timesheet ID"},
+			{Text: "Synopsis"},
+			{Text: "Started At"},
+			{Text: "Duration"},
+			{Text: "Note"},
+		},
+	}
+	for _, result := range results {
+		sheet := result.Timesheet
+		durationdisplay := "RUNNING"
+		if sheet.StopTime.Valid {
+			durationdisplay = config.FormatDuration(sheet.StopTime.Time.Sub(sheet.StartTime).Truncate(time.Second))
+		}
+		rec := []*simpletable.Cell{
+			{Text: strconv.Itoa(int(sheet.ID))},
+			{Text: sheet.Task.Synopsis},
+			{Text: sheet.StartTime.Format(config.TimestampFormat())},
+			{Text: durationdisplay},
+			{Text: cli.Highlight(result.Snippet)},
+		}
+		table.Body.Cells = append(table.Body.Cells, rec)
+	}
+	if len(table.Body.Cells) == 0 {
+		fmt.Println(color.WhiteString("There are no matching timesheets")) // i18n
+		return nil
+	}
+	table.SetStyle(simpletable.StyleCompactLite)
+	fmt.Println(table.String())
+	return nil
+}
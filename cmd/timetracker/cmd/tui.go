@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/ui/cli"
+	"github.com/neflyte/timetracker/lib/ui/tui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	tuiCmd = &cobra.Command{
+		Use:   "tui",
+		Short: "Show the terminal UI",
+		Long:  "Show a full-screen terminal UI to filter and start tasks, see today's and this week's totals, and edit timesheets",
+		Args:  cobra.ExactArgs(0),
+		RunE:  runTUI,
+	}
+)
+
+func runTUI(_ *cobra.Command, _ []string) error {
+	log := logger.GetLogger("runTUI")
+	err := tui.Run()
+	if err != nil {
+		cli.PrintAndLogError(log, err, tterrors.RunTUIError)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/fatih/color"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/models"
+	"github.com/neflyte/timetracker/lib/ui/cli"
+	"github.com/spf13/cobra"
+)
+
+var (
+	undoCmd = &cobra.Command{
+		Use:   "undo",
+		Short: "Undo the last action",
+		Long:  "Undoes the last action that changed a task or timesheet, such as starting or stopping a task",
+		Args:  cobra.ExactArgs(0),
+		RunE:  undo,
+	}
+	redoCmd = &cobra.Command{
+		Use:   "redo",
+		Short: "Redo the last undone action",
+		Long:  "Redoes the action that was undone by the last undo; undone actions cannot be redone after another action",
+		Args:  cobra.ExactArgs(0),
+		RunE:  redo,
+	}
+)
+
+func undo(_ *cobra.Command, _ []string) error {
+	log := logger.GetLogger("undo")
+	entry, err := models.NewJournal().Undo()
+	if errors.Is(err, tterrors.ErrNothingToUndo{}) {
+		fmt.Println(color.YellowString(tterrors.NothingToUndoError))
+		return nil
+	}
+	if err != nil {
+		cli.PrintAndLogError(log, err, tterrors.UndoError)
+		return err
+	}
+	fmt.Println(color.GreenString("Undid:"), color.WhiteString(entry.Description)) // i18n
+	return nil
+}
+
+func redo(_ *cobra.Command, _ []string) error {
+	log := logger.GetLogger("redo")
+	entry, err := models.NewJournal().Redo()
+	if errors.Is(err, tterrors.ErrNothingToRedo{}) {
+		fmt.Println(color.YellowString(tterrors.NothingToRedoError))
+		return nil
+	}
+	if err != nil {
+		cli.PrintAndLogError(log, err, tterrors.RedoError)
+		return err
+	}
+	fmt.Println(color.GreenString("Redid:"), color.WhiteString(entry.Description)) // i18n
+	return nil
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"github.com/neflyte/timetracker/cmd/timetracker/cmd/webhooks"
+	"github.com/spf13/cobra"
+)
+
+var (
+	webhooksCmd = &cobra.Command{
+		Use:     "webhooks",
+		Aliases: []string{"webhook", "wh"},
+		Short:   "Webhook operations",
+		Long:    "Manage the endpoints that receive task events",
+	}
+)
+
+func init() {
+	webhooksCmd.AddCommand(
+		webhooks.AddCmd,
+		webhooks.ListCmd,
+		webhooks.RemoveCmd,
+		webhooks.TestCmd,
+		webhooks.FlushCmd,
+	)
+}
@@ -0,0 +1,56 @@
+package webhooks
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/models"
+	"github.com/neflyte/timetracker/lib/ui/cli"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// AddCmd represents the command to add a webhook endpoint
+	AddCmd = &cobra.Command{
+		Use:     "add [url]",
+		Aliases: []string{"a", "create"},
+		Short:   "Add a webhook endpoint",
+		Long:    "Add an endpoint that receives a signed JSON payload whenever a task is created, started, stopped or deleted",
+		Args:    cobra.ExactArgs(1),
+		RunE:    addWebhook,
+	}
+	webhookSecret string
+	webhookEvents []string
+)
+
+func init() {
+	AddCmd.Flags().StringVarP(&webhookSecret, "secret", "s", "", "The secret used to sign payloads with HMAC-SHA256")
+	AddCmd.Flags().StringSliceVarP(&webhookEvents, "events", "e", []string{}, fmt.Sprintf("The events to send; default is all events (%s)", eventTypeList()))
+}
+
+func addWebhook(_ *cobra.Command, args []string) error {
+	log := logger.GetLogger("addWebhook")
+	webhook := models.NewWebhook()
+	webhook.Data().URL = args[0]
+	webhook.Data().Secret = webhookSecret
+	webhook.Data().Events = strings.Join(webhookEvents, ",")
+	err := webhook.Create()
+	if err != nil {
+		cli.PrintAndLogError(log, err, tterrors.CreateWebhookError)
+		return err
+	}
+	fmt.Println(color.WhiteString("Webhook ID %d", webhook.Data().ID), color.GreenString("created")) // i18n
+	return nil
+}
+
+// eventTypeList returns a comma-separated list of the event types that a webhook can receive
+func eventTypeList() string {
+	eventTypes := make([]string, len(models.AllEventTypes))
+	for idx, eventType := range models.AllEventTypes {
+		eventTypes[idx] = string(eventType)
+	}
+	return strings.Join(eventTypes, ", ")
+}
@@ -0,0 +1,33 @@
+package webhooks
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/ui/cli"
+	"github.com/neflyte/timetracker/lib/webhooks"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// FlushCmd represents the command to deliver queued webhook events
+	FlushCmd = &cobra.Command{
+		Use:     "flush",
+		Aliases: []string{"f", "retry"},
+		Short:   "Deliver queued webhook events that are due for a retry",
+		RunE:    flushWebhooks,
+	}
+)
+
+func flushWebhooks(_ *cobra.Command, _ []string) error {
+	log := logger.GetLogger("flushWebhooks")
+	delivered, failed, err := webhooks.DeliverPending()
+	if err != nil {
+		cli.PrintAndLogError(log, err, tterrors.DeliverWebhookError)
+		return err
+	}
+	fmt.Println(color.WhiteString("Delivered %d event(s);", delivered), color.RedString("%d failed", failed)) // i18n
+	return nil
+}
@@ -0,0 +1,72 @@
+package webhooks
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/alexeyco/simpletable"
+	"github.com/neflyte/timetracker/lib/config"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/models"
+	"github.com/neflyte/timetracker/lib/ui/cli"
+	"github.com/neflyte/timetracker/lib/webhooks"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// ListCmd represents the command to list webhook endpoints
+	ListCmd = &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"l", "ls"},
+		Short:   "List webhook endpoints",
+		RunE:    listWebhooks,
+	}
+)
+
+func listWebhooks(_ *cobra.Command, _ []string) error {
+	log := logger.GetLogger("listWebhooks")
+	webhookList, err := models.NewWebhook().LoadAll()
+	if err != nil {
+		cli.PrintAndLogError(log, err, tterrors.ListWebhookError)
+		return err
+	}
+	table := simpletable.New()
+	table.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Text: "ID"},
+			{Text: "URL"},
+			{Text: "Events"},
+			{Text: "Signed"},
+			{Text: "Pending"},
+			{Text: "Created At"},
+		},
+	}
+	for _, webhook := range webhookList {
+		pending, pendingErr := webhooks.Pending(webhook.ID)
+		if pendingErr != nil {
+			cli.PrintAndLogError(log, pendingErr, tterrors.ListWebhookError)
+			return pendingErr
+		}
+		events := webhook.Events
+		if events == "" {
+			events = "all" // i18n
+		}
+		signed := "no" // i18n
+		if webhook.Secret != "" {
+			signed = "yes" // i18n
+		}
+		rec := []*simpletable.Cell{
+			{Text: strconv.Itoa(int(webhook.ID))},
+			{Text: webhook.URL},
+			{Text: events},
+			{Text: signed},
+			{Text: strconv.FormatInt(pending, 10)},
+			{Text: webhook.CreatedAt.Format(config.TimestampFormat())},
+		}
+		table.Body.Cells = append(table.Body.Cells, rec)
+	}
+	table.SetStyle(simpletable.StyleCompactLite)
+	fmt.Println(table.String())
+	return nil
+}
@@ -0,0 +1,55 @@
+package webhooks
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/fatih/color"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/models"
+	"github.com/neflyte/timetracker/lib/ui/cli"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// RemoveCmd represents the command to remove a webhook endpoint
+	RemoveCmd = &cobra.Command{
+		Use:     "remove [webhook id]",
+		Aliases: []string{"rm", "delete"},
+		Short:   "Remove a webhook endpoint and its undelivered events",
+		Args:    cobra.ExactArgs(1),
+		RunE:    removeWebhook,
+	}
+)
+
+func removeWebhook(_ *cobra.Command, args []string) error {
+	log := logger.GetLogger("removeWebhook")
+	webhook, err := loadWebhook(args[0])
+	if err != nil {
+		cli.PrintAndLogError(log, err, tterrors.DeleteWebhookError)
+		return err
+	}
+	err = webhook.Delete()
+	if err != nil {
+		cli.PrintAndLogError(log, err, "%s; webhook=%s", tterrors.DeleteWebhookError, webhook.String())
+		return err
+	}
+	fmt.Println(color.WhiteString("Webhook ID %d", webhook.Data().ID), color.RedString("removed")) // i18n
+	return nil
+}
+
+// loadWebhook loads the webhook endpoint identified by the supplied ID argument
+func loadWebhook(idArg string) (models.Webhook, error) {
+	webhookID, err := strconv.ParseUint(idArg, 10, 0)
+	if err != nil {
+		return nil, err
+	}
+	webhook := models.NewWebhook()
+	webhook.Data().ID = uint(webhookID)
+	err = webhook.Load()
+	if err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
@@ -0,0 +1,62 @@
+package webhooks
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/models"
+	"github.com/neflyte/timetracker/lib/ui/cli"
+	"github.com/neflyte/timetracker/lib/webhooks"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// TestCmd represents the command to send a test event to webhook endpoints
+	TestCmd = &cobra.Command{
+		Use:     "test [webhook id]",
+		Aliases: []string{"t"},
+		Short:   "Send a test event to a webhook endpoint",
+		Long:    "Send a test event to the specified webhook endpoint, or to every endpoint if no ID is specified",
+		Args:    cobra.MaximumNArgs(1),
+		RunE:    testWebhooks,
+	}
+)
+
+func testWebhooks(_ *cobra.Command, args []string) error {
+	log := logger.GetLogger("testWebhooks")
+	webhookList := make([]models.Webhook, 0)
+	if len(args) > 0 {
+		webhook, err := loadWebhook(args[0])
+		if err != nil {
+			cli.PrintAndLogError(log, err, tterrors.TestWebhookError)
+			return err
+		}
+		webhookList = append(webhookList, webhook)
+	} else {
+		webhookDatas, err := models.NewWebhook().LoadAll()
+		if err != nil {
+			cli.PrintAndLogError(log, err, tterrors.ListWebhookError)
+			return err
+		}
+		for _, webhookData := range webhookDatas {
+			webhookList = append(webhookList, models.NewWebhookWithData(webhookData))
+		}
+	}
+	if len(webhookList) == 0 {
+		fmt.Println(color.YellowString("There are no webhooks to test")) // i18n
+		return nil
+	}
+	var lastErr error
+	for _, webhook := range webhookList {
+		err := webhooks.Test(webhook)
+		if err != nil {
+			cli.PrintAndLogError(log, err, "%s; webhook=%s", tterrors.TestWebhookError, webhook.String())
+			lastErr = err
+			continue
+		}
+		fmt.Println(color.WhiteString("Webhook %s", webhook.String()), color.GreenString("OK")) // i18n
+	}
+	return lastErr
+}
@@ -0,0 +1,240 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/models"
+	"github.com/neflyte/timetracker/lib/monitor"
+	"github.com/neflyte/timetracker/lib/utils"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultListenAddress is the address that the API server listens on by default
+	DefaultListenAddress = "127.0.0.1:7777"
+	// TokenEnvironmentVariable is the environment variable that can contain the API token
+	TokenEnvironmentVariable = "TIMETRACKER_API_TOKEN"
+	// PathPrefix is the path that all API resources are found under
+	PathPrefix = "/api/v1"
+
+	tokenQueryParameter = "access_token"
+	bearerPrefix        = "Bearer "
+	tokenLengthBytes    = 32
+	readHeaderTimeout   = 10 * time.Second
+)
+
+// ServerData is the main data struct of the Server
+type ServerData struct {
+	log                zerolog.Logger
+	httpServer         *http.Server
+	monitorService     monitor.Service
+	monitorQuitChan    chan bool
+	unsubscribeMonitor func()
+	subscribers        map[chan StatusResource]bool
+	token              string
+	lastStatus         StatusResource
+	subscribersMtx     sync.Mutex
+	statusMtx          sync.Mutex
+	statusKnown        bool
+}
+
+// Server is the interface to the REST API server
+type Server interface {
+	Handler() http.Handler
+	Token() string
+	ListenAndServe() error
+	Shutdown(ctx context.Context) error
+}
+
+// NewServer returns a Server that listens on the supplied address and requires the supplied token
+func NewServer(listenAddress string, token string) Server {
+	s := &ServerData{
+		log:             logger.GetStructLogger("api.ServerData"),
+		token:           token,
+		subscribers:     make(map[chan StatusResource]bool),
+		subscribersMtx:  sync.Mutex{},
+		statusMtx:       sync.Mutex{},
+		monitorQuitChan: make(chan bool, 1),
+	}
+	s.httpServer = &http.Server{
+		Addr:              listenAddress,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+	return s
+}
+
+// GenerateToken returns a new random API token
+func GenerateToken() (string, error) {
+	tokenBytes := make([]byte, tokenLengthBytes)
+	_, err := rand.Read(tokenBytes)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(tokenBytes), nil
+}
+
+// Token returns the token that clients must supply
+func (s *ServerData) Token() string {
+	return s.token
+}
+
+// Handler returns the http.Handler that serves the API
+func (s *ServerData) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(PathPrefix+"/status", s.handle(s.handleStatus))
+	mux.HandleFunc(PathPrefix+"/stop", s.handle(s.handleStop))
+	mux.HandleFunc(PathPrefix+"/events", s.handle(s.handleEvents))
+	mux.HandleFunc(PathPrefix+"/tasks", s.handle(s.handleTasks))
+	mux.HandleFunc(PathPrefix+"/tasks/", s.handle(s.handleTask))
+	mux.HandleFunc(PathPrefix+"/timesheets", s.handle(s.handleTimesheets))
+	mux.HandleFunc(PathPrefix+"/timesheets/", s.handle(s.handleTimesheet))
+	mux.HandleFunc(PathPrefix+"/reports", s.handle(s.handleReports))
+	return s.authenticate(mux)
+}
+
+// ListenAndServe starts watching for status changes and serves the API until the server is shut down
+func (s *ServerData) ListenAndServe() error {
+	log := logger.GetFuncLogger(s.log, "ListenAndServe")
+	// Changes made through the API are published immediately...
+	models.AddEventListener(func(_ models.Event) {
+		s.publishStatus(s.currentStatus())
+	})
+	// ...and changes made by other apps are picked up by the monitor
+	s.monitorService = monitor.NewService(s.monitorQuitChan)
+	monitorEvents, unsubscribe := s.monitorService.Subscribe()
+	s.unsubscribeMonitor = unsubscribe
+	monitorEvents.ForEach(
+		s.handleMonitorEvent,
+		utils.ObservableErrorHandler("monitor", s.log),
+		utils.ObservableCloseHandler("monitor", s.log),
+	)
+	s.monitorService.Start(nil)
+	log.Info().
+		Str("address", s.httpServer.Addr).
+		Msg("API server listening")
+	err := s.httpServer.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Shutdown gracefully stops the server and disconnects event stream clients
+func (s *ServerData) Shutdown(ctx context.Context) error {
+	if s.monitorService != nil && s.monitorService.IsRunning() {
+		s.monitorService.Stop()
+	}
+	if s.unsubscribeMonitor != nil {
+		s.unsubscribeMonitor()
+	}
+	s.subscribersMtx.Lock()
+	for subscriber := range s.subscribers {
+		close(subscriber)
+		delete(s.subscribers, subscriber)
+	}
+	s.subscribersMtx.Unlock()
+	return s.httpServer.Shutdown(ctx)
+}
+
+// authenticate rejects requests that do not include the API token
+func (s *ServerData) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestToken := r.URL.Query().Get(tokenQueryParameter)
+		authHeader := r.Header.Get("Authorization")
+		if strings.HasPrefix(authHeader, bearerPrefix) {
+			requestToken = strings.TrimPrefix(authHeader, bearerPrefix)
+		}
+		if s.token == "" || subtle.ConstantTimeCompare([]byte(requestToken), []byte(s.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="timetracker"`)
+			writeJSON(w, http.StatusUnauthorized, ErrorResource{Error: tterrors.UnauthorizedAPIError})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handle adapts a handler that returns an error into an http.HandlerFunc that reports the error to the client
+func (s *ServerData) handle(handler func(w http.ResponseWriter, r *http.Request) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := handler(w, r)
+		if err == nil {
+			return
+		}
+		log := logger.GetFuncLogger(s.log, "handle")
+		statusCode := http.StatusInternalServerError
+		var requestErr tterrors.ErrAPIRequest
+		var taskStateErr tterrors.ErrInvalidTaskState
+		var timesheetStateErr tterrors.ErrInvalidTimesheetState
+		switch {
+		case errors.As(err, &requestErr):
+			statusCode = requestErr.StatusCode
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			statusCode = http.StatusNotFound
+		case errors.Is(err, tterrors.ErrNoRunningTask{}):
+			statusCode = http.StatusConflict
+		case errors.As(err, &taskStateErr), errors.As(err, &timesheetStateErr):
+			statusCode = http.StatusBadRequest
+		}
+		log.Err(err).
+			Str("method", r.Method).
+			Str("path", r.URL.Path).
+			Int("status", statusCode).
+			Msg("API request failed")
+		writeJSON(w, statusCode, ErrorResource{Error: err.Error()})
+	}
+}
+
+// writeJSON writes the object to the response as JSON with the supplied status code
+func writeJSON(w http.ResponseWriter, statusCode int, obj interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	err := json.NewEncoder(w).Encode(obj)
+	if err != nil {
+		log := logger.GetFuncLogger(logger.GetPackageLogger("api"), "writeJSON")
+		log.Err(err).
+			Msg("error writing JSON response")
+	}
+}
+
+// readJSON decodes the request body into the supplied object
+func readJSON(r *http.Request, obj interface{}) error {
+	err := json.NewDecoder(r.Body).Decode(obj)
+	if err != nil {
+		return tterrors.ErrAPIRequest{
+			Details:    tterrors.InvalidBodyAPIError,
+			StatusCode: http.StatusBadRequest,
+			Wrapped:    err,
+		}
+	}
+	return nil
+}
+
+// methodNotAllowed returns an error for a request that used an unsupported method
+func methodNotAllowed(w http.ResponseWriter, allowed ...string) error {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	return tterrors.ErrAPIRequest{
+		Details:    tterrors.MethodNotAllowedAPIError,
+		StatusCode: http.StatusMethodNotAllowed,
+	}
+}
+
+// notFound returns an error for a request for a resource that does not exist
+func notFound() error {
+	return tterrors.ErrAPIRequest{
+		Details:    tterrors.NotFoundAPIError,
+		StatusCode: http.StatusNotFound,
+	}
+}
@@ -0,0 +1,253 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/neflyte/timetracker/lib/database"
+	"github.com/neflyte/timetracker/lib/models"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	TestDSN = "file:api_test.db?cache=shared&mode=memory"
+
+	testToken = "test-token"
+)
+
+func MustOpenTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(TestDSN), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Warn),
+	})
+	if err != nil {
+		t.Fatalf("error opening test db: %s", err)
+	}
+	err = models.Migrate(db)
+	if err != nil {
+		t.Fatalf("error automigrating test db schema: %s", err)
+	}
+	return db
+}
+
+func CloseTestDB(t *testing.T, db *gorm.DB) {
+	if db != nil {
+		sqldb, err := db.DB()
+		if err != nil {
+			t.Logf("error getting sql.DB handle: %s\n", err)
+		} else {
+			err = sqldb.Close()
+			if err != nil {
+				t.Logf("error closing DB handle: %s\n", err)
+			}
+		}
+	}
+}
+
+// doRequest sends an authenticated request to the test server and decodes the JSON response into obj
+func doRequest(t *testing.T, server *httptest.Server, method string, path string, body interface{}, obj interface{}) int {
+	var bodyReader *bytes.Reader
+	if body != nil {
+		bodyBytes, err := json.Marshal(body)
+		require.Nil(t, err)
+		bodyReader = bytes.NewReader(bodyBytes)
+	} else {
+		bodyReader = bytes.NewReader([]byte{})
+	}
+	req, err := http.NewRequest(method, server.URL+PathPrefix+path, bodyReader)
+	require.Nil(t, err)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	resp, err := server.Client().Do(req)
+	require.Nil(t, err)
+	defer resp.Body.Close()
+	if obj != nil {
+		require.Nil(t, json.NewDecoder(resp.Body).Decode(obj))
+	}
+	return resp.StatusCode
+}
+
+func stringPtr(value string) *string {
+	return &value
+}
+
+func TestUnit_Server_Authentication(t *testing.T) {
+	server := httptest.NewServer(NewServer("", testToken).Handler())
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL + PathPrefix + "/status")
+	require.Nil(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+PathPrefix+"/status", nil)
+	require.Nil(t, err)
+	req.Header.Set("Authorization", "Bearer wrong-token")
+	resp, err = server.Client().Do(req)
+	require.Nil(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestUnit_Server_TaskLifecycle(t *testing.T) {
+	db := MustOpenTestDB(t)
+	defer CloseTestDB(t, db)
+	database.Set(db)
+	server := httptest.NewServer(NewServer("", testToken).Handler())
+	defer server.Close()
+
+	// Create a task
+	created := TaskResource{}
+	statusCode := doRequest(t, server, http.MethodPost, "/tasks", TaskRequest{
+		Synopsis:    stringPtr("api-task"),
+		Description: stringPtr("a task created through the API"),
+	}, &created)
+	require.Equal(t, http.StatusCreated, statusCode)
+	require.NotEqual(t, uint(0), created.ID)
+
+	// Creating a task without a synopsis is a bad request
+	errResource := ErrorResource{}
+	statusCode = doRequest(t, server, http.MethodPost, "/tasks", TaskRequest{}, &errResource)
+	require.Equal(t, http.StatusBadRequest, statusCode)
+	require.NotEmpty(t, errResource.Error)
+
+	// List and load the task
+	tasks := make([]TaskResource, 0)
+	statusCode = doRequest(t, server, http.MethodGet, "/tasks", nil, &tasks)
+	require.Equal(t, http.StatusOK, statusCode)
+	require.Len(t, tasks, 1)
+	loaded := TaskResource{}
+	statusCode = doRequest(t, server, http.MethodGet, "/tasks/api-task", nil, &loaded)
+	require.Equal(t, http.StatusOK, statusCode)
+	require.Equal(t, created.ID, loaded.ID)
+
+	// Update the task
+	updated := TaskResource{}
+	statusCode = doRequest(t, server, http.MethodPatch, "/tasks/api-task", TaskRequest{Description: stringPtr("updated")}, &updated)
+	require.Equal(t, http.StatusOK, statusCode)
+	require.Equal(t, "api-task", updated.Synopsis)
+	require.Equal(t, "updated", updated.Description)
+
+	// Start the task and check the status
+	started := TimesheetResource{}
+	statusCode = doRequest(t, server, http.MethodPost, "/tasks/api-task/start", nil, &started)
+	require.Equal(t, http.StatusCreated, statusCode)
+	require.Equal(t, created.ID, started.Task.ID)
+	require.Nil(t, started.StopTime)
+	status := StatusResource{}
+	statusCode = doRequest(t, server, http.MethodGet, "/status", nil, &status)
+	require.Equal(t, http.StatusOK, statusCode)
+	require.Equal(t, StatusRunning, status.Status)
+	require.NotNil(t, status.Timesheet)
+	require.Equal(t, started.ID, status.Timesheet.ID)
+
+	// Stop the task
+	stopped := TimesheetResource{}
+	statusCode = doRequest(t, server, http.MethodPost, "/stop", nil, &stopped)
+	require.Equal(t, http.StatusOK, statusCode)
+	require.NotNil(t, stopped.StopTime)
+	statusCode = doRequest(t, server, http.MethodPost, "/stop", nil, &errResource)
+	require.Equal(t, http.StatusConflict, statusCode)
+	statusCode = doRequest(t, server, http.MethodGet, "/status", nil, &status)
+	require.Equal(t, http.StatusOK, statusCode)
+	require.Equal(t, StatusIdle, status.Status)
+
+	// Load the timesheet
+	timesheet := TimesheetResource{}
+	statusCode = doRequest(t, server, http.MethodGet, "/timesheets/"+strconv.Itoa(int(started.ID)), nil, &timesheet)
+	require.Equal(t, http.StatusOK, statusCode)
+	require.Equal(t, started.ID, timesheet.ID)
+	timesheets := make([]TimesheetResource, 0)
+	today := time.Now().Format("2006-01-02")
+	statusCode = doRequest(t, server, http.MethodGet, "/timesheets?start="+today+"&end="+today, nil, &timesheets)
+	require.Equal(t, http.StatusOK, statusCode)
+	require.Len(t, timesheets, 1)
+
+	// Delete the task
+	statusCode = doRequest(t, server, http.MethodDelete, "/tasks/api-task", nil, nil)
+	require.Equal(t, http.StatusNoContent, statusCode)
+	statusCode = doRequest(t, server, http.MethodGet, "/tasks/api-task", nil, &errResource)
+	require.Equal(t, http.StatusNotFound, statusCode)
+}
+
+func TestUnit_Server_Reports(t *testing.T) {
+	db := MustOpenTestDB(t)
+	defer CloseTestDB(t, db)
+	database.Set(db)
+	server := httptest.NewServer(NewServer("", testToken).Handler())
+	defer server.Close()
+
+	errResource := ErrorResource{}
+	statusCode := doRequest(t, server, http.MethodGet, "/reports", nil, &errResource)
+	require.Equal(t, http.StatusBadRequest, statusCode)
+	statusCode = doRequest(t, server, http.MethodGet, "/reports?start=yesterday&end=2024-01-01", nil, &errResource)
+	require.Equal(t, http.StatusBadRequest, statusCode)
+
+	report := make(models.TaskReport, 0)
+	statusCode = doRequest(t, server, http.MethodGet, "/reports?start=2024-01-01&end=2024-01-31", nil, &report)
+	require.Equal(t, http.StatusOK, statusCode)
+	require.Len(t, report, 0)
+}
+
+func TestUnit_Server_StatusEvents(t *testing.T) {
+	db := MustOpenTestDB(t)
+	defer CloseTestDB(t, db)
+	database.Set(db)
+	apiServer, ok := NewServer("", testToken).(*ServerData)
+	require.True(t, ok)
+	server := httptest.NewServer(apiServer.Handler())
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL + PathPrefix + "/events?access_token=" + testToken)
+	require.Nil(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	reader := bufio.NewReader(resp.Body)
+
+	// The current status is sent as soon as the client connects
+	status := readStatusEvent(t, reader)
+	require.Equal(t, StatusIdle, status.Status)
+
+	// An unchanged status is not sent again
+	apiServer.publishStatus(apiServer.currentStatus())
+
+	// Start a task
+	task := models.NewTask()
+	task.Data().Synopsis = "sse-task"
+	require.Nil(t, task.Create())
+	_, err = startTask(task)
+	require.Nil(t, err)
+	apiServer.publishStatus(apiServer.currentStatus())
+	status = readStatusEvent(t, reader)
+	require.Equal(t, StatusRunning, status.Status)
+	require.NotNil(t, status.Timesheet)
+	require.Equal(t, "sse-task", status.Timesheet.Task.Synopsis)
+}
+
+// readStatusEvent reads the next server-sent status event from the stream
+func readStatusEvent(t *testing.T, reader *bufio.Reader) StatusResource {
+	eventName := ""
+	for {
+		line, err := reader.ReadString('\n')
+		require.Nil(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			eventName = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			require.Equal(t, StatusEventName, eventName)
+			status := StatusResource{}
+			require.Nil(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &status))
+			return status
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/neflyte/timetracker/lib/constants"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/models"
+	"github.com/neflyte/timetracker/lib/monitor"
+)
+
+const (
+	// StatusEventName is the name of the server-sent event that contains a StatusResource
+	StatusEventName = "status"
+
+	subscriberChanSize = 8
+	keepAliveInterval  = 30 * time.Second
+)
+
+// handleStatus returns the running task status
+func (s *ServerData) handleStatus(w http.ResponseWriter, r *http.Request) error {
+	if r.Method != http.MethodGet {
+		return methodNotAllowed(w, http.MethodGet)
+	}
+	writeJSON(w, http.StatusOK, s.currentStatus())
+	return nil
+}
+
+// handleEvents streams status changes to the client as server-sent events
+func (s *ServerData) handleEvents(w http.ResponseWriter, r *http.Request) error {
+	if r.Method != http.MethodGet {
+		return methodNotAllowed(w, http.MethodGet)
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return tterrors.ErrAPIRequest{
+			Details:    tterrors.StreamingUnsupportedAPIError,
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+	// Subscribe before reading the current status so that a change made in between is not missed
+	subscriber := s.subscribe()
+	defer s.unsubscribe(subscriber)
+	status := s.currentStatus()
+	s.publishStatus(status)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	// Send the current status straight away so clients do not need a separate request
+	err := writeEvent(w, StatusEventName, status)
+	if err != nil {
+		return err
+	}
+	flusher.Flush()
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return nil
+		case changedStatus, open := <-subscriber:
+			if !open {
+				return nil
+			}
+			// The current status may also have been published to this client
+			if changedStatus.SameAs(status) {
+				continue
+			}
+			status = changedStatus
+			err = writeEvent(w, StatusEventName, changedStatus)
+			if err != nil {
+				return err
+			}
+		case <-keepAlive.C:
+			_, err = fmt.Fprint(w, ": keep-alive\n\n")
+			if err != nil {
+				return err
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// writeEvent writes a single server-sent event containing the object encoded as JSON
+func writeEvent(w http.ResponseWriter, name string, obj interface{}) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, data)
+	return err
+}
+
+// subscribe returns a channel that receives status changes
+func (s *ServerData) subscribe() chan StatusResource {
+	subscriber := make(chan StatusResource, subscriberChanSize)
+	s.subscribersMtx.Lock()
+	defer s.subscribersMtx.Unlock()
+	s.subscribers[subscriber] = true
+	return subscriber
+}
+
+// unsubscribe stops sending status changes to the channel
+func (s *ServerData) unsubscribe(subscriber chan StatusResource) {
+	s.subscribersMtx.Lock()
+	defer s.subscribersMtx.Unlock()
+	if _, ok := s.subscribers[subscriber]; ok {
+		delete(s.subscribers, subscriber)
+		close(subscriber)
+	}
+}
+
+// currentStatus loads the running task status from the database
+func (s *ServerData) currentStatus() StatusResource {
+	runningTimesheet, err := models.NewTimesheet().RunningTimesheet()
+	if err != nil {
+		if errors.Is(err, tterrors.ErrNoRunningTask{}) {
+			return NewStatusResource(constants.TimesheetStatusIdle, nil, nil)
+		}
+		return NewStatusResource(constants.TimesheetStatusError, nil, err)
+	}
+	return NewStatusResource(constants.TimesheetStatusRunning, runningTimesheet, nil)
+}
+
+// handleMonitorEvent publishes the status reported by the monitor service
+func (s *ServerData) handleMonitorEvent(item interface{}) {
+	switch item.(type) {
+	case monitor.ServiceUpdateEvent,
+		monitor.TaskStartedEvent,
+		monitor.TaskStoppedEvent,
+		monitor.TaskSwitchedEvent,
+		monitor.ErrorRaisedEvent,
+		monitor.ErrorClearedEvent:
+	default:
+		return
+	}
+	s.publishStatus(NewStatusResource(
+		s.monitorService.TimesheetStatus(),
+		s.monitorService.RunningTimesheet(),
+		s.monitorService.TimesheetError(),
+	))
+}
+
+// publishStatus sends the status to every subscriber if it is different from the last published status
+func (s *ServerData) publishStatus(status StatusResource) {
+	s.statusMtx.Lock()
+	if s.statusKnown && s.lastStatus.SameAs(status) {
+		s.statusMtx.Unlock()
+		return
+	}
+	s.lastStatus = status
+	s.statusKnown = true
+	s.statusMtx.Unlock()
+	s.subscribersMtx.Lock()
+	defer s.subscribersMtx.Unlock()
+	for subscriber := range s.subscribers {
+		select {
+		case subscriber <- status:
+		default:
+			log := logger.GetFuncLogger(s.log, "publishStatus")
+			log.Warn().
+				Msg("event stream client is not keeping up; dropping status event")
+		}
+	}
+}
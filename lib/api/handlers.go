@@ -0,0 +1,272 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/now"
+	"github.com/neflyte/timetracker/lib/constants"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/models"
+)
+
+const (
+	queryDeleted = "deleted"
+	querySearch  = "search"
+	queryStart   = "start"
+	queryEnd     = "end"
+	actionStart  = "start"
+)
+
+// handleTasks lists, searches and creates tasks
+func (s *ServerData) handleTasks(w http.ResponseWriter, r *http.Request) error {
+	switch r.Method {
+	case http.MethodGet:
+		var tasks []models.TaskData
+		var err error
+		searchText := r.URL.Query().Get(querySearch)
+		if searchText != "" {
+			tasks, err = models.NewTask().Search(searchText)
+		} else {
+			tasks, err = models.NewTask().LoadAll(queryBool(r, queryDeleted))
+		}
+		if err != nil {
+			return err
+		}
+		resources := make([]TaskResource, len(tasks))
+		for idx := range tasks {
+			resources[idx] = NewTaskResource(tasks[idx])
+		}
+		writeJSON(w, http.StatusOK, resources)
+		return nil
+	case http.MethodPost:
+		request := TaskRequest{}
+		err := readJSON(r, &request)
+		if err != nil {
+			return err
+		}
+		task := models.NewTask()
+		if request.Synopsis != nil {
+			task.Data().Synopsis = *request.Synopsis
+		}
+		if request.Description != nil {
+			task.Data().Description = *request.Description
+		}
+		err = task.Create()
+		if err != nil {
+			return err
+		}
+		if request.Start {
+			_, err = startTask(task)
+			if err != nil {
+				return err
+			}
+		}
+		writeJSON(w, http.StatusCreated, NewTaskResource(*task.Data()))
+		return nil
+	default:
+		return methodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+// handleTask reads, updates, deletes and starts a single task identified by ID or synopsis
+func (s *ServerData) handleTask(w http.ResponseWriter, r *http.Request) error {
+	pathParts := strings.Split(strings.TrimPrefix(r.URL.Path, PathPrefix+"/tasks/"), "/")
+	if pathParts[0] == "" || len(pathParts) > 2 {
+		return notFound()
+	}
+	task := models.NewTask()
+	task.Data().ID, task.Data().Synopsis = task.Resolve(pathParts[0])
+	err := task.Load(r.Method == http.MethodGet && queryBool(r, queryDeleted))
+	if err != nil {
+		return err
+	}
+	if len(pathParts) == 2 {
+		if pathParts[1] != actionStart {
+			return notFound()
+		}
+		if r.Method != http.MethodPost {
+			return methodNotAllowed(w, http.MethodPost)
+		}
+		timesheet, startErr := startTask(task)
+		if startErr != nil {
+			return startErr
+		}
+		writeJSON(w, http.StatusCreated, NewTimesheetResource(*timesheet.Data()))
+		return nil
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, NewTaskResource(*task.Data()))
+		return nil
+	case http.MethodPatch, http.MethodPut:
+		request := TaskRequest{}
+		err = readJSON(r, &request)
+		if err != nil {
+			return err
+		}
+		if request.Synopsis != nil {
+			task.Data().Synopsis = *request.Synopsis
+		}
+		if request.Description != nil {
+			task.Data().Description = *request.Description
+		}
+		err = task.Update(false)
+		if err != nil {
+			return err
+		}
+		writeJSON(w, http.StatusOK, NewTaskResource(*task.Data()))
+		return nil
+	case http.MethodDelete:
+		err = task.Delete()
+		if err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	default:
+		return methodNotAllowed(w, http.MethodGet, http.MethodPatch, http.MethodPut, http.MethodDelete)
+	}
+}
+
+// handleStop stops the running task
+func (s *ServerData) handleStop(w http.ResponseWriter, r *http.Request) error {
+	if r.Method != http.MethodPost {
+		return methodNotAllowed(w, http.MethodPost)
+	}
+	stoppedTimesheet, err := models.NewTask().StopRunningTask()
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusOK, NewTimesheetResource(*stoppedTimesheet))
+	return nil
+}
+
+// handleTimesheets lists timesheets, optionally limited to a date range
+func (s *ServerData) handleTimesheets(w http.ResponseWriter, r *http.Request) error {
+	if r.Method != http.MethodGet {
+		return methodNotAllowed(w, http.MethodGet)
+	}
+	withDeleted := queryBool(r, queryDeleted)
+	startDate, err := queryDate(r, queryStart)
+	if err != nil {
+		return err
+	}
+	endDate, err := queryDate(r, queryEnd)
+	if err != nil {
+		return err
+	}
+	var timesheets []models.TimesheetData
+	if startDate.IsZero() && endDate.IsZero() {
+		timesheets, err = models.NewTimesheet().LoadAll(withDeleted)
+	} else {
+		searchTimesheet := models.NewTimesheet()
+		searchTimesheet.Data().StartTime = now.With(startDate).BeginningOfDay()
+		if !endDate.IsZero() {
+			searchTimesheet.Data().StopTime.Time = now.With(endDate).EndOfDay()
+			searchTimesheet.Data().StopTime.Valid = true
+		}
+		timesheets, err = searchTimesheet.SearchDateRange(withDeleted)
+	}
+	if err != nil {
+		return err
+	}
+	resources := make([]TimesheetResource, len(timesheets))
+	for idx := range timesheets {
+		resources[idx] = NewTimesheetResource(timesheets[idx])
+	}
+	writeJSON(w, http.StatusOK, resources)
+	return nil
+}
+
+// handleTimesheet reads a single timesheet
+func (s *ServerData) handleTimesheet(w http.ResponseWriter, r *http.Request) error {
+	if r.Method != http.MethodGet {
+		return methodNotAllowed(w, http.MethodGet)
+	}
+	timesheetID, err := strconv.ParseUint(strings.TrimPrefix(r.URL.Path, PathPrefix+"/timesheets/"), 10, 0)
+	if err != nil {
+		return tterrors.ErrAPIRequest{
+			Details:    tterrors.InvalidIDAPIError,
+			StatusCode: http.StatusBadRequest,
+			Wrapped:    err,
+		}
+	}
+	timesheet := models.NewTimesheet()
+	timesheet.Data().ID = uint(timesheetID)
+	err = timesheet.Load()
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusOK, NewTimesheetResource(*timesheet.Data()))
+	return nil
+}
+
+// handleReports returns the task report between two dates
+func (s *ServerData) handleReports(w http.ResponseWriter, r *http.Request) error {
+	if r.Method != http.MethodGet {
+		return methodNotAllowed(w, http.MethodGet)
+	}
+	startDate, err := queryDate(r, queryStart)
+	if err != nil {
+		return err
+	}
+	endDate, err := queryDate(r, queryEnd)
+	if err != nil {
+		return err
+	}
+	if startDate.IsZero() || endDate.IsZero() {
+		return tterrors.ErrAPIRequest{
+			Details:    tterrors.MissingDateRangeAPIError,
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+	reportData, err := models.NewTimesheet().TaskReport(startDate, endDate, queryBool(r, queryDeleted))
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusOK, reportData)
+	return nil
+}
+
+// startTask stops the running task, if any, and starts the supplied task
+func startTask(task models.Task) (models.Timesheet, error) {
+	_, err := task.StopRunningTask()
+	if err != nil && !errors.Is(err, tterrors.ErrNoRunningTask{}) {
+		return nil, err
+	}
+	timesheet := models.NewTimesheet()
+	timesheet.Data().Task = *task.Data()
+	timesheet.Data().StartTime = time.Now()
+	err = timesheet.Create()
+	if err != nil {
+		return nil, err
+	}
+	return timesheet, nil
+}
+
+// queryBool returns true if the query parameter is set to a true value
+func queryBool(r *http.Request, name string) bool {
+	value, err := strconv.ParseBool(r.URL.Query().Get(name))
+	return err == nil && value
+}
+
+// queryDate parses the query parameter as a date; the zero time is returned if the parameter is not set
+func queryDate(r *http.Request, name string) (time.Time, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	date, err := time.ParseInLocation(constants.TimestampDateLayout, value, time.Local)
+	if err != nil {
+		return time.Time{}, tterrors.ErrAPIRequest{
+			Details:    tterrors.InvalidDateAPIError,
+			StatusCode: http.StatusBadRequest,
+			Wrapped:    err,
+		}
+	}
+	return date, nil
+}
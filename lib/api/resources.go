@@ -0,0 +1,121 @@
+package api
+
+import (
+	"time"
+
+	"github.com/neflyte/timetracker/lib/constants"
+	"github.com/neflyte/timetracker/lib/models"
+)
+
+const (
+	// StatusIdle indicates that no task is running
+	StatusIdle = "idle"
+	// StatusRunning indicates that a task is running
+	StatusRunning = "running"
+	// StatusError indicates that the running task could not be determined
+	StatusError = "error"
+)
+
+// TaskResource is the API representation of a task
+type TaskResource struct {
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+	Synopsis    string     `json:"synopsis"`
+	Description string     `json:"description"`
+	ID          uint       `json:"id"`
+}
+
+// NewTaskResource creates a TaskResource from the supplied task data
+func NewTaskResource(taskData models.TaskData) TaskResource {
+	resource := TaskResource{
+		ID:          taskData.ID,
+		Synopsis:    taskData.Synopsis,
+		Description: taskData.Description,
+		CreatedAt:   taskData.CreatedAt,
+		UpdatedAt:   taskData.UpdatedAt,
+	}
+	if taskData.DeletedAt.Valid {
+		deletedAt := taskData.DeletedAt.Time
+		resource.DeletedAt = &deletedAt
+	}
+	return resource
+}
+
+// TimesheetResource is the API representation of a timesheet
+type TimesheetResource struct {
+	StartTime       time.Time    `json:"start_time"`
+	StopTime        *time.Time   `json:"stop_time,omitempty"`
+	Task            TaskResource `json:"task"`
+	ID              uint         `json:"id"`
+	DurationSeconds int64        `json:"duration_seconds"`
+}
+
+// NewTimesheetResource creates a TimesheetResource from the supplied timesheet data. The duration of a running
+// timesheet is the time elapsed since it was started.
+func NewTimesheetResource(timesheetData models.TimesheetData) TimesheetResource {
+	resource := TimesheetResource{
+		ID:        timesheetData.ID,
+		Task:      NewTaskResource(timesheetData.Task),
+		StartTime: timesheetData.StartTime,
+	}
+	stopTime := time.Now()
+	if timesheetData.StopTime.Valid {
+		stopTime = timesheetData.StopTime.Time
+		resource.StopTime = &stopTime
+	}
+	resource.DurationSeconds = int64(stopTime.Sub(timesheetData.StartTime).Truncate(time.Second).Seconds())
+	return resource
+}
+
+// StatusResource is the API representation of the running task status
+type StatusResource struct {
+	Timesheet *TimesheetResource `json:"timesheet,omitempty"`
+	Status    string             `json:"status"`
+	Error     string             `json:"error,omitempty"`
+}
+
+// NewStatusResource creates a StatusResource from a timesheet status, the running timesheet and the status error
+func NewStatusResource(timesheetStatus int, runningTimesheet models.Timesheet, statusErr error) StatusResource {
+	switch timesheetStatus {
+	case constants.TimesheetStatusRunning:
+		resource := StatusResource{Status: StatusRunning}
+		if runningTimesheet != nil && runningTimesheet.Data() != nil {
+			timesheetResource := NewTimesheetResource(*runningTimesheet.Data())
+			resource.Timesheet = &timesheetResource
+		}
+		return resource
+	case constants.TimesheetStatusError:
+		resource := StatusResource{Status: StatusError}
+		if statusErr != nil {
+			resource.Error = statusErr.Error()
+		}
+		return resource
+	default:
+		return StatusResource{Status: StatusIdle}
+	}
+}
+
+// SameAs determines if two statuses describe the same state, ignoring the elapsed duration of a running timesheet
+func (sr StatusResource) SameAs(other StatusResource) bool {
+	if sr.Status != other.Status || sr.Error != other.Error {
+		return false
+	}
+	if sr.Timesheet == nil || other.Timesheet == nil {
+		return sr.Timesheet == other.Timesheet
+	}
+	return sr.Timesheet.ID == other.Timesheet.ID
+}
+
+// TaskRequest is the body of a request that creates or updates a task. Fields that are nil are not changed.
+type TaskRequest struct {
+	Synopsis    *string `json:"synopsis"`
+	Description *string `json:"description"`
+	// Start starts the task after it is created
+	Start bool `json:"start"`
+}
+
+// ErrorResource is the body of an unsuccessful response
+type ErrorResource struct {
+	Error string `json:"error"`
+}
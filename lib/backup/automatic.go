@@ -0,0 +1,306 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/neflyte/timetracker/lib/config"
+	"github.com/neflyte/timetracker/lib/constants"
+	"github.com/neflyte/timetracker/lib/database"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"gorm.io/gorm"
+)
+
+const (
+	// ReasonDaily marks the automatic backup of a day
+	ReasonDaily = "daily"
+	// ReasonMigration marks the automatic backup taken before the schema of the database is migrated
+	ReasonMigration = "migration"
+
+	// directoryMode is the mode of the backup directory if it needs to be created
+	directoryMode = 0700
+	// monthLayout is the layout that groups backups by month
+	monthLayout = "2006-01"
+)
+
+var (
+	// inFlight tracks the backups that are running in the background
+	inFlight = sync.WaitGroup{}
+	// runningMtx prevents more than one background backup at a time
+	runningMtx = sync.Mutex{}
+)
+
+// File is a backup in the backup directory
+type File struct {
+	// CreatedAt is the time in the file name of the backup
+	CreatedAt time.Time
+	// Name is the file name of the backup
+	Name string
+	// Path is the full path and file name of the backup
+	Path string
+	// Reason is why an automatic backup was taken; it is empty for a backup that was created with the CLI
+	Reason string
+	// Size is the size of the backup in bytes
+	Size int64
+}
+
+// Retention is the number of days, weeks and months whose last backup is kept
+type Retention struct {
+	// Daily is the number of days
+	Daily int
+	// Weekly is the number of weeks
+	Weekly int
+	// Monthly is the number of months
+	Monthly int
+}
+
+// ConfiguredRetention returns the retention in the configuration file
+func ConfiguredRetention() Retention {
+	return Retention{
+		Daily:   config.GetInt(config.KeyBackupKeepDaily),
+		Weekly:  config.GetInt(config.KeyBackupKeepWeekly),
+		Monthly: config.GetInt(config.KeyBackupKeepMonthly),
+	}
+}
+
+// Directory returns the directory of automatic backups, creating it if necessary; it is the backup.directory
+// setting, or the backups directory in the user config directory
+func Directory() (string, error) {
+	dir := config.GetString(config.KeyBackupDirectory)
+	if dir == "" {
+		userConfigDir, err := os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(userConfigDir, "timetracker", "backups")
+	}
+	err := os.MkdirAll(dir, directoryMode)
+	if err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// CreateAutomatic backs up the database to the backup directory and then prunes the backups that the configured
+// retention does not keep. Automatic backups are not encrypted since there is no one to enter a passphrase.
+func CreateAutomatic(db *gorm.DB, reason string) (File, error) {
+	log := logger.GetFuncLogger(backupLog, "CreateAutomatic")
+	dir, err := Directory()
+	if err != nil {
+		return File{}, err
+	}
+	createdAt := time.Now()
+	name := backupFileName(databaseStem(), createdAt, reason)
+	manifest, err := create(db, filepath.Join(dir, name), "")
+	if err != nil {
+		return File{}, err
+	}
+	log.Info().
+		Str("name", name).
+		Str("reason", reason).
+		Msg("automatic backup created")
+	_, err = Prune(dir, ConfiguredRetention(), false)
+	if err != nil {
+		return File{}, err
+	}
+	return File{
+		Name:      name,
+		Path:      filepath.Join(dir, name),
+		CreatedAt: createdAt.Truncate(time.Second),
+		Reason:    reason,
+		Size:      manifest.Size,
+	}, nil
+}
+
+// BackupIfDueInBackground takes the daily backup in a new goroutine if automatic backups are enabled and there is
+// no backup of the database from today
+func BackupIfDueInBackground() {
+	if !config.GetBool(config.KeyBackupAutomatic) {
+		return
+	}
+	inFlight.Add(1)
+	go func() {
+		defer inFlight.Done()
+		log := logger.GetFuncLogger(backupLog, "BackupIfDueInBackground")
+		if !runningMtx.TryLock() {
+			return
+		}
+		defer runningMtx.Unlock()
+		due, err := dailyBackupDue(time.Now())
+		if err == nil && due {
+			_, err = CreateAutomatic(database.Get(), ReasonDaily)
+		}
+		if err != nil {
+			log.Err(err).
+				Msg(tterrors.AutomaticBackupError)
+		}
+	}()
+}
+
+// Wait waits for the backups that are running in the background to finish
+func Wait() {
+	inFlight.Wait()
+}
+
+// List returns the backups of the database in the directory, newest first. Backups are recognized by their
+// file names, which start with the name of the database file and the time that they were created.
+func List(dir string) ([]File, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	stem := databaseStem()
+	files := make([]File, 0)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		file, ok := parseBackupFileName(stem, entry.Name())
+		if !ok {
+			continue
+		}
+		info, infoErr := entry.Info()
+		if infoErr != nil {
+			return nil, infoErr
+		}
+		file.Path = filepath.Join(dir, entry.Name())
+		file.Size = info.Size()
+		files = append(files, file)
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].CreatedAt.After(files[j].CreatedAt)
+	})
+	return files, nil
+}
+
+// Prune removes the backups in the directory that the retention does not keep and returns them; with dryRun
+// they are only returned
+func Prune(dir string, retention Retention, dryRun bool) ([]File, error) {
+	log := logger.GetFuncLogger(backupLog, "Prune")
+	files, err := List(dir)
+	if err != nil {
+		return nil, err
+	}
+	kept := retained(files, retention, config.WeekStart())
+	pruned := make([]File, 0)
+	for _, file := range files {
+		if kept[file.Path] {
+			continue
+		}
+		pruned = append(pruned, file)
+		if dryRun {
+			continue
+		}
+		err = os.Remove(file.Path)
+		if err != nil {
+			return pruned, err
+		}
+		log.Debug().
+			Str("name", file.Name).
+			Msg("pruned backup")
+	}
+	return pruned, nil
+}
+
+// retained returns the paths of the backups that are kept: the last backup of each of the most recent days, weeks
+// and months that have backups, and always the newest backup. The files must be sorted newest first.
+func retained(files []File, retention Retention, weekStart time.Weekday) map[string]bool {
+	kept := make(map[string]bool)
+	if len(files) == 0 {
+		return kept
+	}
+	kept[files[0].Path] = true
+	periods := []struct {
+		key   func(createdAt time.Time) string
+		seen  map[string]bool
+		count int
+	}{
+		{count: retention.Daily, key: func(createdAt time.Time) string { return createdAt.Format(constants.TimestampDateLayout) }},
+		{count: retention.Weekly, key: func(createdAt time.Time) string { return weekOf(createdAt, weekStart) }},
+		{count: retention.Monthly, key: func(createdAt time.Time) string { return createdAt.Format(monthLayout) }},
+	}
+	for idx := range periods {
+		periods[idx].seen = make(map[string]bool)
+	}
+	for _, file := range files {
+		for idx := range periods {
+			period := &periods[idx]
+			key := period.key(file.CreatedAt)
+			if period.seen[key] || len(period.seen) >= period.count {
+				continue
+			}
+			period.seen[key] = true
+			kept[file.Path] = true
+		}
+	}
+	return kept
+}
+
+// weekOf returns the date of the first day of the week of the time
+func weekOf(at time.Time, weekStart time.Weekday) string {
+	offset := (int(at.Weekday()) - int(weekStart) + 7) % 7
+	return at.AddDate(0, 0, -offset).Format(constants.TimestampDateLayout)
+}
+
+// dailyBackupDue determines if there is no backup of the database from the day of the time
+func dailyBackupDue(at time.Time) (bool, error) {
+	dir, err := Directory()
+	if err != nil {
+		return false, err
+	}
+	files, err := List(dir)
+	if err != nil {
+		return false, err
+	}
+	today := at.Format(constants.TimestampDateLayout)
+	return len(files) == 0 || files[0].CreatedAt.Format(constants.TimestampDateLayout) != today, nil
+}
+
+// backupFileName returns the file name of a backup of the database with the stem, such as
+// timetracker-20240131-093000-daily.ttbackup
+func backupFileName(stem string, at time.Time, reason string) string {
+	name := stem + "-" + at.Format(fileNameTimeLayout)
+	if reason != "" {
+		name += "-" + reason
+	}
+	return name + FileExtension
+}
+
+// parseBackupFileName parses the file name of a backup of the database with the stem
+func parseBackupFileName(stem string, name string) (File, bool) {
+	if !strings.HasPrefix(name, stem+"-") || !strings.HasSuffix(name, FileExtension) {
+		return File{}, false
+	}
+	rest := strings.TrimSuffix(strings.TrimPrefix(name, stem+"-"), FileExtension)
+	if len(rest) < len(fileNameTimeLayout) {
+		return File{}, false
+	}
+	createdAt, err := time.ParseInLocation(fileNameTimeLayout, rest[:len(fileNameTimeLayout)], time.Local)
+	if err != nil {
+		return File{}, false
+	}
+	reason := rest[len(fileNameTimeLayout):]
+	if reason != "" && !strings.HasPrefix(reason, "-") {
+		return File{}, false
+	}
+	return File{
+		Name:      name,
+		CreatedAt: createdAt,
+		Reason:    strings.TrimPrefix(reason, "-"),
+	}, true
+}
+
+// databaseStem returns the file name of the open database without its extension; backups of different databases
+// in the same directory are told apart by it
+func databaseStem() string {
+	name := filepath.Base(database.FileName())
+	if name == "." || name == string(filepath.Separator) {
+		name = constants.DefaultDatabaseFileName
+	}
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
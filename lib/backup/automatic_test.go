@@ -0,0 +1,75 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_Backup_Retained(t *testing.T) {
+	// Sunday, October 18 2026 at noon and one backup a day for 40 days before it
+	newest := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.Local)
+	files := []File{{Path: "newest", CreatedAt: newest}, {Path: "earlier today", CreatedAt: newest.Add(-time.Hour)}}
+	for day := 1; day <= 40; day++ {
+		createdAt := newest.AddDate(0, 0, -day)
+		files = append(files, File{Path: createdAt.Format("2006-01-02"), CreatedAt: createdAt})
+	}
+	kept := retained(files, Retention{Daily: 3, Weekly: 2, Monthly: 2}, time.Monday)
+	require.Equal(t, map[string]bool{
+		"newest":     true, // today, this week and this month
+		"2026-10-17": true, // daily
+		"2026-10-16": true, // daily
+		"2026-10-11": true, // last week, which started on Monday October 5
+		"2026-09-30": true, // last month
+	}, kept)
+
+	// The newest backup is kept even when nothing else is
+	require.Equal(t, map[string]bool{"newest": true}, retained(files, Retention{}, time.Monday))
+}
+
+func TestUnit_Backup_ParseFileName(t *testing.T) {
+	file, ok := parseBackupFileName("timetracker", "timetracker-20261018-120000-daily.ttbackup")
+	require.True(t, ok)
+	require.Equal(t, ReasonDaily, file.Reason)
+	require.Equal(t, time.Date(2026, time.October, 18, 12, 0, 0, 0, time.Local), file.CreatedAt)
+
+	file, ok = parseBackupFileName("timetracker", "timetracker-20261018-120000.ttbackup")
+	require.True(t, ok)
+	require.Equal(t, "", file.Reason)
+
+	// Backups of other databases and other files are not recognized
+	for _, name := range []string{"timetracker-work-20261018-120000.ttbackup", "timetracker-20261018-120000.db", "notes.txt"} {
+		_, ok = parseBackupFileName("timetracker", name)
+		require.False(t, ok, name)
+	}
+}
+
+func TestUnit_Backup_Prune(t *testing.T) {
+	setupTestDB(t)
+	dir := t.TempDir()
+	newest := time.Now()
+	for day := 0; day < 10; day++ {
+		name := backupFileName("timetracker", newest.AddDate(0, 0, -day), ReasonDaily)
+		require.Nil(t, os.WriteFile(filepath.Join(dir, name), []byte{}, 0600))
+	}
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte{}, 0600))
+
+	pruned, err := Prune(dir, Retention{Daily: 7}, true)
+	require.Nil(t, err)
+	require.Len(t, pruned, 3)
+	files, err := List(dir)
+	require.Nil(t, err)
+	require.Len(t, files, 10)
+
+	_, err = Prune(dir, Retention{Daily: 7}, false)
+	require.Nil(t, err)
+	files, err = List(dir)
+	require.Nil(t, err)
+	require.Len(t, files, 7)
+	require.Equal(t, newest.Truncate(time.Second), files[0].CreatedAt)
+	_, err = os.Stat(filepath.Join(dir, "notes.txt"))
+	require.Nil(t, err)
+}
@@ -0,0 +1,471 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/neflyte/timetracker/lib/constants"
+	"github.com/neflyte/timetracker/lib/database"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/models"
+	"github.com/neflyte/timetracker/lib/utils"
+	"gorm.io/gorm"
+)
+
+const (
+	// FileExtension is the extension of backup files
+	FileExtension = ".ttbackup"
+	// ManifestVersion is the version of the manifest that is written to new backups
+	ManifestVersion = 1
+	// PreviousDatabaseSuffix is appended to the file name of the database that a restore replaces
+	PreviousDatabaseSuffix = ".before-restore"
+
+	// manifestEntry is the name of the manifest in the archive
+	manifestEntry = "manifest.json"
+	// databaseEntry is the name of the database snapshot in the archive
+	databaseEntry = "timetracker.db"
+	// backupFileMode is the mode of backup files and restored databases; they contain client names
+	backupFileMode = 0600
+	// fileNameTimeLayout is the layout of the time in the default file name of a backup
+	fileNameTimeLayout = "20060102-150405"
+)
+
+var (
+	backupLog = logger.GetPackageLogger("backup")
+	// walSuffixes are the suffixes of the write-ahead log files of an SQLite database in WAL mode
+	walSuffixes = []string{"-wal", "-shm"}
+)
+
+// Manifest describes the database snapshot in a backup
+type Manifest struct {
+	// CreatedAt is the time that the snapshot was taken
+	CreatedAt time.Time `json:"createdAt"`
+	// Database is the file name of the database that was backed up
+	Database string `json:"database"`
+	// SHA256 is the hex SHA-256 digest of the snapshot
+	SHA256 string `json:"sha256"`
+	// Size is the size of the snapshot in bytes
+	Size int64 `json:"size"`
+	// Tasks is the number of tasks in the snapshot, including deleted tasks
+	Tasks int64 `json:"tasks"`
+	// Timesheets is the number of timesheets in the snapshot, including deleted timesheets
+	Timesheets int64 `json:"timesheets"`
+	// Version is the version of the manifest
+	Version int `json:"version"`
+}
+
+// Entry is a file in a backup archive
+type Entry struct {
+	// Name is the name of the file
+	Name string
+	// Size is the size of the file in bytes
+	Size int64
+}
+
+// Archive is a backup that was read and verified; its database snapshot is extracted to a temporary directory
+// until Close is called
+type Archive struct {
+	// Entries are the files in the archive
+	Entries []Entry
+	// dir is the temporary directory of the extracted snapshot
+	dir string
+	// Manifest describes the database snapshot
+	Manifest Manifest
+	// Encrypted determines if the backup was encrypted
+	Encrypted bool
+}
+
+// DefaultFileName returns the file name of a backup of the open database taken at the specified time
+func DefaultFileName(at time.Time) string {
+	return backupFileName(databaseStem(), at, "")
+}
+
+// Create takes a consistent snapshot of the open database and writes it to a backup archive with a manifest. The
+// archive is encrypted with the passphrase unless it is empty.
+func Create(fileName string, passphrase string) (Manifest, error) {
+	return create(database.Get(), fileName, passphrase)
+}
+
+// create takes a snapshot of the database and writes it to a backup archive
+func create(db *gorm.DB, fileName string, passphrase string) (Manifest, error) {
+	log := logger.GetFuncLogger(backupLog, "create")
+	dir, err := os.MkdirTemp("", "timetracker-backup-")
+	if err != nil {
+		return Manifest{}, err
+	}
+	defer removeAll(dir)
+	snapshotFile := filepath.Join(dir, databaseEntry)
+	// VACUUM INTO writes a consistent copy even while other apps are writing to the database
+	err = db.Exec("VACUUM INTO ?", snapshotFile).Error
+	if err != nil {
+		return Manifest{}, err
+	}
+	manifest, err := describeSnapshot(snapshotFile)
+	if err != nil {
+		return Manifest{}, err
+	}
+	manifest.Version = ManifestVersion
+	manifest.CreatedAt = time.Now()
+	manifest.Database = filepath.Base(database.FileName())
+	data, err := writeArchive(manifest, snapshotFile)
+	if err != nil {
+		return Manifest{}, err
+	}
+	if passphrase != "" {
+		data, err = encrypt(data, passphrase)
+		if err != nil {
+			return Manifest{}, err
+		}
+	}
+	err = writeFileAtomic(fileName, bytes.NewReader(data))
+	if err != nil {
+		return Manifest{}, err
+	}
+	log.Debug().
+		Str("fileName", fileName).
+		Bool("encrypted", passphrase != "").
+		Int64("size", manifest.Size).
+		Msg("backup created")
+	return manifest, nil
+}
+
+// Open reads a backup archive, decrypting it with the passphrase if it is encrypted, and verifies the snapshot:
+// its digest and size must match the manifest and SQLite's integrity check must pass
+func Open(fileName string, passphrase string) (*Archive, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	archive := &Archive{
+		Encrypted: isEncrypted(data),
+	}
+	if archive.Encrypted {
+		data, err = decrypt(data, passphrase)
+		if err != nil {
+			return nil, err
+		}
+	}
+	archive.dir, err = os.MkdirTemp("", "timetracker-restore-")
+	if err != nil {
+		return nil, err
+	}
+	err = archive.extract(data)
+	if err == nil {
+		err = archive.verify()
+	}
+	if err != nil {
+		archive.Close()
+		return nil, err
+	}
+	return archive, nil
+}
+
+// Restore replaces the database file with the snapshot. The database must be closed first, and the tray and GUI
+// apps must not be running; the replaced database and its write-ahead log are kept next to it with
+// PreviousDatabaseSuffix appended to their names.
+func (a *Archive) Restore(target string) (string, error) {
+	log := logger.GetFuncLogger(backupLog, "Restore")
+	err := checkAppsStopped()
+	if err != nil {
+		return "", err
+	}
+	snapshot, err := os.Open(a.snapshotFile())
+	if err != nil {
+		return "", err
+	}
+	defer closeFile(snapshot)
+	// The snapshot is copied next to the database first so that the database is replaced at once
+	restoredFile := target + ".restore"
+	err = writeFileAtomic(restoredFile, snapshot)
+	if err != nil {
+		return "", err
+	}
+	previousFile := ""
+	if _, statErr := os.Stat(target); statErr == nil {
+		previousFile = target + PreviousDatabaseSuffix
+		// The write-ahead log is kept with the replaced database, which would lose the commits that are not yet
+		// checkpointed without it, and must not be applied to the restored one
+		err = renameDatabase(target, previousFile)
+		if err != nil {
+			removeFile(restoredFile)
+			return "", err
+		}
+	} else {
+		for _, suffix := range walSuffixes {
+			removeFile(target + suffix)
+		}
+	}
+	err = os.Rename(restoredFile, target)
+	if err != nil {
+		removeFile(restoredFile)
+		if previousFile != "" {
+			restoreErr := renameDatabase(previousFile, target)
+			if restoreErr != nil {
+				log.Err(restoreErr).
+					Str("previous", previousFile).
+					Msg("error putting the replaced database back")
+			}
+		}
+		return "", err
+	}
+	log.Debug().
+		Str("target", target).
+		Str("previous", previousFile).
+		Msg("backup restored")
+	return previousFile, nil
+}
+
+// Close removes the extracted snapshot
+func (a *Archive) Close() {
+	if a.dir != "" {
+		removeAll(a.dir)
+		a.dir = ""
+	}
+}
+
+// snapshotFile returns the file name of the extracted snapshot
+func (a *Archive) snapshotFile() string {
+	return filepath.Join(a.dir, databaseEntry)
+}
+
+// extract reads the manifest and extracts the snapshot from the archive; other files are only listed
+func (a *Archive) extract(data []byte) error {
+	gzipReader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return tterrors.ErrBackupIntegrity{Reason: "it is not a timetracker backup"} // i18n
+	}
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, nextErr := tarReader.Next()
+		if errors.Is(nextErr, io.EOF) {
+			return nil
+		}
+		if nextErr != nil {
+			return tterrors.ErrBackupIntegrity{Reason: nextErr.Error()}
+		}
+		a.Entries = append(a.Entries, Entry{Name: header.Name, Size: header.Size})
+		switch header.Name {
+		case manifestEntry:
+			err = json.NewDecoder(tarReader).Decode(&a.Manifest)
+			if err != nil {
+				return tterrors.ErrBackupIntegrity{Reason: "the manifest cannot be read"} // i18n
+			}
+		case databaseEntry:
+			err = writeFileAtomic(a.snapshotFile(), tarReader)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// verify checks the extracted snapshot against the manifest
+func (a *Archive) verify() error {
+	if a.Manifest.Version == 0 {
+		return tterrors.ErrBackupIntegrity{Reason: "the manifest is missing"} // i18n
+	}
+	if a.Manifest.Version > ManifestVersion {
+		return tterrors.ErrBackupIntegrity{Reason: fmt.Sprintf("manifest version %d is newer than %d; upgrade timetracker", a.Manifest.Version, ManifestVersion)} // i18n
+	}
+	if _, err := os.Stat(a.snapshotFile()); err != nil {
+		return tterrors.ErrBackupIntegrity{Reason: "the database is missing"} // i18n
+	}
+	snapshot, err := describeSnapshot(a.snapshotFile())
+	if err != nil {
+		return tterrors.ErrBackupIntegrity{Reason: err.Error()}
+	}
+	if snapshot.Size != a.Manifest.Size || snapshot.SHA256 != a.Manifest.SHA256 {
+		return tterrors.ErrBackupIntegrity{Reason: "the database does not match its checksum"} // i18n
+	}
+	if snapshot.Tasks != a.Manifest.Tasks || snapshot.Timesheets != a.Manifest.Timesheets {
+		return tterrors.ErrBackupIntegrity{Reason: "the database does not contain the tasks and timesheets in the manifest"} // i18n
+	}
+	return nil
+}
+
+// describeSnapshot returns the digest, size and record counts of a snapshot after checking its integrity
+func describeSnapshot(fileName string) (Manifest, error) {
+	manifest := Manifest{}
+	snapshot, err := os.Open(fileName)
+	if err != nil {
+		return manifest, err
+	}
+	defer closeFile(snapshot)
+	hash := sha256.New()
+	manifest.Size, err = io.Copy(hash, snapshot)
+	if err != nil {
+		return manifest, err
+	}
+	manifest.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	db, err := database.OpenReadOnly(fileName)
+	if err != nil {
+		return manifest, err
+	}
+	defer database.Close(db)
+	err = checkIntegrity(db)
+	if err != nil {
+		return manifest, err
+	}
+	err = db.Unscoped().Model(new(models.TaskData)).Count(&manifest.Tasks).Error
+	if err != nil {
+		return manifest, err
+	}
+	err = db.Unscoped().Model(new(models.TimesheetData)).Count(&manifest.Timesheets).Error
+	return manifest, err
+}
+
+// checkIntegrity runs SQLite's integrity check on the database
+func checkIntegrity(db *gorm.DB) error {
+	results := make([]string, 0, 1)
+	err := db.Raw("PRAGMA integrity_check").Scan(&results).Error
+	if err != nil {
+		return err
+	}
+	if len(results) != 1 || results[0] != "ok" {
+		return fmt.Errorf("integrity check failed: %v", results) // i18n
+	}
+	return nil
+}
+
+// writeArchive returns the gzipped tar archive of the manifest and the snapshot
+func writeArchive(manifest Manifest, snapshotFile string) ([]byte, error) {
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := os.Open(snapshotFile)
+	if err != nil {
+		return nil, err
+	}
+	defer closeFile(snapshot)
+	buf := new(bytes.Buffer)
+	gzipWriter := gzip.NewWriter(buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	entries := []struct {
+		reader io.Reader
+		name   string
+		size   int64
+	}{
+		{name: manifestEntry, size: int64(len(manifestJSON)), reader: bytes.NewReader(manifestJSON)},
+		{name: databaseEntry, size: manifest.Size, reader: snapshot},
+	}
+	for _, entry := range entries {
+		err = tarWriter.WriteHeader(&tar.Header{
+			Name:    entry.name,
+			Mode:    backupFileMode,
+			Size:    entry.size,
+			ModTime: manifest.CreatedAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+		_, err = io.Copy(tarWriter, entry.reader)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = tarWriter.Close()
+	if err != nil {
+		return nil, err
+	}
+	err = gzipWriter.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeFileAtomic writes a file through a temporary file so that a partial file never has the file name
+func writeFileAtomic(fileName string, reader io.Reader) error {
+	tempFileName := fileName + ".tmp"
+	file, err := os.OpenFile(tempFileName, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, backupFileMode)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(file, reader)
+	if err == nil {
+		err = file.Sync()
+	}
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		removeFile(tempFileName)
+		return err
+	}
+	return os.Rename(tempFileName, fileName)
+}
+
+// closeFile closes a file and logs any error
+func closeFile(file *os.File) {
+	err := file.Close()
+	if err != nil {
+		backupLog.Err(err).Str("file", file.Name()).Msg("error closing file")
+	}
+}
+
+// renameDatabase renames a database file along with its write-ahead log files, if there are any. If a file cannot
+// be renamed, the files that were already renamed are renamed back.
+func renameDatabase(from string, to string) error {
+	renamed := make([]string, 0)
+	for _, suffix := range append([]string{""}, walSuffixes...) {
+		err := os.Rename(from+suffix, to+suffix)
+		if suffix != "" && errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			for _, renamedSuffix := range renamed {
+				_ = os.Rename(to+renamedSuffix, from+renamedSuffix)
+			}
+			return err
+		}
+		renamed = append(renamed, suffix)
+	}
+	return nil
+}
+
+// checkAppsStopped returns an error if the tray or GUI app is running, since it would keep using the database
+// that is replaced
+func checkAppsStopped() error {
+	userConfigDir, err := os.UserConfigDir()
+	if err != nil {
+		return nil
+	}
+	for _, pidfile := range []string{constants.TrayPidfile, constants.GUIPidfile} {
+		// A pidfile that does not exist or is stale is not running
+		running, _ := utils.CheckPidfile(filepath.Join(userConfigDir, "timetracker", pidfile))
+		if running {
+			return tterrors.ErrAppRunning{App: strings.TrimSuffix(pidfile, filepath.Ext(pidfile))}
+		}
+	}
+	return nil
+}
+
+// removeFile removes a file if it exists and logs any error
+func removeFile(fileName string) {
+	err := os.Remove(fileName)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		backupLog.Err(err).Str("file", fileName).Msg("error removing file")
+	}
+}
+
+// removeAll removes a temporary directory and logs any error
+func removeAll(dir string) {
+	err := os.RemoveAll(dir)
+	if err != nil {
+		backupLog.Err(err).Str("dir", dir).Msg("error removing directory")
+	}
+}
@@ -0,0 +1,105 @@
+package backup
+
+import (
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/neflyte/timetracker/lib/constants"
+	"github.com/neflyte/timetracker/lib/database"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/models"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestDB(t *testing.T) {
+	db, err := database.Open(filepath.Join(t.TempDir(), "timetracker.db"))
+	require.Nil(t, err)
+	err = models.Migrate(db)
+	require.Nil(t, err)
+	database.Set(db)
+	t.Cleanup(func() { database.Close(db) })
+	for _, synopsis := range []string{"Client A", "Client B"} {
+		task := models.NewTask()
+		task.Data().Synopsis = synopsis
+		require.Nil(t, task.Create())
+	}
+}
+
+func TestUnit_Backup_EncryptedRoundTrip(t *testing.T) {
+	setupTestDB(t)
+	fileName := filepath.Join(t.TempDir(), DefaultFileName(time.Now()))
+	manifest, err := Create(fileName, "correct horse")
+	require.Nil(t, err)
+	require.Equal(t, int64(2), manifest.Tasks)
+
+	data, err := os.ReadFile(fileName)
+	require.Nil(t, err)
+	require.True(t, isEncrypted(data))
+	require.NotContains(t, string(data), "Client A")
+
+	_, err = Open(fileName, "")
+	require.True(t, errors.Is(err, tterrors.ErrPassphraseRequired{}))
+	_, err = Open(fileName, "wrong")
+	require.True(t, errors.Is(err, tterrors.ErrWrongPassphrase{}))
+
+	archive, err := Open(fileName, "correct horse")
+	require.Nil(t, err)
+	defer archive.Close()
+	require.True(t, archive.Encrypted)
+	require.Equal(t, manifest.SHA256, archive.Manifest.SHA256)
+	require.Len(t, archive.Entries, 2)
+
+	target := filepath.Join(t.TempDir(), "restored.db")
+	require.Nil(t, os.WriteFile(target, []byte("old"), 0600))
+	require.Nil(t, os.WriteFile(target+"-wal", []byte("old commits"), 0600))
+
+	// A backup is not restored while the tray has the database open
+	configDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+	pidfile := filepath.Join(configDir, "timetracker", constants.TrayPidfile)
+	require.Nil(t, os.MkdirAll(filepath.Dir(pidfile), 0700))
+	require.Nil(t, os.WriteFile(pidfile, []byte(strconv.Itoa(os.Getpid())), 0600))
+	_, err = archive.Restore(target)
+	require.True(t, errors.As(err, new(tterrors.ErrAppRunning)))
+	require.Nil(t, os.Remove(pidfile))
+
+	previousFile, err := archive.Restore(target)
+	require.Nil(t, err)
+	require.Equal(t, target+PreviousDatabaseSuffix, previousFile)
+	// The write-ahead log is kept with the replaced database
+	previousWAL, err := os.ReadFile(previousFile + "-wal")
+	require.Nil(t, err)
+	require.Equal(t, "old commits", string(previousWAL))
+	restored, err := database.OpenReadOnly(target)
+	require.Nil(t, err)
+	defer database.Close(restored)
+	var tasks int64
+	require.Nil(t, restored.Model(new(models.TaskData)).Count(&tasks).Error)
+	require.Equal(t, int64(2), tasks)
+}
+
+func TestUnit_Backup_DetectsDamage(t *testing.T) {
+	setupTestDB(t)
+	for _, passphrase := range []string{"", "correct horse"} {
+		fileName := filepath.Join(t.TempDir(), "damaged"+FileExtension)
+		_, err := Create(fileName, passphrase)
+		require.Nil(t, err)
+		data, err := os.ReadFile(fileName)
+		require.Nil(t, err)
+		data[len(data)/2] ^= 0xff
+		require.Nil(t, os.WriteFile(fileName, data, 0600))
+		_, err = Open(fileName, passphrase)
+		require.Error(t, err)
+	}
+}
+
+func TestUnit_DeriveKey(t *testing.T) {
+	// The RFC 7914 PBKDF2-HMAC-SHA256 test vector
+	key := deriveKey("password", []byte("salt"), 2)
+	require.Equal(t, "ae4d0c95af6b46d32d0adff928f06dd02a303f8ef3c251dfd6e2d85a95474c43", hex.EncodeToString(key))
+}
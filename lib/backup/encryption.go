@@ -0,0 +1,128 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// kdfIterations is the number of PBKDF2-HMAC-SHA256 iterations that derive the key of a new backup
+	kdfIterations = 600000
+	// maxKDFIterations limits the work that reading a damaged or hostile header can cause
+	maxKDFIterations = 10000000
+	// keySize is the size of the AES-256 key
+	keySize = 32
+	// saltSize is the size of the random salt of the key
+	saltSize = 16
+	// encryptionVersion is the version of the encrypted format
+	encryptionVersion = 1
+)
+
+var (
+	// encryptedMagic starts every encrypted backup
+	encryptedMagic = []byte("TTBACKUP")
+)
+
+// encryptionHeader precedes the ciphertext of an encrypted backup. It is authenticated along with the ciphertext,
+// so changing the KDF parameters fails decryption.
+type encryptionHeader struct {
+	Salt       [saltSize]byte
+	Nonce      [12]byte
+	Iterations uint32
+	Version    uint8
+}
+
+// isEncrypted determines if the data is an encrypted backup
+func isEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, encryptedMagic)
+}
+
+// encrypt encrypts the data with AES-256-GCM using a key derived from the passphrase
+func encrypt(data []byte, passphrase string) ([]byte, error) {
+	header := encryptionHeader{
+		Version:    encryptionVersion,
+		Iterations: kdfIterations,
+	}
+	_, err := io.ReadFull(rand.Reader, header.Salt[:])
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.ReadFull(rand.Reader, header.Nonce[:])
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(passphrase, header)
+	if err != nil {
+		return nil, err
+	}
+	headerBytes, err := header.marshal()
+	if err != nil {
+		return nil, err
+	}
+	return gcm.Seal(headerBytes, header.Nonce[:], data, headerBytes), nil
+}
+
+// decrypt decrypts an encrypted backup; a wrong passphrase and a damaged backup cannot be told apart
+func decrypt(data []byte, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, tterrors.ErrPassphraseRequired{}
+	}
+	header := encryptionHeader{}
+	reader := bytes.NewReader(data[len(encryptedMagic):])
+	err := binary.Read(reader, binary.BigEndian, &header)
+	if err != nil {
+		return nil, tterrors.ErrBackupIntegrity{Reason: "the encryption header is incomplete"} // i18n
+	}
+	if header.Version != encryptionVersion {
+		return nil, tterrors.ErrBackupIntegrity{Reason: "the encryption version is not supported"} // i18n
+	}
+	if header.Iterations == 0 || header.Iterations > maxKDFIterations {
+		return nil, tterrors.ErrBackupIntegrity{Reason: "the key derivation parameters are invalid"} // i18n
+	}
+	gcm, err := newGCM(passphrase, header)
+	if err != nil {
+		return nil, err
+	}
+	headerSize := len(data) - reader.Len()
+	plaintext, err := gcm.Open(nil, header.Nonce[:], data[headerSize:], data[:headerSize])
+	if err != nil {
+		return nil, tterrors.ErrWrongPassphrase{}
+	}
+	return plaintext, nil
+}
+
+// marshal returns the header as it is written to the backup
+func (eh encryptionHeader) marshal() ([]byte, error) {
+	buf := bytes.NewBuffer(append([]byte{}, encryptedMagic...))
+	err := binary.Write(buf, binary.BigEndian, eh)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// newGCM returns the AES-GCM cipher of the key derived from the passphrase with the parameters of the header
+func newGCM(passphrase string, header encryptionHeader) (cipher.AEAD, error) {
+	if passphrase == "" {
+		return nil, errors.New(tterrors.EmptyPassphraseError)
+	}
+	block, err := aes.NewCipher(deriveKey(passphrase, header.Salt[:], int(header.Iterations)))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// deriveKey derives the AES-256 key of a backup from the passphrase with PBKDF2-HMAC-SHA256
+func deriveKey(passphrase string, salt []byte, iterations int) []byte {
+	return pbkdf2.Key([]byte(passphrase), salt, iterations, keySize, sha256.New)
+}
@@ -0,0 +1,492 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jinzhu/now"
+	"github.com/neflyte/timetracker/lib/constants"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/spf13/viper"
+)
+
+const (
+	// KeyDatabase is the full path and filename of the database; when it is empty, the selected profile is used
+	KeyDatabase = "database"
+	// KeyLogLevel is the logging level
+	KeyLogLevel = "log-level"
+	// KeyLogConsole logs messages to the console as well as the log file
+	KeyLogConsole = "log-console"
+	// KeyTimestampFormat is the Go time layout used to display timestamps
+	KeyTimestampFormat = "timestamp-format"
+	// KeyDateFormat is the Go time layout used to display dates
+	KeyDateFormat = "date-format"
+	// KeyWeekStart is the first day of the week
+	KeyWeekStart = "week-start"
+	// KeyReportPeriod is the period that a report covers when no dates are supplied
+	KeyReportPeriod = "report.period"
+	// KeyReportOutputFormat is the default output format of a report
+	KeyReportOutputFormat = "report.output-format"
+	// KeyReportDeleted includes deleted timesheets in reports by default
+	KeyReportDeleted = "report.deleted"
+	// KeyTrayStopTaskConfirm prompts for confirmation when the tray stops a running task
+	KeyTrayStopTaskConfirm = "tray.stop-task-confirm"
+	// KeyTrayRecentTasks is the number of recently started tasks listed in the tray's Recent tasks menu
+	KeyTrayRecentTasks = "tray.recent-tasks"
+	// KeyTrayIcon is what the tray icon shows while a task is running or toward the daily goal
+	KeyTrayIcon = "tray.icon"
+	// KeyDailyGoal is the time to track each day
+	KeyDailyGoal = "daily-goal"
+	// KeyGUICloseWindowStopTask closes the main window after the GUI stops a running task
+	KeyGUICloseWindowStopTask = "gui.close-window-stop-task"
+	// KeyGUITheme is the colour theme of the GUI
+	KeyGUITheme = "gui.theme"
+	// KeyDurationFormat is how durations are displayed
+	KeyDurationFormat = "duration-format"
+	// KeyNotificationsEnabled shows desktop notifications
+	KeyNotificationsEnabled = "notifications.enabled"
+	// KeyNotificationsQuietHoursStart is the time of day, as HH:MM, when desktop notifications stop being shown
+	KeyNotificationsQuietHoursStart = "notifications.quiet-hours-start"
+	// KeyNotificationsQuietHoursEnd is the time of day, as HH:MM, when desktop notifications are shown again
+	KeyNotificationsQuietHoursEnd = "notifications.quiet-hours-end"
+	// KeySyncFolder is the shared folder that changes are exported to and imported from
+	KeySyncFolder = "sync.folder"
+	// KeyBackupAutomatic backs up the database each day and before its schema is migrated
+	KeyBackupAutomatic = "backup.automatic"
+	// KeyBackupDirectory is the directory of automatic backups
+	KeyBackupDirectory = "backup.directory"
+	// KeyBackupKeepDaily is the number of days whose last automatic backup is kept
+	KeyBackupKeepDaily = "backup.keep-daily"
+	// KeyBackupKeepWeekly is the number of weeks whose last automatic backup is kept
+	KeyBackupKeepWeekly = "backup.keep-weekly"
+	// KeyBackupKeepMonthly is the number of months whose last automatic backup is kept
+	KeyBackupKeepMonthly = "backup.keep-monthly"
+
+	// EnvironmentPrefix is the prefix of the environment variables that override the configuration file
+	EnvironmentPrefix = "TIMETRACKER"
+	// ReportPeriodNone means that reports require a start and end date
+	ReportPeriodNone = "none"
+	// ReportPeriodToday means that reports cover today by default
+	ReportPeriodToday = "today"
+	// ReportPeriodWeek means that reports cover the current week by default
+	ReportPeriodWeek = "week"
+	// ReportPeriodMonth means that reports cover the current month by default
+	ReportPeriodMonth = "month"
+
+	// DurationFormatGo displays durations like 1h30m0s
+	DurationFormatGo = "go"
+	// DurationFormatClock displays durations like 1:30:00
+	DurationFormatClock = "clock"
+	// DurationFormatDecimal displays durations as decimal hours like 1.50h
+	DurationFormatDecimal = "decimal"
+
+	// ThemeSystem follows the light or dark preference of the operating system
+	ThemeSystem = "system"
+	// ThemeLight always uses the light theme
+	ThemeLight = "light"
+	// ThemeDark always uses the dark theme
+	ThemeDark = "dark"
+
+	// TrayIconStatic shows a static icon for each timesheet status
+	TrayIconStatic = "static"
+	// TrayIconProgress shows a ring around the icon that fills toward the daily goal
+	TrayIconProgress = "progress"
+	// TrayIconElapsed shows the minutes elapsed since the running task was started on the icon
+	TrayIconElapsed = "elapsed"
+
+	// SourceEnvironment means that the value of a setting comes from an environment variable
+	SourceEnvironment = "env"
+	// SourceFile means that the value of a setting comes from the configuration file
+	SourceFile = "file"
+	// SourceDefault means that a setting has its default value
+	SourceDefault = "default"
+
+	configFileName = "timetracker.yaml"
+	configFileType = "yaml"
+	configFileMode = 0600
+
+	defaultRecentTasks = 5
+	maxRecentTasks     = 25
+	defaultKeepDaily   = 7
+	defaultKeepWeekly  = 4
+	defaultKeepMonthly = 12
+	quietHoursLayout   = "15:04"
+	minutesPerHour     = 60
+	maxDailyGoal       = 24 * time.Hour
+)
+
+// Key describes a configuration setting
+type Key struct {
+	// Default is the value that is used when the setting is not in the environment or the configuration file
+	Default interface{}
+	// Validate returns an error if the value is not allowed; it is nil if any value of the right type is allowed
+	Validate func(value string) error
+	// Name is the name of the setting in the configuration file
+	Name string
+	// Description explains what the setting does
+	Description string
+	// Values is the list of allowed values; it is empty if the value is not one of a fixed list
+	Values []string
+}
+
+var (
+	// Keys is the list of every configuration setting
+	Keys = []Key{
+		{Name: KeyDatabase, Default: "", Description: "Full path and filename of the database; if empty, the selected profile is used"},
+		{Name: KeyLogLevel, Default: constants.DefaultLogLevel, Description: "Logging level (trace, debug, info, warn, error)", Values: []string{"trace", "debug", "info", "warn", "error", "fatal", "panic"}},
+		{Name: KeyLogConsole, Default: false, Description: "Log messages to the console as well as the log file"},
+		{Name: KeyTimestampFormat, Default: constants.TimestampLayout, Description: "Go time layout used to display timestamps"},
+		{Name: KeyDateFormat, Default: constants.TimestampDateLayout, Description: "Go time layout used to display dates"},
+		{Name: KeyDurationFormat, Default: DurationFormatGo, Description: "How durations are displayed (go, clock, decimal)", Values: []string{DurationFormatGo, DurationFormatClock, DurationFormatDecimal}},
+		{Name: KeyWeekStart, Default: "sunday", Description: "First day of the week", Validate: validateWeekday},
+		{Name: KeyReportPeriod, Default: ReportPeriodNone, Description: "Period covered by a report when no dates are supplied (none, today, week, month)", Values: []string{ReportPeriodNone, ReportPeriodToday, ReportPeriodWeek, ReportPeriodMonth}},
+		{Name: KeyReportOutputFormat, Default: "text", Description: "Default report output format (text, csv, json, xml, markdown, html)", Values: []string{"text", "csv", "json", "xml", "markdown", "html"}},
+		{Name: KeyReportDeleted, Default: false, Description: "Include deleted timesheets in reports"},
+		{Name: KeyNotificationsEnabled, Default: true, Description: "Show desktop notifications"},
+		{Name: KeyNotificationsQuietHoursStart, Default: "", Description: "Time of day (HH:MM) when desktop notifications stop being shown; if empty, there are no quiet hours", Validate: validateTimeOfDay},
+		{Name: KeyNotificationsQuietHoursEnd, Default: "", Description: "Time of day (HH:MM) when desktop notifications are shown again", Validate: validateTimeOfDay},
+		{Name: KeyTrayStopTaskConfirm, Default: true, Description: "Prompt for confirmation when the tray stops a running task"},
+		{Name: KeyTrayRecentTasks, Default: defaultRecentTasks, Description: "Number of recently started tasks listed in the tray's Recent tasks menu (0 to 25)", Validate: validateRecentTasks},
+		{Name: KeyTrayIcon, Default: TrayIconStatic, Description: "What the tray icon shows (static, progress toward the daily goal, elapsed minutes of the running task)", Values: []string{TrayIconStatic, TrayIconProgress, TrayIconElapsed}},
+		{Name: KeyDailyGoal, Default: "8h", Description: "Time to track each day, such as 7h30m", Validate: validateDailyGoal},
+		{Name: KeyGUICloseWindowStopTask, Default: false, Description: "Close the main window after the GUI stops a running task"},
+		{Name: KeyGUITheme, Default: ThemeSystem, Description: "Colour theme of the GUI (system, light, dark)", Values: []string{ThemeSystem, ThemeLight, ThemeDark}},
+		{Name: KeySyncFolder, Default: "", Description: "Shared folder, such as a synced cloud folder, that timetracker sync exchanges changes through"},
+		{Name: KeyBackupAutomatic, Default: true, Description: "Back up the database each day while the tray or GUI is running, and before its schema is migrated"},
+		{Name: KeyBackupDirectory, Default: "", Description: "Directory of automatic backups; if empty, the backups directory in the user config directory is used"},
+		{Name: KeyBackupKeepDaily, Default: defaultKeepDaily, Description: "Number of days whose last automatic backup is kept", Validate: validateKeepCount},
+		{Name: KeyBackupKeepWeekly, Default: defaultKeepWeekly, Description: "Number of weeks whose last automatic backup is kept", Validate: validateKeepCount},
+		{Name: KeyBackupKeepMonthly, Default: defaultKeepMonthly, Description: "Number of months whose last automatic backup is kept", Validate: validateKeepCount},
+	}
+
+	settings    = newSettings()
+	settingsMtx = sync.RWMutex{}
+	configFile  = ""
+)
+
+// SetConfigFile overrides the location of the configuration file
+func SetConfigFile(fileName string) {
+	settingsMtx.Lock()
+	defer settingsMtx.Unlock()
+	configFile = fileName
+}
+
+// ConfigFile returns the location of the configuration file; by default it is in the user config directory
+func ConfigFile() (string, error) {
+	if configFile != "" {
+		return configFile, nil
+	}
+	userConfigDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	userConfigDir = path.Join(userConfigDir, "timetracker")
+	err = os.MkdirAll(userConfigDir, constants.ConfigDirectoryMode)
+	if err != nil {
+		return "", err
+	}
+	return path.Join(userConfigDir, configFileName), nil
+}
+
+// EnvironmentVariable returns the name of the environment variable that overrides the setting
+func EnvironmentVariable(key string) string {
+	return EnvironmentPrefix + "_" + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(key))
+}
+
+// Load reads the configuration file. Settings in the environment take precedence over the file, and the
+// file takes precedence over the defaults.
+func Load() error {
+	fileSettings, err := readFile()
+	if err != nil {
+		return err
+	}
+	loaded := newSettings()
+	err = loaded.MergeConfigMap(fileSettings.AllSettings())
+	if err != nil {
+		return err
+	}
+	settingsMtx.Lock()
+	settings = loaded
+	settingsMtx.Unlock()
+	now.WeekStartDay = WeekStart()
+	return nil
+}
+
+// Lookup returns the named Key
+func Lookup(key string) (Key, bool) {
+	for _, k := range Keys {
+		if k.Name == key {
+			return k, true
+		}
+	}
+	return Key{}, false
+}
+
+// Get returns the value of the setting
+func Get(key string) interface{} {
+	settingsMtx.RLock()
+	defer settingsMtx.RUnlock()
+	return settings.Get(key)
+}
+
+// GetString returns the value of the setting as a string
+func GetString(key string) string {
+	settingsMtx.RLock()
+	defer settingsMtx.RUnlock()
+	return settings.GetString(key)
+}
+
+// GetBool returns the value of the setting as a bool
+func GetBool(key string) bool {
+	settingsMtx.RLock()
+	defer settingsMtx.RUnlock()
+	return settings.GetBool(key)
+}
+
+// GetInt returns the value of the setting as an int
+func GetInt(key string) int {
+	settingsMtx.RLock()
+	defer settingsMtx.RUnlock()
+	return settings.GetInt(key)
+}
+
+// Source returns where the value of the setting comes from: SourceEnvironment, SourceFile or SourceDefault
+func Source(key string) string {
+	if _, ok := os.LookupEnv(EnvironmentVariable(key)); ok {
+		return SourceEnvironment
+	}
+	fileSettings, err := readFile()
+	if err == nil && fileSettings.IsSet(key) {
+		return SourceFile
+	}
+	return SourceDefault
+}
+
+// Set validates the value and saves it in the configuration file
+func Set(key string, value string) error {
+	k, ok := Lookup(key)
+	if !ok {
+		return tterrors.ErrUnknownConfigKey{Key: key}
+	}
+	typedValue, err := k.parse(value)
+	if err != nil {
+		return err
+	}
+	fileSettings, err := readFile()
+	if err != nil {
+		return err
+	}
+	fileSettings.Set(key, typedValue)
+	fileName, err := ConfigFile()
+	if err != nil {
+		return err
+	}
+	err = fileSettings.WriteConfigAs(fileName)
+	if err != nil {
+		return err
+	}
+	err = os.Chmod(fileName, configFileMode)
+	if err != nil {
+		return err
+	}
+	return Load()
+}
+
+// TimestampFormat returns the layout used to display timestamps
+func TimestampFormat() string {
+	return GetString(KeyTimestampFormat)
+}
+
+// DateFormat returns the layout used to display dates
+func DateFormat() string {
+	return GetString(KeyDateFormat)
+}
+
+// WeekStart returns the first day of the week
+func WeekStart() time.Weekday {
+	weekday, err := parseWeekday(GetString(KeyWeekStart))
+	if err != nil {
+		return time.Sunday
+	}
+	return weekday
+}
+
+// FormatDuration returns the duration as it is displayed with the configured duration format
+func FormatDuration(d time.Duration) string {
+	switch GetString(KeyDurationFormat) {
+	case DurationFormatClock:
+		d = d.Round(time.Second)
+		sign := ""
+		if d < 0 {
+			sign = "-"
+			d = -d
+		}
+		return fmt.Sprintf("%s%d:%02d:%02d", sign, int(d.Hours()), int(d.Minutes())%minutesPerHour, int(d.Seconds())%minutesPerHour)
+	case DurationFormatDecimal:
+		return strconv.FormatFloat(d.Hours(), 'f', 2, 64) + "h"
+	default:
+		return d.String()
+	}
+}
+
+// DailyGoal returns the time to track each day
+func DailyGoal() time.Duration {
+	goal, err := parseDailyGoal(GetString(KeyDailyGoal))
+	if err != nil {
+		return 0
+	}
+	return goal
+}
+
+// NotificationsAllowed returns true if desktop notifications are enabled and the time is not within the quiet hours.
+// Quiet hours that end before they start continue past midnight.
+func NotificationsAllowed(at time.Time) bool {
+	if !GetBool(KeyNotificationsEnabled) {
+		return false
+	}
+	start, startErr := parseTimeOfDay(GetString(KeyNotificationsQuietHoursStart))
+	end, endErr := parseTimeOfDay(GetString(KeyNotificationsQuietHoursEnd))
+	if startErr != nil || endErr != nil || start < 0 || end < 0 || start == end {
+		return true
+	}
+	minute := at.Hour()*minutesPerHour + at.Minute()
+	if start < end {
+		return minute < start || minute >= end
+	}
+	return minute < start && minute >= end
+}
+
+// parse converts the value to the type of the setting's default and validates it
+func (k Key) parse(value string) (interface{}, error) {
+	var typedValue interface{} = value
+	if _, isBool := k.Default.(bool); isBool {
+		boolValue, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be true or false", k.Name)
+		}
+		typedValue = boolValue
+	}
+	if _, isInt := k.Default.(int); isInt {
+		intValue, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a whole number", k.Name)
+		}
+		typedValue = intValue
+	}
+	if len(k.Values) > 0 {
+		err := oneOf(value, k.Values)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if k.Validate != nil {
+		err := k.Validate(value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return typedValue, nil
+}
+
+// newSettings returns a viper instance with the defaults and environment variables set up
+func newSettings() *viper.Viper {
+	v := viper.New()
+	for _, k := range Keys {
+		v.SetDefault(k.Name, k.Default)
+	}
+	v.SetEnvPrefix(EnvironmentPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+	v.AutomaticEnv()
+	return v
+}
+
+// readFile reads only the settings in the configuration file
+func readFile() (*viper.Viper, error) {
+	fileName, err := ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	fileSettings := viper.New()
+	fileSettings.SetConfigFile(fileName)
+	fileSettings.SetConfigType(configFileType)
+	err = fileSettings.ReadInConfig()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	return fileSettings, nil
+}
+
+func oneOf(value string, allowed []string) error {
+	for _, allowedValue := range allowed {
+		if value == allowedValue {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s is not one of: %s", value, strings.Join(allowed, ", "))
+}
+
+func validateWeekday(value string) error {
+	_, err := parseWeekday(value)
+	return err
+}
+
+func parseWeekday(value string) (time.Weekday, error) {
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if strings.EqualFold(value, weekday.String()) {
+			return weekday, nil
+		}
+	}
+	return time.Sunday, fmt.Errorf("%s is not a day of the week", value)
+}
+
+func validateRecentTasks(value string) error {
+	count, err := strconv.Atoi(value)
+	if err != nil || count < 0 || count > maxRecentTasks {
+		return fmt.Errorf("%s is not a number from 0 to %d", value, maxRecentTasks)
+	}
+	return nil
+}
+
+func validateKeepCount(value string) error {
+	count, err := strconv.Atoi(value)
+	if err != nil || count < 0 {
+		return fmt.Errorf("%s is not a number of 0 or more", value)
+	}
+	return nil
+}
+
+func validateDailyGoal(value string) error {
+	_, err := parseDailyGoal(value)
+	return err
+}
+
+func parseDailyGoal(value string) (time.Duration, error) {
+	goal, err := time.ParseDuration(value)
+	if err != nil || goal <= 0 || goal > maxDailyGoal {
+		return 0, fmt.Errorf("%s is not a duration of up to 24 hours, such as 7h30m", value)
+	}
+	return goal, nil
+}
+
+func validateTimeOfDay(value string) error {
+	_, err := parseTimeOfDay(value)
+	return err
+}
+
+// parseTimeOfDay returns the number of minutes after midnight of a HH:MM time, or -1 if the value is empty
+func parseTimeOfDay(value string) (int, error) {
+	if value == "" {
+		return -1, nil
+	}
+	timeOfDay, err := time.Parse(quietHoursLayout, value)
+	if err != nil {
+		return -1, fmt.Errorf("%s is not a time of day like 22:30", value)
+	}
+	return timeOfDay.Hour()*minutesPerHour + timeOfDay.Minute(), nil
+}
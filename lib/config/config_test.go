@@ -0,0 +1,102 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func useTempConfigFile(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "timetracker.yaml")
+	SetConfigFile(fileName)
+	t.Cleanup(func() {
+		SetConfigFile("")
+		_ = Load()
+	})
+	require.Nil(t, Load())
+}
+
+func TestUnit_Config_Defaults(t *testing.T) {
+	useTempConfigFile(t)
+	require.Equal(t, "info", GetString(KeyLogLevel))
+	require.True(t, GetBool(KeyTrayStopTaskConfirm))
+	require.Equal(t, time.Sunday, WeekStart())
+	require.Equal(t, SourceDefault, Source(KeyWeekStart))
+}
+
+func TestUnit_Config_Precedence(t *testing.T) {
+	useTempConfigFile(t)
+	require.Nil(t, Set(KeyWeekStart, "monday"))
+	require.Equal(t, time.Monday, WeekStart())
+	require.Equal(t, SourceFile, Source(KeyWeekStart))
+
+	t.Setenv(EnvironmentVariable(KeyWeekStart), "friday")
+	require.Equal(t, "TIMETRACKER_WEEK_START", EnvironmentVariable(KeyWeekStart))
+	require.Nil(t, Load())
+	require.Equal(t, time.Friday, WeekStart())
+	require.Equal(t, SourceEnvironment, Source(KeyWeekStart))
+}
+
+func TestUnit_Config_SetValidation(t *testing.T) {
+	useTempConfigFile(t)
+	err := Set("no-such-key", "value")
+	require.True(t, errors.As(err, &tterrors.ErrUnknownConfigKey{}))
+	require.NotNil(t, Set(KeyReportOutputFormat, "pdf"))
+	require.NotNil(t, Set(KeyWeekStart, "someday"))
+	require.NotNil(t, Set(KeyReportDeleted, "maybe"))
+	require.NotNil(t, Set(KeyTrayRecentTasks, "many"))
+	require.NotNil(t, Set(KeyTrayRecentTasks, "-1"))
+	require.Nil(t, Set(KeyTrayRecentTasks, "8"))
+	require.Equal(t, 8, GetInt(KeyTrayRecentTasks))
+	require.NotNil(t, Set(KeyTrayIcon, "sparkles"))
+	require.Nil(t, Set(KeyTrayIcon, TrayIconProgress))
+	require.NotNil(t, Set(KeyDailyGoal, "eight hours"))
+	require.NotNil(t, Set(KeyDailyGoal, "25h"))
+	require.Equal(t, 8*time.Hour, DailyGoal())
+	require.Nil(t, Set(KeyDailyGoal, "7h30m"))
+	require.Equal(t, 450*time.Minute, DailyGoal())
+	require.Nil(t, Set(KeyReportDeleted, "true"))
+	require.True(t, GetBool(KeyReportDeleted))
+	require.Equal(t, SourceDefault, Source(KeyReportOutputFormat))
+}
+
+func TestUnit_Config_FormatDuration(t *testing.T) {
+	useTempConfigFile(t)
+	duration := 90*time.Minute + 5*time.Second
+	require.Equal(t, "1h30m5s", FormatDuration(duration))
+	require.Nil(t, Set(KeyDurationFormat, DurationFormatClock))
+	require.Equal(t, "1:30:05", FormatDuration(duration))
+	require.Equal(t, "26:00:00", FormatDuration(26*time.Hour))
+	require.Nil(t, Set(KeyDurationFormat, DurationFormatDecimal))
+	require.Equal(t, "1.50h", FormatDuration(90*time.Minute))
+	require.NotNil(t, Set(KeyDurationFormat, "roman"))
+}
+
+func TestUnit_Config_NotificationsAllowed(t *testing.T) {
+	useTempConfigFile(t)
+	at := func(hour int, minute int) time.Time {
+		return time.Date(2024, time.March, 6, hour, minute, 0, 0, time.Local)
+	}
+	require.True(t, NotificationsAllowed(at(23, 0)))
+	require.NotNil(t, Set(KeyNotificationsQuietHoursStart, "25:00"))
+
+	require.Nil(t, Set(KeyNotificationsQuietHoursStart, "22:30"))
+	require.True(t, NotificationsAllowed(at(23, 0)), "quiet hours have no end")
+	require.Nil(t, Set(KeyNotificationsQuietHoursEnd, "07:00"))
+	require.False(t, NotificationsAllowed(at(23, 0)))
+	require.False(t, NotificationsAllowed(at(6, 59)))
+	require.True(t, NotificationsAllowed(at(7, 0)))
+	require.True(t, NotificationsAllowed(at(22, 29)))
+
+	require.Nil(t, Set(KeyNotificationsQuietHoursStart, "12:00"))
+	require.Nil(t, Set(KeyNotificationsQuietHoursEnd, "13:00"))
+	require.False(t, NotificationsAllowed(at(12, 30)))
+	require.True(t, NotificationsAllowed(at(13, 30)))
+
+	require.Nil(t, Set(KeyNotificationsEnabled, "false"))
+	require.False(t, NotificationsAllowed(at(13, 30)))
+}
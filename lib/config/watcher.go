@@ -0,0 +1,126 @@
+package config
+
+import (
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// changeDebounceDelay is how long to wait for writes to the configuration file to settle before reading it
+	changeDebounceDelay = 100 * time.Millisecond
+	watcherChanSize     = 1
+)
+
+// WatcherData is the main data struct of the Watcher
+type WatcherData struct {
+	log         zerolog.Logger
+	fileWatcher *fsnotify.Watcher
+	changes     chan bool
+	quitChan    chan bool
+	fileName    string
+	waitGroup   sync.WaitGroup
+}
+
+// Watcher reloads the configuration when the configuration file changes, for example when another app saves a setting
+type Watcher interface {
+	Changes() <-chan bool
+	Close() error
+}
+
+// NewWatcher starts watching the configuration file for changes
+func NewWatcher() (Watcher, error) {
+	fileName, err := ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	fileName, err = filepath.Abs(fileName)
+	if err != nil {
+		return nil, err
+	}
+	fileWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	// The directory is watched since the file may not exist yet, and viper replaces it when it is written
+	err = fileWatcher.Add(filepath.Dir(fileName))
+	if err != nil {
+		_ = fileWatcher.Close()
+		return nil, err
+	}
+	w := &WatcherData{
+		log:         logger.GetStructLogger("config.WatcherData"),
+		fileWatcher: fileWatcher,
+		changes:     make(chan bool, watcherChanSize),
+		quitChan:    make(chan bool, 1),
+		fileName:    fileName,
+		waitGroup:   sync.WaitGroup{},
+	}
+	w.waitGroup.Add(1)
+	go w.watchLoop()
+	return w, nil
+}
+
+// Changes returns a channel that receives a value after the configuration has been reloaded
+func (w *WatcherData) Changes() <-chan bool {
+	return w.changes
+}
+
+// Close stops watching the configuration file
+func (w *WatcherData) Close() error {
+	w.quitChan <- true
+	w.waitGroup.Wait()
+	return w.fileWatcher.Close()
+}
+
+func (w *WatcherData) watchLoop() {
+	log := logger.GetFuncLogger(w.log, "watchLoop")
+	defer w.waitGroup.Done()
+	debounceTimer := time.NewTimer(changeDebounceDelay)
+	debounceTimer.Stop()
+	for {
+		select {
+		case <-w.quitChan:
+			debounceTimer.Stop()
+			return
+		case event, ok := <-w.fileWatcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) == w.fileName {
+				debounceTimer.Reset(changeDebounceDelay)
+			}
+		case err, ok := <-w.fileWatcher.Errors:
+			if !ok {
+				return
+			}
+			log.Err(err).
+				Msg("error watching configuration file")
+		case <-debounceTimer.C:
+			w.reload()
+		}
+	}
+}
+
+// reload reads the configuration file and reports the change
+func (w *WatcherData) reload() {
+	log := logger.GetFuncLogger(w.log, "reload")
+	err := Load()
+	if err != nil {
+		log.Err(err).
+			Msg("error reloading configuration file")
+		return
+	}
+	log.Debug().
+		Str("file", w.fileName).
+		Msg("configuration reloaded")
+	select {
+	case w.changes <- true:
+	default:
+		// A change that has not been received yet already covers this one
+	}
+}
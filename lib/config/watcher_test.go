@@ -0,0 +1,32 @@
+package config
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	changeWaitTimeout = 5 * time.Second
+)
+
+func TestUnit_Watcher_FileChanged(t *testing.T) {
+	useTempConfigFile(t)
+	watcher, err := NewWatcher()
+	require.Nil(t, err)
+	defer func() {
+		require.Nil(t, watcher.Close())
+	}()
+	fileName, err := ConfigFile()
+	require.Nil(t, err)
+	// Another app saves a setting
+	require.Nil(t, os.WriteFile(fileName, []byte("gui:\n  theme: dark\n"), configFileMode))
+	select {
+	case <-watcher.Changes():
+		require.Equal(t, ThemeDark, GetString(KeyGUITheme))
+	case <-time.After(changeWaitTimeout):
+		t.Fatal("the configuration change was not reported")
+	}
+}
@@ -12,7 +12,7 @@ const (
 	UnicodeHeavyCheckmark = "✔"
 	// UnicodeHeavyX is the character that represents an error
 	UnicodeHeavyX = "✘"
-	// ActionLoopDelaySeconds is the number of seconds to delay in the ActionLoop before running the loop again
+	// ActionLoopDelaySeconds is the number of seconds between checks for database changes when change notifications are unavailable
 	ActionLoopDelaySeconds = 5
 
 	// TimesheetStatusIdle represents an idle timesheet
@@ -22,15 +22,22 @@ const (
 	// TimesheetStatusError represents a timesheet error
 	TimesheetStatusError
 
+	// ActionLoopIdleDelaySeconds is the number of seconds between checks for database changes that change notifications may have missed
+	ActionLoopIdleDelaySeconds = 30
+	// BackupCheckDelayMinutes is the number of minutes between checks for a daily backup that is due
+	BackupCheckDelayMinutes = 15
+
 	// DefaultDatabaseFileName is the default file name of the timetracker database
 	DefaultDatabaseFileName = "timetracker.db"
 
+	// TrayPidfile is the pidfile in the timetracker config directory that the tray app holds while it is running
+	TrayPidfile = "timetracker-tray.pid"
+	// GUIPidfile is the pidfile in the timetracker config directory that the GUI app holds while it is running
+	GUIPidfile = "timetracker-gui.pid"
+
 	// ConfigDirectoryMode is the octal mode of the timetracker config directory if it needs to be created
 	ConfigDirectoryMode = 0755
 
 	// DefaultLogLevel is the default logger level
 	DefaultLogLevel = "info"
-
-	// PrefKeyCloseWindowStopTask is the preferences key for the flag which causes the main window to close after creating a new task
-	PrefKeyCloseWindowStopTask = "close-window:stop-task"
 )
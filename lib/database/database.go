@@ -12,6 +12,8 @@ import (
 var (
 	// dbInstance is the singleton database handle
 	dbInstance *gorm.DB
+	// dbFileName is the file name of the most recently opened database
+	dbFileName = ""
 
 	gormConfig = &gorm.Config{
 		Logger: newGormLogger(),
@@ -24,9 +26,27 @@ func Open(fileName string) (*gorm.DB, error) {
 	log := logger.GetFuncLogger(databaseLog, "Open")
 	dsn := fmt.Sprintf("file:%s?_foreign_keys=1&_journal_mode=WAL&_mode=rwc", fileName)
 	log.Printf("opening sqlite db at %s\n", dsn)
+	db, err := gorm.Open(sqlite.Open(dsn), gormConfig)
+	if err != nil {
+		return nil, err
+	}
+	dbFileName = fileName
+	return db, nil
+}
+
+// OpenReadOnly opens a SQLite database file for reading only; it does not change the file name that FileName returns
+func OpenReadOnly(fileName string) (*gorm.DB, error) {
+	log := logger.GetFuncLogger(databaseLog, "OpenReadOnly")
+	dsn := fmt.Sprintf("file:%s?_foreign_keys=1&mode=ro", fileName)
+	log.Printf("opening sqlite db at %s\n", dsn)
 	return gorm.Open(sqlite.Open(dsn), gormConfig)
 }
 
+// FileName returns the file name of the most recently opened database
+func FileName() string {
+	return dbFileName
+}
+
 // Close closes an open database connection
 func Close(db *gorm.DB) {
 	log := logger.GetFuncLogger(databaseLog, "Close")
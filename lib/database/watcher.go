@@ -0,0 +1,211 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/neflyte/timetracker/lib/constants"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+const (
+	// changeDebounceDelay is how long to wait for file writes to settle before checking for a change
+	changeDebounceDelay = 100 * time.Millisecond
+	walFileSuffix       = "-wal"
+	journalFileSuffix   = "-journal"
+)
+
+// ChangeWatcherData is the main data struct of the ChangeWatcher
+type ChangeWatcherData struct {
+	log         zerolog.Logger
+	conn        *sql.Conn
+	fileWatcher *fsnotify.Watcher
+	changes     chan struct{}
+	quitChan    chan bool
+	watchNames  map[string]bool
+	waitGroup   sync.WaitGroup
+	pollDelay   time.Duration
+	lastVersion int64
+}
+
+// ChangeWatcher notifies when the database has been changed by any connection, including other processes
+type ChangeWatcher interface {
+	Changes() <-chan struct{}
+	Close() error
+}
+
+// NewChangeWatcher starts watching the database for changes. Writes to the database file are watched when
+// possible, and the database is polled when they cannot be watched. A change is only reported if the SQLite
+// data_version of the database has changed.
+func NewChangeWatcher(db *gorm.DB, fileName string) (ChangeWatcher, error) {
+	log := logger.GetFuncLogger(databaseLog, "NewChangeWatcher")
+	if db == nil {
+		return nil, errors.New("cannot watch a nil database")
+	}
+	sqldb, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	// PRAGMA data_version only reports changes made by other connections, so a dedicated connection is used
+	conn, err := sqldb.Conn(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	w := &ChangeWatcherData{
+		log:        logger.GetStructLogger("ChangeWatcherData"),
+		conn:       conn,
+		changes:    make(chan struct{}, 1),
+		quitChan:   make(chan bool, 1),
+		watchNames: make(map[string]bool),
+		waitGroup:  sync.WaitGroup{},
+		pollDelay:  constants.ActionLoopIdleDelaySeconds * time.Second,
+	}
+	w.lastVersion, err = w.dataVersion()
+	if err != nil {
+		closeErr := conn.Close()
+		if closeErr != nil {
+			log.Err(closeErr).
+				Msg("error closing watcher connection")
+		}
+		return nil, err
+	}
+	err = w.watchFile(fileName)
+	if err != nil {
+		log.Warn().
+			Err(err).
+			Str("fileName", fileName).
+			Msg("unable to watch database file; polling for changes instead")
+		w.pollDelay = constants.ActionLoopDelaySeconds * time.Second
+	}
+	w.waitGroup.Add(1)
+	go w.watchLoop()
+	return w, nil
+}
+
+// Changes returns a channel that receives a value after the database has changed. Changes that happen
+// before the previous one has been received are coalesced.
+func (w *ChangeWatcherData) Changes() <-chan struct{} {
+	return w.changes
+}
+
+// Close stops watching the database
+func (w *ChangeWatcherData) Close() error {
+	w.quitChan <- true
+	w.waitGroup.Wait()
+	if w.fileWatcher != nil {
+		err := w.fileWatcher.Close()
+		if err != nil {
+			w.log.Err(err).
+				Msg("error closing file watcher")
+		}
+	}
+	return w.conn.Close()
+}
+
+// watchFile watches the directory containing the database file for writes to the database or its journal
+func (w *ChangeWatcherData) watchFile(fileName string) error {
+	if fileName == "" {
+		return errors.New("the database file name is unknown")
+	}
+	absFileName, err := filepath.Abs(fileName)
+	if err != nil {
+		return err
+	}
+	fileWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	// Watching the directory rather than the files means that journal files can come and go
+	err = fileWatcher.Add(filepath.Dir(absFileName))
+	if err != nil {
+		closeErr := fileWatcher.Close()
+		if closeErr != nil {
+			w.log.Err(closeErr).
+				Msg("error closing file watcher")
+		}
+		return err
+	}
+	w.watchNames[absFileName] = true
+	w.watchNames[absFileName+walFileSuffix] = true
+	w.watchNames[absFileName+journalFileSuffix] = true
+	w.fileWatcher = fileWatcher
+	return nil
+}
+
+func (w *ChangeWatcherData) watchLoop() {
+	log := logger.GetFuncLogger(w.log, "watchLoop")
+	defer w.waitGroup.Done()
+	var fileEvents chan fsnotify.Event
+	var fileErrors chan error
+	if w.fileWatcher != nil {
+		fileEvents = w.fileWatcher.Events
+		fileErrors = w.fileWatcher.Errors
+	}
+	debounceTimer := time.NewTimer(changeDebounceDelay)
+	debounceTimer.Stop()
+	pollTicker := time.NewTicker(w.pollDelay)
+	defer pollTicker.Stop()
+	for {
+		select {
+		case <-w.quitChan:
+			debounceTimer.Stop()
+			return
+		case event, ok := <-fileEvents:
+			if !ok {
+				fileEvents = nil
+				continue
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create) != 0 && w.watchNames[filepath.Clean(event.Name)] {
+				debounceTimer.Reset(changeDebounceDelay)
+			}
+		case err, ok := <-fileErrors:
+			if !ok {
+				fileErrors = nil
+				continue
+			}
+			log.Err(err).
+				Msg("error watching database file")
+		case <-debounceTimer.C:
+			w.checkVersion()
+		case <-pollTicker.C:
+			w.checkVersion()
+		}
+	}
+}
+
+// checkVersion reports a change if the data_version of the database is different to the last check
+func (w *ChangeWatcherData) checkVersion() {
+	log := logger.GetFuncLogger(w.log, "checkVersion")
+	version, err := w.dataVersion()
+	if err != nil {
+		log.Err(err).
+			Msg("error reading database data_version")
+		return
+	}
+	if version == w.lastVersion {
+		return
+	}
+	log.Trace().
+		Int64("lastVersion", w.lastVersion).
+		Int64("version", version).
+		Msg("database changed")
+	w.lastVersion = version
+	select {
+	case w.changes <- struct{}{}:
+	default:
+		// A change is already waiting to be received
+	}
+}
+
+func (w *ChangeWatcherData) dataVersion() (int64, error) {
+	var version int64
+	err := w.conn.QueryRowContext(context.Background(), "PRAGMA data_version").Scan(&version)
+	return version, err
+}
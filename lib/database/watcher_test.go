@@ -0,0 +1,64 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	changeWaitTimeout = 5 * time.Second
+	noChangeWaitDelay = 500 * time.Millisecond
+)
+
+func TestUnit_ChangeWatcher_ReportsChangesFromOtherConnections(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "watcher_test.db")
+	db, err := Open(fileName)
+	require.Nil(t, err)
+	defer Close(db)
+	err = db.Exec("CREATE TABLE thing (id INTEGER PRIMARY KEY, name TEXT)").Error
+	require.Nil(t, err)
+
+	watcher, err := NewChangeWatcher(db, fileName)
+	require.Nil(t, err)
+	defer func() {
+		require.Nil(t, watcher.Close())
+	}()
+
+	// Nothing has changed yet
+	select {
+	case <-watcher.Changes():
+		t.Fatal("a change was reported before the database changed")
+	case <-time.After(noChangeWaitDelay):
+	}
+
+	// Write to the database from a separate handle, as another process would
+	otherDB, err := Open(fileName)
+	require.Nil(t, err)
+	defer Close(otherDB)
+	err = otherDB.Exec("INSERT INTO thing (name) VALUES (?)", "changed").Error
+	require.Nil(t, err)
+	select {
+	case <-watcher.Changes():
+	case <-time.After(changeWaitTimeout):
+		t.Fatal("the change was not reported")
+	}
+
+	// Reading does not count as a change
+	var count int64
+	err = otherDB.Raw("SELECT COUNT(*) FROM thing").Scan(&count).Error
+	require.Nil(t, err)
+	require.Equal(t, int64(1), count)
+	select {
+	case <-watcher.Changes():
+		t.Fatal("a change was reported after reading the database")
+	case <-time.After(noChangeWaitDelay):
+	}
+}
+
+func TestUnit_ChangeWatcher_NilDatabase(t *testing.T) {
+	_, err := NewChangeWatcher(nil, "")
+	require.NotNil(t, err)
+}
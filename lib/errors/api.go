@@ -0,0 +1,46 @@
+package errors
+
+import "fmt"
+
+const (
+	// StartAPIServerError represents an error that occurs when starting the REST API server
+	StartAPIServerError = "error starting API server"
+	// UnauthorizedAPIError represents an error that occurs when a request does not include a valid API token
+	UnauthorizedAPIError = "a valid API token is required"
+	// NotFoundAPIError represents an error that occurs when a request is made for a resource that does not exist
+	NotFoundAPIError = "resource not found"
+	// MethodNotAllowedAPIError represents an error that occurs when a request uses an HTTP method that the resource does not support
+	MethodNotAllowedAPIError = "method not allowed"
+	// InvalidIDAPIError represents an error that occurs when a request contains an ID that is not a number
+	InvalidIDAPIError = "invalid ID"
+	// InvalidBodyAPIError represents an error that occurs when a request body cannot be decoded
+	InvalidBodyAPIError = "invalid request body"
+	// InvalidDateAPIError represents an error that occurs when a request contains a date that cannot be parsed
+	InvalidDateAPIError = "invalid date; expected YYYY-MM-DD"
+	// MissingDateRangeAPIError represents an error that occurs when a request is missing the start or end date
+	MissingDateRangeAPIError = "both start and end dates must be specified"
+	// StreamingUnsupportedAPIError represents an error that occurs when the connection does not support server-sent events
+	StreamingUnsupportedAPIError = "streaming is not supported by this connection"
+)
+
+// ErrAPIRequest represents an error in an API request that is returned to the client
+type ErrAPIRequest struct {
+	// Wrapped is the underlying error, if any
+	Wrapped error
+	// Details is the error message returned to the client
+	Details string
+	// StatusCode is the HTTP status code returned to the client
+	StatusCode int
+}
+
+func (e ErrAPIRequest) Error() string {
+	if e.Wrapped != nil {
+		return fmt.Sprintf("%s: %s", e.Details, e.Wrapped.Error())
+	}
+	return e.Details
+}
+
+// Unwrap implements a Wrapped error
+func (e ErrAPIRequest) Unwrap() error {
+	return e.Wrapped
+}
@@ -0,0 +1,68 @@
+package errors
+
+import "fmt"
+
+const (
+	// CreateBackupError represents an error that occurs when creating a backup
+	CreateBackupError = "error creating backup"
+	// RestoreBackupError represents an error that occurs when restoring a backup
+	RestoreBackupError = "error restoring backup"
+	// ReadBackupError represents an error that occurs when reading a backup
+	ReadBackupError = "error reading backup"
+	// ReadPassphraseError represents an error that occurs when reading the passphrase of a backup
+	ReadPassphraseError = "error reading passphrase"
+	// PassphraseRequiredError represents an error that occurs when an encrypted backup is read without a passphrase
+	PassphraseRequiredError = "the backup is encrypted; a passphrase is required"
+	// WrongPassphraseError represents an error that occurs when an encrypted backup cannot be decrypted
+	WrongPassphraseError = "the passphrase is wrong or the backup is damaged"
+	// PassphraseMismatchError represents an error that occurs when the passphrase and its confirmation differ
+	PassphraseMismatchError = "the passphrases do not match"
+	// EmptyPassphraseError represents an error that occurs when an empty passphrase is entered
+	EmptyPassphraseError = "the passphrase is empty"
+	// NoTerminalPassphraseError represents an error that occurs when a passphrase is needed but cannot be prompted for
+	NoTerminalPassphraseError = "a passphrase is required; set TIMETRACKER_BACKUP_PASSPHRASE or run in a terminal"
+	// AutomaticBackupError represents an error that occurs when creating an automatic backup
+	AutomaticBackupError = "error creating automatic backup"
+	// ListBackupsError represents an error that occurs when listing the backups in the backup directory
+	ListBackupsError = "error listing backups"
+	// PruneBackupsError represents an error that occurs when removing old backups from the backup directory
+	PruneBackupsError = "error pruning backups"
+	// AppRunningRestoreError represents an error that occurs when a backup is restored while an app has the database open
+	AppRunningRestoreError = "quit the app before restoring a backup; it has the database open"
+	// BackupIntegrityError represents an error that occurs when a backup fails verification
+	BackupIntegrityError = "the backup failed verification"
+)
+
+// ErrWrongPassphrase represents an error that occurs when an encrypted backup cannot be decrypted
+type ErrWrongPassphrase struct{}
+
+func (e ErrWrongPassphrase) Error() string {
+	return WrongPassphraseError
+}
+
+// ErrPassphraseRequired represents an error that occurs when an encrypted backup is read without a passphrase
+type ErrPassphraseRequired struct{}
+
+func (e ErrPassphraseRequired) Error() string {
+	return PassphraseRequiredError
+}
+
+// ErrAppRunning represents an error that occurs when a backup is restored while the tray or GUI app is running
+type ErrAppRunning struct {
+	// App is the name of the running app
+	App string
+}
+
+func (e ErrAppRunning) Error() string {
+	return fmt.Sprintf("%s: %s", AppRunningRestoreError, e.App)
+}
+
+// ErrBackupIntegrity represents an error that occurs when a backup fails verification
+type ErrBackupIntegrity struct {
+	// Reason describes the check that failed
+	Reason string
+}
+
+func (e ErrBackupIntegrity) Error() string {
+	return fmt.Sprintf("%s: %s", BackupIntegrityError, e.Reason)
+}
@@ -0,0 +1,21 @@
+package errors
+
+import "fmt"
+
+const (
+	// LoadConfigError represents an error that occurs when reading the configuration file
+	LoadConfigError = "error reading configuration file"
+	// SetConfigError represents an error that occurs when saving a setting in the configuration file
+	SetConfigError = "error saving setting"
+	// EditConfigError represents an error that occurs when editing the configuration file
+	EditConfigError = "error editing configuration file"
+)
+
+// ErrUnknownConfigKey represents an error that occurs when a configuration setting does not exist
+type ErrUnknownConfigKey struct {
+	Key string
+}
+
+func (e ErrUnknownConfigKey) Error() string {
+	return fmt.Sprintf("unknown configuration key %s", e.Key)
+}
@@ -0,0 +1,35 @@
+package errors
+
+import "fmt"
+
+const (
+	// StartIPCServerError represents an error that occurs when the GUI cannot start listening for IPC requests
+	StartIPCServerError = "error starting IPC server"
+	// SendIPCRequestError represents an error that occurs when sending a request to the running GUI
+	SendIPCRequestError = "error sending request to the GUI"
+	// LaunchGUIError represents an error that occurs when launching the GUI
+	LaunchGUIError = "error launching the GUI"
+	// UnknownCommandIPCError represents an error that occurs when an IPC request contains a command that does not exist
+	UnknownCommandIPCError = "unknown IPC command"
+	// AlreadyStartedIPCServerError represents an error that occurs when the IPC server is started a second time
+	AlreadyStartedIPCServerError = "the IPC server is already started"
+)
+
+// ErrGUINotRunning represents an error that occurs when there is no running GUI to receive an IPC request
+type ErrGUINotRunning struct {
+	// Wrapped is the error that occurred when connecting to the GUI
+	Wrapped error
+}
+
+func (e ErrGUINotRunning) Error() string {
+	details := "(none)"
+	if e.Wrapped != nil {
+		details = e.Wrapped.Error()
+	}
+	return fmt.Sprintf("the GUI is not running; details: %s", details)
+}
+
+// Unwrap implements a Wrapped error
+func (e ErrGUINotRunning) Unwrap() error {
+	return e.Wrapped
+}
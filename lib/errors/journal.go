@@ -0,0 +1,49 @@
+package errors
+
+import "fmt"
+
+const (
+	// UndoError represents an error that occurs when undoing an action
+	UndoError = "error undoing the last action"
+	// RedoError represents an error that occurs when redoing an action
+	RedoError = "error redoing the last undone action"
+	// RecordJournalError represents an error that occurs when recording an action in the journal
+	RecordJournalError = "error recording the action in the journal"
+	// NothingToUndoError represents an error that occurs when there is no action to undo
+	NothingToUndoError = "there is nothing to undo"
+	// NothingToRedoError represents an error that occurs when there is no undone action to redo
+	NothingToRedoError = "there is nothing to redo"
+	// ChangedSinceJournalError represents an error that occurs when a task or timesheet was changed after the action that is undone or redone
+	ChangedSinceJournalError = "it was changed since"
+	// OtherTaskRunningJournalError represents an error that occurs when undoing or redoing an action would leave two tasks running
+	OtherTaskRunningJournalError = "another task is running"
+	// LoadHistoryError represents an error that occurs when loading the audit history of a task or timesheet
+	LoadHistoryError = "error loading history"
+)
+
+// ErrNothingToUndo represents an error that occurs when there is no action to undo
+type ErrNothingToUndo struct{}
+
+func (e ErrNothingToUndo) Error() string {
+	return NothingToUndoError
+}
+
+// ErrNothingToRedo represents an error that occurs when there is no undone action to redo
+type ErrNothingToRedo struct{}
+
+func (e ErrNothingToRedo) Error() string {
+	return NothingToRedoError
+}
+
+// ErrJournalConflict represents an error that occurs when an action cannot be undone or redone without losing
+// a later change
+type ErrJournalConflict struct {
+	// Action is the description of the action
+	Action string
+	// Details is the reason why the action cannot be undone or redone
+	Details string
+}
+
+func (e ErrJournalConflict) Error() string {
+	return fmt.Sprintf("cannot undo or redo %q: %s", e.Action, e.Details)
+}
@@ -0,0 +1,34 @@
+package errors
+
+import "fmt"
+
+const (
+	// ListProfileError represents an error that occurs when listing profiles
+	ListProfileError = "error listing profiles"
+	// CreateProfileError represents an error that occurs when creating a profile
+	CreateProfileError = "error creating profile"
+	// UseProfileError represents an error that occurs when selecting a profile
+	UseProfileError = "error selecting profile"
+	// SwitchProfileError represents an error that occurs when switching to the database of another profile
+	SwitchProfileError = "error switching profile"
+	// InvalidProfileNameError represents an error that occurs when a profile name contains characters that are not allowed
+	InvalidProfileNameError = "profile names may only contain letters, numbers, '-' and '_'"
+)
+
+// ErrProfileNotFound represents an error that occurs when a profile does not exist
+type ErrProfileNotFound struct {
+	Name string
+}
+
+func (e ErrProfileNotFound) Error() string {
+	return fmt.Sprintf("profile %s does not exist", e.Name)
+}
+
+// ErrProfileExists represents an error that occurs when creating a profile that already exists
+type ErrProfileExists struct {
+	Name string
+}
+
+func (e ErrProfileExists) Error() string {
+	return fmt.Sprintf("profile %s already exists", e.Name)
+}
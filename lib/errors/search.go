@@ -0,0 +1,37 @@
+package errors
+
+import "fmt"
+
+const (
+	// SearchIndexUnavailableError represents an error that occurs when a full-text search is made without a search index
+	SearchIndexUnavailableError = "full-text search is not available; SQLite was built without FTS5"
+	// MigrateSearchIndexError represents an error that occurs when creating the full-text search index
+	MigrateSearchIndexError = "error creating the full-text search index"
+	// InvalidSearchQueryError represents an error that occurs when a full-text search query cannot be parsed
+	InvalidSearchQueryError = "invalid search query"
+	// SearchTimesheetError represents an error that occurs when searching for timesheets
+	SearchTimesheetError = "error searching for timesheets"
+)
+
+// ErrSearchIndexUnavailable represents an error that occurs when a full-text search is made without a search index
+type ErrSearchIndexUnavailable struct{}
+
+func (e ErrSearchIndexUnavailable) Error() string {
+	return SearchIndexUnavailableError
+}
+
+// ErrInvalidSearchQuery represents an error that occurs when a full-text search query cannot be parsed
+type ErrInvalidSearchQuery struct {
+	// Wrapped is the error returned by SQLite
+	Wrapped error
+	// Query is the search query
+	Query string
+}
+
+func (e ErrInvalidSearchQuery) Error() string {
+	return fmt.Sprintf("%s %q: %s", InvalidSearchQueryError, e.Query, e.Wrapped)
+}
+
+func (e ErrInvalidSearchQuery) Unwrap() error {
+	return e.Wrapped
+}
@@ -0,0 +1,12 @@
+package errors
+
+const (
+	// MigrateChangeLogError represents an error that occurs when assigning UUIDs to existing tasks and timesheets
+	MigrateChangeLogError = "error preparing the change log"
+	// ExportChangesError represents an error that occurs when exporting changes to the sync folder
+	ExportChangesError = "error exporting changes"
+	// ImportChangesError represents an error that occurs when importing changes from the sync folder
+	ImportChangesError = "error importing changes"
+	// NoSyncFolderError represents an error that occurs when a sync folder was expected but not found
+	NoSyncFolderError = "no sync folder was specified; set sync.folder or use --folder"
+)
@@ -1,6 +1,9 @@
 package errors
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 const (
 	// CreateTaskError represents an error that occurs when creating a new task
@@ -37,6 +40,14 @@ const (
 	UpdateEmptySynopsisTaskError = "cannot update a task to have an empty synopsis"
 	// InvalidTaskDataError represents an error that occurs when a task is found to have invalid data
 	InvalidTaskDataError = "the task is invalid"
+	// AmbiguousTaskError represents an error that occurs when a task name matches more than one task
+	AmbiguousTaskError = "more than one task matches"
+	// PinInvalidTaskError represents an error that occurs when an attempt is made to pin a task that does not exist
+	PinInvalidTaskError = "cannot pin a task that does not exist"
+	// PinTaskError represents an error that occurs when a task cannot be pinned or unpinned
+	PinTaskError = "error pinning task"
+	// InvalidTaskSortError represents an error that occurs when tasks are sorted in an order that does not exist
+	InvalidTaskSortError = "invalid task sort"
 )
 
 // ErrInvalidTaskState represents an error that occurs when a task is in an invalid state
@@ -60,3 +71,27 @@ type ErrInvalidTaskData struct{}
 func (e ErrInvalidTaskData) Error() string {
 	return InvalidTaskDataError
 }
+
+// ErrAmbiguousTask represents an error that occurs when a task name matches more than one task
+type ErrAmbiguousTask struct {
+	// Name is the task name that was searched for
+	Name string
+	// Candidates are the tasks that match the name, best match first
+	Candidates []string
+}
+
+func (e ErrAmbiguousTask) Error() string {
+	return fmt.Sprintf("%s %q: %s", AmbiguousTaskError, e.Name, strings.Join(e.Candidates, ", "))
+}
+
+// ErrInvalidTaskSort represents an error that occurs when tasks are sorted in an order that does not exist
+type ErrInvalidTaskSort struct {
+	// Sort is the name of the sort
+	Sort string
+	// Valid are the names of the sorts that exist
+	Valid []string
+}
+
+func (e ErrInvalidTaskSort) Error() string {
+	return fmt.Sprintf("%s %q; use one of %s", InvalidTaskSortError, e.Sort, strings.Join(e.Valid, ", "))
+}
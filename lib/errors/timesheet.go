@@ -22,6 +22,26 @@ const (
 	UpdateInvalidTimesheetError = "cannot update a timesheet that does not exist"
 	// DeleteInvalidTimesheetError represents an error that occurs when an attempt is made to delete a timesheet with an invalid (nonexistant) ID
 	DeleteInvalidTimesheetError = "cannot delete a timesheet that does not exist"
+	// SaveTimesheetError represents an error that occurs when saving changes to a timesheet
+	SaveTimesheetError = "error saving timesheet"
+	// DeleteTimesheetError represents an error that occurs when deleting a timesheet
+	DeleteTimesheetError = "error deleting timesheet"
+	// StopBeforeStartTimesheetError represents an error that occurs when a timesheet stops before it starts
+	StopBeforeStartTimesheetError = "the stop time must be after the start time"
+	// StopInFutureTimesheetError represents an error that occurs when a timesheet stops in the future
+	StopInFutureTimesheetError = "the stop time cannot be in the future"
+	// StartInFutureTimesheetError represents an error that occurs when a timesheet starts in the future
+	StartInFutureTimesheetError = "the start time cannot be in the future"
+	// AlreadyRunningTimesheetError represents an error that occurs when a timesheet would be running while another one is
+	AlreadyRunningTimesheetError = "another task is already running"
+	// OverlappingTimesheetError represents an error that occurs when a timesheet overlaps the time of another timesheet
+	OverlappingTimesheetError = "the timesheet overlaps another timesheet"
+	// SplitOutsideTimesheetError represents an error that occurs when a timesheet is split at a time outside of it
+	SplitOutsideTimesheetError = "the split time must be between the start and stop times"
+	// SplitTimesheetError represents an error that occurs when splitting a timesheet in two
+	SplitTimesheetError = "error splitting timesheet"
+	// InvalidTimeOfDayError represents an error that occurs when an entered time cannot be parsed
+	InvalidTimeOfDayError = "times must be HH:MM, HH:MM:SS, YYYY-MM-DD HH:MM or YYYY-MM-DD HH:MM:SS"
 )
 
 // ErrInvalidTimesheetState represents an error that occurs when an timesheet is in an invalid state
@@ -33,3 +53,13 @@ type ErrInvalidTimesheetState struct {
 func (e ErrInvalidTimesheetState) Error() string {
 	return fmt.Sprintf("Invalid timesheet state: %s", e.Details)
 }
+
+// ErrOverlappingTimesheet represents an error that occurs when a timesheet overlaps the time of another timesheet
+type ErrOverlappingTimesheet struct {
+	// Other describes the timesheet that is overlapped
+	Other string
+}
+
+func (e ErrOverlappingTimesheet) Error() string {
+	return fmt.Sprintf("%s: %s", OverlappingTimesheetError, e.Other)
+}
@@ -0,0 +1,6 @@
+package errors
+
+const (
+	// RunTUIError represents an error that occurs when running the terminal UI
+	RunTUIError = "error running the terminal UI"
+)
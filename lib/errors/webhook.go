@@ -0,0 +1,50 @@
+package errors
+
+import "fmt"
+
+const (
+	// CreateWebhookError represents an error that occurs when creating a new webhook endpoint
+	CreateWebhookError = "error creating new webhook"
+	// DeleteWebhookError represents an error that occurs when removing a webhook endpoint
+	DeleteWebhookError = "error removing webhook"
+	// ListWebhookError represents an error that occurs when listing webhook endpoints
+	ListWebhookError = "error listing webhooks"
+	// TestWebhookError represents an error that occurs when sending a test event to a webhook endpoint
+	TestWebhookError = "error testing webhook"
+	// DeliverWebhookError represents an error that occurs when delivering queued events to webhook endpoints
+	DeliverWebhookError = "error delivering webhook events"
+	// OverwriteWebhookByCreateError represents an error that occurs when a webhook is about to be overwritten by creating it again
+	OverwriteWebhookByCreateError = "cannot overwrite a webhook by creating it"
+	// EmptyURLWebhookError represents an error that occurs when a webhook URL was expected but not found
+	EmptyURLWebhookError = "cannot create a webhook with an empty URL"
+	// UnknownEventTypeWebhookError represents an error that occurs when a webhook subscribes to an event type that does not exist
+	UnknownEventTypeWebhookError = "unknown event type"
+	// LoadInvalidWebhookError represents an error that occurs when an attempt is made to load a webhook with an invalid (nonexistant) ID
+	LoadInvalidWebhookError = "cannot load a webhook that does not exist"
+	// DeleteInvalidWebhookError represents an error that occurs when an attempt is made to delete a webhook with an invalid (nonexistant) ID
+	DeleteInvalidWebhookError = "cannot delete a webhook that does not exist"
+	// DatabaseNotOpenWebhookError represents an error that occurs when queued events are delivered before the database is opened
+	DatabaseNotOpenWebhookError = "the database is not open"
+)
+
+// ErrInvalidWebhookState represents an error that occurs when a webhook is in an invalid state
+type ErrInvalidWebhookState struct {
+	// Details is any extra information related to the error
+	Details string
+}
+
+func (e ErrInvalidWebhookState) Error() string {
+	return fmt.Sprintf("Invalid webhook state: %s", e.Details)
+}
+
+// ErrWebhookResponse represents an error that occurs when a webhook endpoint responds with an unsuccessful status code
+type ErrWebhookResponse struct {
+	// URL is the address of the webhook endpoint
+	URL string
+	// StatusCode is the HTTP status code of the response
+	StatusCode int
+}
+
+func (e ErrWebhookResponse) Error() string {
+	return fmt.Sprintf("webhook %s responded with status %d", e.URL, e.StatusCode)
+}
@@ -0,0 +1,51 @@
+//go:build !windows
+
+package ipc
+
+import (
+	"errors"
+	"net"
+	"os"
+	"time"
+
+	"github.com/neflyte/timetracker/lib/logger"
+)
+
+const (
+	// endpointFileName is the name of the Unix socket that the GUI listens on
+	endpointFileName = "timetracker-gui.sock"
+	endpointFileMode = 0600
+	dialTimeout      = 2 * time.Second
+)
+
+func listen(endpoint string) (net.Listener, error) {
+	// Only one GUI runs at a time so an existing socket was left behind by a GUI that did not exit cleanly
+	err := os.Remove(endpoint)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	listener, err := net.Listen("unix", endpoint)
+	if err != nil {
+		return nil, err
+	}
+	err = os.Chmod(endpoint, endpointFileMode)
+	if err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+func dial(endpoint string) (net.Conn, error) {
+	return net.DialTimeout("unix", endpoint, dialTimeout)
+}
+
+func cleanupEndpoint(endpoint string) {
+	log := logger.GetFuncLogger(packageLogger, "cleanupEndpoint")
+	err := os.Remove(endpoint)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Err(err).
+			Str("endpoint", endpoint).
+			Msg("error removing IPC socket")
+	}
+}
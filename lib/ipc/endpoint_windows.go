@@ -0,0 +1,56 @@
+package ipc
+
+import (
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/neflyte/timetracker/lib/logger"
+)
+
+const (
+	// endpointFileName is the name of the file containing the loopback TCP port that the GUI listens on
+	endpointFileName = "timetracker-gui.port"
+	endpointFileMode = 0600
+	dialTimeout      = 2 * time.Second
+	loopbackAddress  = "127.0.0.1"
+)
+
+func listen(endpoint string) (net.Listener, error) {
+	listener, err := net.Listen("tcp", net.JoinHostPort(loopbackAddress, "0"))
+	if err != nil {
+		return nil, err
+	}
+	tcpAddr, ok := listener.Addr().(*net.TCPAddr)
+	if !ok {
+		_ = listener.Close()
+		return nil, errors.New("listener address is not a TCP address")
+	}
+	err = os.WriteFile(endpoint, []byte(strconv.Itoa(tcpAddr.Port)), endpointFileMode)
+	if err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+func dial(endpoint string) (net.Conn, error) {
+	portBytes, err := os.ReadFile(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return net.DialTimeout("tcp", net.JoinHostPort(loopbackAddress, strings.TrimSpace(string(portBytes))), dialTimeout)
+}
+
+func cleanupEndpoint(endpoint string) {
+	log := logger.GetFuncLogger(packageLogger, "cleanupEndpoint")
+	err := os.Remove(endpoint)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Err(err).
+			Str("endpoint", endpoint).
+			Msg("error removing IPC port file")
+	}
+}
@@ -0,0 +1,297 @@
+package ipc
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/neflyte/timetracker/lib/constants"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// CommandShow shows the main window
+	CommandShow Command = "show"
+	// CommandStopRunningTask shows the main window and confirms if the running task should be stopped
+	CommandStopRunningTask Command = "stop-running-task"
+	// CommandCreateAndStart shows the main window and then the Create and Start dialog
+	CommandCreateAndStart Command = "create-and-start"
+	// CommandManage shows the main window and then the Manage window
+	CommandManage Command = "manage"
+	// CommandTimesheets shows the main window and then the Timesheets window
+	CommandTimesheets Command = "timesheets"
+	// CommandSettings shows the main window and then the Settings window
+	CommandSettings Command = "settings"
+	// CommandSummary shows the main window and then the report of today
+	CommandSummary Command = "summary"
+	// CommandAbout shows the main window and then the About dialog
+	CommandAbout Command = "about"
+
+	guiExecutableName = "timetracker-gui"
+	requestTimeout    = 5 * time.Second
+)
+
+var (
+	// AllCommands is the list of every command that the GUI accepts
+	AllCommands = []Command{CommandShow, CommandStopRunningTask, CommandCreateAndStart, CommandManage, CommandTimesheets, CommandSettings, CommandSummary, CommandAbout}
+
+	packageLogger   = logger.GetPackageLogger("ipc")
+	endpointPath    = ""
+	endpointPathMtx = sync.RWMutex{}
+)
+
+// Command is an action that the running GUI is asked to perform
+type Command string
+
+// GUIOption returns the command-line option that makes a newly-launched GUI perform the command
+func (c Command) GUIOption() string {
+	if c == CommandShow {
+		return ""
+	}
+	return "-" + string(c)
+}
+
+// Valid determines if the command is one that the GUI accepts
+func (c Command) Valid() bool {
+	for _, command := range AllCommands {
+		if command == c {
+			return true
+		}
+	}
+	return false
+}
+
+// Request is a message sent to the running GUI
+type Request struct {
+	Command Command `json:"command"`
+}
+
+// Response is the reply to a Request
+type Response struct {
+	Error string `json:"error,omitempty"`
+}
+
+// Handler is a function that performs a command in the GUI
+type Handler func(command Command) error
+
+// SetEndpointPath overrides the location of the IPC endpoint
+func SetEndpointPath(endpoint string) {
+	endpointPathMtx.Lock()
+	defer endpointPathMtx.Unlock()
+	endpointPath = endpoint
+}
+
+// EndpointPath returns the location of the IPC endpoint; by default it is in the user config directory
+func EndpointPath() (string, error) {
+	endpointPathMtx.RLock()
+	defer endpointPathMtx.RUnlock()
+	if endpointPath != "" {
+		return endpointPath, nil
+	}
+	userConfigDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	userConfigDir = path.Join(userConfigDir, "timetracker")
+	err = os.MkdirAll(userConfigDir, constants.ConfigDirectoryMode)
+	if err != nil {
+		return "", err
+	}
+	return path.Join(userConfigDir, endpointFileName), nil
+}
+
+// ServerData is the main data struct of the Server
+type ServerData struct {
+	log       zerolog.Logger
+	listener  net.Listener
+	handler   Handler
+	endpoint  string
+	waitGroup sync.WaitGroup
+}
+
+// Server is the interface to the IPC endpoint hosted by the GUI
+type Server interface {
+	Start() error
+	Stop() error
+}
+
+// NewServer returns a Server that performs commands using the supplied handler
+func NewServer(handler Handler) Server {
+	return &ServerData{
+		log:       logger.GetStructLogger("ipc.ServerData"),
+		handler:   handler,
+		waitGroup: sync.WaitGroup{},
+	}
+}
+
+// Start starts listening for requests
+func (s *ServerData) Start() error {
+	log := logger.GetFuncLogger(s.log, "Start")
+	if s.listener != nil {
+		return errors.New(tterrors.AlreadyStartedIPCServerError)
+	}
+	endpoint, err := EndpointPath()
+	if err != nil {
+		return err
+	}
+	listener, err := listen(endpoint)
+	if err != nil {
+		return err
+	}
+	s.endpoint = endpoint
+	s.listener = listener
+	s.waitGroup.Add(1)
+	go s.acceptLoop()
+	log.Debug().
+		Str("endpoint", endpoint).
+		Msg("listening for IPC requests")
+	return nil
+}
+
+// Stop stops listening for requests and removes the endpoint
+func (s *ServerData) Stop() error {
+	if s.listener == nil {
+		return nil
+	}
+	err := s.listener.Close()
+	s.waitGroup.Wait()
+	s.listener = nil
+	cleanupEndpoint(s.endpoint)
+	return err
+}
+
+func (s *ServerData) acceptLoop() {
+	log := logger.GetFuncLogger(s.log, "acceptLoop")
+	defer s.waitGroup.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				log.Debug().
+					Msg("listener closed; exiting loop")
+				return
+			}
+			log.Err(err).
+				Msg("error accepting IPC connection")
+			continue
+		}
+		s.handleConnection(conn)
+	}
+}
+
+func (s *ServerData) handleConnection(conn net.Conn) {
+	log := logger.GetFuncLogger(s.log, "handleConnection")
+	defer func() {
+		closeErr := conn.Close()
+		if closeErr != nil {
+			log.Err(closeErr).
+				Msg("error closing IPC connection")
+		}
+	}()
+	err := conn.SetDeadline(time.Now().Add(requestTimeout))
+	if err != nil {
+		log.Err(err).
+			Msg("error setting IPC connection deadline")
+		return
+	}
+	request := Request{}
+	response := Response{}
+	err = json.NewDecoder(bufio.NewReader(conn)).Decode(&request)
+	switch {
+	case err != nil:
+		response.Error = err.Error()
+	case !request.Command.Valid():
+		response.Error = fmt.Sprintf("%s: %s", tterrors.UnknownCommandIPCError, request.Command)
+	default:
+		log.Debug().
+			Str("command", string(request.Command)).
+			Msg("received IPC command")
+		err = s.handler(request.Command)
+		if err != nil {
+			response.Error = err.Error()
+		}
+	}
+	err = json.NewEncoder(conn).Encode(response)
+	if err != nil {
+		log.Err(err).
+			Msg("error writing IPC response")
+	}
+}
+
+// Send asks the running GUI to perform the command. If the GUI is not running, ErrGUINotRunning is returned.
+func Send(command Command) error {
+	endpoint, err := EndpointPath()
+	if err != nil {
+		return err
+	}
+	conn, err := dial(endpoint)
+	if err != nil {
+		return tterrors.ErrGUINotRunning{Wrapped: err}
+	}
+	defer conn.Close()
+	err = conn.SetDeadline(time.Now().Add(requestTimeout))
+	if err != nil {
+		return err
+	}
+	err = json.NewEncoder(conn).Encode(Request{Command: command})
+	if err != nil {
+		return err
+	}
+	response := Response{}
+	err = json.NewDecoder(bufio.NewReader(conn)).Decode(&response)
+	if err != nil {
+		return err
+	}
+	if response.Error != "" {
+		return errors.New(response.Error)
+	}
+	return nil
+}
+
+// Launch starts a new GUI process that performs the command
+func Launch(command Command) error {
+	log := logger.GetFuncLogger(packageLogger, "Launch")
+	timetrackerExecutable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	guiExecutable := path.Join(path.Dir(timetrackerExecutable), guiExecutableName)
+	if runtime.GOOS == "windows" {
+		guiExecutable += ".exe"
+	}
+	guiOptions := make([]string, 0)
+	if command.GUIOption() != "" {
+		guiOptions = append(guiOptions, command.GUIOption())
+	}
+	guiCmd := exec.Command(guiExecutable, guiOptions...)
+	log.Debug().
+		Str("command", guiCmd.String()).
+		Msg("launching gui")
+	return guiCmd.Start()
+}
+
+// Show asks the running GUI to perform the command, launching the GUI if it is not running
+func Show(command Command) error {
+	log := logger.GetFuncLogger(packageLogger, "Show")
+	err := Send(command)
+	if err == nil {
+		return nil
+	}
+	if !errors.As(err, &tterrors.ErrGUINotRunning{}) {
+		return err
+	}
+	log.Debug().
+		Err(err).
+		Msg("gui is not running; launching it")
+	return Launch(command)
+}
@@ -0,0 +1,80 @@
+package ipc
+
+import (
+	"errors"
+	"os"
+	"path"
+	"sync"
+	"testing"
+
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_Command_GUIOption(t *testing.T) {
+	require.Equal(t, "", CommandShow.GUIOption())
+	require.Equal(t, "-stop-running-task", CommandStopRunningTask.GUIOption())
+	require.Equal(t, "-manage", CommandManage.GUIOption())
+	require.Equal(t, "-timesheets", CommandTimesheets.GUIOption())
+	require.Equal(t, "-settings", CommandSettings.GUIOption())
+	require.Equal(t, "-summary", CommandSummary.GUIOption())
+	require.True(t, CommandAbout.Valid())
+	require.False(t, Command("explode").Valid())
+}
+
+func TestUnit_Send_NotRunning(t *testing.T) {
+	SetEndpointPath(path.Join(t.TempDir(), endpointFileName))
+	defer SetEndpointPath("")
+
+	err := Send(CommandShow)
+	require.NotNil(t, err)
+	require.True(t, errors.As(err, &tterrors.ErrGUINotRunning{}))
+}
+
+func TestUnit_Server_Nominal(t *testing.T) {
+	SetEndpointPath(path.Join(t.TempDir(), endpointFileName))
+	defer SetEndpointPath("")
+
+	receivedMtx := sync.Mutex{}
+	received := make([]Command, 0)
+	server := NewServer(func(command Command) error {
+		receivedMtx.Lock()
+		defer receivedMtx.Unlock()
+		received = append(received, command)
+		if command == CommandAbout {
+			return errors.New("no about for you")
+		}
+		return nil
+	})
+	require.Nil(t, server.Start())
+	defer func() {
+		require.Nil(t, server.Stop())
+	}()
+
+	require.Nil(t, Send(CommandManage))
+	require.Nil(t, Send(CommandStopRunningTask))
+	require.EqualError(t, Send(CommandAbout), "no about for you")
+	err := Send(Command("explode"))
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), tterrors.UnknownCommandIPCError)
+
+	receivedMtx.Lock()
+	defer receivedMtx.Unlock()
+	require.Equal(t, []Command{CommandManage, CommandStopRunningTask, CommandAbout}, received)
+}
+
+func TestUnit_Server_StaleEndpoint(t *testing.T) {
+	endpoint := path.Join(t.TempDir(), endpointFileName)
+	SetEndpointPath(endpoint)
+	defer SetEndpointPath("")
+
+	// A GUI that did not exit cleanly leaves its endpoint behind
+	require.Nil(t, os.WriteFile(endpoint, []byte("stale"), 0600))
+
+	server := NewServer(func(_ Command) error { return nil })
+	require.Nil(t, server.Start())
+	defer func() {
+		require.Nil(t, server.Stop())
+	}()
+	require.Nil(t, Send(CommandShow))
+}
@@ -0,0 +1,330 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/neflyte/timetracker/lib/database"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	// AuditSourceCLI is the source of changes made by the timetracker CLI
+	AuditSourceCLI = "cli"
+	// AuditSourceGUI is the source of changes made by the timetracker GUI
+	AuditSourceGUI = "gui"
+	// AuditSourceTray is the source of changes made by the timetracker system tray app
+	AuditSourceTray = "tray"
+	// AuditSourceAPI is the source of changes made through the REST API
+	AuditSourceAPI = "api"
+	// AuditSourceSync is the source of changes that were imported from another database
+	AuditSourceSync = "sync"
+
+	// AuditActionCreate is the action of a record that was created
+	AuditActionCreate = "create"
+	// AuditActionUpdate is the action of a record that was changed
+	AuditActionUpdate = "update"
+	// AuditActionDelete is the action of a record that was deleted
+	AuditActionDelete = "delete"
+	// AuditActionRestore is the action of a deleted record that was restored
+	AuditActionRestore = "restore"
+
+	// AuditRecordTask is the record type of a task
+	AuditRecordTask = "task"
+	// AuditRecordTimesheet is the record type of a timesheet
+	AuditRecordTimesheet = "timesheet"
+
+	// auditBeforeKey is the key of the state of a record before a change in the GORM statement
+	auditBeforeKey = "timetracker:audit_before"
+	// auditFieldDeletedAt is the audited field of the time that a record was deleted
+	auditFieldDeletedAt = "deleted_at"
+)
+
+var (
+	// auditSource is the app that changes are made by
+	auditSource    = AuditSourceCLI
+	auditSourceMtx = sync.RWMutex{}
+	// auditFields are the audited fields of each record type, in the order that they are listed
+	auditFields = map[string][]string{
+		AuditRecordTask:      {"synopsis", "description", "pinned", auditFieldDeletedAt},
+		AuditRecordTimesheet: {"task_id", "start_time", "stop_time", "note", auditFieldDeletedAt},
+	}
+)
+
+// AuditData is an entry of the audit trail: the old and new value of a field of a task or timesheet that was
+// changed, when it was changed, and the app that changed it. Entries are never changed or removed.
+type AuditData struct {
+	// CreatedAt is the time of the change
+	CreatedAt time.Time `gorm:"not null" json:"CreatedAt" xml:"CreatedAt" csv:"created_at"`
+	// log is the struct logger
+	log zerolog.Logger `gorm:"-"`
+	// RecordType is AuditRecordTask or AuditRecordTimesheet
+	RecordType string `gorm:"not null;index:idx_audit_record" json:"RecordType" xml:"RecordType" csv:"record_type"`
+	// Action is AuditActionCreate, AuditActionUpdate, AuditActionDelete or AuditActionRestore
+	Action string `gorm:"not null" json:"Action" xml:"Action" csv:"action"`
+	// Field is the database column of the field
+	Field string `gorm:"not null" json:"Field" xml:"Field" csv:"field"`
+	// Source is the app that made the change, such as AuditSourceCLI
+	Source string `gorm:"not null" json:"Source" xml:"Source" csv:"source"`
+	// OldValue is the value before the change; it is NULL if the record was created or the field was empty
+	OldValue sql.NullString `json:"OldValue" xml:"OldValue" csv:"old_value"`
+	// NewValue is the value after the change; it is NULL if the record was removed or the field is empty
+	NewValue sql.NullString `json:"NewValue" xml:"NewValue" csv:"new_value"`
+	// ID is the database ID of the entry
+	ID uint `gorm:"primarykey" json:"ID" xml:"ID" csv:"id"`
+	// RecordID is the database ID of the task or timesheet
+	RecordID uint `gorm:"not null;index:idx_audit_record" json:"RecordID" xml:"RecordID" csv:"record_id"`
+}
+
+// NewAudit returns a newly-initialized Audit interface
+func NewAudit() Audit {
+	return NewAuditWithData(NewAuditData())
+}
+
+// NewAuditWithData returns a new Audit interface based on the supplied AuditData struct
+func NewAuditWithData(data AuditData) Audit {
+	return &data
+}
+
+// NewAuditData returns a newly-initialized AuditData struct
+func NewAuditData() AuditData {
+	return AuditData{
+		log: logger.GetStructLogger("AuditData"),
+	}
+}
+
+// TableName implements schema.Tabler
+func (ad *AuditData) TableName() string {
+	return "audit"
+}
+
+// Audit is the main interface to the audit trail
+type Audit interface {
+	fmt.Stringer
+	schema.Tabler
+	Data() *AuditData
+	History(recordType string, recordID uint) ([]AuditData, error)
+}
+
+// Data returns the underlying struct of the interface
+func (ad *AuditData) Data() *AuditData {
+	return ad
+}
+
+// String implements fmt.Stringer
+func (ad *AuditData) String() string {
+	return fmt.Sprintf("%s %s #%d %s (#%d)", ad.Action, ad.RecordType, ad.RecordID, ad.Field, ad.ID)
+}
+
+// History returns the changes to a task or timesheet, oldest first
+func (ad *AuditData) History(recordType string, recordID uint) ([]AuditData, error) {
+	log := logger.GetFuncLogger(ad.log, "History")
+	if _, ok := auditFields[recordType]; !ok {
+		return nil, fmt.Errorf("unknown record type %s", recordType)
+	}
+	history := make([]AuditData, 0)
+	err := database.Get().
+		Where("record_type = ? AND record_id = ?", recordType, recordID).
+		Order("id").
+		Find(&history).
+		Error
+	if err != nil {
+		log.Err(err).
+			Str("recordType", recordType).
+			Uint("recordID", recordID).
+			Msg("error loading history")
+		return nil, err
+	}
+	return history, nil
+}
+
+// SetAuditSource sets the app that changes are recorded as made by
+func SetAuditSource(source string) {
+	auditSourceMtx.Lock()
+	defer auditSourceMtx.Unlock()
+	auditSource = source
+}
+
+// AuditSource returns the app that changes are recorded as made by
+func AuditSource() string {
+	auditSourceMtx.RLock()
+	defer auditSourceMtx.RUnlock()
+	return auditSource
+}
+
+// auditValues are the audited fields of a record and their values; empty fields are not included
+type auditValues map[string]string
+
+// BeforeUpdate implements GORM's BeforeUpdate hook; it remembers the task before the change
+func (td *TaskData) BeforeUpdate(tx *gorm.DB) error {
+	return auditBefore(tx, AuditRecordTask, td.ID)
+}
+
+// AfterUpdate implements GORM's AfterUpdate hook; it records the changes to the task
+func (td *TaskData) AfterUpdate(tx *gorm.DB) error {
+	return auditAfter(tx, AuditRecordTask, td.ID, AuditActionUpdate)
+}
+
+// AfterCreate implements GORM's AfterCreate hook; it records the new task
+func (td *TaskData) AfterCreate(tx *gorm.DB) error {
+	return auditAfter(tx, AuditRecordTask, td.ID, AuditActionCreate)
+}
+
+// BeforeDelete implements GORM's BeforeDelete hook; it remembers the task before it is deleted
+func (td *TaskData) BeforeDelete(tx *gorm.DB) error {
+	return auditBefore(tx, AuditRecordTask, td.ID)
+}
+
+// AfterDelete implements GORM's AfterDelete hook; it records the deletion of the task
+func (td *TaskData) AfterDelete(tx *gorm.DB) error {
+	return auditAfter(tx, AuditRecordTask, td.ID, AuditActionDelete)
+}
+
+// BeforeUpdate implements GORM's BeforeUpdate hook; it remembers the timesheet before the change
+func (tsd *TimesheetData) BeforeUpdate(tx *gorm.DB) error {
+	return auditBefore(tx, AuditRecordTimesheet, tsd.ID)
+}
+
+// AfterUpdate implements GORM's AfterUpdate hook; it records the changes to the timesheet
+func (tsd *TimesheetData) AfterUpdate(tx *gorm.DB) error {
+	return auditAfter(tx, AuditRecordTimesheet, tsd.ID, AuditActionUpdate)
+}
+
+// AfterCreate implements GORM's AfterCreate hook; it records the new timesheet
+func (tsd *TimesheetData) AfterCreate(tx *gorm.DB) error {
+	return auditAfter(tx, AuditRecordTimesheet, tsd.ID, AuditActionCreate)
+}
+
+// BeforeDelete implements GORM's BeforeDelete hook; it remembers the timesheet before it is deleted
+func (tsd *TimesheetData) BeforeDelete(tx *gorm.DB) error {
+	return auditBefore(tx, AuditRecordTimesheet, tsd.ID)
+}
+
+// AfterDelete implements GORM's AfterDelete hook; it records the deletion of the timesheet
+func (tsd *TimesheetData) AfterDelete(tx *gorm.DB) error {
+	return auditAfter(tx, AuditRecordTimesheet, tsd.ID, AuditActionDelete)
+}
+
+// auditBefore remembers the audited fields of a record in the statement that changes it
+func auditBefore(tx *gorm.DB, recordType string, recordID uint) error {
+	if recordID == 0 {
+		return nil
+	}
+	before, err := loadAuditValues(tx, recordType, recordID)
+	if err != nil {
+		return err
+	}
+	// The hooks get a new session of the statement, so the state is kept in the statement itself
+	tx.Statement.Settings.Store(auditBeforeKey, before)
+	return nil
+}
+
+// auditAfter records each audited field of a record that the statement changed, and logs the change for syncing.
+// Statements that did not change a row, such as the upsert of the task of a new timesheet, are not recorded.
+func auditAfter(tx *gorm.DB, recordType string, recordID uint, action string) error {
+	if recordID == 0 || tx.Statement.RowsAffected == 0 {
+		return nil
+	}
+	var before auditValues
+	if action != AuditActionCreate {
+		if value, ok := tx.Statement.Settings.Load(auditBeforeKey); ok {
+			before, _ = value.(auditValues)
+		}
+	}
+	after, err := loadAuditValues(tx, recordType, recordID)
+	if err != nil {
+		return err
+	}
+	if action == AuditActionUpdate && before[auditFieldDeletedAt] != "" && after[auditFieldDeletedAt] == "" {
+		action = AuditActionRestore
+	}
+	source := AuditSource()
+	if isImport(tx) {
+		source = AuditSourceSync
+	}
+	entries := make([]AuditData, 0)
+	for _, field := range auditFields[recordType] {
+		oldValue, hadOld := before[field]
+		newValue, hasNew := after[field]
+		if hadOld == hasNew && oldValue == newValue {
+			continue
+		}
+		entries = append(entries, AuditData{
+			RecordType: recordType,
+			RecordID:   recordID,
+			Action:     action,
+			Field:      field,
+			OldValue:   sql.NullString{String: oldValue, Valid: hadOld},
+			NewValue:   sql.NullString{String: newValue, Valid: hasNew},
+			Source:     source,
+		})
+	}
+	if len(entries) > 0 {
+		err = tx.Session(&gorm.Session{NewDB: true}).Create(&entries).Error
+		if err != nil {
+			return err
+		}
+	}
+	return logChanges(tx, recordType, before, after, time.Now())
+}
+
+// loadAuditValues loads the audited and synced fields of a record, including a deleted record; a record that does
+// not exist has no fields
+func loadAuditValues(tx *gorm.DB, recordType string, recordID uint) (auditValues, error) {
+	session := tx.Session(&gorm.Session{NewDB: true})
+	values := make(auditValues)
+	switch recordType {
+	case AuditRecordTask:
+		tasks := make([]TaskData, 0, 1)
+		err := session.Unscoped().Where("id = ?", recordID).Limit(1).Find(&tasks).Error
+		if err != nil || len(tasks) == 0 {
+			return values, err
+		}
+		values.set(syncFieldUUID, tasks[0].UUID)
+		values.set("synopsis", tasks[0].Synopsis)
+		values.set("description", tasks[0].Description)
+		values.set("pinned", strconv.FormatBool(tasks[0].Pinned))
+		values.setTime(auditFieldDeletedAt, sql.NullTime(tasks[0].DeletedAt))
+	case AuditRecordTimesheet:
+		timesheets := make([]TimesheetData, 0, 1)
+		err := session.Unscoped().Where("id = ?", recordID).Limit(1).Find(&timesheets).Error
+		if err != nil || len(timesheets) == 0 {
+			return values, err
+		}
+		values.set(syncFieldUUID, timesheets[0].UUID)
+		values.set("task_id", strconv.FormatUint(uint64(timesheets[0].TaskID), 10))
+		taskUUIDs := make([]sql.NullString, 0, 1)
+		err = session.Unscoped().Model(new(TaskData)).Where("id = ?", timesheets[0].TaskID).Pluck("uuid", &taskUUIDs).Error
+		if err != nil {
+			return values, err
+		}
+		if len(taskUUIDs) > 0 {
+			values.set(syncFieldTask, taskUUIDs[0].String)
+		}
+		values.setTime("start_time", sql.NullTime{Time: timesheets[0].StartTime, Valid: true})
+		values.setTime("stop_time", timesheets[0].StopTime)
+		values.set("note", timesheets[0].Note)
+		values.setTime(auditFieldDeletedAt, sql.NullTime(timesheets[0].DeletedAt))
+	}
+	return values, nil
+}
+
+// set sets the value of a field unless it is empty
+func (av auditValues) set(field string, value string) {
+	if value != "" {
+		av[field] = value
+	}
+}
+
+// setTime sets the value of a time field unless it is NULL
+func (av auditValues) setTime(field string, value sql.NullTime) {
+	if value.Valid {
+		av[field] = value.Time.Format(time.RFC3339Nano)
+	}
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/neflyte/timetracker/lib/database"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_Audit_History(t *testing.T) {
+	db := MustOpenTestDB(t)
+	defer CloseTestDB(t, db)
+	database.Set(db)
+	SetAuditSource(AuditSourceTray)
+	defer SetAuditSource(AuditSourceCLI)
+
+	task := NewTask()
+	task.Data().Synopsis = "review"
+	require.Nil(t, task.Create())
+	timesheet := NewTimesheet()
+	timesheet.Data().Task = *task.Data()
+	timesheet.Data().StartTime = time.Now().Add(-time.Hour)
+	require.Nil(t, timesheet.Create())
+	// Creating the timesheet must not record the task again
+	history, err := NewAudit().History(AuditRecordTask, task.Data().ID)
+	require.Nil(t, err)
+	require.Len(t, history, 2)
+	require.Equal(t, AuditActionCreate, history[0].Action)
+	require.Equal(t, "synopsis", history[0].Field)
+	require.False(t, history[0].OldValue.Valid)
+	require.Equal(t, "review", history[0].NewValue.String)
+	require.Equal(t, AuditSourceTray, history[0].Source)
+
+	// Stopping the task records the stop time
+	SetAuditSource(AuditSourceGUI)
+	_, err = task.StopRunningTask()
+	require.Nil(t, err)
+	history, err = NewAudit().History(AuditRecordTimesheet, timesheet.Data().ID)
+	require.Nil(t, err)
+	last := history[len(history)-1]
+	require.Equal(t, AuditActionUpdate, last.Action)
+	require.Equal(t, "stop_time", last.Field)
+	require.False(t, last.OldValue.Valid)
+	require.True(t, last.NewValue.Valid)
+	require.Equal(t, AuditSourceGUI, last.Source)
+
+	// Deleting and restoring the task with undo
+	require.Nil(t, task.Delete())
+	_, err = NewJournal().Undo()
+	require.Nil(t, err)
+	history, err = NewAudit().History(AuditRecordTask, task.Data().ID)
+	require.Nil(t, err)
+	require.Len(t, history, 4)
+	require.Equal(t, AuditActionDelete, history[2].Action)
+	require.Equal(t, "deleted_at", history[2].Field)
+	require.Equal(t, AuditActionRestore, history[3].Action)
+	require.True(t, history[3].OldValue.Valid)
+	require.False(t, history[3].NewValue.Valid)
+
+	_, err = NewAudit().History("project", 1)
+	require.NotNil(t, err)
+}
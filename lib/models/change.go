@@ -0,0 +1,289 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/neflyte/timetracker/lib/database"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	// ChangeFieldMergedInto is the field of a change that merges a task into the task with the UUID in the value
+	// because they have the same synopsis
+	ChangeFieldMergedInto = "merged_into"
+
+	// syncFieldTask is the synced field of the UUID of the task of a timesheet
+	syncFieldTask = "task"
+	// syncFieldUUID is the UUID of a record among its audited values
+	syncFieldUUID = "uuid"
+)
+
+var (
+	// syncFields are the fields of each record type that are logged and synced. A timesheet refers to its task by
+	// the UUID of the task, since database IDs differ between databases.
+	syncFields = map[string][]string{
+		AuditRecordTask:      {"synopsis", "description", "pinned", auditFieldDeletedAt},
+		AuditRecordTimesheet: {syncFieldTask, "start_time", "stop_time", "note", auditFieldDeletedAt},
+	}
+	// uuidNamespace is the namespace of the UUIDs that are assigned to existing records
+	uuidNamespace = uuid.Must(uuid.FromString("2f0c7d4e-6a51-4bd6-9a0e-4f7ad4b1c6e3"))
+)
+
+// importContextKey marks the context of changes that are imported from other databases
+type importContextKey struct{}
+
+// ReplicaData identifies a database among the databases that are synced with each other
+type ReplicaData struct {
+	// UUID identifies the database
+	UUID string `gorm:"not null"`
+	// ID is the database ID of the row; there is only one row
+	ID uint `gorm:"primarykey"`
+}
+
+// TableName implements schema.Tabler
+func (rd *ReplicaData) TableName() string {
+	return "replica"
+}
+
+// ChangeData is an entry of the change log: the new value of a field of a task or timesheet, the time that it
+// was changed, and the database that it was changed in. The change log is append-only; it is what is exchanged
+// when databases are synced.
+type ChangeData struct {
+	// ChangedAt is the time of the change
+	ChangedAt time.Time `gorm:"not null" json:"changedAt"`
+	// log is the struct logger
+	log zerolog.Logger `gorm:"-"`
+	// Value is the new value of the field; it is nil if the field was emptied
+	Value *string `json:"value"`
+	// UUID identifies the change
+	UUID string `gorm:"not null;uniqueIndex" json:"uuid"`
+	// Replica is the UUID of the database that the change was made in
+	Replica string `gorm:"not null;index" json:"replica"`
+	// RecordType is AuditRecordTask or AuditRecordTimesheet
+	RecordType string `gorm:"not null" json:"recordType"`
+	// RecordUUID is the UUID of the task or timesheet
+	RecordUUID string `gorm:"not null;index" json:"recordUuid"`
+	// Field is the name of the field, such as synopsis or ChangeFieldMergedInto
+	Field string `gorm:"not null" json:"field"`
+	// ID is the database ID of the change
+	ID uint `gorm:"primarykey" json:"-"`
+}
+
+// NewChange returns a newly-initialized Change interface
+func NewChange() Change {
+	return NewChangeWithData(NewChangeData())
+}
+
+// NewChangeWithData returns a new Change interface based on the supplied ChangeData struct
+func NewChangeWithData(data ChangeData) Change {
+	return &data
+}
+
+// NewChangeData returns a newly-initialized ChangeData struct
+func NewChangeData() ChangeData {
+	return ChangeData{
+		log: logger.GetStructLogger("ChangeData"),
+	}
+}
+
+// TableName implements schema.Tabler
+func (cd *ChangeData) TableName() string {
+	return "change_log"
+}
+
+// Change is the main interface to the change log
+type Change interface {
+	fmt.Stringer
+	schema.Tabler
+	Data() *ChangeData
+	ReplicaID() (string, error)
+	LocalChanges() ([]ChangeData, error)
+	Import(changes []ChangeData) (*SyncReport, error)
+}
+
+// Data returns the underlying struct of the interface
+func (cd *ChangeData) Data() *ChangeData {
+	return cd
+}
+
+// String implements fmt.Stringer
+func (cd *ChangeData) String() string {
+	return fmt.Sprintf("%s %s %s (%s)", cd.RecordType, cd.RecordUUID, cd.Field, cd.UUID)
+}
+
+// ReplicaID returns the UUID of the database
+func (cd *ChangeData) ReplicaID() (string, error) {
+	return replicaID(database.Get())
+}
+
+// LocalChanges returns the changes that were made in this database, oldest first
+func (cd *ChangeData) LocalChanges() ([]ChangeData, error) {
+	log := logger.GetFuncLogger(cd.log, "LocalChanges")
+	db := database.Get()
+	replica, err := replicaID(db)
+	if err != nil {
+		return nil, err
+	}
+	changes := make([]ChangeData, 0)
+	err = db.Where("replica = ?", replica).
+		Order("id").
+		Find(&changes).
+		Error
+	if err != nil {
+		log.Err(err).
+			Msg("error loading local changes")
+		return nil, err
+	}
+	return changes, nil
+}
+
+// BeforeCreate implements GORM's BeforeCreate hook; it assigns a UUID to a new task
+func (td *TaskData) BeforeCreate(_ *gorm.DB) error {
+	if td.UUID == "" {
+		td.UUID = newUUID()
+	}
+	return nil
+}
+
+// BeforeCreate implements GORM's BeforeCreate hook; it assigns a UUID to a new timesheet
+func (tsd *TimesheetData) BeforeCreate(_ *gorm.DB) error {
+	if tsd.UUID == "" {
+		tsd.UUID = newUUID()
+	}
+	return nil
+}
+
+// MigrateChangeLog assigns a UUID to each task and timesheet that does not have one and logs its fields as they
+// are, so that records from before the change log are synced too. The UUIDs are derived from the records, so
+// copies of a database get the same UUIDs.
+func MigrateChangeLog(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		tasks := make([]TaskData, 0)
+		err := tx.Unscoped().Where("uuid IS NULL OR uuid = ''").Find(&tasks).Error
+		if err != nil {
+			return err
+		}
+		for idx := range tasks {
+			err = migrateRecord(tx, AuditRecordTask, tasks[idx].Model, new(TaskData))
+			if err != nil {
+				return err
+			}
+		}
+		timesheets := make([]TimesheetData, 0)
+		err = tx.Unscoped().Where("uuid IS NULL OR uuid = ''").Find(&timesheets).Error
+		if err != nil {
+			return err
+		}
+		for idx := range timesheets {
+			err = migrateRecord(tx, AuditRecordTimesheet, timesheets[idx].Model, new(TimesheetData))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// migrateRecord assigns a UUID to an existing record and logs its fields as they were when it was last updated
+func migrateRecord(tx *gorm.DB, recordType string, record gorm.Model, model interface{}) error {
+	recordUUID := uuid.NewV5(
+		uuidNamespace,
+		fmt.Sprintf("%s:%d:%s", recordType, record.ID, record.CreatedAt.UTC().Format(time.RFC3339Nano)),
+	).String()
+	err := tx.Session(&gorm.Session{SkipHooks: true}).
+		Unscoped().
+		Model(model).
+		Where("id = ?", record.ID).
+		UpdateColumn("uuid", recordUUID).
+		Error
+	if err != nil {
+		return err
+	}
+	after, err := loadAuditValues(tx, recordType, record.ID)
+	if err != nil {
+		return err
+	}
+	return logChanges(tx, recordType, nil, after, record.UpdatedAt)
+}
+
+// logChanges logs each synced field of a record that changed. All fields of a new record are logged, and a record
+// that was removed from the database is logged as deleted. Changes that are imported are already logged.
+func logChanges(tx *gorm.DB, recordType string, before auditValues, after auditValues, changedAt time.Time) error {
+	if isImport(tx) {
+		return nil
+	}
+	recordUUID := after[syncFieldUUID]
+	if recordUUID == "" {
+		recordUUID = before[syncFieldUUID]
+	}
+	if recordUUID == "" {
+		return nil
+	}
+	if len(after) == 0 {
+		after = make(auditValues)
+		for field, value := range before {
+			after[field] = value
+		}
+		after[auditFieldDeletedAt] = changedAt.Format(time.RFC3339Nano)
+	}
+	replica, err := replicaID(tx)
+	if err != nil {
+		return err
+	}
+	changes := make([]ChangeData, 0)
+	for _, field := range syncFields[recordType] {
+		oldValue, hadOld := before[field]
+		newValue, hasNew := after[field]
+		if len(before) > 0 && hadOld == hasNew && oldValue == newValue {
+			continue
+		}
+		change := ChangeData{
+			UUID:       newUUID(),
+			Replica:    replica,
+			RecordType: recordType,
+			RecordUUID: recordUUID,
+			Field:      field,
+			ChangedAt:  changedAt,
+		}
+		if hasNew {
+			change.Value = &newValue
+		}
+		changes = append(changes, change)
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return tx.Session(&gorm.Session{NewDB: true}).Create(&changes).Error
+}
+
+// replicaID returns the UUID of the database, creating it the first time
+func replicaID(tx *gorm.DB) (string, error) {
+	replica := ReplicaData{ID: 1}
+	err := tx.Session(&gorm.Session{NewDB: true}).
+		Where(ReplicaData{ID: 1}).
+		Attrs(ReplicaData{UUID: newUUID()}).
+		FirstOrCreate(&replica).
+		Error
+	return replica.UUID, err
+}
+
+// isImport determines if the statement imports changes from another database
+func isImport(tx *gorm.DB) bool {
+	return tx.Statement.Context != nil && tx.Statement.Context.Value(importContextKey{}) != nil
+}
+
+// importContext returns a context that marks changes that are imported from another database
+func importContext() context.Context {
+	return context.WithValue(context.Background(), importContextKey{}, true)
+}
+
+// newUUID returns a new random UUID
+func newUUID() string {
+	return uuid.Must(uuid.NewV4()).String()
+}
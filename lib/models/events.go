@@ -0,0 +1,83 @@
+package models
+
+import (
+	"sync"
+	"time"
+)
+
+// EventType identifies the kind of change that was made to a task or timesheet
+type EventType string
+
+const (
+	// EventTaskCreated is published after a new task is created
+	EventTaskCreated EventType = "task.created"
+	// EventTaskStarted is published after a task is started by opening a new timesheet
+	EventTaskStarted EventType = "task.started"
+	// EventTaskStopped is published after the running task is stopped
+	EventTaskStopped EventType = "task.stopped"
+	// EventTaskDeleted is published after a task is marked as deleted
+	EventTaskDeleted EventType = "task.deleted"
+)
+
+var (
+	// AllEventTypes is the list of every event type that can be published
+	AllEventTypes = []EventType{EventTaskCreated, EventTaskStarted, EventTaskStopped, EventTaskDeleted}
+
+	eventListeners    = make([]EventListener, 0)
+	eventListenersMtx = sync.RWMutex{}
+)
+
+// Event describes a change that was made to a task or timesheet
+type Event struct {
+	// Time is the time that the event was published
+	Time time.Time
+	// Timesheet is the timesheet involved in the event; it is nil for events that only involve a task
+	Timesheet *TimesheetData
+	// Type is the kind of event
+	Type EventType
+	// Task is the task involved in the event
+	Task TaskData
+}
+
+// EventListener is a function that is called after an Event is published
+type EventListener func(event Event)
+
+// AddEventListener registers a function that will be called for every published Event
+func AddEventListener(listener EventListener) {
+	if listener == nil {
+		return
+	}
+	eventListenersMtx.Lock()
+	defer eventListenersMtx.Unlock()
+	eventListeners = append(eventListeners, listener)
+}
+
+// ClearEventListeners removes all registered event listeners
+func ClearEventListeners() {
+	eventListenersMtx.Lock()
+	defer eventListenersMtx.Unlock()
+	eventListeners = make([]EventListener, 0)
+}
+
+// publishEvent sends an Event to each registered listener
+func publishEvent(eventType EventType, task TaskData, timesheet *TimesheetData) {
+	eventListenersMtx.RLock()
+	listeners := make([]EventListener, len(eventListeners))
+	copy(listeners, eventListeners)
+	eventListenersMtx.RUnlock()
+	if len(listeners) == 0 {
+		return
+	}
+	event := Event{
+		Type: eventType,
+		Task: task,
+		Time: time.Now(),
+	}
+	if timesheet != nil {
+		timesheetCopy := *timesheet
+		event.Timesheet = &timesheetCopy
+	}
+	for _, listener := range listeners {
+		listener(event)
+	}
+}
@@ -0,0 +1,222 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/neflyte/timetracker/lib/database"
+)
+
+const (
+	// fuzzyScoreMatch is the score of each matched character
+	fuzzyScoreMatch = 16
+	// fuzzyBonusBoundary is added when a matched character starts a word
+	fuzzyBonusBoundary = 16
+	// fuzzyBonusFirst is added when the first character of the text is matched
+	fuzzyBonusFirst = 8
+	// fuzzyBonusConsecutive is added when a matched character follows another matched character
+	fuzzyBonusConsecutive = 12
+	// fuzzyBonusExact is added when the whole text is matched
+	fuzzyBonusExact = 32
+	// fuzzyPenaltyGap is subtracted for each unmatched character between two matched characters
+	fuzzyPenaltyGap = 3
+	// fuzzyDescriptionDivisor reduces the score of a match in the description instead of the synopsis
+	fuzzyDescriptionDivisor = 2
+	// fuzzyRecencyWindow is how far back the timesheet history is searched for recency boosts
+	fuzzyRecencyWindow = 30 * 24 * time.Hour
+)
+
+// fuzzyRecencyBoosts are added to the score of a task that was last started within the specified age
+var fuzzyRecencyBoosts = []struct {
+	age   time.Duration
+	boost int
+}{
+	{age: 24 * time.Hour, boost: 30},
+	{age: 7 * 24 * time.Hour, boost: 20},
+	{age: fuzzyRecencyWindow, boost: 10},
+}
+
+// FuzzyMatch tests whether the characters of pattern appear in text in the same order, ignoring case, and
+// returns a score that is higher for matches that are consecutive or at the start of words
+func FuzzyMatch(pattern string, text string) (int, bool) {
+	patternRunes := []rune(strings.ToLower(pattern))
+	if len(patternRunes) == 0 {
+		return 0, true
+	}
+	textRunes := []rune(text)
+	lowerRunes := []rune(strings.ToLower(text))
+	if len(lowerRunes) != len(textRunes) {
+		// Some characters change length when lowercased; fall back to matching the lowercased text only
+		textRunes = lowerRunes
+	}
+	bestScore := 0
+	matched := false
+	// Try a greedy match from each position where the first character matches and keep the best one
+	for start := range lowerRunes {
+		if lowerRunes[start] != patternRunes[0] {
+			continue
+		}
+		score, ok := fuzzyMatchFrom(patternRunes, textRunes, lowerRunes, start)
+		if ok && (!matched || score > bestScore) {
+			bestScore = score
+			matched = true
+		}
+	}
+	return bestScore, matched
+}
+
+// fuzzyMatchFrom matches the pattern in the text greedily from the specified position
+func fuzzyMatchFrom(patternRunes []rune, textRunes []rune, lowerRunes []rune, start int) (int, bool) {
+	score := 0
+	patternIdx := 0
+	lastMatch := -1
+	for idx := start; idx < len(lowerRunes) && patternIdx < len(patternRunes); idx++ {
+		if lowerRunes[idx] != patternRunes[patternIdx] {
+			continue
+		}
+		score += fuzzyScoreMatch
+		if isWordStart(textRunes, idx) {
+			score += fuzzyBonusBoundary
+		}
+		if idx == 0 {
+			score += fuzzyBonusFirst
+		}
+		if lastMatch >= 0 {
+			if idx == lastMatch+1 {
+				score += fuzzyBonusConsecutive
+			} else {
+				score -= (idx - lastMatch - 1) * fuzzyPenaltyGap
+			}
+		}
+		lastMatch = idx
+		patternIdx++
+	}
+	if patternIdx < len(patternRunes) {
+		return 0, false
+	}
+	if start == 0 && len(patternRunes) == len(lowerRunes) {
+		score += fuzzyBonusExact
+	}
+	return score, true
+}
+
+// isWordStart tests whether the character at the specified position starts a word
+func isWordStart(textRunes []rune, idx int) bool {
+	if idx == 0 {
+		return true
+	}
+	current, previous := textRunes[idx], textRunes[idx-1]
+	switch {
+	case !unicode.IsLetter(previous) && !unicode.IsDigit(previous):
+		return unicode.IsLetter(current) || unicode.IsDigit(current)
+	case unicode.IsLower(previous) && unicode.IsUpper(current):
+		return true
+	case unicode.IsLetter(previous) && unicode.IsDigit(current):
+		return true
+	}
+	return false
+}
+
+// fuzzyScoreTask returns the score of a task for a search; every word of the search must match the synopsis
+// or the description of the task
+func fuzzyScoreTask(terms []string, task TaskData) (int, bool) {
+	total := 0
+	for _, term := range terms {
+		score, ok := FuzzyMatch(term, task.Synopsis)
+		if !ok {
+			score, ok = FuzzyMatch(term, task.Description)
+			if !ok {
+				return 0, false
+			}
+			score /= fuzzyDescriptionDivisor
+		}
+		total += score
+	}
+	return total, true
+}
+
+// fuzzyRecencyBoost returns the score to add to a task that was last started at the specified time
+func fuzzyRecencyBoost(lastStarted time.Time, currentTime time.Time) int {
+	if lastStarted.IsZero() {
+		return 0
+	}
+	age := currentTime.Sub(lastStarted)
+	for _, recencyBoost := range fuzzyRecencyBoosts {
+		if age <= recencyBoost.age {
+			return recencyBoost.boost
+		}
+	}
+	return 0
+}
+
+// lastStartTimes returns the time that each task was last started at during the recency window
+func lastStartTimes(currentTime time.Time) (map[uint]time.Time, error) {
+	timesheets := make([]TimesheetData, 0)
+	err := database.Get().
+		Model(new(TimesheetData)).
+		Select("task_id", "start_time").
+		Where("start_time >= ?", currentTime.Add(-fuzzyRecencyWindow)).
+		Order("start_time DESC").
+		Find(&timesheets).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	startTimes := make(map[uint]time.Time)
+	for _, timesheet := range timesheets {
+		if _, ok := startTimes[timesheet.TaskID]; !ok {
+			startTimes[timesheet.TaskID] = timesheet.StartTime
+		}
+	}
+	return startTimes, nil
+}
+
+// FuzzySearch searches for tasks whose synopsis or description fuzzy-matches every word of the text. The
+// results are ranked by how well they match, with a boost for tasks that were started recently.
+func (td *TaskData) FuzzySearch(text string) ([]TaskData, error) {
+	tasks, err := td.LoadAll(false)
+	if err != nil {
+		return nil, err
+	}
+	currentTime := time.Now()
+	startTimes, err := lastStartTimes(currentTime)
+	if err != nil {
+		return nil, err
+	}
+	type scoredTask struct {
+		task  TaskData
+		score int
+	}
+	terms := strings.Fields(text)
+	scoredTasks := make([]scoredTask, 0, len(tasks))
+	for _, task := range tasks {
+		score, ok := fuzzyScoreTask(terms, task)
+		if !ok {
+			continue
+		}
+		score += fuzzyRecencyBoost(startTimes[task.ID], currentTime)
+		scoredTasks = append(scoredTasks, scoredTask{task: task, score: score})
+	}
+	sort.SliceStable(scoredTasks, func(i, j int) bool {
+		if scoredTasks[i].score != scoredTasks[j].score {
+			return scoredTasks[i].score > scoredTasks[j].score
+		}
+		startI, startJ := startTimes[scoredTasks[i].task.ID], startTimes[scoredTasks[j].task.ID]
+		if !startI.Equal(startJ) {
+			return startI.After(startJ)
+		}
+		// Prefer the closer match when the scores are the same
+		lengthI, lengthJ := len([]rune(scoredTasks[i].task.Synopsis)), len([]rune(scoredTasks[j].task.Synopsis))
+		if lengthI != lengthJ {
+			return lengthI < lengthJ
+		}
+		return scoredTasks[i].task.ID > scoredTasks[j].task.ID
+	})
+	results := make([]TaskData, len(scoredTasks))
+	for idx := range scoredTasks {
+		results[idx] = scoredTasks[idx].task
+	}
+	return results, nil
+}
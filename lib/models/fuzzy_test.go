@@ -0,0 +1,91 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/neflyte/timetracker/lib/database"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_FuzzyMatch(t *testing.T) {
+	_, ok := FuzzyMatch("rvw", "review")
+	require.True(t, ok)
+	_, ok = FuzzyMatch("RVW", "Code Review")
+	require.True(t, ok)
+	_, ok = FuzzyMatch("wvr", "review")
+	require.False(t, ok)
+	_, ok = FuzzyMatch("reviews", "review")
+	require.False(t, ok)
+
+	exact, _ := FuzzyMatch("docs", "docs")
+	prefix, _ := FuzzyMatch("docs", "docs-review")
+	substring, _ := FuzzyMatch("docs", "update-docs")
+	scattered, _ := FuzzyMatch("docs", "design of cats")
+	require.Greater(t, exact, prefix)
+	require.Greater(t, prefix, substring)
+	require.Greater(t, substring, scattered)
+
+	// Matching the start of each word ranks higher than matching inside words
+	boundary, _ := FuzzyMatch("cr", "code-review")
+	inside, _ := FuzzyMatch("cr", "ascribe")
+	require.Greater(t, boundary, inside)
+	camelCase, _ := FuzzyMatch("cr", "codeReview")
+	require.Greater(t, camelCase, inside)
+}
+
+func TestUnit_Task_FuzzySearch_Ranking(t *testing.T) {
+	db := MustOpenTestDB(t)
+	defer CloseTestDB(t, db)
+	database.Set(db)
+
+	tasks := []TaskData{
+		{Synopsis: "review", Description: "Code review"},
+		{Synopsis: "server-view", Description: "Status page of the servers"},
+		{Synopsis: "meetings", Description: "Weekly review with the team"},
+		{Synopsis: "docs", Description: "Write documentation"},
+	}
+	for idx := range tasks {
+		task := NewTaskWithData(tasks[idx])
+		require.Nil(t, task.Create())
+		tasks[idx] = *task.Data()
+	}
+
+	results, err := NewTask().FuzzySearch("rvw")
+	require.Nil(t, err)
+	require.Equal(t, []string{"review", "server-view", "meetings"}, synopses(results))
+
+	// Every word has to match the synopsis or the description
+	results, err = NewTask().FuzzySearch("team rvw")
+	require.Nil(t, err)
+	require.Equal(t, []string{"meetings"}, synopses(results))
+	results, err = NewTask().FuzzySearch("xyz")
+	require.Nil(t, err)
+	require.Empty(t, results)
+
+	// A recently started task is boosted above a slightly better match
+	timesheet := NewTimesheet()
+	timesheet.Data().Task = tasks[1]
+	timesheet.Data().StartTime = time.Now().Add(-time.Hour)
+	require.Nil(t, timesheet.Create())
+	results, err = NewTask().FuzzySearch("rvw")
+	require.Nil(t, err)
+	require.Equal(t, []string{"server-view", "review", "meetings"}, synopses(results))
+}
+
+func TestUnit_FuzzyRecencyBoost(t *testing.T) {
+	currentTime := time.Now()
+	require.Equal(t, 0, fuzzyRecencyBoost(time.Time{}, currentTime))
+	require.Equal(t, 30, fuzzyRecencyBoost(currentTime.Add(-time.Hour), currentTime))
+	require.Equal(t, 20, fuzzyRecencyBoost(currentTime.Add(-3*24*time.Hour), currentTime))
+	require.Equal(t, 10, fuzzyRecencyBoost(currentTime.Add(-20*24*time.Hour), currentTime))
+	require.Equal(t, 0, fuzzyRecencyBoost(currentTime.Add(-40*24*time.Hour), currentTime))
+}
+
+func synopses(tasks []TaskData) []string {
+	names := make([]string, len(tasks))
+	for idx := range tasks {
+		names[idx] = tasks[idx].Synopsis
+	}
+	return names
+}
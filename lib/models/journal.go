@@ -0,0 +1,527 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/neflyte/timetracker/lib/database"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	// JournalTableTask is the table of a change to a task
+	JournalTableTask = "task"
+	// JournalTableTimesheet is the table of a change to a timesheet
+	JournalTableTimesheet = "timesheet"
+
+	// journalSize is the number of actions that are kept in the journal
+	journalSize = 100
+	// switchWindow is how soon a task has to be started after the running task is stopped for both actions to be
+	// journaled as one switch
+	switchWindow = 2 * time.Second
+)
+
+var (
+	// lastStop is the task that was stopped most recently by this process
+	lastStop    stoppedTask
+	lastStopMtx = sync.Mutex{}
+)
+
+// stoppedTask is the journal entry of a task that was stopped
+type stoppedTask struct {
+	at time.Time
+	// db is the database that the task was stopped in
+	db        *gorm.DB
+	synopsis  string
+	journalID uint
+}
+
+// JournalData is an entry of the operation journal: an action that changed tasks or timesheets, such as starting
+// a task, with the state of each changed record before and after the action, so that it can be undone and redone
+type JournalData struct {
+	// log is the struct logger
+	log        zerolog.Logger `gorm:"-"`
+	gorm.Model `json:"-" xml:"-" csv:"-"`
+	// Description describes the action, such as "Start task review"
+	Description string `gorm:"not null" json:"Description" xml:"Description" csv:"description"`
+	// Changes is the JSON list of the records that the action changed
+	Changes string `gorm:"not null" json:"-" xml:"-" csv:"-"`
+	// Undone is true after the action has been undone
+	Undone bool `gorm:"not null;default:false" json:"Undone" xml:"Undone" csv:"undone"`
+}
+
+// JournalChange is the state of a task or timesheet before and after an action
+type JournalChange struct {
+	// Before is the state of the record before the action; it is nil if the action created the record
+	Before *JournalRecord `json:"before,omitempty"`
+	// After is the state of the record after the action
+	After *JournalRecord `json:"after,omitempty"`
+	// Table is JournalTableTask or JournalTableTimesheet
+	Table string `json:"table"`
+	// ID is the database ID of the record
+	ID uint `json:"id"`
+}
+
+// JournalRecord is the state of the fields of a task or timesheet that an action changes
+type JournalRecord struct {
+	// StartTime is the start time of a timesheet
+	StartTime time.Time `json:"startTime,omitempty"`
+	// StopTime is the stop time of a timesheet; it is nil while the timesheet is running
+	StopTime *time.Time `json:"stopTime,omitempty"`
+	// DeletedAt is the time that the record was deleted; it is nil if the record is not deleted
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+	// Synopsis is the synopsis of a task
+	Synopsis string `json:"synopsis,omitempty"`
+	// Description is the description of a task
+	Description string `json:"description,omitempty"`
+	// Note is the note of a timesheet
+	Note string `json:"note,omitempty"`
+	// UUID is the identifier of the record that is the same in every database that it is synced to
+	UUID string `json:"uuid,omitempty"`
+	// TaskID is the task of a timesheet
+	TaskID uint `json:"taskId,omitempty"`
+}
+
+// NewJournal returns a newly-initialized Journal interface
+func NewJournal() Journal {
+	return NewJournalWithData(NewJournalData())
+}
+
+// NewJournalWithData returns a new Journal interface based on the supplied JournalData struct
+func NewJournalWithData(data JournalData) Journal {
+	return &data
+}
+
+// NewJournalData returns a newly-initialized JournalData struct
+func NewJournalData() JournalData {
+	return JournalData{
+		log: logger.GetStructLogger("JournalData"),
+	}
+}
+
+// TableName implements schema.Tabler
+func (jd *JournalData) TableName() string {
+	return "journal"
+}
+
+// Journal is the main interface to the operation journal
+type Journal interface {
+	fmt.Stringer
+	schema.Tabler
+	Data() *JournalData
+	ChangeList() ([]JournalChange, error)
+	Last() (*JournalData, error)
+	Undo() (*JournalData, error)
+	Redo() (*JournalData, error)
+}
+
+// Data returns the underlying struct of the interface
+func (jd *JournalData) Data() *JournalData {
+	return jd
+}
+
+// String implements fmt.Stringer
+func (jd *JournalData) String() string {
+	return fmt.Sprintf("%s (#%d)", jd.Description, jd.ID)
+}
+
+// ChangeList returns the records that the action changed, in the order that they were changed
+func (jd *JournalData) ChangeList() ([]JournalChange, error) {
+	changes := make([]JournalChange, 0)
+	err := json.Unmarshal([]byte(jd.Changes), &changes)
+	return changes, err
+}
+
+// Last returns the most recent action that can be undone
+func (jd *JournalData) Last() (*JournalData, error) {
+	entry := new(JournalData)
+	err := database.Get().
+		Where("undone = ?", false).
+		Order("id DESC").
+		First(entry).
+		Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, tterrors.ErrNothingToUndo{}
+	}
+	return entry, err
+}
+
+// Undo restores the records that were changed by the most recent action that has not been undone to their state
+// before the action, and returns the action. Records that were changed again since cannot be restored.
+func (jd *JournalData) Undo() (*JournalData, error) {
+	tx := database.Get().Begin()
+	entry := new(JournalData)
+	var events []Event
+	err := tx.Where("undone = ?", false).
+		Order("id DESC").
+		First(entry).
+		Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = tterrors.ErrNothingToUndo{}
+	}
+	if err == nil {
+		var changes []JournalChange
+		changes, err = entry.ChangeList()
+		// The changes are undone in the reverse order that they were made
+		for idx := len(changes) - 1; idx >= 0 && err == nil; idx-- {
+			err = applyJournalChange(tx, entry.Description, changes[idx], changes[idx].After, changes[idx].Before)
+			if err == nil {
+				events, err = appendJournalEvent(tx, events, changes[idx], changes[idx].After, changes[idx].Before)
+			}
+		}
+	}
+	if err == nil {
+		err = tx.Model(entry).Update("undone", true).Error
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	tx.Commit()
+	publishJournalEvents(events)
+	return entry, nil
+}
+
+// Redo changes the records of the action that was undone most recently back to their state after the action, and
+// returns the action. Undone actions are the newest in the journal, so the oldest of them was undone last. Undone
+// actions can no longer be redone once another action is journaled.
+func (jd *JournalData) Redo() (*JournalData, error) {
+	tx := database.Get().Begin()
+	entry := new(JournalData)
+	var events []Event
+	err := tx.Where("undone = ?", true).
+		Order("id").
+		First(entry).
+		Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = tterrors.ErrNothingToRedo{}
+	}
+	if err == nil {
+		var changes []JournalChange
+		changes, err = entry.ChangeList()
+		for idx := 0; idx < len(changes) && err == nil; idx++ {
+			err = applyJournalChange(tx, entry.Description, changes[idx], changes[idx].Before, changes[idx].After)
+			if err == nil {
+				events, err = appendJournalEvent(tx, events, changes[idx], changes[idx].Before, changes[idx].After)
+			}
+		}
+	}
+	if err == nil {
+		err = tx.Model(entry).Update("undone", false).Error
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	tx.Commit()
+	publishJournalEvents(events)
+	return entry, nil
+}
+
+// recordJournal adds an action to the journal in the transaction that made its changes. Actions that were undone
+// can no longer be redone after a new action, and only the most recent actions are kept.
+func recordJournal(tx *gorm.DB, description string, changes ...JournalChange) (uint, error) {
+	changesJSON, err := json.Marshal(changes)
+	if err != nil {
+		return 0, err
+	}
+	err = tx.Where("undone = ?", true).
+		Unscoped().
+		Delete(new(JournalData)).
+		Error
+	if err != nil {
+		return 0, err
+	}
+	entry := JournalData{
+		Description: description,
+		Changes:     string(changesJSON),
+	}
+	err = tx.Create(&entry).Error
+	if err != nil {
+		return 0, err
+	}
+	if entry.ID > journalSize {
+		err = tx.Where("id <= ?", entry.ID-journalSize).
+			Unscoped().
+			Delete(new(JournalData)).
+			Error
+	}
+	return entry.ID, err
+}
+
+// recordStartJournal journals the start of a task. A task that is started right after this process stopped the
+// running task is journaled as a switch from that task, so that both are undone at once.
+func recordStartJournal(tx *gorm.DB, synopsis string, change JournalChange) error {
+	lastStopMtx.Lock()
+	stopped := lastStop
+	lastStop = stoppedTask{}
+	lastStopMtx.Unlock()
+	if stopped.journalID != 0 && stopped.db == database.Get() && time.Since(stopped.at) <= switchWindow {
+		latest := new(JournalData)
+		err := tx.Order("id DESC").First(latest).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil && latest.ID == stopped.journalID && !latest.Undone {
+			changes, err := latest.ChangeList()
+			if err != nil {
+				return err
+			}
+			changesJSON, err := json.Marshal(append(changes, change))
+			if err != nil {
+				return err
+			}
+			return tx.Model(latest).Updates(map[string]interface{}{
+				"description": fmt.Sprintf("Switch from task %s to %s", stopped.synopsis, synopsis), // i18n
+				"changes":     string(changesJSON),
+			}).Error
+		}
+	}
+	_, err := recordJournal(tx, fmt.Sprintf("Start task %s", synopsis), change) // i18n
+	return err
+}
+
+// rememberStop remembers the journal entry of a task that was stopped, so that a task that is started right after
+// it is journaled as a switch
+func rememberStop(synopsis string, journalID uint) {
+	lastStopMtx.Lock()
+	defer lastStopMtx.Unlock()
+	lastStop = stoppedTask{
+		at:        time.Now(),
+		db:        database.Get(),
+		synopsis:  synopsis,
+		journalID: journalID,
+	}
+}
+
+// journalChange returns the change of a record from its state before an action to its state in the transaction
+func journalChange(tx *gorm.DB, table string, id uint, before *JournalRecord) (JournalChange, error) {
+	after, err := loadJournalRecord(tx, table, id)
+	if err != nil {
+		return JournalChange{}, err
+	}
+	return JournalChange{
+		Table:  table,
+		ID:     id,
+		Before: before,
+		After:  after,
+	}, nil
+}
+
+// loadJournalRecord returns the state of a task or timesheet, including one that is deleted, or nil if it does
+// not exist
+func loadJournalRecord(tx *gorm.DB, table string, id uint) (*JournalRecord, error) {
+	switch table {
+	case JournalTableTask:
+		task := new(TaskData)
+		err := tx.Unscoped().First(task, id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &JournalRecord{
+			Synopsis:    task.Synopsis,
+			Description: task.Description,
+			DeletedAt:   nullTimePtr(sql.NullTime(task.DeletedAt)),
+			UUID:        task.UUID,
+		}, nil
+	case JournalTableTimesheet:
+		timesheet := new(TimesheetData)
+		err := tx.Unscoped().First(timesheet, id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &JournalRecord{
+			TaskID:    timesheet.TaskID,
+			StartTime: timesheet.StartTime,
+			StopTime:  nullTimePtr(timesheet.StopTime),
+			Note:      timesheet.Note,
+			DeletedAt: nullTimePtr(sql.NullTime(timesheet.DeletedAt)),
+			UUID:      timesheet.UUID,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown journal table %s", table)
+	}
+}
+
+// applyJournalChange changes a record from one of its journaled states to the other. The record must still be in
+// the first state, and a running timesheet is only restored if no other task is running.
+func applyJournalChange(tx *gorm.DB, action string, change JournalChange, from *JournalRecord, to *JournalRecord) error {
+	current, err := loadJournalRecord(tx, change.Table, change.ID)
+	if err != nil {
+		return err
+	}
+	if !current.equals(from) {
+		return tterrors.ErrJournalConflict{
+			Action:  action,
+			Details: fmt.Sprintf("%s #%d %s", change.Table, change.ID, tterrors.ChangedSinceJournalError),
+		}
+	}
+	if change.Table == JournalTableTimesheet && to != nil && to.StopTime == nil && to.DeletedAt == nil {
+		var running int64
+		err = tx.Model(new(TimesheetData)).
+			Where("id <> ? AND stop_time IS NULL", change.ID).
+			Count(&running).
+			Error
+		if err != nil {
+			return err
+		}
+		if running > 0 {
+			return tterrors.ErrJournalConflict{
+				Action:  action,
+				Details: tterrors.OtherTaskRunningJournalError,
+			}
+		}
+	}
+	return writeJournalRecord(tx, change.Table, change.ID, to)
+}
+
+// appendJournalEvent appends the task started or stopped event of a timesheet change that started or stopped its
+// task to the events
+func appendJournalEvent(tx *gorm.DB, events []Event, change JournalChange, from *JournalRecord, to *JournalRecord) ([]Event, error) {
+	if change.Table != JournalTableTimesheet || from.running() == to.running() {
+		return events, nil
+	}
+	eventType := EventTaskStarted
+	record := to
+	if from.running() {
+		eventType = EventTaskStopped
+		if to == nil {
+			// Undoing the start of a task removes its timesheet
+			record = from
+		}
+	}
+	task := NewTaskData()
+	err := tx.Unscoped().First(&task, record.TaskID).Error
+	if err != nil {
+		return events, err
+	}
+	timesheet := NewTimesheetData()
+	timesheet.ID = change.ID
+	timesheet.UUID = record.UUID
+	timesheet.Task = task
+	timesheet.TaskID = record.TaskID
+	timesheet.StartTime = record.StartTime
+	timesheet.StopTime = nullTime(record.StopTime)
+	timesheet.Note = record.Note
+	timesheet.DeletedAt = gorm.DeletedAt(nullTime(record.DeletedAt))
+	return append(events, Event{Type: eventType, Task: task, Timesheet: &timesheet}), nil
+}
+
+// publishJournalEvents publishes the events of an action that was undone or redone, in the order that its changes
+// were applied
+func publishJournalEvents(events []Event) {
+	for _, event := range events {
+		publishEvent(event.Type, event.Task, event.Timesheet)
+	}
+}
+
+// writeJournalRecord changes a task or timesheet to the journaled state, creating it if it does not exist, or
+// removes it if the state is nil
+func writeJournalRecord(tx *gorm.DB, table string, id uint, record *JournalRecord) error {
+	switch table {
+	case JournalTableTask:
+		if record == nil {
+			return tx.Unscoped().Delete(&TaskData{Model: gorm.Model{ID: id}}).Error
+		}
+		task := NewTaskData()
+		task.ID = id
+		task.UUID = record.UUID
+		task.Synopsis = record.Synopsis
+		task.Description = record.Description
+		task.DeletedAt = gorm.DeletedAt(nullTime(record.DeletedAt))
+		// The model has the ID for the audit hooks
+		result := tx.Unscoped().
+			Model(&TaskData{Model: gorm.Model{ID: id}}).
+			Updates(map[string]interface{}{
+				"synopsis":    task.Synopsis,
+				"description": task.Description,
+				"deleted_at":  task.DeletedAt,
+			})
+		if result.Error != nil || result.RowsAffected > 0 {
+			return result.Error
+		}
+		return tx.Create(&task).Error
+	case JournalTableTimesheet:
+		if record == nil {
+			return tx.Unscoped().Delete(&TimesheetData{Model: gorm.Model{ID: id}}).Error
+		}
+		timesheet := NewTimesheetData()
+		timesheet.ID = id
+		timesheet.UUID = record.UUID
+		timesheet.TaskID = record.TaskID
+		timesheet.StartTime = record.StartTime
+		timesheet.StopTime = nullTime(record.StopTime)
+		timesheet.Note = record.Note
+		timesheet.DeletedAt = gorm.DeletedAt(nullTime(record.DeletedAt))
+		result := tx.Unscoped().
+			Model(&TimesheetData{Model: gorm.Model{ID: id}}).
+			Updates(map[string]interface{}{
+				"task_id":    timesheet.TaskID,
+				"start_time": timesheet.StartTime,
+				"stop_time":  timesheet.StopTime,
+				"note":       timesheet.Note,
+				"deleted_at": timesheet.DeletedAt,
+			})
+		if result.Error != nil || result.RowsAffected > 0 {
+			return result.Error
+		}
+		return tx.Omit(clause.Associations).Create(&timesheet).Error
+	default:
+		return fmt.Errorf("unknown journal table %s", table)
+	}
+}
+
+// equals determines if two journaled states are the same; a nil state is only equal to another nil state
+func (jr *JournalRecord) equals(other *JournalRecord) bool {
+	if jr == nil || other == nil {
+		return jr == other
+	}
+	return jr.Synopsis == other.Synopsis &&
+		jr.Description == other.Description &&
+		jr.Note == other.Note &&
+		jr.TaskID == other.TaskID &&
+		jr.StartTime.Equal(other.StartTime) &&
+		timePtrEqual(jr.StopTime, other.StopTime) &&
+		timePtrEqual(jr.DeletedAt, other.DeletedAt)
+}
+
+// running determines if the journaled state is of a timesheet whose task is running
+func (jr *JournalRecord) running() bool {
+	return jr != nil && jr.StopTime == nil && jr.DeletedAt == nil
+}
+
+func nullTimePtr(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Time
+}
+
+func nullTime(value *time.Time) sql.NullTime {
+	if value == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *value, Valid: true}
+}
+
+func timePtrEqual(a *time.Time, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
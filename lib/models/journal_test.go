@@ -0,0 +1,122 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/neflyte/timetracker/lib/database"
+	ttErrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_Journal_UndoRedoSwitch(t *testing.T) {
+	db := MustOpenTestDB(t)
+	defer CloseTestDB(t, db)
+	database.Set(db)
+
+	review := NewTask()
+	review.Data().Synopsis = "review"
+	require.Nil(t, review.Create())
+	docs := NewTask()
+	docs.Data().Synopsis = "docs"
+	require.Nil(t, docs.Create())
+	started := NewTimesheet()
+	started.Data().Task = *review.Data()
+	started.Data().StartTime = time.Now()
+	require.Nil(t, started.Create())
+	last, err := NewJournal().Last()
+	require.Nil(t, err)
+	require.Equal(t, "Start task review", last.Description)
+
+	// Stopping a task and starting another one right away is one action
+	_, err = NewTask().StopRunningTask()
+	require.Nil(t, err)
+	switched := NewTimesheet()
+	switched.Data().Task = *docs.Data()
+	switched.Data().StartTime = time.Now()
+	require.Nil(t, switched.Create())
+	last, err = NewJournal().Last()
+	require.Nil(t, err)
+	require.Equal(t, "Switch from task review to docs", last.Description)
+	changes, err := last.ChangeList()
+	require.Nil(t, err)
+	require.Len(t, changes, 2)
+
+	// Undoing and redoing the switch publishes the events of the tasks that it stops and starts
+	defer ClearEventListeners()
+	received := make([]string, 0)
+	AddEventListener(func(event Event) {
+		received = append(received, string(event.Type)+" "+event.Task.Synopsis)
+	})
+
+	undone, err := NewJournal().Undo()
+	require.Nil(t, err)
+	require.Equal(t, last.ID, undone.ID)
+	require.Equal(t, []string{"task.stopped docs", "task.started review"}, received)
+	running, err := NewTimesheet().RunningTimesheet()
+	require.Nil(t, err)
+	require.Equal(t, started.Data().ID, running.Data().ID)
+	require.NotNil(t, switched.Load())
+
+	received = received[:0]
+	redone, err := NewJournal().Redo()
+	require.Nil(t, err)
+	require.Equal(t, last.ID, redone.ID)
+	require.Equal(t, []string{"task.stopped review", "task.started docs"}, received)
+	running, err = NewTimesheet().RunningTimesheet()
+	require.Nil(t, err)
+	require.Equal(t, switched.Data().ID, running.Data().ID)
+	_, err = NewJournal().Redo()
+	require.True(t, errors.Is(err, ttErrors.ErrNothingToRedo{}))
+}
+
+func TestUnit_Journal_UndoDeleteAndConflicts(t *testing.T) {
+	db := MustOpenTestDB(t)
+	defer CloseTestDB(t, db)
+	database.Set(db)
+
+	_, err := NewJournal().Undo()
+	require.True(t, errors.Is(err, ttErrors.ErrNothingToUndo{}))
+	task := NewTask()
+	task.Data().Synopsis = "review"
+	require.Nil(t, task.Create())
+	require.Nil(t, task.Delete())
+	_, err = NewJournal().Undo()
+	require.Nil(t, err)
+	loaded := NewTask()
+	loaded.Data().ID = task.Data().ID
+	require.Nil(t, loaded.Load(false))
+
+	// A new action means that the undone action can no longer be redone
+	loaded.Data().Description = "Review pull requests"
+	require.Nil(t, loaded.Update(false))
+	_, err = NewJournal().Redo()
+	require.True(t, errors.Is(err, ttErrors.ErrNothingToRedo{}))
+
+	// A record that was changed outside the journal is not overwritten
+	require.Nil(t, db.Model(loaded.Data()).UpdateColumn("description", "changed elsewhere").Error)
+	_, err = NewJournal().Undo()
+	require.True(t, errors.As(err, new(ttErrors.ErrJournalConflict)))
+	last, err := NewJournal().Last()
+	require.Nil(t, err)
+	require.Equal(t, "Update task review", last.Description)
+
+	// Undoing the start of a timesheet is refused while another task is running
+	require.Nil(t, db.Model(loaded.Data()).UpdateColumn("description", "Review pull requests").Error)
+	require.Nil(t, task.Data().Load(false))
+	first := NewTimesheet()
+	first.Data().Task = *task.Data()
+	first.Data().StartTime = time.Now()
+	require.Nil(t, first.Create())
+	_, err = NewTask().StopRunningTask()
+	require.Nil(t, err)
+	second := NewTimesheet()
+	second.Data().Task = *task.Data()
+	second.Data().StartTime = time.Now()
+	require.Nil(t, db.Create(second.Data()).Error)
+	_, err = NewJournal().Undo()
+	var conflict ttErrors.ErrJournalConflict
+	require.True(t, errors.As(err, &conflict))
+	require.Equal(t, ttErrors.OtherTaskRunningJournalError, conflict.Details)
+}
@@ -30,7 +30,7 @@ func MustOpenTestDB(t *testing.T) *gorm.DB {
 	if err != nil {
 		t.Fatalf("error opening test db: %s", err)
 	}
-	err = db.AutoMigrate(new(TaskData), new(TimesheetData))
+	err = db.AutoMigrate(Models()...)
 	if err != nil {
 		t.Fatalf("error automigrating test db schema: %s", err)
 	}
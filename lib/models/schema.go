@@ -0,0 +1,40 @@
+package models
+
+import (
+	"fmt"
+
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"gorm.io/gorm"
+)
+
+// Models returns the models whose tables are migrated
+func Models() []interface{} {
+	return []interface{}{
+		new(TaskData),
+		new(TimesheetData),
+		new(WebhookData),
+		new(WebhookDeliveryData),
+		new(JournalData),
+		new(AuditData),
+		new(ReplicaData),
+		new(ChangeData),
+	}
+}
+
+// Migrate migrates the schema of the database: the tables of the models, the full-text search index and the
+// change log
+func Migrate(db *gorm.DB) error {
+	err := db.AutoMigrate(Models()...)
+	if err != nil {
+		return err
+	}
+	err = MigrateSearchIndex(db)
+	if err != nil {
+		return fmt.Errorf("%s: %w", tterrors.MigrateSearchIndexError, err)
+	}
+	err = MigrateChangeLog(db)
+	if err != nil {
+		return fmt.Errorf("%s: %w", tterrors.MigrateChangeLogError, err)
+	}
+	return nil
+}
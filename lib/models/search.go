@@ -0,0 +1,280 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/neflyte/timetracker/lib/database"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"gorm.io/gorm"
+)
+
+const (
+	// SearchHighlightStart marks the start of a matched term in a search snippet
+	SearchHighlightStart = "\x02"
+	// SearchHighlightEnd marks the end of a matched term in a search snippet
+	SearchHighlightEnd = "\x03"
+	// searchSnippetEllipsis is shown where a search snippet omits text
+	searchSnippetEllipsis = "…"
+	// searchSnippetTokens is the maximum number of words in a search snippet
+	searchSnippetTokens = 12
+
+	taskSearchTable      = "task_fts"
+	timesheetSearchTable = "timesheet_fts"
+	// searchTokenizer stems English words so that a search for "review" also finds "reviewing"
+	searchTokenizer = "porter unicode61"
+)
+
+var (
+	// searchIndexStatements create the full-text search tables and the triggers that keep them in sync with the
+	// task and timesheet tables
+	searchIndexStatements = []string{
+		fmt.Sprintf("CREATE VIRTUAL TABLE IF NOT EXISTS %s USING fts5(synopsis, description, content='task', content_rowid='id', tokenize='%s')", taskSearchTable, searchTokenizer),
+		`CREATE TRIGGER IF NOT EXISTS task_fts_insert AFTER INSERT ON task BEGIN
+			INSERT INTO task_fts(rowid, synopsis, description) VALUES (new.id, new.synopsis, new.description);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS task_fts_delete AFTER DELETE ON task BEGIN
+			INSERT INTO task_fts(task_fts, rowid, synopsis, description) VALUES ('delete', old.id, old.synopsis, old.description);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS task_fts_update AFTER UPDATE OF synopsis, description ON task BEGIN
+			INSERT INTO task_fts(task_fts, rowid, synopsis, description) VALUES ('delete', old.id, old.synopsis, old.description);
+			INSERT INTO task_fts(rowid, synopsis, description) VALUES (new.id, new.synopsis, new.description);
+		END`,
+		fmt.Sprintf("CREATE VIRTUAL TABLE IF NOT EXISTS %s USING fts5(note, content='timesheet', content_rowid='id', tokenize='%s')", timesheetSearchTable, searchTokenizer),
+		`CREATE TRIGGER IF NOT EXISTS timesheet_fts_insert AFTER INSERT ON timesheet BEGIN
+			INSERT INTO timesheet_fts(rowid, note) VALUES (new.id, new.note);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS timesheet_fts_delete AFTER DELETE ON timesheet BEGIN
+			INSERT INTO timesheet_fts(timesheet_fts, rowid, note) VALUES ('delete', old.id, old.note);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS timesheet_fts_update AFTER UPDATE OF note ON timesheet BEGIN
+			INSERT INTO timesheet_fts(timesheet_fts, rowid, note) VALUES ('delete', old.id, old.note);
+			INSERT INTO timesheet_fts(rowid, note) VALUES (new.id, new.note);
+		END`,
+	}
+	// searchQueryErrorPrefixes are the start of the SQLite errors that are caused by an invalid search query
+	searchQueryErrorPrefixes = []string{"fts5:", "no such column", "unterminated string", "unknown special query"}
+	// searchIndexTables are the full-text search tables
+	searchIndexTables = []string{taskSearchTable, timesheetSearchTable}
+	// searchIndexTriggers are the triggers that keep the full-text search tables in sync
+	searchIndexTriggers = []string{
+		"task_fts_insert", "task_fts_delete", "task_fts_update",
+		"timesheet_fts_insert", "timesheet_fts_delete", "timesheet_fts_update",
+	}
+)
+
+// TaskSearchResult is a task that matches a full-text search
+type TaskSearchResult struct {
+	// Synopsis is the synopsis of the task with the matched terms highlighted
+	Synopsis string
+	// Snippet is the part of the description that best matches the search, with the matched terms highlighted
+	Snippet string
+	Task    TaskData
+}
+
+// TimesheetSearchResult is a timesheet that matches a full-text search
+type TimesheetSearchResult struct {
+	// Snippet is the part of the note that best matches the search, with the matched terms highlighted
+	Snippet   string
+	Timesheet TimesheetData
+}
+
+// MigrateSearchIndex creates the full-text search index of tasks and timesheet notes if it does not exist yet.
+// If the SQLite library was built without FTS5, the triggers of an existing index are removed so that tasks and
+// timesheets can still be changed; the index is rebuilt the next time the database is opened with FTS5.
+func MigrateSearchIndex(db *gorm.DB) error {
+	log := logger.GetFuncLogger(logger.GetPackageLogger("models"), "MigrateSearchIndex")
+	var fts5Enabled bool
+	err := db.Raw("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&fts5Enabled).Error
+	if err != nil {
+		return err
+	}
+	if !fts5Enabled {
+		log.Warn().
+			Msg("SQLite was built without FTS5; full-text search is not available")
+		return db.Transaction(func(tx *gorm.DB) error {
+			for _, trigger := range searchIndexTriggers {
+				err := tx.Exec(fmt.Sprintf("DROP TRIGGER IF EXISTS %s", trigger)).Error
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	if hasSearchIndex(db) {
+		return nil
+	}
+	log.Info().
+		Msg("building full-text search index")
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, statement := range searchIndexStatements {
+			err := tx.Exec(statement).Error
+			if err != nil {
+				return err
+			}
+		}
+		// Index the existing tasks and timesheets
+		for _, table := range searchIndexTables {
+			err := tx.Exec(fmt.Sprintf("INSERT INTO %[1]s(%[1]s) VALUES ('rebuild')", table)).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// HasSearchIndex tests whether the database has a full-text search index that is kept up to date
+func HasSearchIndex() bool {
+	return hasSearchIndex(database.Get())
+}
+
+func hasSearchIndex(db *gorm.DB) bool {
+	names := append(append(make([]string, 0), searchIndexTables...), searchIndexTriggers...)
+	var count int
+	err := db.Raw("SELECT COUNT(*) FROM sqlite_master WHERE name IN ?", names).
+		Scan(&count).
+		Error
+	return err == nil && count == len(names)
+}
+
+// FullTextSearch searches the synopsis and description of tasks with the SQLite FTS5 query syntax, which
+// supports phrases ("code review"), prefixes (rev*), boolean operators (docs OR review, docs NOT api) and
+// columns (synopsis:docs). The results are ranked best match first.
+func (td *TaskData) FullTextSearch(query string) ([]TaskSearchResult, error) {
+	if !HasSearchIndex() {
+		return nil, tterrors.ErrSearchIndexUnavailable{}
+	}
+	rows := make([]struct {
+		Synopsis string
+		Snippet  string
+		ID       uint
+	}, 0)
+	err := database.Get().
+		Raw(
+			fmt.Sprintf(
+				"SELECT task.id, highlight(%[1]s, 0, ?, ?) AS synopsis, snippet(%[1]s, 1, ?, ?, ?, ?) AS snippet "+
+					"FROM %[1]s JOIN task ON task.id = %[1]s.rowid "+
+					"WHERE %[1]s MATCH ? AND task.deleted_at IS NULL ORDER BY rank",
+				taskSearchTable,
+			),
+			SearchHighlightStart, SearchHighlightEnd,
+			SearchHighlightStart, SearchHighlightEnd, searchSnippetEllipsis, searchSnippetTokens,
+			query,
+		).
+		Scan(&rows).
+		Error
+	if err != nil {
+		return nil, searchError(query, err)
+	}
+	ids := make([]uint, len(rows))
+	for idx, row := range rows {
+		ids[idx] = row.ID
+	}
+	tasks := make([]TaskData, 0, len(rows))
+	err = database.Get().
+		Where("id IN ?", ids).
+		Find(&tasks).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	tasksByID := make(map[uint]TaskData, len(tasks))
+	for _, task := range tasks {
+		tasksByID[task.ID] = task
+	}
+	results := make([]TaskSearchResult, 0, len(rows))
+	for _, row := range rows {
+		task, ok := tasksByID[row.ID]
+		if !ok {
+			continue
+		}
+		results = append(results, TaskSearchResult{
+			Task:     task,
+			Synopsis: row.Synopsis,
+			Snippet:  row.Snippet,
+		})
+	}
+	return results, nil
+}
+
+// FullTextSearch searches the notes of timesheets with the SQLite FTS5 query syntax. The results are ranked
+// best match first.
+func (tsd *TimesheetData) FullTextSearch(query string) ([]TimesheetSearchResult, error) {
+	if !HasSearchIndex() {
+		return nil, tterrors.ErrSearchIndexUnavailable{}
+	}
+	rows := make([]struct {
+		Snippet string
+		ID      uint
+	}, 0)
+	err := database.Get().
+		Raw(
+			fmt.Sprintf(
+				"SELECT timesheet.id, snippet(%[1]s, 0, ?, ?, ?, ?) AS snippet "+
+					"FROM %[1]s JOIN timesheet ON timesheet.id = %[1]s.rowid "+
+					"WHERE %[1]s MATCH ? AND timesheet.deleted_at IS NULL ORDER BY rank",
+				timesheetSearchTable,
+			),
+			SearchHighlightStart, SearchHighlightEnd, searchSnippetEllipsis, searchSnippetTokens,
+			query,
+		).
+		Scan(&rows).
+		Error
+	if err != nil {
+		return nil, searchError(query, err)
+	}
+	ids := make([]uint, len(rows))
+	for idx, row := range rows {
+		ids[idx] = row.ID
+	}
+	timesheets := make([]TimesheetData, 0, len(rows))
+	err = database.Get().
+		Joins("Task").
+		Where("timesheet.id IN ?", ids).
+		Find(&timesheets).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	timesheetsByID := make(map[uint]TimesheetData, len(timesheets))
+	for _, timesheet := range timesheets {
+		timesheetsByID[timesheet.ID] = timesheet
+	}
+	results := make([]TimesheetSearchResult, 0, len(rows))
+	for _, row := range rows {
+		timesheet, ok := timesheetsByID[row.ID]
+		if !ok {
+			continue
+		}
+		results = append(results, TimesheetSearchResult{
+			Timesheet: timesheet,
+			Snippet:   row.Snippet,
+		})
+	}
+	return results, nil
+}
+
+// SearchNotes searches the notes of timesheets using SQL LIKE; it is used when there is no full-text search index
+func (tsd *TimesheetData) SearchNotes(text string) ([]TimesheetData, error) {
+	timesheets := make([]TimesheetData, 0)
+	err := database.Get().
+		Joins("Task").
+		Where("note LIKE ?", fmt.Sprintf("%%%s%%", text)).
+		Order("start_time").
+		Find(&timesheets).
+		Error
+	return timesheets, err
+}
+
+// searchError returns ErrInvalidSearchQuery if SQLite could not parse the query
+func searchError(query string, err error) error {
+	for _, prefix := range searchQueryErrorPrefixes {
+		if strings.HasPrefix(err.Error(), prefix) {
+			return tterrors.ErrInvalidSearchQuery{Query: query, Wrapped: err}
+		}
+	}
+	return err
+}
@@ -0,0 +1,133 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/neflyte/timetracker/lib/database"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// fts5Enabled tests whether the SQLite library was built with FTS5, which needs the sqlite_fts5 build tag
+func fts5Enabled(t *testing.T, db *gorm.DB) bool {
+	var enabled bool
+	require.Nil(t, db.Raw("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&enabled).Error)
+	return enabled
+}
+
+func TestUnit_MigrateSearchIndex_WithoutFTS5(t *testing.T) {
+	db := MustOpenTestDB(t)
+	defer CloseTestDB(t, db)
+	database.Set(db)
+	if fts5Enabled(t, db) {
+		t.Skip("SQLite was built with FTS5")
+	}
+
+	require.Nil(t, MigrateSearchIndex(db))
+	require.False(t, HasSearchIndex())
+	_, err := NewTask().FullTextSearch("review")
+	require.True(t, errors.Is(err, tterrors.ErrSearchIndexUnavailable{}))
+
+	// Notes can still be searched
+	task := NewTask()
+	task.Data().Synopsis = "review"
+	require.Nil(t, task.Create())
+	timesheet := NewTimesheet()
+	timesheet.Data().Task = *task.Data()
+	timesheet.Data().StartTime = time.Now()
+	timesheet.Data().Note = "Reviewed the webhook retries"
+	require.Nil(t, timesheet.Create())
+	timesheets, err := NewTimesheet().SearchNotes("webhook")
+	require.Nil(t, err)
+	require.Len(t, timesheets, 1)
+	require.Equal(t, "review", timesheets[0].Task.Synopsis)
+}
+
+func TestUnit_Task_FullTextSearch(t *testing.T) {
+	db := MustOpenTestDB(t)
+	defer CloseTestDB(t, db)
+	database.Set(db)
+	if !fts5Enabled(t, db) {
+		t.Skip("SQLite was built without FTS5; use the sqlite_fts5 build tag")
+	}
+
+	// Tasks that exist before the index is created are indexed too
+	existing := NewTask()
+	existing.Data().Synopsis = "code-review"
+	existing.Data().Description = "Reviewing pull requests for the API"
+	require.Nil(t, existing.Create())
+	require.Nil(t, MigrateSearchIndex(db))
+	require.True(t, HasSearchIndex())
+	require.Nil(t, MigrateSearchIndex(db))
+	docs := NewTask()
+	docs.Data().Synopsis = "docs"
+	docs.Data().Description = "Write the API documentation"
+	require.Nil(t, docs.Create())
+
+	results, err := NewTask().FullTextSearch("reviews")
+	require.Nil(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, "code-review", results[0].Task.Synopsis)
+	require.Equal(t, "code-"+SearchHighlightStart+"review"+SearchHighlightEnd, results[0].Synopsis)
+	require.Equal(t, SearchHighlightStart+"Reviewing"+SearchHighlightEnd+" pull requests for the API", results[0].Snippet)
+
+	results, err = NewTask().FullTextSearch(`"pull requests" OR doc*`)
+	require.Nil(t, err)
+	require.Len(t, results, 2)
+	results, err = NewTask().FullTextSearch("api NOT synopsis:docs")
+	require.Nil(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, "code-review", results[0].Task.Synopsis)
+
+	// The index follows updates and deletes
+	docs.Data().Description = "Write the user guide"
+	require.Nil(t, docs.Update(false))
+	results, err = NewTask().FullTextSearch("api")
+	require.Nil(t, err)
+	require.Len(t, results, 1)
+	require.Nil(t, existing.Delete())
+	results, err = NewTask().FullTextSearch("api")
+	require.Nil(t, err)
+	require.Empty(t, results)
+
+	_, err = NewTask().FullTextSearch(`"unterminated`)
+	require.True(t, errors.As(err, new(tterrors.ErrInvalidSearchQuery)))
+}
+
+func TestUnit_Timesheet_FullTextSearch(t *testing.T) {
+	db := MustOpenTestDB(t)
+	defer CloseTestDB(t, db)
+	database.Set(db)
+	if !fts5Enabled(t, db) {
+		t.Skip("SQLite was built without FTS5; use the sqlite_fts5 build tag")
+	}
+	require.Nil(t, MigrateSearchIndex(db))
+
+	task := NewTask()
+	task.Data().Synopsis = "review"
+	require.Nil(t, task.Create())
+	timesheet := NewTimesheet()
+	timesheet.Data().Task = *task.Data()
+	timesheet.Data().StartTime = time.Now().Add(-time.Hour)
+	timesheet.Data().Note = "Reviewed the webhook retries"
+	require.Nil(t, timesheet.Create())
+
+	results, err := NewTimesheet().FullTextSearch("webhooks")
+	require.Nil(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, timesheet.Data().ID, results[0].Timesheet.ID)
+	require.Equal(t, "review", results[0].Timesheet.Task.Synopsis)
+	require.Equal(t, "Reviewed the "+SearchHighlightStart+"webhook"+SearchHighlightEnd+" retries", results[0].Snippet)
+
+	timesheet.Data().Note = "Planned the sprint"
+	require.Nil(t, timesheet.Update())
+	results, err = NewTimesheet().FullTextSearch("webhook")
+	require.Nil(t, err)
+	require.Empty(t, results)
+	results, err = NewTimesheet().FullTextSearch("plan*")
+	require.Nil(t, err)
+	require.Len(t, results, 1)
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// TaskTotal is the time spent on a task during a period
+type TaskTotal struct {
+	Synopsis string
+	Duration time.Duration
+	TaskID   uint
+}
+
+// Summarize adds up the time spent on each task between periodStart and periodEnd, longest first. Timesheets
+// that are still running are counted up to the specified current time.
+func Summarize(timesheets []TimesheetData, periodStart time.Time, periodEnd time.Time, currentTime time.Time) []TaskTotal {
+	totals := make(map[uint]*TaskTotal)
+	for _, timesheet := range timesheets {
+		start := timesheet.StartTime
+		if start.Before(periodStart) {
+			start = periodStart
+		}
+		stop := currentTime
+		if timesheet.StopTime.Valid {
+			stop = timesheet.StopTime.Time
+		}
+		if stop.After(periodEnd) {
+			stop = periodEnd
+		}
+		if !stop.After(start) {
+			continue
+		}
+		total, ok := totals[timesheet.Task.ID]
+		if !ok {
+			total = &TaskTotal{
+				TaskID:   timesheet.Task.ID,
+				Synopsis: timesheet.Task.Synopsis,
+			}
+			totals[timesheet.Task.ID] = total
+		}
+		total.Duration += stop.Sub(start)
+	}
+	summary := make([]TaskTotal, 0, len(totals))
+	for _, total := range totals {
+		summary = append(summary, *total)
+	}
+	sort.Slice(summary, func(i, j int) bool {
+		if summary[i].Duration == summary[j].Duration {
+			return summary[i].Synopsis < summary[j].Synopsis
+		}
+		return summary[i].Duration > summary[j].Duration
+	})
+	return summary
+}
+
+// TotalDuration returns the time spent on all tasks of a summary
+func TotalDuration(summary []TaskTotal) time.Duration {
+	var total time.Duration
+	for _, taskTotal := range summary {
+		total += taskTotal.Duration
+	}
+	return total
+}
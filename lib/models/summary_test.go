@@ -0,0 +1,44 @@
+package models
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_Summarize_ClipsAndOrders(t *testing.T) {
+	periodStart := time.Date(2023, 6, 5, 0, 0, 0, 0, time.Local)
+	periodEnd := periodStart.Add(24*time.Hour - time.Nanosecond)
+	currentTime := periodStart.Add(12 * time.Hour)
+	docs := TaskData{Synopsis: "docs"}
+	docs.ID = 1
+	review := TaskData{Synopsis: "review"}
+	review.ID = 2
+	timesheets := []TimesheetData{
+		{
+			// Started the day before; only the part in the period counts
+			Task:      docs,
+			StartTime: periodStart.Add(-time.Hour),
+			StopTime:  sql.NullTime{Time: periodStart.Add(time.Hour), Valid: true},
+		},
+		{
+			// Still running; counts up to the current time
+			Task:      review,
+			StartTime: periodStart.Add(10 * time.Hour),
+		},
+		{
+			Task:      docs,
+			StartTime: periodStart.Add(8 * time.Hour),
+			StopTime:  sql.NullTime{Time: periodStart.Add(8*time.Hour + 30*time.Minute), Valid: true},
+		},
+	}
+	summary := Summarize(timesheets, periodStart, periodEnd, currentTime)
+	require.Equal(t, []TaskTotal{
+		{TaskID: 2, Synopsis: "review", Duration: 2 * time.Hour},
+		{TaskID: 1, Synopsis: "docs", Duration: 90 * time.Minute},
+	}, summary)
+	require.Equal(t, 210*time.Minute, TotalDuration(summary))
+	require.Equal(t, time.Duration(0), TotalDuration(nil))
+}
@@ -0,0 +1,448 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/neflyte/timetracker/lib/database"
+	"github.com/neflyte/timetracker/lib/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// maxMergeDepth is the number of times that a task can be merged into another before the merges are ignored
+	maxMergeDepth = 16
+)
+
+// SyncReport describes what an import of changes from other databases changed
+type SyncReport struct {
+	// Messages describe each task and timesheet that was created, changed or merged
+	Messages []string
+	// Received is the number of changes that were imported
+	Received int
+	// New is the number of changes that had not been imported before
+	New int
+}
+
+// syncState is the latest value of each synced field of a record among all of the changes to it; a field that was
+// never changed is missing, and a field that was emptied is nil
+type syncState map[string]*string
+
+// Import adds the changes made in other databases to the change log, and changes the tasks and timesheets to the
+// latest value of each field among all of the changes to them. Tasks with the same synopsis are merged into the
+// task with the lowest UUID, and if tasks are running in more than one database, all but the last one started are
+// stopped when the next one was started. The same changes give the same result in every database.
+func (cd *ChangeData) Import(changes []ChangeData) (*SyncReport, error) {
+	log := logger.GetFuncLogger(cd.log, "Import")
+	report := &SyncReport{
+		Messages: make([]string, 0),
+		Received: len(changes),
+	}
+	err := database.Get().
+		WithContext(importContext()).
+		Transaction(func(tx *gorm.DB) error {
+			return importChanges(tx, changes, report)
+		})
+	if err != nil {
+		log.Err(err).
+			Int("changes", len(changes)).
+			Msg("error importing changes")
+		return nil, err
+	}
+	return report, nil
+}
+
+// importChanges logs the changes that are new, and then syncs the tasks and the timesheets that they changed
+func importChanges(tx *gorm.DB, changes []ChangeData, report *SyncReport) error {
+	replica, err := replicaID(tx)
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].isBefore(&changes[j])
+	})
+	taskUUIDs := make(map[string]bool)
+	timesheetUUIDs := make(map[string]bool)
+	for idx := range changes {
+		change := changes[idx]
+		if change.Replica == replica || change.UUID == "" {
+			continue
+		}
+		var count int64
+		err = tx.Model(new(ChangeData)).Where("uuid = ?", change.UUID).Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+		change.ID = 0
+		err = tx.Create(&change).Error
+		if err != nil {
+			return err
+		}
+		report.New++
+		switch change.RecordType {
+		case AuditRecordTask:
+			taskUUIDs[change.RecordUUID] = true
+		case AuditRecordTimesheet:
+			timesheetUUIDs[change.RecordUUID] = true
+		}
+	}
+	// The tasks are synced first so that the timesheets can refer to them
+	for _, taskUUID := range sortedKeys(taskUUIDs) {
+		err = syncTask(tx, taskUUID, report, 0)
+		if err != nil {
+			return err
+		}
+	}
+	for _, timesheetUUID := range sortedKeys(timesheetUUIDs) {
+		err = syncTimesheet(tx, timesheetUUID, report)
+		if err != nil {
+			return err
+		}
+	}
+	if report.New == 0 {
+		return nil
+	}
+	return stopConcurrentTimesheets(tx, report)
+}
+
+// syncTask changes a task to the latest state of it and of the tasks that were merged into it
+func syncTask(tx *gorm.DB, recordUUID string, report *SyncReport, depth int) error {
+	taskUUID, err := resolveTaskUUID(tx, recordUUID)
+	if err != nil {
+		return err
+	}
+	uuids, err := mergedTaskUUIDs(tx, taskUUID)
+	if err != nil {
+		return err
+	}
+	state, err := loadSyncState(tx, AuditRecordTask, uuids)
+	if err != nil {
+		return err
+	}
+	synopsis := state.value("synopsis")
+	// A different task with the same synopsis is merged with this one
+	other := new(TaskData)
+	if synopsis != "" {
+		err = tx.Unscoped().
+			Where("synopsis = ? AND (uuid IS NULL OR uuid NOT IN ?)", synopsis, uuids).
+			Limit(1).
+			Find(other).
+			Error
+		if err != nil {
+			return err
+		}
+	}
+	if other.ID > 0 && depth < maxMergeDepth {
+		survivor, merged := taskUUID, other.UUID
+		if other.UUID < taskUUID {
+			survivor, merged = other.UUID, taskUUID
+		}
+		err = logMerge(tx, merged, survivor)
+		if err != nil {
+			return err
+		}
+		report.add("Merged the tasks named %s", synopsis) // i18n
+		return syncTask(tx, survivor, report, depth+1)
+	}
+	tasks := make([]TaskData, 0)
+	err = tx.Unscoped().Where("uuid IN ?", uuids).Order("id").Find(&tasks).Error
+	if err != nil {
+		return err
+	}
+	if len(tasks) == 0 {
+		if synopsis == "" {
+			report.add("Skipped task %s since its synopsis is unknown", taskUUID) // i18n
+			return nil
+		}
+		task := NewTaskData()
+		task.UUID = taskUUID
+		task.Synopsis = synopsis
+		task.Description = state.value("description")
+		task.Pinned = state.value("pinned") == strconv.FormatBool(true)
+		task.DeletedAt = gorm.DeletedAt(state.time(auditFieldDeletedAt))
+		report.add("Created task %s", synopsis) // i18n
+		return tx.Create(&task).Error
+	}
+	// Tasks that were merged into this one are folded into the first one
+	task := tasks[0]
+	for idx := 1; idx < len(tasks); idx++ {
+		err = foldTask(tx, tasks[idx], task)
+		if err != nil {
+			return err
+		}
+	}
+	updates := make(map[string]interface{})
+	if task.UUID != taskUUID {
+		updates[syncFieldUUID] = taskUUID
+	}
+	if state.has("synopsis") && synopsis != "" && synopsis != task.Synopsis {
+		updates["synopsis"] = synopsis
+	}
+	if state.has("description") && state.value("description") != task.Description {
+		updates["description"] = state.value("description")
+	}
+	if pinned := state.value("pinned") == strconv.FormatBool(true); state.has("pinned") && pinned != task.Pinned {
+		updates["pinned"] = pinned
+	}
+	if deletedAt := state.time(auditFieldDeletedAt); state.has(auditFieldDeletedAt) && !nullTimeEqual(deletedAt, sql.NullTime(task.DeletedAt)) {
+		updates[auditFieldDeletedAt] = gorm.DeletedAt(deletedAt)
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+	report.add("Updated task %s", task.Synopsis) // i18n
+	return tx.Unscoped().Model(&TaskData{Model: gorm.Model{ID: task.ID}}).Updates(updates).Error
+}
+
+// foldTask moves the timesheets of a task that was merged into another task, and removes it
+func foldTask(tx *gorm.DB, merged TaskData, survivor TaskData) error {
+	timesheets := make([]TimesheetData, 0)
+	err := tx.Unscoped().Where("task_id = ?", merged.ID).Find(&timesheets).Error
+	if err != nil {
+		return err
+	}
+	for idx := range timesheets {
+		err = tx.Unscoped().
+			Model(&TimesheetData{Model: gorm.Model{ID: timesheets[idx].ID}}).
+			Update("task_id", survivor.ID).
+			Error
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Unscoped().Delete(&TaskData{Model: gorm.Model{ID: merged.ID}}).Error
+}
+
+// logMerge logs that a task was merged into another one; it is logged as a change of this database so that the
+// other databases learn of it
+func logMerge(tx *gorm.DB, merged string, survivor string) error {
+	replica, err := replicaID(tx)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&ChangeData{
+		UUID:       newUUID(),
+		Replica:    replica,
+		RecordType: AuditRecordTask,
+		RecordUUID: merged,
+		Field:      ChangeFieldMergedInto,
+		Value:      &survivor,
+		ChangedAt:  time.Now(),
+	}).Error
+}
+
+// syncTimesheet changes a timesheet to its latest state, creating it if it does not exist
+func syncTimesheet(tx *gorm.DB, timesheetUUID string, report *SyncReport) error {
+	state, err := loadSyncState(tx, AuditRecordTimesheet, []string{timesheetUUID})
+	if err != nil {
+		return err
+	}
+	taskUUID, err := resolveTaskUUID(tx, state.value(syncFieldTask))
+	if err != nil {
+		return err
+	}
+	task := new(TaskData)
+	err = tx.Unscoped().Where("uuid = ?", taskUUID).Limit(1).Find(task).Error
+	if err != nil {
+		return err
+	}
+	startTime := state.time("start_time")
+	if task.ID == 0 || !startTime.Valid {
+		report.add("Skipped timesheet %s since its task or start time is unknown", timesheetUUID) // i18n
+		return nil
+	}
+	timesheets := make([]TimesheetData, 0, 1)
+	err = tx.Unscoped().Where("uuid = ?", timesheetUUID).Limit(1).Find(&timesheets).Error
+	if err != nil {
+		return err
+	}
+	if len(timesheets) == 0 {
+		timesheet := NewTimesheetData()
+		timesheet.UUID = timesheetUUID
+		timesheet.TaskID = task.ID
+		timesheet.StartTime = startTime.Time
+		timesheet.StopTime = state.time("stop_time")
+		timesheet.Note = state.value("note")
+		timesheet.DeletedAt = gorm.DeletedAt(state.time(auditFieldDeletedAt))
+		report.add("Added timesheet of task %s started at %s", task.Synopsis, startTime.Time.Local().Format(time.Stamp)) // i18n
+		return tx.Omit(clause.Associations).Create(&timesheet).Error
+	}
+	timesheet := timesheets[0]
+	updates := make(map[string]interface{})
+	if timesheet.TaskID != task.ID {
+		updates["task_id"] = task.ID
+	}
+	if !timesheet.StartTime.Equal(startTime.Time) {
+		updates["start_time"] = startTime.Time
+	}
+	if stopTime := state.time("stop_time"); state.has("stop_time") && !nullTimeEqual(stopTime, timesheet.StopTime) {
+		updates["stop_time"] = stopTime
+	}
+	if state.has("note") && state.value("note") != timesheet.Note {
+		updates["note"] = state.value("note")
+	}
+	if deletedAt := state.time(auditFieldDeletedAt); state.has(auditFieldDeletedAt) && !nullTimeEqual(deletedAt, sql.NullTime(timesheet.DeletedAt)) {
+		updates[auditFieldDeletedAt] = gorm.DeletedAt(deletedAt)
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+	report.add("Updated timesheet of task %s started at %s", task.Synopsis, startTime.Time.Local().Format(time.Stamp)) // i18n
+	return tx.Unscoped().Model(&TimesheetData{Model: gorm.Model{ID: timesheet.ID}}).Updates(updates).Error
+}
+
+// stopConcurrentTimesheets stops each running timesheet when the next one was started, since only one task can be
+// running. Tasks may have been started in more than one database. These changes are logged in this database.
+func stopConcurrentTimesheets(tx *gorm.DB, report *SyncReport) error {
+	running := make([]TimesheetData, 0)
+	err := tx.Preload("Task").
+		Where("stop_time IS NULL").
+		Order("start_time").
+		Order("uuid").
+		Find(&running).
+		Error
+	if err != nil {
+		return err
+	}
+	for idx := 0; idx+1 < len(running); idx++ {
+		stopTime := running[idx+1].StartTime
+		if stopTime.Before(running[idx].StartTime) {
+			stopTime = running[idx].StartTime
+		}
+		err = tx.WithContext(context.Background()).
+			Model(&TimesheetData{Model: gorm.Model{ID: running[idx].ID}}).
+			Update("stop_time", sql.NullTime{Time: stopTime, Valid: true}).
+			Error
+		if err != nil {
+			return err
+		}
+		report.add("Stopped task %s since task %s was started later", running[idx].Task.Synopsis, running[idx+1].Task.Synopsis) // i18n
+	}
+	return nil
+}
+
+// resolveTaskUUID returns the UUID of the task that a task was merged into, or the same UUID if it was not merged
+func resolveTaskUUID(tx *gorm.DB, taskUUID string) (string, error) {
+	for depth := 0; depth < maxMergeDepth; depth++ {
+		merges := make([]ChangeData, 0, 1)
+		err := tx.Where("record_type = ? AND record_uuid = ? AND field = ?", AuditRecordTask, taskUUID, ChangeFieldMergedInto).
+			Order("changed_at DESC").
+			Limit(1).
+			Find(&merges).
+			Error
+		if err != nil {
+			return "", err
+		}
+		if len(merges) == 0 || merges[0].Value == nil || *merges[0].Value == taskUUID {
+			return taskUUID, nil
+		}
+		taskUUID = *merges[0].Value
+	}
+	return taskUUID, nil
+}
+
+// mergedTaskUUIDs returns the UUID of a task and the UUIDs of the tasks that were merged into it
+func mergedTaskUUIDs(tx *gorm.DB, taskUUID string) ([]string, error) {
+	uuids := []string{taskUUID}
+	for idx := 0; idx < len(uuids) && idx < maxMergeDepth; idx++ {
+		merged := make([]string, 0)
+		err := tx.Model(new(ChangeData)).
+			Where("record_type = ? AND field = ? AND value = ?", AuditRecordTask, ChangeFieldMergedInto, uuids[idx]).
+			Distinct().
+			Pluck("record_uuid", &merged).
+			Error
+		if err != nil {
+			return nil, err
+		}
+		uuids = append(uuids, merged...)
+	}
+	return uuids, nil
+}
+
+// loadSyncState returns the latest value of each synced field among the changes to the records
+func loadSyncState(tx *gorm.DB, recordType string, uuids []string) (syncState, error) {
+	state := make(syncState)
+	for _, field := range syncFields[recordType] {
+		latest := make([]ChangeData, 0, 1)
+		err := tx.Where("record_type = ? AND record_uuid IN ? AND field = ?", recordType, uuids, field).
+			Order("changed_at DESC").
+			Order("replica DESC").
+			Order("uuid DESC").
+			Limit(1).
+			Find(&latest).
+			Error
+		if err != nil {
+			return nil, err
+		}
+		if len(latest) > 0 {
+			state[field] = latest[0].Value
+		}
+	}
+	return state, nil
+}
+
+// has determines if the field was ever changed
+func (ss syncState) has(field string) bool {
+	_, ok := ss[field]
+	return ok
+}
+
+// value returns the value of the field, or an empty string if it is empty or was never changed
+func (ss syncState) value(field string) string {
+	if value := ss[field]; value != nil {
+		return *value
+	}
+	return ""
+}
+
+// time returns the value of a time field, or NULL if it is empty or was never changed
+func (ss syncState) time(field string) sql.NullTime {
+	timestamp, err := time.Parse(time.RFC3339Nano, ss.value(field))
+	if err != nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: timestamp, Valid: true}
+}
+
+// isBefore determines if a change was made before another one; changes made at the same time are ordered by the
+// database that they were made in and then by their UUID so that every database orders them the same way
+func (cd *ChangeData) isBefore(other *ChangeData) bool {
+	if !cd.ChangedAt.Equal(other.ChangedAt) {
+		return cd.ChangedAt.Before(other.ChangedAt)
+	}
+	if cd.Replica != other.Replica {
+		return cd.Replica < other.Replica
+	}
+	return cd.UUID < other.UUID
+}
+
+// add adds a message to the report
+func (sr *SyncReport) add(format string, args ...interface{}) {
+	sr.Messages = append(sr.Messages, fmt.Sprintf(format, args...))
+}
+
+// nullTimeEqual determines if two nullable times are the same
+func nullTimeEqual(first sql.NullTime, second sql.NullTime) bool {
+	if !first.Valid || !second.Valid {
+		return first.Valid == second.Valid
+	}
+	return first.Time.Equal(second.Time)
+}
+
+// sortedKeys returns the keys of the set in order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -32,6 +32,10 @@ type TaskData struct {
 	Synopsis string `gorm:"uniqueindex" json:"Synopsis" xml:"Synopsis" csv:"synopsis"`
 	// Description is a longer description of the task
 	Description string `json:"Description" xml:"Description" csv:"description"`
+	// UUID identifies the task in every database that it is synced to
+	UUID string `gorm:"uniqueIndex" json:"UUID" xml:"UUID" csv:"uuid"`
+	// Pinned marks a favorite task that is listed in the tray's Favorites menu
+	Pinned bool `gorm:"not null;default:false" json:"Pinned" xml:"Pinned" csv:"pinned"`
 }
 
 // NewTask creates a new TaskData structure and returns a Task interface to it
@@ -66,6 +70,8 @@ func (td *TaskData) Clone() Task {
 	// Clone TaskData fields
 	clone.Data().Synopsis = td.Synopsis
 	clone.Data().Description = td.Description
+	clone.Data().Pinned = td.Pinned
+	clone.Data().UUID = td.UUID
 	return clone
 }
 
@@ -93,7 +99,11 @@ type Task interface {
 	Clear()
 	Clone() Task
 	LoadAll(withDeleted bool) ([]TaskData, error)
+	LoadPinned() ([]TaskData, error)
+	SetPinned(pinned bool) error
 	Search(text string) ([]TaskData, error)
+	FuzzySearch(text string) ([]TaskData, error)
+	FullTextSearch(query string) ([]TaskSearchResult, error)
 	SearchBySynopsis(synopsis string) ([]TaskData, error)
 	StopRunningTask() (*TimesheetData, error)
 	FindTaskBySynopsis(tasks []TaskData, synopsis string) *TaskData
@@ -135,11 +145,15 @@ func (td *TaskData) Create() error {
 	}
 	tx := database.Get().Begin()
 	err := tx.Create(td).Error
+	if err == nil {
+		err = journalTask(tx, td.ID, nil, fmt.Sprintf("Create task %s", td.Synopsis)) // i18n
+	}
 	if err != nil {
 		tx.Rollback()
 		return err
 	}
 	tx.Commit()
+	publishEvent(EventTaskCreated, *td, nil)
 	return nil
 }
 
@@ -172,12 +186,19 @@ func (td *TaskData) Delete() error {
 		return err
 	}
 	tx := database.Get().Begin()
-	err = tx.Delete(td).Error
+	before, err := loadJournalRecord(tx, JournalTableTask, td.ID)
+	if err == nil {
+		err = tx.Delete(td).Error
+	}
+	if err == nil {
+		err = journalTask(tx, td.ID, before, fmt.Sprintf("Delete task %s", td.Synopsis)) // i18n
+	}
 	if err != nil {
 		tx.Rollback()
 		return err
 	}
 	tx.Commit()
+	publishEvent(EventTaskDeleted, *td, nil)
 	return nil
 }
 
@@ -192,6 +213,34 @@ func (td *TaskData) LoadAll(withDeleted bool) ([]TaskData, error) {
 	return tasks, err
 }
 
+// LoadPinned loads the tasks that are pinned as favorites, ordered by synopsis
+func (td *TaskData) LoadPinned() ([]TaskData, error) {
+	tasks := make([]TaskData, 0)
+	err := database.Get().
+		Where("pinned = ?", true).
+		Order("synopsis").
+		Find(&tasks).
+		Error
+	return tasks, err
+}
+
+// SetPinned pins the task as a favorite or unpins it
+func (td *TaskData) SetPinned(pinned bool) error {
+	if td.ID == 0 {
+		return tterrors.ErrInvalidTaskState{
+			Details: tterrors.PinInvalidTaskError,
+		}
+	}
+	tx := database.Get().Begin()
+	err := tx.Model(td).Update("pinned", pinned).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	tx.Commit()
+	return nil
+}
+
 // Search searches for a task by synopsis or description using SQL LIKE
 func (td *TaskData) Search(text string) ([]TaskData, error) {
 	tasks := make([]TaskData, 0)
@@ -215,7 +264,7 @@ func (td *TaskData) SearchBySynopsis(synopsis string) ([]TaskData, error) {
 	return tasks, err
 }
 
-// Update writes task changes to the database
+// Update writes task changes to the database. Whether the task is pinned is only changed by SetPinned.
 func (td *TaskData) Update(withDeleted bool) error {
 	if td.ID == 0 {
 		return tterrors.ErrInvalidTaskState{
@@ -234,14 +283,31 @@ func (td *TaskData) Update(withDeleted bool) error {
 		return errors.New("cannot update a deleted task")
 	}
 	tx := db.Begin()
-	err := tx.Save(td).Error
+	before, err := loadJournalRecord(tx, JournalTableTask, td.ID)
+	if err == nil {
+		err = tx.Omit("pinned").Save(td).Error
+	}
+	if err == nil {
+		err = journalTask(tx, td.ID, before, fmt.Sprintf("Update task %s", td.Synopsis)) // i18n
+	}
 	if err != nil {
+		tx.Rollback()
 		return err
 	}
 	tx.Commit()
 	return nil
 }
 
+// journalTask journals the change of a task from its state before an action
+func journalTask(tx *gorm.DB, id uint, before *JournalRecord, description string) error {
+	change, err := journalChange(tx, JournalTableTask, id, before)
+	if err != nil {
+		return err
+	}
+	_, err = recordJournal(tx, description, change)
+	return err
+}
+
 // StopRunningTask stops the currently running task, if any
 func (td *TaskData) StopRunningTask() (timesheetData *TimesheetData, err error) {
 	log := logger.GetFuncLogger(td.log, "StopRunningTask")
@@ -268,6 +334,7 @@ func (td *TaskData) StopRunningTask() (timesheetData *TimesheetData, err error)
 		log.Err(err).Msg("error updating running timesheet")
 		return nil, err
 	}
+	publishEvent(EventTaskStopped, timesheetData.Task, timesheetData)
 	return
 }
 
@@ -276,6 +343,7 @@ func (td *TaskData) Clear() {
 	td.ID = 0
 	td.Synopsis = ""
 	td.Description = ""
+	td.Pinned = false
 	td.CreatedAt = time.Now()
 	td.DeletedAt.Time = time.Now()
 	td.DeletedAt.Valid = false
@@ -0,0 +1,166 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/neflyte/timetracker/lib/database"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+)
+
+// TaskSort is the order in which tasks are listed
+type TaskSort string
+
+const (
+	// TaskSortDefault keeps the order in which the tasks were loaded; search results are best match first
+	TaskSortDefault TaskSort = ""
+	// TaskSortSynopsis sorts tasks by synopsis from A to Z
+	TaskSortSynopsis TaskSort = "synopsis"
+	// TaskSortSynopsisDesc sorts tasks by synopsis from Z to A
+	TaskSortSynopsisDesc TaskSort = "synopsis-desc"
+	// TaskSortRecentlyStarted sorts the most recently started tasks first; tasks that were never started are last
+	TaskSortRecentlyStarted TaskSort = "recent"
+	// TaskSortTotalTime sorts the tasks with the most total time spent on them first
+	TaskSortTotalTime TaskSort = "total-time"
+	// TaskSortCreated sorts the newest tasks first
+	TaskSortCreated TaskSort = "created"
+)
+
+var (
+	// TaskSorts is the list of every task sort
+	TaskSorts = []TaskSort{TaskSortSynopsis, TaskSortSynopsisDesc, TaskSortRecentlyStarted, TaskSortTotalTime, TaskSortCreated}
+	// TaskSortNames are the names of the task sorts shown to the user
+	TaskSortNames = map[TaskSort]string{
+		TaskSortDefault:         "Best match",       // i18n
+		TaskSortSynopsis:        "Synopsis A–Z",     // i18n
+		TaskSortSynopsisDesc:    "Synopsis Z–A",     // i18n
+		TaskSortRecentlyStarted: "Recently started", // i18n
+		TaskSortTotalTime:       "Most total time",  // i18n
+		TaskSortCreated:         "Newest first",     // i18n
+	}
+)
+
+const (
+	// unixEpochJulianDay is the Julian day number of the Unix epoch
+	unixEpochJulianDay = 2440587.5
+	// julianDay is the length of a day in Julian day numbers
+	julianDay = 24 * time.Hour
+)
+
+// taskTimes is the time spent on a task
+type taskTimes struct {
+	lastStarted time.Time
+	total       time.Duration
+}
+
+// ParseTaskSort returns the task sort with the specified name
+func ParseTaskSort(name string) (TaskSort, error) {
+	if name == string(TaskSortDefault) {
+		return TaskSortDefault, nil
+	}
+	for _, taskSort := range TaskSorts {
+		if string(taskSort) == name {
+			return taskSort, nil
+		}
+	}
+	valid := make([]string, len(TaskSorts))
+	for idx, taskSort := range TaskSorts {
+		valid[idx] = string(taskSort)
+	}
+	return TaskSortDefault, tterrors.ErrInvalidTaskSort{Sort: name, Valid: valid}
+}
+
+// SortTasks sorts the tasks in place
+func SortTasks(tasks []TaskData, taskSort TaskSort) error {
+	var less func(a, b *TaskData) bool
+	switch taskSort {
+	case TaskSortDefault:
+		return nil
+	case TaskSortSynopsis, TaskSortSynopsisDesc:
+		less = func(a, b *TaskData) bool {
+			synopsisA, synopsisB := strings.ToLower(a.Synopsis), strings.ToLower(b.Synopsis)
+			if synopsisA == synopsisB {
+				return a.Synopsis < b.Synopsis
+			}
+			return synopsisA < synopsisB
+		}
+		if taskSort == TaskSortSynopsisDesc {
+			ascending := less
+			less = func(a, b *TaskData) bool {
+				return ascending(b, a)
+			}
+		}
+	case TaskSortRecentlyStarted, TaskSortTotalTime:
+		taskIDs := make([]uint, len(tasks))
+		for idx := range tasks {
+			taskIDs[idx] = tasks[idx].ID
+		}
+		times, err := loadTaskTimes(taskIDs, time.Now())
+		if err != nil {
+			return err
+		}
+		less = func(a, b *TaskData) bool {
+			timesA, timesB := times[a.ID], times[b.ID]
+			if taskSort == TaskSortTotalTime && timesA.total != timesB.total {
+				return timesA.total > timesB.total
+			}
+			if !timesA.lastStarted.Equal(timesB.lastStarted) {
+				return timesA.lastStarted.After(timesB.lastStarted)
+			}
+			return a.ID > b.ID
+		}
+	case TaskSortCreated:
+		less = func(a, b *TaskData) bool {
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.After(b.CreatedAt)
+			}
+			return a.ID > b.ID
+		}
+	default:
+		_, err := ParseTaskSort(string(taskSort))
+		return err
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return less(&tasks[i], &tasks[j])
+	})
+	return nil
+}
+
+// loadTaskTimes returns the time that each of the tasks was last started at and the total time spent on it. Timesheets
+// that are still running are counted up to the specified current time. The times are added up by SQLite as Julian
+// days, since times with different UTC offsets cannot be compared as text.
+func loadTaskTimes(taskIDs []uint, currentTime time.Time) (map[uint]taskTimes, error) {
+	rows := make([]struct {
+		TaskID      uint
+		LastStarted float64
+		Total       float64
+	}, 0)
+	err := database.Get().
+		Model(new(TimesheetData)).
+		Select(
+			"task_id, MAX(julianday(start_time)) AS last_started, "+
+				"SUM(julianday(COALESCE(stop_time, ?)) - julianday(start_time)) AS total",
+			currentTime,
+		).
+		Where("task_id IN ?", taskIDs).
+		Group("task_id").
+		Scan(&rows).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	times := make(map[uint]taskTimes)
+	for _, row := range rows {
+		times[row.TaskID] = taskTimes{
+			lastStarted: julianDayTime(row.LastStarted),
+			total:       time.Duration(row.Total * float64(julianDay)),
+		}
+	}
+	return times, nil
+}
+
+// julianDayTime returns the time of a Julian day number
+func julianDayTime(day float64) time.Time {
+	return time.Unix(0, int64((day-unixEpochJulianDay)*float64(julianDay)))
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/neflyte/timetracker/lib/database"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_SortTasks(t *testing.T) {
+	db := MustOpenTestDB(t)
+	defer CloseTestDB(t, db)
+	database.Set(db)
+
+	tasks := []TaskData{
+		{Synopsis: "beta"},
+		{Synopsis: "Alpha"},
+		{Synopsis: "gamma"},
+		{Synopsis: "delta"},
+	}
+	for idx := range tasks {
+		task := NewTaskWithData(tasks[idx])
+		require.Nil(t, task.Create())
+		tasks[idx] = *task.Data()
+	}
+	// beta: 3 hours, started 2 days ago; gamma: 1 hour, started yesterday; delta: running for 2 hours
+	now := time.Now()
+	for _, timesheetData := range []TimesheetData{
+		{Task: tasks[0], StartTime: now.Add(-48 * time.Hour), StopTime: sql.NullTime{Time: now.Add(-45 * time.Hour), Valid: true}},
+		{Task: tasks[2], StartTime: now.Add(-24 * time.Hour), StopTime: sql.NullTime{Time: now.Add(-23 * time.Hour), Valid: true}},
+		{Task: tasks[3], StartTime: now.Add(-2 * time.Hour)},
+	} {
+		require.Nil(t, NewTimesheetWithData(timesheetData).Create())
+	}
+
+	for _, testCase := range []struct {
+		taskSort TaskSort
+		want     []string
+	}{
+		{taskSort: TaskSortDefault, want: []string{"beta", "Alpha", "gamma", "delta"}},
+		{taskSort: TaskSortSynopsis, want: []string{"Alpha", "beta", "delta", "gamma"}},
+		{taskSort: TaskSortSynopsisDesc, want: []string{"gamma", "delta", "beta", "Alpha"}},
+		{taskSort: TaskSortRecentlyStarted, want: []string{"delta", "gamma", "beta", "Alpha"}},
+		{taskSort: TaskSortTotalTime, want: []string{"beta", "delta", "gamma", "Alpha"}},
+		{taskSort: TaskSortCreated, want: []string{"delta", "gamma", "Alpha", "beta"}},
+	} {
+		sorted := append(make([]TaskData, 0, len(tasks)), tasks...)
+		require.Nil(t, SortTasks(sorted, testCase.taskSort))
+		require.Equal(t, testCase.want, synopses(sorted), testCase.taskSort)
+	}
+
+	// Only the times of the tasks being sorted are loaded
+	times, err := loadTaskTimes([]uint{tasks[0].ID, tasks[3].ID}, now)
+	require.Nil(t, err)
+	require.Len(t, times, 2)
+	require.InDelta(t, float64(3*time.Hour), float64(times[tasks[0].ID].total), float64(time.Millisecond))
+	require.WithinDuration(t, now.Add(-48*time.Hour), times[tasks[0].ID].lastStarted, time.Millisecond)
+	require.InDelta(t, float64(2*time.Hour), float64(times[tasks[3].ID].total), float64(time.Millisecond))
+}
+
+func TestUnit_ParseTaskSort(t *testing.T) {
+	for _, taskSort := range append(TaskSorts, TaskSortDefault) {
+		parsed, err := ParseTaskSort(string(taskSort))
+		require.Nil(t, err)
+		require.Equal(t, taskSort, parsed)
+		require.NotEmpty(t, TaskSortNames[taskSort])
+	}
+	_, err := ParseTaskSort("size")
+	require.True(t, errors.As(err, new(tterrors.ErrInvalidTaskSort)))
+	require.EqualError(t, err, `invalid task sort "size"; use one of synopsis, synopsis-desc, recent, total-time, created`)
+	require.NotNil(t, SortTasks(nil, TaskSort("size")))
+}
@@ -308,6 +308,46 @@ func TestUnit_Task_LoadAll_Nominal(t *testing.T) {
 	}
 }
 
+func TestUnit_Task_SetPinned(t *testing.T) {
+	db := MustOpenTestDB(t)
+	defer CloseTestDB(t, db)
+	database.Set(db)
+
+	tasks := []TaskData{
+		{Synopsis: "Task-2", Description: "Task number two"},
+		{Synopsis: testTaskSynopsis, Description: testTaskDescription},
+		{Synopsis: "Task-3", Description: "Task number three"},
+	}
+	for idx := range tasks {
+		task := NewTaskWithData(tasks[idx])
+		require.Nil(t, task.Create())
+		tasks[idx] = *task.Data()
+	}
+	require.Nil(t, NewTaskWithData(tasks[0]).SetPinned(true))
+	require.Nil(t, NewTaskWithData(tasks[1]).SetPinned(true))
+	pinnedTasks, err := NewTask().LoadPinned()
+	require.Nil(t, err)
+	require.Len(t, pinnedTasks, 2)
+	require.Equal(t, testTaskSynopsis, pinnedTasks[0].Synopsis)
+	require.Equal(t, "Task-2", pinnedTasks[1].Synopsis)
+
+	// Editing a task does not unpin it
+	edited := NewTask()
+	edited.Data().ID = tasks[0].ID
+	edited.Data().Synopsis = "Task-2b"
+	require.Nil(t, edited.Update(false))
+	require.Nil(t, edited.Load(false))
+	require.True(t, edited.Data().Pinned)
+
+	require.Nil(t, edited.SetPinned(false))
+	pinnedTasks, err = NewTask().LoadPinned()
+	require.Nil(t, err)
+	require.Len(t, pinnedTasks, 1)
+
+	err = NewTask().SetPinned(true)
+	require.True(t, errors.As(err, &ttErrors.ErrInvalidTaskState{}))
+}
+
 func TestUnit_Task_LoadAll_WithDeleted(t *testing.T) {
 	db := MustOpenTestDB(t)
 	defer CloseTestDB(t, db)
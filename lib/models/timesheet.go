@@ -25,8 +25,6 @@ const (
 // TimesheetData is the main timesheet data structure
 type TimesheetData struct {
 	XMLName xml.Name `gorm:"-" xml:"Timesheet" json:"-" csv:"-"`
-	// Task is the task object linked to this Timesheet
-	Task TaskData `json:"Task" xml:"Task" csv:"-"`
 	// log is the struct logger
 	log zerolog.Logger `gorm:"-"`
 	// StartTime is the time that the task was started at
@@ -34,6 +32,12 @@ type TimesheetData struct {
 	gorm.Model `json:"-" xml:"-" csv:"-"`
 	// StopTime is the time that the task was stopped at; if it is NULL, that means the task is still running
 	StopTime sql.NullTime `gorm:"uniqueIndex:idx_timesheet_stoptime" json:"StopTime,omitempty" xml:"StopTime,omitempty" csv:"stop_time,omitempty"`
+	// Note is a free-form note about the work done during the timesheet
+	Note string `json:"Note,omitempty" xml:"Note,omitempty" csv:"note,omitempty"`
+	// UUID identifies the timesheet in every database that it is synced to
+	UUID string `gorm:"uniqueIndex" json:"UUID,omitempty" xml:"UUID,omitempty" csv:"uuid,omitempty"`
+	// Task is the task object linked to this Timesheet
+	Task TaskData `json:"Task" xml:"Task" csv:"-"`
 	// TaskID is the database ID of the linked task object
 	TaskID uint `gorm:"index:idx_timesheet_laststarted" json:"TaskID" xml:"TaskID" csv:"task_id"`
 }
@@ -75,9 +79,16 @@ type Timesheet interface {
 	CountOpen() (int, error)
 	SearchOpen() ([]TimesheetData, error)
 	SearchDateRange(withDeleted bool) ([]TimesheetData, error)
+	SearchStarted(startTime, endTime time.Time) ([]TimesheetData, error)
+	SearchOverlapping(startTime, endTime time.Time) ([]TimesheetData, error)
+	SearchNotes(text string) ([]TimesheetData, error)
+	FullTextSearch(query string) ([]TimesheetSearchResult, error)
 	LastStartedTasks(limit uint) (startedTasks []TaskData, err error)
 	TaskReport(startDate, endDate time.Time, withDeleted bool) (reportData TaskReport, err error)
 	RunningTimesheet() (Timesheet, error)
+	Validate(currentTime time.Time) error
+	Overlapping(currentTime time.Time) ([]TimesheetData, error)
+	Split(at time.Time) (Timesheet, error)
 	Equals(other Timesheet) bool
 }
 
@@ -125,11 +136,26 @@ func (tsd *TimesheetData) Create() error {
 	}
 	tx := database.Get().Begin()
 	err := tx.Create(tsd).Error
+	var change JournalChange
+	if err == nil {
+		change, err = journalChange(tx, JournalTableTimesheet, tsd.ID, nil)
+	}
+	if err == nil {
+		// A timesheet without a stop time means that the task has started
+		if tsd.StopTime.Valid {
+			_, err = recordJournal(tx, fmt.Sprintf("Add timesheet of task %s", tsd.Task.Synopsis), change) // i18n
+		} else {
+			err = recordStartJournal(tx, tsd.Task.Synopsis, change)
+		}
+	}
 	if err != nil {
 		tx.Rollback()
 		return err
 	}
 	tx.Commit()
+	if !tsd.StopTime.Valid {
+		publishEvent(EventTaskStarted, tsd.Task, tsd)
+	}
 	return nil
 }
 
@@ -157,9 +183,24 @@ func (tsd *TimesheetData) Delete() error {
 	if err != nil {
 		return err
 	}
-	return database.Get().
-		Delete(tsd).
-		Error
+	tx := database.Get().Begin()
+	before, err := loadJournalRecord(tx, JournalTableTimesheet, tsd.ID)
+	if err == nil {
+		err = tx.Delete(tsd).Error
+	}
+	var change JournalChange
+	if err == nil {
+		change, err = journalChange(tx, JournalTableTimesheet, tsd.ID, before)
+	}
+	if err == nil {
+		_, err = recordJournal(tx, fmt.Sprintf("Delete timesheet of task %s", tsd.Task.Synopsis), change) // i18n
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	tx.Commit()
+	return nil
 }
 
 // LoadAll loads all timesheet records, optionally including deleted timesheets
@@ -233,6 +274,32 @@ func (tsd *TimesheetData) SearchDateRange(withDeleted bool) ([]TimesheetData, er
 	return timesheets, err
 }
 
+// SearchStarted returns the timesheets that started between startTime and endTime, including any that are still
+// running, ordered by their start time
+func (tsd *TimesheetData) SearchStarted(startTime, endTime time.Time) ([]TimesheetData, error) {
+	timesheets := make([]TimesheetData, 0)
+	err := database.Get().
+		Joins("Task").
+		Where("start_time >= ? AND start_time <= ?", startTime, endTime).
+		Order("start_time").
+		Find(&timesheets).
+		Error
+	return timesheets, err
+}
+
+// SearchOverlapping returns the timesheets that were running at any time between startTime and endTime, including
+// any that started before startTime, ordered by their start time
+func (tsd *TimesheetData) SearchOverlapping(startTime, endTime time.Time) ([]TimesheetData, error) {
+	timesheets := make([]TimesheetData, 0)
+	err := database.Get().
+		Joins("Task").
+		Where("start_time < ? AND (stop_time IS NULL OR stop_time > ?)", endTime, startTime).
+		Order("start_time").
+		Find(&timesheets).
+		Error
+	return timesheets, err
+}
+
 // Update attempts to update the timesheet record in the database
 func (tsd *TimesheetData) Update() error {
 	if tsd.ID == 0 {
@@ -246,12 +313,32 @@ func (tsd *TimesheetData) Update() error {
 		}
 	}
 	tx := database.Get().Begin()
-	err := tx.Save(tsd).Error
+	before, err := loadJournalRecord(tx, JournalTableTimesheet, tsd.ID)
+	if err == nil {
+		err = tx.Save(tsd).Error
+	}
+	var change JournalChange
+	if err == nil {
+		change, err = journalChange(tx, JournalTableTimesheet, tsd.ID, before)
+	}
+	// A timesheet that gets a stop time means that the task has stopped
+	stopped := before != nil && before.StopTime == nil && tsd.StopTime.Valid
+	description := fmt.Sprintf("Edit timesheet of task %s", tsd.Task.Synopsis) // i18n
+	if stopped {
+		description = fmt.Sprintf("Stop task %s", tsd.Task.Synopsis) // i18n
+	}
+	var journalID uint
+	if err == nil {
+		journalID, err = recordJournal(tx, description, change)
+	}
 	if err != nil {
 		tx.Rollback()
 		return err
 	}
 	tx.Commit()
+	if stopped {
+		rememberStop(tsd.Task.Synopsis, journalID)
+	}
 	return nil
 }
 
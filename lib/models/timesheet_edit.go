@@ -0,0 +1,121 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/neflyte/timetracker/lib/database"
+	ttErrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/utils"
+)
+
+// Validate checks that the timesheet can be saved. It must start in the past, stop after it starts and not in the
+// future, be the only running timesheet and not overlap another timesheet.
+func (tsd *TimesheetData) Validate(currentTime time.Time) error {
+	if tsd.StartTime.After(currentTime) {
+		return errors.New(ttErrors.StartInFutureTimesheetError)
+	}
+	if tsd.StopTime.Valid {
+		if !tsd.StopTime.Time.After(tsd.StartTime) {
+			return errors.New(ttErrors.StopBeforeStartTimesheetError)
+		}
+		if tsd.StopTime.Time.After(currentTime) {
+			return errors.New(ttErrors.StopInFutureTimesheetError)
+		}
+	} else {
+		// Only one timesheet can be running
+		openTimesheets, err := NewTimesheet().SearchOpen()
+		if err != nil {
+			return err
+		}
+		for _, openTimesheet := range openTimesheets {
+			if openTimesheet.ID != tsd.ID {
+				return errors.New(ttErrors.AlreadyRunningTimesheetError)
+			}
+		}
+	}
+	overlapping, err := tsd.Overlapping(currentTime)
+	if err != nil {
+		return err
+	}
+	if len(overlapping) > 0 {
+		return ttErrors.ErrOverlappingTimesheet{
+			Other: overlapping[0].describe(tsd.StartTime),
+		}
+	}
+	return nil
+}
+
+// Overlapping returns the other timesheets whose time overlaps the time of this one, ordered by their start time.
+// A running timesheet lasts until currentTime.
+func (tsd *TimesheetData) Overlapping(currentTime time.Time) ([]TimesheetData, error) {
+	stopTime := currentTime
+	if tsd.StopTime.Valid {
+		stopTime = tsd.StopTime.Time
+	}
+	timesheets := make([]TimesheetData, 0)
+	err := database.Get().
+		Joins("Task").
+		Where("timesheet.id <> ? AND start_time < ? AND (stop_time IS NULL OR stop_time > ?)", tsd.ID, stopTime, tsd.StartTime).
+		Order("start_time").
+		Find(&timesheets).
+		Error
+	return timesheets, err
+}
+
+// Split splits the timesheet in two at the specified time. The timesheet stops at that time and a new timesheet for
+// the same task starts at it and stops when the timesheet used to; the new timesheet is returned.
+func (tsd *TimesheetData) Split(at time.Time) (Timesheet, error) {
+	if tsd.ID == 0 {
+		return nil, ttErrors.ErrInvalidTimesheetState{
+			Details: ttErrors.UpdateInvalidTimesheetError,
+		}
+	}
+	if !at.After(tsd.StartTime) || (tsd.StopTime.Valid && !at.Before(tsd.StopTime.Time)) || at.After(time.Now()) {
+		return nil, ttErrors.ErrInvalidTimesheetState{
+			Details: ttErrors.SplitOutsideTimesheetError,
+		}
+	}
+	second := NewTimesheet()
+	second.Data().Task = tsd.Task
+	second.Data().TaskID = tsd.TaskID
+	second.Data().StartTime = at
+	second.Data().StopTime = tsd.StopTime
+	second.Data().Note = tsd.Note
+	tx := database.Get().Begin()
+	before, err := loadJournalRecord(tx, JournalTableTimesheet, tsd.ID)
+	if err == nil {
+		err = tx.Model(tsd).Update("stop_time", at).Error
+	}
+	if err == nil {
+		err = tx.Create(second.Data()).Error
+	}
+	var firstChange, secondChange JournalChange
+	if err == nil {
+		firstChange, err = journalChange(tx, JournalTableTimesheet, tsd.ID, before)
+	}
+	if err == nil {
+		secondChange, err = journalChange(tx, JournalTableTimesheet, second.Data().ID, nil)
+	}
+	if err == nil {
+		_, err = recordJournal(tx, fmt.Sprintf("Split timesheet of task %s", tsd.Task.Synopsis), firstChange, secondChange) // i18n
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	tx.Commit()
+	tsd.StopTime = sql.NullTime{Time: at, Valid: true}
+	return second, nil
+}
+
+// describe returns a short description of the timesheet with the times on the specified day
+func (tsd *TimesheetData) describe(day time.Time) string {
+	stopTime := "now" // i18n
+	if tsd.StopTime.Valid {
+		stopTime = utils.FormatTimeOfDay(tsd.StopTime.Time, day)
+	}
+	return fmt.Sprintf("%s from %s to %s", tsd.Task.Synopsis, utils.FormatTimeOfDay(tsd.StartTime, day), stopTime) // i18n
+}
@@ -0,0 +1,106 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/neflyte/timetracker/lib/database"
+	ttErrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_Timesheet_Validate(t *testing.T) {
+	db := MustOpenTestDB(t)
+	defer CloseTestDB(t, db)
+	database.Set(db)
+
+	task := NewTask()
+	task.Data().Synopsis = "review"
+	require.Nil(t, task.Create())
+	day := time.Date(2023, 6, 5, 0, 0, 0, 0, time.Local)
+	currentTime := day.Add(12 * time.Hour)
+	existing := NewTimesheet()
+	existing.Data().Task = *task.Data()
+	existing.Data().StartTime = day.Add(9 * time.Hour)
+	existing.Data().StopTime = sql.NullTime{Time: day.Add(10 * time.Hour), Valid: true}
+	require.Nil(t, existing.Create())
+
+	timesheet := NewTimesheet()
+	timesheet.Data().Task = *task.Data()
+	timesheet.Data().StartTime = day.Add(13 * time.Hour)
+	require.EqualError(t, timesheet.Validate(currentTime), ttErrors.StartInFutureTimesheetError)
+	timesheet.Data().StartTime = day.Add(11 * time.Hour)
+	timesheet.Data().StopTime = sql.NullTime{Time: day.Add(10 * time.Hour), Valid: true}
+	require.EqualError(t, timesheet.Validate(currentTime), ttErrors.StopBeforeStartTimesheetError)
+	timesheet.Data().StopTime.Time = day.Add(13 * time.Hour)
+	require.EqualError(t, timesheet.Validate(currentTime), ttErrors.StopInFutureTimesheetError)
+
+	// Timesheets may touch but not overlap
+	timesheet.Data().StartTime = day.Add(10 * time.Hour)
+	timesheet.Data().StopTime.Time = day.Add(11 * time.Hour)
+	require.Nil(t, timesheet.Validate(currentTime))
+	timesheet.Data().StartTime = day.Add(9*time.Hour + 30*time.Minute)
+	err := timesheet.Validate(currentTime)
+	require.True(t, errors.As(err, new(ttErrors.ErrOverlappingTimesheet)))
+	require.EqualError(t, err, ttErrors.OverlappingTimesheetError+": review from 09:00:00 to 10:00:00")
+
+	// A running timesheet lasts until now
+	timesheet.Data().StartTime = day.Add(8 * time.Hour)
+	timesheet.Data().StopTime = sql.NullTime{}
+	overlapping, err := timesheet.Overlapping(currentTime)
+	require.Nil(t, err)
+	require.Len(t, overlapping, 1)
+	require.Equal(t, existing.Data().ID, overlapping[0].ID)
+
+	// A timesheet does not overlap itself
+	require.Nil(t, existing.Validate(currentTime))
+}
+
+func TestUnit_Timesheet_Split(t *testing.T) {
+	db := MustOpenTestDB(t)
+	defer CloseTestDB(t, db)
+	database.Set(db)
+
+	task := NewTask()
+	task.Data().Synopsis = "review"
+	require.Nil(t, task.Create())
+	startTime := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+	timesheet := NewTimesheet()
+	timesheet.Data().Task = *task.Data()
+	timesheet.Data().StartTime = startTime
+	timesheet.Data().StopTime = sql.NullTime{Time: startTime.Add(time.Hour), Valid: true}
+	timesheet.Data().Note = "webhooks"
+	require.Nil(t, timesheet.Create())
+
+	_, err := timesheet.Split(startTime)
+	require.True(t, errors.As(err, new(ttErrors.ErrInvalidTimesheetState)))
+	_, err = timesheet.Split(startTime.Add(time.Hour))
+	require.True(t, errors.As(err, new(ttErrors.ErrInvalidTimesheetState)))
+
+	second, err := timesheet.Split(startTime.Add(20 * time.Minute))
+	require.Nil(t, err)
+	require.NotZero(t, second.Data().ID)
+	require.Nil(t, timesheet.Load())
+	require.Nil(t, second.Load())
+	require.True(t, timesheet.Data().StopTime.Time.Equal(startTime.Add(20*time.Minute)))
+	require.True(t, second.Data().StartTime.Equal(startTime.Add(20*time.Minute)))
+	require.True(t, second.Data().StopTime.Time.Equal(startTime.Add(time.Hour)))
+	require.Equal(t, task.Data().ID, second.Data().Task.ID)
+	require.Equal(t, "webhooks", second.Data().Note)
+
+	// The second part of a running timesheet keeps running
+	running := NewTimesheet()
+	running.Data().Task = *task.Data()
+	running.Data().StartTime = startTime.Add(time.Hour)
+	require.Nil(t, running.Create())
+	_, err = running.Split(time.Now().Add(time.Minute))
+	require.True(t, errors.As(err, new(ttErrors.ErrInvalidTimesheetState)))
+	second, err = running.Split(startTime.Add(90 * time.Minute))
+	require.Nil(t, err)
+	require.False(t, second.Data().StopTime.Valid)
+	runningTimesheet, err := NewTimesheet().RunningTimesheet()
+	require.Nil(t, err)
+	require.Equal(t, second.Data().ID, runningTimesheet.Data().ID)
+}
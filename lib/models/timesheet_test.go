@@ -470,3 +470,73 @@ func TestUnit_Timesheet_TaskReport(t *testing.T) {
 		)
 	}
 }
+
+func TestUnit_Timesheet_SearchStarted_Nominal(t *testing.T) {
+	db := MustOpenTestDB(t)
+	defer CloseTestDB(t, db)
+	database.Set(db)
+
+	td := NewTask()
+	td.Data().Synopsis = testTaskSynopsis
+	td.Data().Description = testTaskDescription
+	err := td.Create()
+	require.Nil(t, err)
+
+	// Yesterday's timesheet is outside the range
+	dayStart := time.Now().Truncate(time.Hour).Add(-2 * time.Hour)
+	startTimes := []time.Time{dayStart.Add(-24 * time.Hour), dayStart.Add(time.Hour), dayStart}
+	for idx, startTime := range startTimes {
+		tsd := NewTimesheet()
+		tsd.Data().Task = *td.Data()
+		tsd.Data().StartTime = startTime
+		if idx < len(startTimes)-1 {
+			tsd.Data().StopTime = sql.NullTime{Time: startTime.Add(30 * time.Minute), Valid: true}
+		}
+		err = tsd.Create()
+		require.Nil(t, err)
+	}
+
+	timesheets, err := NewTimesheet().SearchStarted(dayStart, time.Now())
+	require.Nil(t, err)
+	require.Len(t, timesheets, 2)
+	require.True(t, timesheets[0].StartTime.Equal(dayStart))
+	require.False(t, timesheets[0].StopTime.Valid)
+	require.True(t, timesheets[1].StartTime.Equal(dayStart.Add(time.Hour)))
+	require.Equal(t, testTaskSynopsis, timesheets[1].Task.Synopsis)
+}
+
+func TestUnit_Timesheet_SearchOverlapping(t *testing.T) {
+	db := MustOpenTestDB(t)
+	defer CloseTestDB(t, db)
+	database.Set(db)
+
+	td := NewTask()
+	td.Data().Synopsis = testTaskSynopsis
+	require.Nil(t, td.Create())
+
+	periodStart := time.Now().Truncate(time.Hour).Add(-2 * time.Hour)
+	// Before the period, across its start, within it, and still running since before it
+	periods := [][2]time.Time{
+		{periodStart.Add(-3 * time.Hour), periodStart.Add(-2 * time.Hour)},
+		{periodStart.Add(-time.Hour), periodStart.Add(30 * time.Minute)},
+		{periodStart.Add(45 * time.Minute), periodStart.Add(time.Hour)},
+		{periodStart.Add(-30 * time.Minute), {}},
+	}
+	for _, period := range periods {
+		tsd := NewTimesheet()
+		tsd.Data().Task = *td.Data()
+		tsd.Data().StartTime = period[0]
+		if !period[1].IsZero() {
+			tsd.Data().StopTime = sql.NullTime{Time: period[1], Valid: true}
+		}
+		require.Nil(t, tsd.Create())
+	}
+
+	timesheets, err := NewTimesheet().SearchOverlapping(periodStart, time.Now())
+	require.Nil(t, err)
+	require.Len(t, timesheets, 3)
+	require.True(t, timesheets[0].StartTime.Equal(periodStart.Add(-time.Hour)))
+	require.True(t, timesheets[1].StartTime.Equal(periodStart.Add(-30*time.Minute)))
+	require.False(t, timesheets[1].StopTime.Valid)
+	require.True(t, timesheets[2].StartTime.Equal(periodStart.Add(45*time.Minute)))
+}
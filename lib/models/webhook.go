@@ -0,0 +1,210 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/neflyte/timetracker/lib/database"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	// webhookEventsSeparator separates the event types in the Events field of a webhook
+	webhookEventsSeparator = ","
+)
+
+// WebhookData is the main webhook endpoint data structure
+type WebhookData struct {
+	// log is the struct logger
+	log        zerolog.Logger `gorm:"-"`
+	gorm.Model `json:"-" xml:"-" csv:"-"`
+	// URL is the address of the endpoint that receives event payloads
+	URL string `gorm:"not null" json:"URL" xml:"URL" csv:"url"`
+	// Secret is the key used to sign event payloads
+	Secret string `json:"-" xml:"-" csv:"-"`
+	// Events is a comma-separated list of the event types sent to the endpoint; empty means all events
+	Events string `json:"Events" xml:"Events" csv:"events"`
+}
+
+// NewWebhook returns a newly-initialized Webhook interface
+func NewWebhook() Webhook {
+	return NewWebhookWithData(NewWebhookData())
+}
+
+// NewWebhookWithData returns a new Webhook interface based on the supplied WebhookData struct
+func NewWebhookWithData(data WebhookData) Webhook {
+	return &data
+}
+
+// NewWebhookData returns a newly-initialized WebhookData struct
+func NewWebhookData() WebhookData {
+	return WebhookData{
+		log: logger.GetStructLogger("WebhookData"),
+	}
+}
+
+// TableName implements schema.Tabler
+func (wd *WebhookData) TableName() string {
+	return "webhook"
+}
+
+// Webhook is the main interface to webhook endpoint definitions
+type Webhook interface {
+	fmt.Stringer
+	schema.Tabler
+	Data() *WebhookData
+	Create() error
+	Load() error
+	Delete() error
+	LoadAll() ([]WebhookData, error)
+	EventTypes() []EventType
+	Accepts(eventType EventType) bool
+}
+
+// Data returns the underlying struct of the interface
+func (wd *WebhookData) Data() *WebhookData {
+	return wd
+}
+
+// String implements fmt.Stringer
+func (wd *WebhookData) String() string {
+	return fmt.Sprintf("%s (#%d)", wd.URL, wd.ID)
+}
+
+// Create creates a new webhook endpoint
+func (wd *WebhookData) Create() error {
+	if wd.ID != 0 {
+		return tterrors.ErrInvalidWebhookState{
+			Details: tterrors.OverwriteWebhookByCreateError,
+		}
+	}
+	if wd.URL == "" {
+		return tterrors.ErrInvalidWebhookState{
+			Details: tterrors.EmptyURLWebhookError,
+		}
+	}
+	for _, eventType := range wd.EventTypes() {
+		if !isKnownEventType(eventType) {
+			return tterrors.ErrInvalidWebhookState{
+				Details: fmt.Sprintf("%s: %s", tterrors.UnknownEventTypeWebhookError, eventType),
+			}
+		}
+	}
+	tx := database.Get().Begin()
+	err := tx.Create(wd).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	tx.Commit()
+	return nil
+}
+
+// Load attempts to load the webhook endpoint specified by ID
+func (wd *WebhookData) Load() error {
+	if wd.ID == 0 {
+		return tterrors.ErrInvalidWebhookState{
+			Details: tterrors.LoadInvalidWebhookError,
+		}
+	}
+	return database.Get().First(wd, wd.ID).Error
+}
+
+// Delete removes the webhook endpoint along with any of its undelivered events
+func (wd *WebhookData) Delete() error {
+	if wd.ID == 0 {
+		return tterrors.ErrInvalidWebhookState{
+			Details: tterrors.DeleteInvalidWebhookError,
+		}
+	}
+	err := wd.Load()
+	if err != nil {
+		return err
+	}
+	tx := database.Get().Begin()
+	err = tx.Where("webhook_id = ?", wd.ID).Delete(new(WebhookDeliveryData)).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	err = tx.Delete(wd).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	tx.Commit()
+	return nil
+}
+
+// LoadAll loads all webhook endpoints
+func (wd *WebhookData) LoadAll() ([]WebhookData, error) {
+	webhooks := make([]WebhookData, 0)
+	err := database.Get().Find(&webhooks).Error
+	return webhooks, err
+}
+
+// EventTypes returns the event types that the endpoint receives; an empty slice means all event types
+func (wd *WebhookData) EventTypes() []EventType {
+	eventTypes := make([]EventType, 0)
+	for _, eventType := range strings.Split(wd.Events, webhookEventsSeparator) {
+		eventType = strings.TrimSpace(eventType)
+		if eventType != "" {
+			eventTypes = append(eventTypes, EventType(eventType))
+		}
+	}
+	return eventTypes
+}
+
+// Accepts determines if the endpoint receives events of the specified type
+func (wd *WebhookData) Accepts(eventType EventType) bool {
+	eventTypes := wd.EventTypes()
+	if len(eventTypes) == 0 {
+		return true
+	}
+	for _, acceptedType := range eventTypes {
+		if acceptedType == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// isKnownEventType determines if the event type is one that can be published
+func isKnownEventType(eventType EventType) bool {
+	for _, knownType := range AllEventTypes {
+		if knownType == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDeliveryData is a queued event payload that is waiting to be delivered to a webhook endpoint
+type WebhookDeliveryData struct {
+	// NextAttemptAt is the earliest time that the next delivery attempt can be made
+	NextAttemptAt time.Time `gorm:"not null;index:idx_webhook_delivery_pending"`
+	// DeliveredAt is the time that the payload was successfully delivered; NULL means it is still pending
+	DeliveredAt sql.NullTime `gorm:"index:idx_webhook_delivery_pending"`
+	gorm.Model
+	// Event is the type of event contained in the payload
+	Event string `gorm:"not null"`
+	// Payload is the JSON-encoded event payload
+	Payload string `gorm:"not null"`
+	// LastError is the error that occurred during the most recent delivery attempt, if any
+	LastError string
+	// Attempts is the number of delivery attempts made so far
+	Attempts int `gorm:"not null;default:0"`
+	// WebhookID is the database ID of the endpoint the payload is sent to
+	WebhookID uint `gorm:"not null;index"`
+}
+
+// TableName implements schema.Tabler
+func (wdd *WebhookDeliveryData) TableName() string {
+	return "webhook_delivery"
+}
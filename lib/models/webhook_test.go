@@ -0,0 +1,115 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/neflyte/timetracker/lib/database"
+	ttErrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testWebhookURL = "http://127.0.0.1:9999/hook"
+)
+
+func TestUnit_Webhook_CreateAndLoad_Nominal(t *testing.T) {
+	db := MustOpenTestDB(t)
+	defer CloseTestDB(t, db)
+	database.Set(db)
+
+	wh := NewWebhook()
+	wh.Data().URL = testWebhookURL
+	wh.Data().Secret = "s3cr3t"
+	wh.Data().Events = "task.started, task.stopped"
+	err := wh.Create()
+	require.Nil(t, err)
+	require.NotEqual(t, uint(0), wh.Data().ID)
+
+	wh2 := NewWebhook()
+	wh2.Data().ID = wh.Data().ID
+	err = wh2.Load()
+	require.Nil(t, err)
+	require.Equal(t, testWebhookURL, wh2.Data().URL)
+	require.Equal(t, []EventType{EventTaskStarted, EventTaskStopped}, wh2.EventTypes())
+	require.True(t, wh2.Accepts(EventTaskStarted))
+	require.False(t, wh2.Accepts(EventTaskCreated))
+}
+
+func TestUnit_Webhook_Create_Invalid(t *testing.T) {
+	db := MustOpenTestDB(t)
+	defer CloseTestDB(t, db)
+	database.Set(db)
+
+	// Empty URL
+	wh := NewWebhook()
+	err := wh.Create()
+	require.True(t, errors.Is(err, ttErrors.ErrInvalidWebhookState{Details: ttErrors.EmptyURLWebhookError}))
+
+	// Unknown event type
+	wh = NewWebhook()
+	wh.Data().URL = testWebhookURL
+	wh.Data().Events = "task.exploded"
+	err = wh.Create()
+	require.NotNil(t, err)
+
+	// Existing ID
+	wh = NewWebhook()
+	wh.Data().ID = 1
+	wh.Data().URL = testWebhookURL
+	err = wh.Create()
+	require.True(t, errors.Is(err, ttErrors.ErrInvalidWebhookState{Details: ttErrors.OverwriteWebhookByCreateError}))
+}
+
+func TestUnit_Webhook_Accepts_AllEvents(t *testing.T) {
+	wh := NewWebhook()
+	for _, eventType := range AllEventTypes {
+		require.True(t, wh.Accepts(eventType))
+	}
+}
+
+func TestUnit_Webhook_Delete_RemovesDeliveries(t *testing.T) {
+	db := MustOpenTestDB(t)
+	defer CloseTestDB(t, db)
+	database.Set(db)
+
+	wh := NewWebhook()
+	wh.Data().URL = testWebhookURL
+	err := wh.Create()
+	require.Nil(t, err)
+	err = db.Create(&WebhookDeliveryData{WebhookID: wh.Data().ID, Event: string(EventTaskCreated), Payload: "{}"}).Error
+	require.Nil(t, err)
+
+	err = wh.Delete()
+	require.Nil(t, err)
+	var count int64
+	err = db.Model(new(WebhookDeliveryData)).Where("webhook_id = ?", wh.Data().ID).Count(&count).Error
+	require.Nil(t, err)
+	require.Equal(t, int64(0), count)
+}
+
+func TestUnit_Events_Published(t *testing.T) {
+	db := MustOpenTestDB(t)
+	defer CloseTestDB(t, db)
+	database.Set(db)
+	defer ClearEventListeners()
+
+	received := make([]EventType, 0)
+	AddEventListener(func(event Event) {
+		received = append(received, event.Type)
+	})
+
+	td := NewTask()
+	td.Data().Synopsis = testTaskSynopsis
+	require.Nil(t, td.Create())
+	tsd := NewTimesheet()
+	tsd.Data().Task = *td.Data()
+	tsd.Data().StartTime = time.Now()
+	require.Nil(t, tsd.Create())
+	stopped, err := td.StopRunningTask()
+	require.Nil(t, err)
+	require.True(t, stopped.StopTime.Valid)
+	require.Nil(t, td.Delete())
+	require.Equal(t, []EventType{EventTaskCreated, EventTaskStarted, EventTaskStopped, EventTaskDeleted}, received)
+}
@@ -0,0 +1,80 @@
+package monitor
+
+import (
+	"github.com/neflyte/timetracker/lib/constants"
+	"github.com/neflyte/timetracker/lib/models"
+)
+
+// ServiceUpdateEvent represents an event indicating the current state of the monitor; it is sent when the monitor
+// starts and when it reconnects to the database
+type ServiceUpdateEvent struct{}
+
+// DatabaseChangedEvent is sent when the database has been changed by this or any other app, such as when a task is
+// created or pinned, after any events about the running task. It is not sent when the database is polled.
+type DatabaseChangedEvent struct{}
+
+// TaskStartedEvent is sent when a task is started while no other task was running
+type TaskStartedEvent struct {
+	// After is the timesheet of the task that was started
+	After models.TimesheetData
+}
+
+// TaskStoppedEvent is sent when the running task is stopped and no other task was started
+type TaskStoppedEvent struct {
+	// Before is the timesheet of the task that was stopped
+	Before models.TimesheetData
+}
+
+// TaskSwitchedEvent is sent when the running timesheet is replaced by a different one
+type TaskSwitchedEvent struct {
+	// Before is the timesheet that was running
+	Before models.TimesheetData
+	// After is the timesheet that is now running
+	After models.TimesheetData
+}
+
+// ErrorRaisedEvent is sent when the running timesheet cannot be determined
+type ErrorRaisedEvent struct {
+	Error error
+}
+
+// ErrorClearedEvent is sent when the running timesheet can be determined again after an error
+type ErrorClearedEvent struct{}
+
+// serviceState is a snapshot of the state of the monitor used to work out which events to send
+type serviceState struct {
+	timesheet *models.TimesheetData
+	err       error
+	status    int
+}
+
+// diffEvents returns the events that describe the change from one state to the next. The timesheet of a
+// state with an error is ignored; changes are reported against the last timesheet known before the error.
+func diffEvents(before serviceState, after serviceState) []interface{} {
+	events := make([]interface{}, 0)
+	if after.status == constants.TimesheetStatusError {
+		if before.status != constants.TimesheetStatusError || errorText(before.err) != errorText(after.err) {
+			events = append(events, ErrorRaisedEvent{Error: after.err})
+		}
+		return events
+	}
+	if before.status == constants.TimesheetStatusError {
+		events = append(events, ErrorClearedEvent{})
+	}
+	switch {
+	case before.timesheet == nil && after.timesheet != nil:
+		events = append(events, TaskStartedEvent{After: *after.timesheet})
+	case before.timesheet != nil && after.timesheet == nil:
+		events = append(events, TaskStoppedEvent{Before: *before.timesheet})
+	case before.timesheet != nil && before.timesheet.ID != after.timesheet.ID:
+		events = append(events, TaskSwitchedEvent{Before: *before.timesheet, After: *after.timesheet})
+	}
+	return events
+}
+
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
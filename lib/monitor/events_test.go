@@ -0,0 +1,96 @@
+package monitor
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/neflyte/timetracker/lib/constants"
+	"github.com/neflyte/timetracker/lib/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_DiffEvents(t *testing.T) {
+	first := &models.TimesheetData{}
+	first.ID = 1
+	second := &models.TimesheetData{}
+	second.ID = 2
+	idle := serviceState{status: constants.TimesheetStatusIdle}
+	runningFirst := serviceState{timesheet: first, status: constants.TimesheetStatusRunning}
+	runningSecond := serviceState{timesheet: second, status: constants.TimesheetStatusRunning}
+	failed := serviceState{err: errors.New("database is locked"), status: constants.TimesheetStatusError}
+
+	testCases := []struct {
+		name     string
+		before   serviceState
+		after    serviceState
+		expected []interface{}
+	}{
+		{"unchanged idle", idle, idle, []interface{}{}},
+		{"unchanged running", runningFirst, runningFirst, []interface{}{}},
+		{"started", idle, runningFirst, []interface{}{TaskStartedEvent{After: *first}}},
+		{"stopped", runningFirst, idle, []interface{}{TaskStoppedEvent{Before: *first}}},
+		{"switched", runningFirst, runningSecond, []interface{}{TaskSwitchedEvent{Before: *first, After: *second}}},
+		{"error raised", runningFirst, failed, []interface{}{ErrorRaisedEvent{Error: failed.err}}},
+		{"same error", failed, failed, []interface{}{}},
+		{"error cleared", failed, idle, []interface{}{ErrorClearedEvent{}}},
+		{
+			"error cleared after switch",
+			serviceState{timesheet: first, err: failed.err, status: constants.TimesheetStatusError},
+			runningSecond,
+			[]interface{}{ErrorClearedEvent{}, TaskSwitchedEvent{Before: *first, After: *second}},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			require.Equal(t, testCase.expected, diffEvents(testCase.before, testCase.after))
+		})
+	}
+}
+
+func TestUnit_Subscribe_MultipleSubscribers(t *testing.T) {
+	service := NewService(make(chan bool, 1)).(*ServiceData)
+	firstObservable, unsubscribeFirst := service.Subscribe()
+	secondObservable, unsubscribeSecond := service.Subscribe()
+	// The observables are hot, so they must be observed before events are published
+	firstItems := make([]interface{}, 0)
+	firstDone := firstObservable.ForEach(func(item interface{}) {
+		firstItems = append(firstItems, item)
+	}, func(error) {}, func() {})
+	secondItems := make([]interface{}, 0)
+	secondDone := secondObservable.ForEach(func(item interface{}) {
+		secondItems = append(secondItems, item)
+	}, func(error) {}, func() {})
+	service.publish(ServiceUpdateEvent{})
+	service.publish(ErrorClearedEvent{})
+	unsubscribeFirst()
+	unsubscribeSecond()
+	// Unsubscribing twice is harmless
+	unsubscribeFirst()
+	<-firstDone
+	<-secondDone
+	expected := []interface{}{ServiceUpdateEvent{}, ErrorClearedEvent{}}
+	require.Equal(t, expected, firstItems)
+	require.Equal(t, expected, secondItems)
+}
+
+func TestUnit_Publish_SlowSubscriber(t *testing.T) {
+	service := NewService(make(chan bool, 1)).(*ServiceData)
+	// Nothing observes the events of this subscriber
+	_, unsubscribe := service.Subscribe()
+	published := make(chan bool)
+	go func() {
+		for idx := 0; idx < subscriberChanSize*2; idx++ {
+			service.publish(ServiceUpdateEvent{})
+		}
+		published <- true
+	}()
+	select {
+	case <-published:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "publish blocked on a subscriber that is not keeping up")
+	}
+	unsubscribe()
+	// Publishing after the last subscriber left is harmless
+	service.publish(ServiceUpdateEvent{})
+}
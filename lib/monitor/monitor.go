@@ -2,51 +2,59 @@ package monitor
 
 import (
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
+	"github.com/neflyte/timetracker/lib/backup"
 	"github.com/neflyte/timetracker/lib/constants"
+	"github.com/neflyte/timetracker/lib/database"
 	ttErrors "github.com/neflyte/timetracker/lib/errors"
 	"github.com/neflyte/timetracker/lib/logger"
 	"github.com/neflyte/timetracker/lib/models"
+	"github.com/neflyte/timetracker/lib/webhooks"
 	"github.com/reactivex/rxgo/v2"
 	"github.com/rs/zerolog"
 )
 
 const (
-	monitorServiceCommandChanSize = 5
+	// subscriberChanSize is the number of events that a subscriber can fall behind by before events are dropped
+	subscriberChanSize = 16
 )
 
-// ServiceUpdateEvent represents an event indicating data has been refreshed
-type ServiceUpdateEvent struct{}
-
 // ServiceData is the main data struct of the Service
 type ServiceData struct {
-	log                 zerolog.Logger
-	runningTimesheet    models.Timesheet
-	timesheetError      error
-	tsModel             models.Timesheet
-	quitChan            chan bool
-	commandChan         chan rxgo.Item
-	timesheetStatus     int
-	runningTimesheetMtx sync.RWMutex
-	timesheetStatusMtx  sync.RWMutex
-	timesheetErrorMtx   sync.RWMutex
-	running             bool
+	log                  zerolog.Logger
+	runningTimesheet     models.Timesheet
+	timesheetBeforeError models.Timesheet
+	timesheetError       error
+	tsModel              models.Timesheet
+	quitChan             chan bool
+	reconnectChan        chan bool
+	subscribers          map[*subscription]bool
+	timesheetStatus      int
+	runningTimesheetMtx  sync.RWMutex
+	timesheetStatusMtx   sync.RWMutex
+	timesheetErrorMtx    sync.RWMutex
+	subscribersMtx       sync.Mutex
+	running              bool
+	automaticBackups     bool
 }
 
 // Service is the interface to the monitor service functions
 type Service interface {
 	Start(startChannel chan bool)
 	Stop()
+	Reconnect()
 	IsRunning() bool
-	Observable() rxgo.Observable
+	Subscribe() (rxgo.Observable, func())
 	RunningTimesheet() models.Timesheet
 	SetRunningTimesheet(timesheet models.Timesheet)
 	TimesheetStatus() int
 	SetTimesheetStatus(status int)
 	TimesheetError() error
 	SetTimesheetError(err error)
+	EnableAutomaticBackups()
 }
 
 // NewService returns an initialized Service using the supplied quit channel
@@ -57,15 +65,23 @@ func NewService(quitChannel chan bool) Service {
 	return &ServiceData{
 		log:                 logger.GetStructLogger("ServiceData"),
 		quitChan:            quitChannel,
-		commandChan:         make(chan rxgo.Item, monitorServiceCommandChanSize),
+		reconnectChan:       make(chan bool, 1),
+		subscribers:         make(map[*subscription]bool),
 		runningTimesheetMtx: sync.RWMutex{},
 		timesheetStatus:     constants.TimesheetStatusIdle,
 		timesheetStatusMtx:  sync.RWMutex{},
 		timesheetErrorMtx:   sync.RWMutex{},
+		subscribersMtx:      sync.Mutex{},
 		tsModel:             models.NewTimesheet(),
 	}
 }
 
+// EnableAutomaticBackups makes the monitor take the daily backup of the database while it is running; it must be
+// called before the monitor is started
+func (m *ServiceData) EnableAutomaticBackups() {
+	m.automaticBackups = true
+}
+
 // Start starts the monitor, optionally using a start channel
 func (m *ServiceData) Start(startChannel chan bool) {
 	log := logger.GetFuncLogger(m.log, "Start")
@@ -77,6 +93,19 @@ func (m *ServiceData) Start(startChannel chan bool) {
 	go m.actionLoop(startChannel)
 }
 
+// Reconnect makes the monitor watch the current database after it has been replaced, such as when a
+// different profile is selected. A ServiceUpdateEvent is sent once the monitor has reconnected.
+func (m *ServiceData) Reconnect() {
+	if !m.IsRunning() {
+		return
+	}
+	select {
+	case m.reconnectChan <- true:
+	default:
+		// A reconnection is already pending
+	}
+}
+
 // Stop stops the monitor
 func (m *ServiceData) Stop() {
 	if !m.IsRunning() {
@@ -90,9 +119,74 @@ func (m *ServiceData) IsRunning() bool {
 	return m.running
 }
 
-// Observable returns an RxGo Observable for the monitor's command channel
-func (m *ServiceData) Observable() rxgo.Observable {
-	return rxgo.FromEventSource(m.commandChan)
+// Subscribe returns an RxGo Observable that receives every event sent by the monitor, and a function
+// that ends the subscription and completes the Observable. The function must be called when the
+// events are no longer needed.
+func (m *ServiceData) Subscribe() (rxgo.Observable, func()) {
+	subscriber := &subscription{
+		items: make(chan rxgo.Item, subscriberChanSize),
+	}
+	m.subscribersMtx.Lock()
+	m.subscribers[subscriber] = true
+	m.subscribersMtx.Unlock()
+	unsubscribe := func() {
+		m.subscribersMtx.Lock()
+		delete(m.subscribers, subscriber)
+		m.subscribersMtx.Unlock()
+		subscriber.close()
+	}
+	return rxgo.FromEventSource(subscriber.items), unsubscribe
+}
+
+// publish sends the event to every subscriber without waiting for any of them; a subscriber that is
+// not keeping up misses the event
+func (m *ServiceData) publish(event interface{}) {
+	m.subscribersMtx.Lock()
+	subscribers := make([]*subscription, 0, len(m.subscribers))
+	for subscriber := range m.subscribers {
+		subscribers = append(subscribers, subscriber)
+	}
+	m.subscribersMtx.Unlock()
+	for _, subscriber := range subscribers {
+		if !subscriber.send(rxgo.Of(event)) {
+			log := logger.GetFuncLogger(m.log, "publish")
+			log.Warn().
+				Str("event", fmt.Sprintf("%T", event)).
+				Msg("subscriber is not keeping up; dropping event")
+		}
+	}
+}
+
+// subscription is the channel of events of one subscriber
+type subscription struct {
+	items  chan rxgo.Item
+	mtx    sync.Mutex
+	closed bool
+}
+
+// send sends the item to the subscriber without waiting; it returns false if the subscriber's channel is full
+func (s *subscription) send(item rxgo.Item) bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.closed {
+		return true
+	}
+	select {
+	case s.items <- item:
+		return true
+	default:
+		return false
+	}
+}
+
+// close closes the subscriber's channel, which completes its Observable; closing it again is harmless
+func (s *subscription) close() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.items)
+	}
 }
 
 func (m *ServiceData) TimesheetStatus() int {
@@ -147,30 +241,132 @@ func (m *ServiceData) actionLoop(startChan chan bool) {
 			Msg("startChan is non-nil; receiving from channel before starting")
 		<-startChan
 	}
+	changes, pollChan, stopWatching := m.watchDatabase()
+	defer func() {
+		stopWatching()
+	}()
+	// Webhook deliveries that failed are retried on their own schedule
+	webhookTicker := time.NewTicker(constants.ActionLoopIdleDelaySeconds * time.Second)
+	defer webhookTicker.Stop()
+	// The daily backup is taken when the monitor starts and, if it is left running, when the day changes
+	var backupChan <-chan time.Time
+	if m.automaticBackups {
+		backupTicker := time.NewTicker(constants.BackupCheckDelayMinutes * time.Minute)
+		defer backupTicker.Stop()
+		backupChan = backupTicker.C
+		backup.BackupIfDueInBackground()
+	}
 	log.Debug().
 		Msg("starting loop")
+	// Always send the initial state
+	m.updateTimesheet()
+	m.publish(ServiceUpdateEvent{})
 	for {
-		log.Trace().
-			Msg("updating timesheet")
-		m.updateTimesheet()
-		log.Trace().
-			Msg("sending UpdateEvent")
-		m.commandChan <- rxgo.Of(ServiceUpdateEvent{})
 		select {
 		case <-m.quitChan:
 			log.Debug().
 				Msg("received from quitChan; exiting loop")
 			return
-		case <-time.After(constants.ActionLoopDelaySeconds * time.Second):
+		case <-m.reconnectChan:
+			log.Debug().
+				Msg("reconnecting to the database")
+			stopWatching()
+			changes, pollChan, stopWatching = m.watchDatabase()
+			m.update()
+			m.publish(ServiceUpdateEvent{})
+			if m.automaticBackups {
+				backup.BackupIfDueInBackground()
+			}
+		case <-changes:
 			log.Trace().
-				Int("seconds", constants.ActionLoopDelaySeconds).
-				Msg("finished delay")
-			break
+				Msg("database changed")
+			m.update()
+			m.publish(DatabaseChangedEvent{})
+		case <-pollChan:
+			m.update()
+		case <-webhookTicker.C:
+			webhooks.DeliverPendingInBackground()
+		case <-backupChan:
+			backup.BackupIfDueInBackground()
+		}
+	}
+}
+
+// watchDatabase watches the database for changes made by this or any other process. If it can't be watched,
+// a channel that fires when the database should be polled is returned instead. The returned function stops
+// watching or polling.
+func (m *ServiceData) watchDatabase() (<-chan struct{}, <-chan time.Time, func()) {
+	log := logger.GetFuncLogger(m.log, "watchDatabase")
+	watcher, err := database.NewChangeWatcher(database.Get(), database.FileName())
+	if err != nil {
+		log.Err(err).
+			Int("seconds", constants.ActionLoopDelaySeconds).
+			Msg("unable to watch database for changes; polling instead")
+		ticker := time.NewTicker(constants.ActionLoopDelaySeconds * time.Second)
+		return nil, ticker.C, ticker.Stop
+	}
+	stop := func() {
+		closeErr := watcher.Close()
+		if closeErr != nil {
+			log.Err(closeErr).
+				Msg("error closing database change watcher")
 		}
 	}
+	return watcher.Changes(), nil, stop
 }
 
-func (m *ServiceData) updateTimesheet() {
+// update refreshes the running timesheet and sends an event for each change
+func (m *ServiceData) update() {
+	log := logger.GetFuncLogger(m.log, "update")
+	events := m.updateTimesheet()
+	if len(events) == 0 {
+		log.Trace().
+			Msg("running timesheet is unchanged")
+		return
+	}
+	// Deliver any webhooks for the change straight away
+	webhooks.DeliverPendingInBackground()
+	for _, event := range events {
+		event = completeEvent(event)
+		log.Trace().
+			Type("event", event).
+			Msg("sending event")
+		m.publish(event)
+	}
+}
+
+// completeEvent reloads the timesheets that stopped running so the event includes their stop time
+func completeEvent(event interface{}) interface{} {
+	switch typedEvent := event.(type) {
+	case TaskStoppedEvent:
+		typedEvent.Before = reloadTimesheet(typedEvent.Before)
+		return typedEvent
+	case TaskSwitchedEvent:
+		typedEvent.Before = reloadTimesheet(typedEvent.Before)
+		return typedEvent
+	default:
+		return event
+	}
+}
+
+// reloadTimesheet returns the latest copy of the timesheet, or the supplied copy if it cannot be loaded
+func reloadTimesheet(timesheetData models.TimesheetData) models.TimesheetData {
+	log := logger.GetFuncLogger(logger.GetPackageLogger("monitor"), "reloadTimesheet")
+	timesheet := models.NewTimesheet()
+	timesheet.Data().ID = timesheetData.ID
+	err := timesheet.Load()
+	if err != nil {
+		log.Warn().
+			Err(err).
+			Uint("id", timesheetData.ID).
+			Msg("unable to reload timesheet")
+		return timesheetData
+	}
+	return *timesheet.Data()
+}
+
+// updateTimesheet loads the running timesheet and returns the events that describe how it changed
+func (m *ServiceData) updateTimesheet() []interface{} {
 	log := logger.GetFuncLogger(m.log, "updateTimesheet")
 	// Get the running timesheet, if any
 	runningTS, err := m.tsModel.RunningTimesheet()
@@ -183,15 +379,27 @@ func (m *ServiceData) updateTimesheet() {
 		m.timesheetStatusMtx.Unlock()
 		m.timesheetErrorMtx.Unlock()
 	}()
+	before := serviceState{
+		timesheet: snapshot(m.runningTimesheet),
+		err:       m.timesheetError,
+		status:    m.timesheetStatus,
+	}
+	if before.status == constants.TimesheetStatusError {
+		before.timesheet = snapshot(m.timesheetBeforeError)
+	}
 	// Error getting the timesheet
 	if err != nil && !errors.Is(err, ttErrors.ErrNoRunningTask{}) {
 		log.Err(err).
 			Msg("unable to get running timesheet")
+		if before.status != constants.TimesheetStatusError {
+			m.timesheetBeforeError = m.runningTimesheet
+		}
 		m.runningTimesheet = nil
 		m.timesheetStatus = constants.TimesheetStatusError
 		m.timesheetError = err
-		return
+		return diffEvents(before, serviceState{err: err, status: m.timesheetStatus})
 	}
+	m.timesheetBeforeError = nil
 	// No open timesheets
 	if runningTS == nil {
 		log.Trace().
@@ -199,7 +407,7 @@ func (m *ServiceData) updateTimesheet() {
 		m.runningTimesheet = nil // Reset running timesheet
 		m.timesheetStatus = constants.TimesheetStatusIdle
 		m.timesheetError = nil
-		return
+		return diffEvents(before, serviceState{status: m.timesheetStatus})
 	}
 	// A timesheet is open
 	log.Trace().
@@ -207,4 +415,14 @@ func (m *ServiceData) updateTimesheet() {
 	m.runningTimesheet = runningTS.Data()
 	m.timesheetStatus = constants.TimesheetStatusRunning
 	m.timesheetError = nil
+	return diffEvents(before, serviceState{timesheet: snapshot(runningTS), status: m.timesheetStatus})
+}
+
+// snapshot returns a copy of the timesheet data, or nil if there is no timesheet
+func snapshot(timesheet models.Timesheet) *models.TimesheetData {
+	if timesheet == nil || timesheet.Data() == nil {
+		return nil
+	}
+	timesheetData := *timesheet.Data()
+	return &timesheetData
 }
@@ -0,0 +1,219 @@
+package profiles
+
+import (
+	"errors"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/neflyte/timetracker/lib/constants"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/spf13/viper"
+)
+
+const (
+	// DefaultProfileName is the name of the profile that uses the default database
+	DefaultProfileName = "default"
+
+	configFileName     = "profiles.yaml"
+	configFileType     = "yaml"
+	keyCurrent         = "current"
+	keyProfiles        = "profiles"
+	keyDatabase        = "database"
+	databaseFilePrefix = "timetracker-"
+	databaseFileSuffix = ".db"
+)
+
+var (
+	profileNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+	configFile       = ""
+	// configMtx serializes changes to the profiles file made by this process
+	configMtx = sync.Mutex{}
+)
+
+// Profile is a named database
+type Profile struct {
+	// Name is the name of the profile
+	Name string `json:"name"`
+	// Database is the full path and filename of the database; it is empty for the default database
+	Database string `json:"database"`
+}
+
+// IsDefault determines if the profile uses the default database
+func (p Profile) IsDefault() bool {
+	return p.Database == ""
+}
+
+// SetConfigFile overrides the location of the profiles file
+func SetConfigFile(fileName string) {
+	configMtx.Lock()
+	defer configMtx.Unlock()
+	configFile = fileName
+}
+
+// ConfigFile returns the location of the profiles file; by default it is in the user config directory
+func ConfigFile() (string, error) {
+	if configFile != "" {
+		return configFile, nil
+	}
+	configDir, err := configDirectory()
+	if err != nil {
+		return "", err
+	}
+	return path.Join(configDir, configFileName), nil
+}
+
+// NormalizeName returns the profile name in the form that it is stored
+func NormalizeName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// List returns every profile sorted by name; the default profile is always included
+func List() ([]Profile, error) {
+	config, err := readConfig()
+	if err != nil {
+		return nil, err
+	}
+	profileList := make([]Profile, 0)
+	for _, profile := range profileMap(config) {
+		profileList = append(profileList, profile)
+	}
+	sort.Slice(profileList, func(i, j int) bool {
+		return profileList[i].Name < profileList[j].Name
+	})
+	return profileList, nil
+}
+
+// Get returns the named profile
+func Get(name string) (Profile, error) {
+	config, err := readConfig()
+	if err != nil {
+		return Profile{}, err
+	}
+	name = NormalizeName(name)
+	profile, ok := profileMap(config)[name]
+	if !ok {
+		return Profile{}, tterrors.ErrProfileNotFound{Name: name}
+	}
+	return profile, nil
+}
+
+// Current returns the selected profile. If the selected profile no longer exists, the default profile is returned.
+func Current() (Profile, error) {
+	config, err := readConfig()
+	if err != nil {
+		return Profile{}, err
+	}
+	profiles := profileMap(config)
+	profile, ok := profiles[NormalizeName(config.GetString(keyCurrent))]
+	if !ok {
+		return profiles[DefaultProfileName], nil
+	}
+	return profile, nil
+}
+
+// Use selects the named profile for apps that are not started with a specific profile
+func Use(name string) error {
+	configMtx.Lock()
+	defer configMtx.Unlock()
+	config, err := readConfig()
+	if err != nil {
+		return err
+	}
+	name = NormalizeName(name)
+	if _, ok := profileMap(config)[name]; !ok {
+		return tterrors.ErrProfileNotFound{Name: name}
+	}
+	config.Set(keyCurrent, name)
+	return writeConfig(config)
+}
+
+// Create adds a new profile. If databaseFileName is empty, a database in the user config directory is used.
+func Create(name string, databaseFileName string) (Profile, error) {
+	configMtx.Lock()
+	defer configMtx.Unlock()
+	name = NormalizeName(name)
+	if !profileNameRegex.MatchString(name) {
+		return Profile{}, errors.New(tterrors.InvalidProfileNameError)
+	}
+	config, err := readConfig()
+	if err != nil {
+		return Profile{}, err
+	}
+	if _, ok := profileMap(config)[name]; ok {
+		return Profile{}, tterrors.ErrProfileExists{Name: name}
+	}
+	if databaseFileName == "" {
+		configDir, dirErr := configDirectory()
+		if dirErr != nil {
+			return Profile{}, dirErr
+		}
+		databaseFileName = path.Join(configDir, databaseFilePrefix+name+databaseFileSuffix)
+	}
+	profile := Profile{
+		Name:     name,
+		Database: databaseFileName,
+	}
+	config.Set(keyProfiles+"."+name+"."+keyDatabase, profile.Database)
+	err = writeConfig(config)
+	if err != nil {
+		return Profile{}, err
+	}
+	return profile, nil
+}
+
+// profileMap returns the profiles in the config keyed by name, including the default profile
+func profileMap(config *viper.Viper) map[string]Profile {
+	profiles := map[string]Profile{
+		DefaultProfileName: {Name: DefaultProfileName},
+	}
+	for name := range config.GetStringMap(keyProfiles) {
+		if name == DefaultProfileName {
+			continue
+		}
+		profiles[name] = Profile{
+			Name:     name,
+			Database: config.GetString(keyProfiles + "." + name + "." + keyDatabase),
+		}
+	}
+	return profiles
+}
+
+func readConfig() (*viper.Viper, error) {
+	fileName, err := ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	config := viper.New()
+	config.SetConfigFile(fileName)
+	config.SetConfigType(configFileType)
+	err = config.ReadInConfig()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	return config, nil
+}
+
+func writeConfig(config *viper.Viper) error {
+	fileName, err := ConfigFile()
+	if err != nil {
+		return err
+	}
+	return config.WriteConfigAs(fileName)
+}
+
+func configDirectory() (string, error) {
+	userConfigDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	userConfigDir = path.Join(userConfigDir, "timetracker")
+	err = os.MkdirAll(userConfigDir, constants.ConfigDirectoryMode)
+	if err != nil {
+		return "", err
+	}
+	return userConfigDir, nil
+}
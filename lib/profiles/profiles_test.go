@@ -0,0 +1,90 @@
+package profiles
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	changeWaitTimeout = 5 * time.Second
+)
+
+func useTempConfigFile(t *testing.T) string {
+	configDir := t.TempDir()
+	SetConfigFile(filepath.Join(configDir, "profiles.yaml"))
+	t.Cleanup(func() {
+		SetConfigFile("")
+	})
+	return configDir
+}
+
+func TestUnit_Profiles_DefaultOnly(t *testing.T) {
+	useTempConfigFile(t)
+	profileList, err := List()
+	require.Nil(t, err)
+	require.Equal(t, []Profile{{Name: DefaultProfileName}}, profileList)
+	current, err := Current()
+	require.Nil(t, err)
+	require.Equal(t, DefaultProfileName, current.Name)
+	require.True(t, current.IsDefault())
+}
+
+func TestUnit_Profiles_CreateAndUse(t *testing.T) {
+	configDir := useTempConfigFile(t)
+	workDatabase := filepath.Join(configDir, "work.db")
+	work, err := Create("Work", workDatabase)
+	require.Nil(t, err)
+	require.Equal(t, Profile{Name: "work", Database: workDatabase}, work)
+
+	_, err = Create("work", "")
+	require.True(t, errors.As(err, &tterrors.ErrProfileExists{}))
+	_, err = Create("not a name", "")
+	require.NotNil(t, err)
+
+	err = Use("personal")
+	require.True(t, errors.As(err, &tterrors.ErrProfileNotFound{}))
+	err = Use("WORK")
+	require.Nil(t, err)
+	current, err := Current()
+	require.Nil(t, err)
+	require.Equal(t, work, current)
+
+	profileList, err := List()
+	require.Nil(t, err)
+	require.Equal(t, []Profile{{Name: DefaultProfileName}, work}, profileList)
+}
+
+func TestUnit_Profiles_CreateInConfigDirectory(t *testing.T) {
+	useTempConfigFile(t)
+	userConfigDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", userConfigDir)
+	t.Setenv("HOME", userConfigDir)
+	t.Setenv("AppData", userConfigDir)
+	personal, err := Create("personal", "")
+	require.Nil(t, err)
+	require.Equal(t, "timetracker-personal.db", filepath.Base(personal.Database))
+}
+
+func TestUnit_Watcher_SelectedProfileChanged(t *testing.T) {
+	configDir := useTempConfigFile(t)
+	_, err := Create("client-a", filepath.Join(configDir, "client-a.db"))
+	require.Nil(t, err)
+	watcher, err := NewWatcher()
+	require.Nil(t, err)
+	defer func() {
+		require.Nil(t, watcher.Close())
+	}()
+	err = Use("client-a")
+	require.Nil(t, err)
+	select {
+	case profile := <-watcher.Changes():
+		require.Equal(t, "client-a", profile.Name)
+	case <-time.After(changeWaitTimeout):
+		t.Fatal("the selected profile change was not reported")
+	}
+}
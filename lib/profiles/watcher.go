@@ -0,0 +1,168 @@
+package profiles
+
+import (
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// changeDebounceDelay is how long to wait for writes to the profiles file to settle before reading it
+	changeDebounceDelay = 100 * time.Millisecond
+	watcherChanSize     = 1
+)
+
+// WatcherData is the main data struct of the Watcher
+type WatcherData struct {
+	log         zerolog.Logger
+	fileWatcher *fsnotify.Watcher
+	changes     chan Profile
+	quitChan    chan bool
+	current     Profile
+	fileName    string
+	profileList []Profile
+	waitGroup   sync.WaitGroup
+}
+
+// Watcher notifies when a different profile is selected or a profile is created, for example by the CLI
+type Watcher interface {
+	Changes() <-chan Profile
+	Close() error
+}
+
+// NewWatcher starts watching the profiles file for a change to the selected profile or the list of profiles
+func NewWatcher() (Watcher, error) {
+	fileName, err := ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	fileName, err = filepath.Abs(fileName)
+	if err != nil {
+		return nil, err
+	}
+	current, err := Current()
+	if err != nil {
+		return nil, err
+	}
+	profileList, err := List()
+	if err != nil {
+		return nil, err
+	}
+	fileWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	// The directory is watched since the file may not exist yet, and editors often replace it
+	err = fileWatcher.Add(filepath.Dir(fileName))
+	if err != nil {
+		_ = fileWatcher.Close()
+		return nil, err
+	}
+	w := &WatcherData{
+		log:         logger.GetStructLogger("profiles.WatcherData"),
+		fileWatcher: fileWatcher,
+		changes:     make(chan Profile, watcherChanSize),
+		quitChan:    make(chan bool, 1),
+		current:     current,
+		profileList: profileList,
+		fileName:    fileName,
+		waitGroup:   sync.WaitGroup{},
+	}
+	w.waitGroup.Add(1)
+	go w.watchLoop()
+	return w, nil
+}
+
+// Changes returns a channel that receives the selected profile after the profiles have changed
+func (w *WatcherData) Changes() <-chan Profile {
+	return w.changes
+}
+
+// Close stops watching the profiles file
+func (w *WatcherData) Close() error {
+	w.quitChan <- true
+	w.waitGroup.Wait()
+	return w.fileWatcher.Close()
+}
+
+func (w *WatcherData) watchLoop() {
+	log := logger.GetFuncLogger(w.log, "watchLoop")
+	defer w.waitGroup.Done()
+	debounceTimer := time.NewTimer(changeDebounceDelay)
+	debounceTimer.Stop()
+	for {
+		select {
+		case <-w.quitChan:
+			debounceTimer.Stop()
+			return
+		case event, ok := <-w.fileWatcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) == w.fileName {
+				debounceTimer.Reset(changeDebounceDelay)
+			}
+		case err, ok := <-w.fileWatcher.Errors:
+			if !ok {
+				return
+			}
+			log.Err(err).
+				Msg("error watching profiles file")
+		case <-debounceTimer.C:
+			w.checkProfiles()
+		}
+	}
+}
+
+// checkProfiles sends the selected profile if it, or the list of profiles, is different to the last one that was seen
+func (w *WatcherData) checkProfiles() {
+	log := logger.GetFuncLogger(w.log, "checkProfiles")
+	current, err := Current()
+	if err != nil {
+		log.Err(err).
+			Msg("error reading selected profile")
+		return
+	}
+	profileList, err := List()
+	if err != nil {
+		log.Err(err).
+			Msg("error reading profiles")
+		return
+	}
+	if current == w.current && sameProfiles(profileList, w.profileList) {
+		return
+	}
+	log.Debug().
+		Str("from", w.current.Name).
+		Str("to", current.Name).
+		Int("profiles", len(profileList)).
+		Msg("profiles changed")
+	w.current = current
+	w.profileList = profileList
+	select {
+	case w.changes <- current:
+	default:
+		// Replace the change that has not been received yet
+		select {
+		case <-w.changes:
+		default:
+		}
+		w.changes <- current
+	}
+}
+
+func sameProfiles(profileList []Profile, otherList []Profile) bool {
+	if len(profileList) != len(otherList) {
+		return false
+	}
+	for idx := range profileList {
+		if profileList[idx] != otherList[idx] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,145 @@
+package replication
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/models"
+)
+
+const (
+	// BundleVersion is the version of the format of the bundles that are written
+	BundleVersion = 1
+
+	bundlePrefix    = "timetracker-"
+	bundleExtension = ".json"
+	bundleFileMode  = 0600
+)
+
+// Bundle is the file of the changes made in one database; each database writes its own bundle to the sync folder
+// and reads the bundles of the others
+type Bundle struct {
+	// ExportedAt is the time that the bundle was written
+	ExportedAt time.Time `json:"exportedAt"`
+	// Replica is the UUID of the database that the changes were made in
+	Replica string `json:"replica"`
+	// Changes are the changes made in the database, oldest first
+	Changes []models.ChangeData `json:"changes"`
+	// Version is the version of the format of the bundle
+	Version int `json:"version"`
+}
+
+// ExportResult describes the bundle that an export wrote
+type ExportResult struct {
+	// FileName is the full path and filename of the bundle
+	FileName string
+	// Changes is the number of changes in the bundle
+	Changes int
+}
+
+// ImportResult describes what an import read and changed
+type ImportResult struct {
+	*models.SyncReport
+	// Bundles is the number of bundles of other databases that were read
+	Bundles int
+}
+
+// Export writes the bundle of the changes made in this database to the sync folder, replacing the previous one
+func Export(folder string) (ExportResult, error) {
+	log := logger.GetFuncLogger(logger.GetPackageLogger("replication"), "Export")
+	change := models.NewChange()
+	replica, err := change.ReplicaID()
+	if err != nil {
+		return ExportResult{}, err
+	}
+	changes, err := change.LocalChanges()
+	if err != nil {
+		return ExportResult{}, err
+	}
+	bundleJSON, err := json.MarshalIndent(Bundle{
+		Version:    BundleVersion,
+		Replica:    replica,
+		ExportedAt: time.Now(),
+		Changes:    changes,
+	}, "", "  ")
+	if err != nil {
+		return ExportResult{}, err
+	}
+	fileName := filepath.Join(folder, bundlePrefix+replica+bundleExtension)
+	// The bundle is replaced at once so that another machine never reads half of it
+	tempFileName := fileName + ".tmp"
+	err = os.WriteFile(tempFileName, bundleJSON, bundleFileMode)
+	if err != nil {
+		return ExportResult{}, err
+	}
+	err = os.Rename(tempFileName, fileName)
+	if err != nil {
+		return ExportResult{}, err
+	}
+	log.Debug().
+		Str("fileName", fileName).
+		Int("changes", len(changes)).
+		Msg("exported changes")
+	return ExportResult{FileName: fileName, Changes: len(changes)}, nil
+}
+
+// Import reads the bundles of the other databases in the sync folder and imports their changes
+func Import(folder string) (ImportResult, error) {
+	log := logger.GetFuncLogger(logger.GetPackageLogger("replication"), "Import")
+	change := models.NewChange()
+	replica, err := change.ReplicaID()
+	if err != nil {
+		return ImportResult{}, err
+	}
+	fileNames, err := filepath.Glob(filepath.Join(folder, bundlePrefix+"*"+bundleExtension))
+	if err != nil {
+		return ImportResult{}, err
+	}
+	result := ImportResult{}
+	changes := make([]models.ChangeData, 0)
+	for _, fileName := range fileNames {
+		bundle, readErr := readBundle(fileName)
+		if readErr != nil {
+			return ImportResult{}, readErr
+		}
+		if bundle.Replica == replica {
+			continue
+		}
+		log.Debug().
+			Str("fileName", fileName).
+			Int("changes", len(bundle.Changes)).
+			Msg("read bundle")
+		result.Bundles++
+		changes = append(changes, bundle.Changes...)
+	}
+	result.SyncReport, err = change.Import(changes)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	return result, nil
+}
+
+// readBundle reads a bundle file; a bundle of a newer version cannot be read
+func readBundle(fileName string) (Bundle, error) {
+	bundle := Bundle{}
+	bundleJSON, err := os.ReadFile(fileName)
+	if err != nil {
+		return bundle, err
+	}
+	err = json.Unmarshal(bundleJSON, &bundle)
+	if err != nil {
+		return bundle, fmt.Errorf("%s: %w", filepath.Base(fileName), err)
+	}
+	if bundle.Version > BundleVersion {
+		return bundle, fmt.Errorf("%s: bundle version %d is newer than %d; upgrade timetracker", filepath.Base(fileName), bundle.Version, BundleVersion)
+	}
+	if bundle.Replica == "" || !strings.HasPrefix(filepath.Base(fileName), bundlePrefix+bundle.Replica) {
+		return bundle, fmt.Errorf("%s: the bundle does not name its database", filepath.Base(fileName))
+	}
+	return bundle, nil
+}
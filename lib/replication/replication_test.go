@@ -0,0 +1,143 @@
+package replication
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/neflyte/timetracker/lib/database"
+	"github.com/neflyte/timetracker/lib/models"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func openTestDB(t *testing.T, fileName string) *gorm.DB {
+	db, err := database.Open(filepath.Join(t.TempDir(), fileName))
+	require.Nil(t, err)
+	err = models.Migrate(db)
+	require.Nil(t, err)
+	t.Cleanup(func() { database.Close(db) })
+	return db
+}
+
+func createTask(t *testing.T, synopsis string, description string) models.Task {
+	task := models.NewTask()
+	task.Data().Synopsis = synopsis
+	task.Data().Description = description
+	require.Nil(t, task.Create())
+	return task
+}
+
+func startTask(t *testing.T, task models.Task, startTime time.Time) {
+	timesheet := models.NewTimesheet()
+	timesheet.Data().Task = *task.Data()
+	timesheet.Data().StartTime = startTime
+	require.Nil(t, timesheet.Create())
+}
+
+// snapshot lists the tasks and timesheets of the database by UUID
+func snapshot(t *testing.T) []string {
+	tasks := make([]models.TaskData, 0)
+	require.Nil(t, database.Get().Unscoped().Find(&tasks).Error)
+	timesheets := make([]models.TimesheetData, 0)
+	require.Nil(t, database.Get().Unscoped().Preload("Task").Find(&timesheets).Error)
+	lines := make([]string, 0)
+	for _, task := range tasks {
+		lines = append(lines, fmt.Sprintf("task %s %s %s %v", task.UUID, task.Synopsis, task.Description, task.DeletedAt.Valid))
+	}
+	for _, timesheet := range timesheets {
+		lines = append(lines, fmt.Sprintf(
+			"timesheet %s %s %d %v %d",
+			timesheet.UUID, timesheet.Task.UUID, timesheet.StartTime.UnixMilli(), timesheet.StopTime.Valid, timesheet.StopTime.Time.UnixMilli(),
+		))
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+func TestUnit_Replication_Converges(t *testing.T) {
+	folder := t.TempDir()
+	laptop := openTestDB(t, "laptop.db")
+	desktop := openTestDB(t, "desktop.db")
+	start := time.Now().Add(-2 * time.Hour)
+
+	database.Set(laptop)
+	laptopReview := createTask(t, "review", "from the laptop")
+	startTask(t, laptopReview, start)
+	_, err := models.NewTask().StopRunningTask()
+	require.Nil(t, err)
+	startTask(t, laptopReview, start.Add(time.Hour))
+
+	database.Set(desktop)
+	createTask(t, "review", "from the desktop")
+	docs := createTask(t, "docs", "")
+	startTask(t, docs, start.Add(90*time.Minute))
+
+	// Two rounds so that the merges and stops made by each import reach the other database
+	var reports []ImportResult
+	for round := 0; round < 2; round++ {
+		for _, db := range []*gorm.DB{laptop, desktop} {
+			database.Set(db)
+			_, err = Export(folder)
+			require.Nil(t, err)
+		}
+		for _, db := range []*gorm.DB{laptop, desktop} {
+			database.Set(db)
+			result, importErr := Import(folder)
+			require.Nil(t, importErr)
+			require.Equal(t, 1, result.Bundles)
+			reports = append(reports, result)
+		}
+	}
+	require.NotEmpty(t, reports[0].Messages)
+
+	database.Set(laptop)
+	laptopSnapshot := snapshot(t)
+	database.Set(desktop)
+	desktopSnapshot := snapshot(t)
+	require.Equal(t, laptopSnapshot, desktopSnapshot)
+
+	// The tasks named review were merged, and only the last task started is running
+	reviews := make([]models.TaskData, 0)
+	require.Nil(t, database.Get().Where("synopsis = ?", "review").Find(&reviews).Error)
+	require.Len(t, reviews, 1)
+	running, err := models.NewTimesheet().RunningTimesheet()
+	require.Nil(t, err)
+	require.Equal(t, "docs", running.Data().Task.Synopsis)
+
+	// The last change to a field wins
+	database.Set(laptop)
+	laptopTask := models.NewTask()
+	laptopTask.Data().Synopsis = "review"
+	require.Nil(t, laptopTask.Load(false))
+	laptopTask.Data().Description = "older"
+	require.Nil(t, laptopTask.Update(false))
+	database.Set(desktop)
+	desktopTask := models.NewTask()
+	desktopTask.Data().Synopsis = "review"
+	require.Nil(t, desktopTask.Load(false))
+	desktopTask.Data().Description = "newer"
+	require.Nil(t, desktopTask.Update(false))
+	for _, db := range []*gorm.DB{laptop, desktop} {
+		database.Set(db)
+		_, err = Export(folder)
+		require.Nil(t, err)
+	}
+	for _, db := range []*gorm.DB{laptop, desktop} {
+		database.Set(db)
+		_, err = Import(folder)
+		require.Nil(t, err)
+		task := models.NewTask()
+		task.Data().Synopsis = "review"
+		require.Nil(t, task.Load(false))
+		require.Equal(t, "newer", task.Data().Description)
+	}
+
+	// Importing the same bundles again changes nothing
+	result, err := Import(folder)
+	require.Nil(t, err)
+	require.Equal(t, 0, result.New)
+	require.Empty(t, result.Messages)
+}
@@ -1,27 +1,50 @@
 package startup
 
 import (
+	"fmt"
 	"os"
 	"path"
 
+	"github.com/neflyte/timetracker/lib/backup"
+	"github.com/neflyte/timetracker/lib/config"
 	"github.com/neflyte/timetracker/lib/constants"
 	"github.com/neflyte/timetracker/lib/database"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
 	"github.com/neflyte/timetracker/lib/logger"
 	"github.com/neflyte/timetracker/lib/models"
+	"github.com/neflyte/timetracker/lib/profiles"
+	"github.com/neflyte/timetracker/lib/webhooks"
+	"gorm.io/gorm"
+)
+
+const (
+	// FlagDatabase is the name of the command-line flag that sets the database file
+	FlagDatabase = "config"
+	// FlagLogLevel is the name of the command-line flag that sets the logging level
+	FlagLogLevel = "logLevel"
+	// FlagConsole is the name of the command-line flag that logs messages to the console
+	FlagConsole = "console"
 )
 
 var (
 	startupLogger    = logger.GetPackageLogger("startup")
 	databaseFileName = constants.DefaultDatabaseFileName
+	profileName      = ""
+	activeProfile    = ""
 	logLevel         = constants.DefaultLogLevel
 	logConsole       = false
 )
 
-// SetDatabaseFileName sets the file name of the database file
+// SetDatabaseFileName sets the file name of the database file; it takes precedence over the profile
 func SetDatabaseFileName(databaseFile string) {
 	databaseFileName = databaseFile
 }
 
+// SetProfileName sets the name of the profile whose database is opened instead of the selected profile
+func SetProfileName(name string) {
+	profileName = profiles.NormalizeName(name)
+}
+
 // SetLogLevel sets the logger level
 func SetLogLevel(level string) {
 	logLevel = level
@@ -32,54 +55,193 @@ func SetConsole(logToConsole bool) {
 	logConsole = logToConsole
 }
 
+// LoadConfig reads the configuration file and uses its database and logging settings for any that were not
+// set on the command line; isFlagSet reports if the named command-line flag was set. A profile named on the
+// command line takes precedence over the database in the configuration file.
+func LoadConfig(isFlagSet func(name string) bool) {
+	err := config.Load()
+	if err != nil {
+		// The logger is not initialized yet
+		_, _ = fmt.Fprintf(os.Stderr, "%s: %s\n", tterrors.LoadConfigError, err.Error())
+	}
+	if !isFlagSet(FlagDatabase) && profileName == "" {
+		databaseFileName = config.GetString(config.KeyDatabase)
+	}
+	if !isFlagSet(FlagLogLevel) {
+		logLevel = config.GetString(config.KeyLogLevel)
+	}
+	if !isFlagSet(FlagConsole) {
+		logConsole = config.GetBool(config.KeyLogConsole)
+	}
+}
+
+// ActiveProfile returns the name of the profile whose database is open; it is empty when
+// a database file name was specified instead
+func ActiveProfile() string {
+	return activeProfile
+}
+
+// FollowsSelectedProfile determines if the app was started without a specific profile or database,
+// and so should switch databases when a different profile is selected
+func FollowsSelectedProfile() bool {
+	return databaseFileName == "" && profileName == ""
+}
+
 // InitDatabase initializes the database system
 func InitDatabase() {
 	log := logger.GetFuncLogger(startupLogger, "InitDatabase")
 	configFile := databaseFileName
 	if configFile == "" {
-		userConfigDir, err := os.UserConfigDir()
+		profile, err := resolveProfile(profileName)
 		if err != nil {
-			log.Err(err).
-				Msg("error getting user config dir")
-			userConfigDir = "."
-		} else {
-			userConfigDir = path.Join(userConfigDir, "timetracker")
-			// Make sure this directory exists...
-			mkdirErr := os.MkdirAll(userConfigDir, constants.ConfigDirectoryMode)
-			if mkdirErr != nil {
-				log.Fatal().
-					Err(mkdirErr).
-					Msg("error creating configuration directory")
-				return
-			}
+			log.Fatal().
+				Err(err).
+				Str("profile", profileName).
+				Msg("error loading profile")
+			return
+		}
+		configFile, err = profileDatabaseFileName(profile)
+		if err != nil {
+			log.Fatal().
+				Err(err).
+				Msg("error creating configuration directory")
+			return
 		}
-		configFile = path.Join(userConfigDir, constants.DefaultDatabaseFileName)
+		activeProfile = profile.Name
 	}
 	log.Debug().
 		Str("configFile", configFile).
+		Str("profile", activeProfile).
 		Msg("resolved config file")
-	db, err := database.Open(configFile)
+	db, err := openDatabase(configFile)
 	if err != nil {
 		log.Fatal().
 			Err(err).
 			Str("database", configFile).
 			Msg("error opening database")
+		return
 	}
-	log.Debug().Msg("database opened")
 	database.Set(db)
-	err = db.AutoMigrate(new(models.TaskData), new(models.TimesheetData))
+	webhooks.Init()
+}
+
+// SwitchProfile closes the open database and opens the database of the named profile
+func SwitchProfile(name string) error {
+	log := logger.GetFuncLogger(startupLogger, "SwitchProfile")
+	profile, err := profiles.Get(name)
 	if err != nil {
-		CleanupDatabase()
-		log.Fatal().
-			Err(err).
-			Msg("error auto-migrating database schema")
-		return
+		return err
+	}
+	configFile, err := profileDatabaseFileName(profile)
+	if err != nil {
+		return err
+	}
+	db, err := openDatabase(configFile)
+	if err != nil {
+		return err
+	}
+	// Let any webhook deliveries and backups in progress finish before replacing the database
+	webhooks.Wait()
+	backup.Wait()
+	previousDB := database.Get()
+	database.Set(db)
+	database.Close(previousDB)
+	databaseFileName = ""
+	profileName = ""
+	activeProfile = profile.Name
+	log.Info().
+		Str("profile", profile.Name).
+		Str("database", configFile).
+		Msg("switched profile")
+	return nil
+}
+
+// resolveProfile returns the named profile, or the selected profile if no name is supplied
+func resolveProfile(name string) (profiles.Profile, error) {
+	if name == "" {
+		return profiles.Current()
+	}
+	return profiles.Get(name)
+}
+
+// profileDatabaseFileName returns the database file of the profile; the default profile uses
+// the default database in the user config directory
+func profileDatabaseFileName(profile profiles.Profile) (string, error) {
+	log := logger.GetFuncLogger(startupLogger, "profileDatabaseFileName")
+	if !profile.IsDefault() {
+		return profile.Database, nil
+	}
+	userConfigDir, err := os.UserConfigDir()
+	if err != nil {
+		log.Err(err).
+			Msg("error getting user config dir")
+		userConfigDir = "."
+	} else {
+		userConfigDir = path.Join(userConfigDir, "timetracker")
+		// Make sure this directory exists...
+		err = os.MkdirAll(userConfigDir, constants.ConfigDirectoryMode)
+		if err != nil {
+			return "", err
+		}
+	}
+	return path.Join(userConfigDir, constants.DefaultDatabaseFileName), nil
+}
+
+// openDatabase opens the database file and migrates its schema
+func openDatabase(configFile string) (*gorm.DB, error) {
+	log := logger.GetFuncLogger(startupLogger, "openDatabase")
+	db, err := database.Open(configFile)
+	if err != nil {
+		return nil, err
+	}
+	log.Debug().Msg("database opened")
+	if config.GetBool(config.KeyBackupAutomatic) && schemaOutdated(db) {
+		// A failed backup is logged but does not keep the database from being opened
+		_, backupErr := backup.CreateAutomatic(db, backup.ReasonMigration)
+		if backupErr != nil {
+			log.Err(backupErr).
+				Msg(tterrors.AutomaticBackupError)
+		}
+	}
+	err = models.Migrate(db)
+	if err != nil {
+		database.Close(db)
+		return nil, err
 	}
 	log.Debug().Msg("schema migrated (if necessary)")
+	return db, nil
+}
+
+// schemaOutdated determines if an existing database is missing a table or column that the migration adds
+func schemaOutdated(db *gorm.DB) bool {
+	migrator := db.Migrator()
+	if !migrator.HasTable(new(models.TaskData)) {
+		// The database is new; there is nothing to back up
+		return false
+	}
+	for _, model := range models.Models() {
+		if !migrator.HasTable(model) {
+			return true
+		}
+		statement := &gorm.Statement{DB: db}
+		err := statement.Parse(model)
+		if err != nil {
+			return true
+		}
+		for _, field := range statement.Schema.Fields {
+			if field.DBName != "" && !migrator.HasColumn(model, field.DBName) {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 // CleanupDatabase tears down the database system
 func CleanupDatabase() {
+	// Let any webhook deliveries and backups in progress finish before closing the database
+	webhooks.Wait()
+	backup.Wait()
 	database.Close(database.Get())
 	database.Set(nil)
 }
@@ -25,7 +25,7 @@ func MustOpenTestDB(t *testing.T) *gorm.DB {
 	if err != nil {
 		t.Fatalf("error opening test db: %s", err)
 	}
-	err = db.AutoMigrate(new(models.TaskData), new(models.TimesheetData))
+	err = models.Migrate(db)
 	if err != nil {
 		t.Fatalf("error automigrating test db schema: %s", err)
 	}
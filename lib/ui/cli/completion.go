@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/neflyte/timetracker/lib/config"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/models"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// completionTimesheetLimit is the maximum number of timesheets that are offered as completions
+	completionTimesheetLimit = 50
+)
+
+// CompleteTasks completes the first argument with task synopses, most recently started first, followed by
+// task IDs described by their synopsis
+func CompleteTasks(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	log := logger.GetLogger("CompleteTasks")
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	tasks, err := models.NewTask().LoadAll(false)
+	if err != nil {
+		log.Err(err).
+			Msg("error loading tasks for completion")
+		return nil, cobra.ShellCompDirectiveError
+	}
+	lastStartedTasks, err := models.NewTimesheet().LastStartedTasks(uint(len(tasks)))
+	if err != nil {
+		log.Err(err).
+			Msg("error loading last started tasks for completion")
+		return nil, cobra.ShellCompDirectiveError
+	}
+	completions := make([]string, 0)
+	completed := make(map[string]bool)
+	// Tasks that have been started come first, followed by the rest in the order they were created
+	for _, task := range append(lastStartedTasks, tasks...) {
+		if task.DeletedAt.Valid || completed[task.Synopsis] || !strings.HasPrefix(task.Synopsis, toComplete) {
+			continue
+		}
+		completed[task.Synopsis] = true
+		completions = append(completions, describe(task.Synopsis, task.Description))
+	}
+	return append(completions, taskIDCompletions(tasks, toComplete)...), cobra.ShellCompDirectiveNoFileComp
+}
+
+// CompleteTaskIDs completes the first argument with task IDs described by their synopsis
+func CompleteTaskIDs(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	log := logger.GetLogger("CompleteTaskIDs")
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	tasks, err := models.NewTask().LoadAll(false)
+	if err != nil {
+		log.Err(err).
+			Msg("error loading tasks for completion")
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return taskIDCompletions(tasks, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// CompleteTimesheetIDs completes the first argument with the IDs of the most recent timesheets described by
+// their task and start time
+func CompleteTimesheetIDs(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	log := logger.GetLogger("CompleteTimesheetIDs")
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	timesheets, err := models.NewTimesheet().LoadAll(false)
+	if err != nil {
+		log.Err(err).
+			Msg("error loading timesheets for completion")
+		return nil, cobra.ShellCompDirectiveError
+	}
+	completions := make([]string, 0)
+	// Offer the latest timesheets first
+	for idx := len(timesheets) - 1; idx >= 0 && len(completions) < completionTimesheetLimit; idx-- {
+		timesheet := timesheets[idx]
+		timesheetID := strconv.Itoa(int(timesheet.ID))
+		if !strings.HasPrefix(timesheetID, toComplete) {
+			continue
+		}
+		completions = append(completions, describe(
+			timesheetID,
+			timesheet.Task.Synopsis+" "+timesheet.StartTime.Format(config.TimestampFormat()),
+		))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// CompleteDates completes a date flag with the DateKeywords
+func CompleteDates(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	completions := make([]string, 0)
+	for _, keyword := range DateKeywords {
+		if strings.HasPrefix(keyword, toComplete) {
+			completions = append(completions, describe(keyword, dateKeywordDescriptions[keyword]))
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// CompleteTaskSorts completes a sort flag with the names of the task sorts
+func CompleteTaskSorts(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	completions := make([]string, 0)
+	for _, taskSort := range models.TaskSorts {
+		if strings.HasPrefix(string(taskSort), toComplete) {
+			completions = append(completions, describe(string(taskSort), models.TaskSortNames[taskSort]))
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// taskIDCompletions returns the IDs of the tasks that start with toComplete, described by their synopsis
+func taskIDCompletions(tasks []models.TaskData, toComplete string) []string {
+	completions := make([]string, 0)
+	for _, task := range tasks {
+		taskID := strconv.Itoa(int(task.ID))
+		if strings.HasPrefix(taskID, toComplete) {
+			completions = append(completions, describe(taskID, task.Synopsis))
+		}
+	}
+	return completions
+}
+
+// describe adds a description to a completion; shells that support descriptions display it next to the completion
+func describe(completion string, description string) string {
+	if description == "" {
+		return completion
+	}
+	return completion + "\t" + strings.ReplaceAll(description, "\n", " ")
+}
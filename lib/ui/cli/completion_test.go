@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/neflyte/timetracker/lib/database"
+	"github.com/neflyte/timetracker/lib/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_CompleteTasks_LastStartedFirst(t *testing.T) {
+	db := MustOpenTestDB(t)
+	defer CloseTestDB(t, db)
+	database.Set(db)
+
+	review := models.NewTask()
+	review.Data().Synopsis = "review"
+	review.Data().Description = "Code review"
+	require.Nil(t, review.Create())
+	docs := models.NewTask()
+	docs.Data().Synopsis = "docs"
+	require.Nil(t, docs.Create())
+	timesheet := models.NewTimesheet()
+	timesheet.Data().Task = *docs.Data()
+	timesheet.Data().StartTime = time.Now()
+	require.Nil(t, timesheet.Create())
+
+	completions, _ := CompleteTasks(nil, nil, "")
+	require.Equal(t, []string{
+		"docs",
+		"review\tCode review",
+		"1\treview",
+		"2\tdocs",
+	}, completions)
+	completions, _ = CompleteTasks(nil, nil, "re")
+	require.Equal(t, []string{"review\tCode review"}, completions)
+	completions, _ = CompleteTaskIDs(nil, nil, "2")
+	require.Equal(t, []string{"2\tdocs"}, completions)
+	completions, _ = CompleteTasks(nil, []string{"docs"}, "")
+	require.Empty(t, completions)
+	completions, _ = CompleteTimesheetIDs(nil, nil, "")
+	require.Len(t, completions, 1)
+	require.True(t, strings.HasPrefix(completions[0], "1\tdocs "), completions[0])
+}
+
+func TestUnit_CompleteTaskSorts(t *testing.T) {
+	completions, _ := CompleteTaskSorts(nil, nil, "synopsis")
+	require.Equal(t, []string{"synopsis\tSynopsis A–Z", "synopsis-desc\tSynopsis Z–A"}, completions)
+	completions, _ = CompleteTaskSorts(nil, nil, "")
+	require.Len(t, completions, len(models.TaskSorts))
+}
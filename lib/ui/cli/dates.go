@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"strings"
+	"time"
+
+	"github.com/jinzhu/now"
+	"github.com/neflyte/timetracker/lib/constants"
+)
+
+const (
+	dateKeywordToday          = "today"
+	dateKeywordYesterday      = "yesterday"
+	dateKeywordWeekStart      = "week-start"
+	dateKeywordWeekEnd        = "week-end"
+	dateKeywordLastWeekStart  = "last-week-start"
+	dateKeywordLastWeekEnd    = "last-week-end"
+	dateKeywordMonthStart     = "month-start"
+	dateKeywordMonthEnd       = "month-end"
+	dateKeywordLastMonthStart = "last-month-start"
+	dateKeywordLastMonthEnd   = "last-month-end"
+)
+
+var (
+	// DateKeywords is the list of relative dates that are accepted in place of a YYYY-MM-DD date
+	DateKeywords = []string{
+		dateKeywordToday,
+		dateKeywordYesterday,
+		dateKeywordWeekStart,
+		dateKeywordWeekEnd,
+		dateKeywordLastWeekStart,
+		dateKeywordLastWeekEnd,
+		dateKeywordMonthStart,
+		dateKeywordMonthEnd,
+		dateKeywordLastMonthStart,
+		dateKeywordLastMonthEnd,
+	}
+	dateKeywordDescriptions = map[string]string{
+		dateKeywordToday:          "Today",                       // i18n
+		dateKeywordYesterday:      "Yesterday",                   // i18n
+		dateKeywordWeekStart:      "The first day of this week",  // i18n
+		dateKeywordWeekEnd:        "The last day of this week",   // i18n
+		dateKeywordLastWeekStart:  "The first day of last week",  // i18n
+		dateKeywordLastWeekEnd:    "The last day of last week",   // i18n
+		dateKeywordMonthStart:     "The first day of this month", // i18n
+		dateKeywordMonthEnd:       "The last day of this month",  // i18n
+		dateKeywordLastMonthStart: "The first day of last month", // i18n
+		dateKeywordLastMonthEnd:   "The last day of last month",  // i18n
+	}
+	// TodayPreset is the range of dates that only includes the current date
+	TodayPreset = DateRangePreset{Name: "Today", StartKeyword: dateKeywordToday, EndKeyword: dateKeywordToday} // i18n
+	// DateRangePresets is the list of commonly reported ranges of dates
+	DateRangePresets = []DateRangePreset{
+		TodayPreset,
+		{Name: "This week", StartKeyword: dateKeywordWeekStart, EndKeyword: dateKeywordWeekEnd},            // i18n
+		{Name: "Last week", StartKeyword: dateKeywordLastWeekStart, EndKeyword: dateKeywordLastWeekEnd},    // i18n
+		{Name: "This month", StartKeyword: dateKeywordMonthStart, EndKeyword: dateKeywordMonthEnd},         // i18n
+		{Name: "Last month", StartKeyword: dateKeywordLastMonthStart, EndKeyword: dateKeywordLastMonthEnd}, // i18n
+	}
+)
+
+// DateRangePreset is a named range of dates from the date of one keyword to the date of another
+type DateRangePreset struct {
+	// Name is the name of the range, such as "This week"
+	Name string
+	// StartKeyword is the keyword of the first day of the range
+	StartKeyword string
+	// EndKeyword is the keyword of the last day of the range
+	EndKeyword string
+}
+
+// Dates returns the first and last days of the range relative to the current date
+func (p DateRangePreset) Dates() (time.Time, time.Time, error) {
+	return p.datesAt(time.Now())
+}
+
+// datesAt returns the first and last days of the range relative to the specified time
+func (p DateRangePreset) datesAt(at time.Time) (time.Time, time.Time, error) {
+	startDate, err := parseDateAt(p.StartKeyword, at)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	endDate, err := parseDateAt(p.EndKeyword, at)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return startDate, endDate, nil
+}
+
+// ParseDate parses a YYYY-MM-DD date or one of the DateKeywords relative to the current date
+func ParseDate(value string) (time.Time, error) {
+	return parseDateAt(value, time.Now())
+}
+
+// parseDateAt parses a YYYY-MM-DD date or one of the DateKeywords relative to the specified time
+func parseDateAt(value string, at time.Time) (time.Time, error) {
+	today := now.With(at).BeginningOfDay()
+	lastWeek := today.AddDate(0, 0, -7)
+	lastMonth := now.With(today).BeginningOfMonth().AddDate(0, -1, 0)
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case dateKeywordToday:
+		return today, nil
+	case dateKeywordYesterday:
+		return today.AddDate(0, 0, -1), nil
+	case dateKeywordWeekStart:
+		return now.With(today).BeginningOfWeek(), nil
+	case dateKeywordWeekEnd:
+		return now.With(today).EndOfWeek(), nil
+	case dateKeywordLastWeekStart:
+		return now.With(lastWeek).BeginningOfWeek(), nil
+	case dateKeywordLastWeekEnd:
+		return now.With(lastWeek).EndOfWeek(), nil
+	case dateKeywordMonthStart:
+		return now.With(today).BeginningOfMonth(), nil
+	case dateKeywordMonthEnd:
+		return now.With(today).EndOfMonth(), nil
+	case dateKeywordLastMonthStart:
+		return lastMonth, nil
+	case dateKeywordLastMonthEnd:
+		return now.With(lastMonth).EndOfMonth(), nil
+	default:
+		return time.ParseInLocation(constants.TimestampDateLayout, value, at.Location())
+	}
+}
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jinzhu/now"
+	"github.com/neflyte/timetracker/lib/constants"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_ParseDate_Keywords(t *testing.T) {
+	weekStartDay := now.WeekStartDay
+	now.WeekStartDay = time.Monday
+	defer func() {
+		now.WeekStartDay = weekStartDay
+	}()
+	// Wednesday, March 6 2024
+	at := time.Date(2024, time.March, 6, 15, 30, 0, 0, time.Local)
+	cases := []struct {
+		want    time.Time
+		keyword string
+	}{
+		{keyword: "today", want: time.Date(2024, time.March, 6, 0, 0, 0, 0, time.Local)},
+		{keyword: "Yesterday", want: time.Date(2024, time.March, 5, 0, 0, 0, 0, time.Local)},
+		{keyword: "week-start", want: time.Date(2024, time.March, 4, 0, 0, 0, 0, time.Local)},
+		{keyword: "last-week-start", want: time.Date(2024, time.February, 26, 0, 0, 0, 0, time.Local)},
+		{keyword: "month-start", want: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.Local)},
+		{keyword: "last-month-start", want: time.Date(2024, time.February, 1, 0, 0, 0, 0, time.Local)},
+		{keyword: "2024-01-31", want: time.Date(2024, time.January, 31, 0, 0, 0, 0, time.Local)},
+	}
+	for _, tc := range cases {
+		date, err := parseDateAt(tc.keyword, at)
+		require.Nil(t, err, tc.keyword)
+		require.True(t, tc.want.Equal(date), "%s: want %s, got %s", tc.keyword, tc.want, date)
+	}
+	lastMonthEnd, err := parseDateAt("last-month-end", at)
+	require.Nil(t, err)
+	require.Equal(t, 29, lastMonthEnd.Day())
+	weekEnd, err := parseDateAt("week-end", at)
+	require.Nil(t, err)
+	require.Equal(t, time.Sunday, weekEnd.Weekday())
+	_, err = parseDateAt("someday", at)
+	require.NotNil(t, err)
+}
+
+func TestUnit_DateRangePresets(t *testing.T) {
+	weekStartDay := now.WeekStartDay
+	now.WeekStartDay = time.Monday
+	defer func() {
+		now.WeekStartDay = weekStartDay
+	}()
+	// Wednesday, March 6 2024
+	at := time.Date(2024, time.March, 6, 15, 30, 0, 0, time.Local)
+	want := map[string][2]string{
+		"Today":      {"2024-03-06", "2024-03-06"},
+		"This week":  {"2024-03-04", "2024-03-10"},
+		"Last week":  {"2024-02-26", "2024-03-03"},
+		"This month": {"2024-03-01", "2024-03-31"},
+		"Last month": {"2024-02-01", "2024-02-29"},
+	}
+	require.Len(t, DateRangePresets, len(want))
+	for _, preset := range DateRangePresets {
+		startDate, endDate, err := preset.datesAt(at)
+		require.Nil(t, err, preset.Name)
+		require.Equal(t, want[preset.Name][0], startDate.Format(constants.TimestampDateLayout), preset.Name)
+		require.Equal(t, want[preset.Name][1], endDate.Format(constants.TimestampDateLayout), preset.Name)
+	}
+}
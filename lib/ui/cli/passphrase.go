@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"golang.org/x/term"
+)
+
+const (
+	// PassphraseEnvironmentVariable is the environment variable that supplies the passphrase of a backup without a prompt
+	PassphraseEnvironmentVariable = "TIMETRACKER_BACKUP_PASSPHRASE"
+)
+
+// ReadPassphrase returns the passphrase in the environment variable, or prompts for it on the terminal; when
+// confirm is true the passphrase is entered twice
+func ReadPassphrase(confirm bool) (string, error) {
+	passphrase, ok := os.LookupEnv(PassphraseEnvironmentVariable)
+	if ok {
+		if passphrase == "" {
+			return "", errors.New(tterrors.EmptyPassphraseError)
+		}
+		return passphrase, nil
+	}
+	stdin := int(os.Stdin.Fd())
+	if !term.IsTerminal(stdin) {
+		return "", errors.New(tterrors.NoTerminalPassphraseError)
+	}
+	passphrase, err := promptPassphrase(stdin, "Passphrase: ") // i18n
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", errors.New(tterrors.EmptyPassphraseError)
+	}
+	if confirm {
+		confirmation, confirmErr := promptPassphrase(stdin, "Confirm passphrase: ") // i18n
+		if confirmErr != nil {
+			return "", confirmErr
+		}
+		if confirmation != passphrase {
+			return "", errors.New(tterrors.PassphraseMismatchError)
+		}
+	}
+	return passphrase, nil
+}
+
+// promptPassphrase prompts for a passphrase on stderr and reads it from the terminal without echoing it
+func promptPassphrase(fd int, prompt string) (string, error) {
+	_, _ = fmt.Fprint(os.Stderr, prompt)
+	passphrase, err := term.ReadPassword(fd)
+	_, _ = fmt.Fprintln(os.Stderr)
+	return string(passphrase), err
+}
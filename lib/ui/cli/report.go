@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+
+	"github.com/neflyte/timetracker/lib/config"
+	"github.com/neflyte/timetracker/lib/constants"
+	"github.com/neflyte/timetracker/lib/models"
+)
+
+const (
+	// ReportFormatCSV is the CSV serialization of a task report
+	ReportFormatCSV = "csv"
+	// ReportFormatJSON is the JSON serialization of a task report
+	ReportFormatJSON = "json"
+	// ReportFormatXML is the XML serialization of a task report
+	ReportFormatXML = "xml"
+	// ReportFormatMarkdown is a Markdown table of a task report
+	ReportFormatMarkdown = "markdown"
+	// ReportFormatHTML is an HTML document with a table of a task report
+	ReportFormatHTML = "html"
+)
+
+var (
+	// ReportFormats is the list of formats that MarshalReport supports
+	ReportFormats = []string{ReportFormatCSV, ReportFormatJSON, ReportFormatXML, ReportFormatMarkdown, ReportFormatHTML}
+
+	reportTableHeader    = []string{"Task ID", "Synopsis", "Started On", "Duration"} // i18n
+	reportCSVTableHeader = []string{"task_id", "synopsis", "started_on", "duration"}
+
+	reportHTMLTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Task Report</title>
+</head>
+<body>
+<table>
+<thead>
+<tr>{{range .Header}}<th>{{.}}</th>{{end}}</tr>
+</thead>
+<tbody>
+{{- range .Rows}}
+<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{- end}}
+</tbody>
+</table>
+</body>
+</html>
+`))
+)
+
+// MarshalReport serializes the task report in the specified format, which is one of ReportFormats
+func MarshalReport(reportData models.TaskReport, format string) ([]byte, error) {
+	switch format {
+	case ReportFormatCSV:
+		return marshalReportCSV(reportData)
+	case ReportFormatJSON:
+		jsonData := struct {
+			TaskReport []models.TaskReportData `json:"Data"`
+		}{
+			TaskReport: reportData,
+		}
+		return json.Marshal(jsonData)
+	case ReportFormatXML:
+		xmlData := struct {
+			XMLName    xml.Name                `xml:"TaskReport"`
+			TaskReport []models.TaskReportData `xml:"Data"`
+		}{
+			TaskReport: reportData,
+		}
+		return xml.Marshal(xmlData)
+	case ReportFormatMarkdown:
+		return marshalReportMarkdown(reportData), nil
+	case ReportFormatHTML:
+		return marshalReportHTML(reportData)
+	default:
+		return nil, fmt.Errorf("unsupported report format %s", format)
+	}
+}
+
+// marshalReportCSV returns the task report as CSV with a header row
+func marshalReportCSV(reportData models.TaskReport) ([]byte, error) {
+	csvData := make([][]string, 0, len(reportData)+1)
+	csvData = append(csvData, reportCSVTableHeader)
+	for idx := range reportData {
+		csvData = append(csvData, []string{
+			strconv.Itoa(int(reportData[idx].TaskID)),
+			reportData[idx].TaskSynopsis,
+			reportData[idx].StartDate.Time.Format(constants.TimestampDateLayout),
+			reportData[idx].Duration().String(),
+		})
+	}
+	var buf bytes.Buffer
+	err := csv.NewWriter(&buf).WriteAll(csvData)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// reportTableRows returns the cells of each row of a task report as they are shown in a table
+func reportTableRows(reportData models.TaskReport) [][]string {
+	rows := make([][]string, 0, len(reportData))
+	for idx := range reportData {
+		rows = append(rows, []string{
+			strconv.Itoa(int(reportData[idx].TaskID)),
+			reportData[idx].TaskSynopsis,
+			reportData[idx].StartDate.Time.Format(config.DateFormat()),
+			config.FormatDuration(reportData[idx].Duration()),
+		})
+	}
+	return rows
+}
+
+// marshalReportMarkdown returns a Markdown table of the task report
+func marshalReportMarkdown(reportData models.TaskReport) []byte {
+	escaper := strings.NewReplacer(`|`, `\|`, "\n", " ")
+	var buf bytes.Buffer
+	buf.WriteString("| " + strings.Join(reportTableHeader, " | ") + " |\n")
+	buf.WriteString(strings.Repeat("| --- ", len(reportTableHeader)) + "|\n")
+	for _, row := range reportTableRows(reportData) {
+		for idx := range row {
+			row[idx] = escaper.Replace(row[idx])
+		}
+		buf.WriteString("| " + strings.Join(row, " | ") + " |\n")
+	}
+	return buf.Bytes()
+}
+
+// marshalReportHTML returns an HTML document with a table of the task report
+func marshalReportHTML(reportData models.TaskReport) ([]byte, error) {
+	var buf bytes.Buffer
+	err := reportHTMLTemplate.Execute(&buf, struct {
+		Header []string
+		Rows   [][]string
+	}{
+		Header: reportTableHeader,
+		Rows:   reportTableRows(reportData),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/neflyte/timetracker/lib/config"
+	"github.com/neflyte/timetracker/lib/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_MarshalReport(t *testing.T) {
+	startedOn := time.Date(2023, 6, 5, 9, 0, 0, 0, time.UTC)
+	reportData := models.TaskReport{
+		{
+			TaskID:          7,
+			TaskSynopsis:    "review <a|b>",
+			StartDate:       sql.NullTime{Time: startedOn, Valid: true},
+			DurationSeconds: 5400,
+		},
+	}
+
+	jsonOut, err := MarshalReport(reportData, ReportFormatJSON)
+	require.Nil(t, err)
+	var decoded struct {
+		Data []models.TaskReportData
+	}
+	require.Nil(t, json.Unmarshal(jsonOut, &decoded))
+	require.Len(t, decoded.Data, 1)
+	require.Equal(t, uint(7), decoded.Data[0].TaskID)
+
+	xmlOut, err := MarshalReport(reportData, ReportFormatXML)
+	require.Nil(t, err)
+	require.True(t, strings.HasPrefix(string(xmlOut), "<TaskReport>"))
+	require.Contains(t, string(xmlOut), "<TaskID>7</TaskID>")
+
+	csvOut, err := MarshalReport(reportData, ReportFormatCSV)
+	require.Nil(t, err)
+	require.Equal(t, "task_id,synopsis,started_on,duration\n7,review <a|b>,2023-06-05,1h30m0s\n", string(csvOut))
+
+	markdownOut, err := MarshalReport(reportData, ReportFormatMarkdown)
+	require.Nil(t, err)
+	lines := strings.Split(strings.TrimSpace(string(markdownOut)), "\n")
+	require.Len(t, lines, 3)
+	require.Equal(t, "| Task ID | Synopsis | Started On | Duration |", lines[0])
+	require.Equal(t, "| --- | --- | --- | --- |", lines[1])
+	require.Equal(t, `| 7 | review <a\|b> | `+startedOn.Format(config.DateFormat())+" | 1h30m0s |", lines[2])
+
+	htmlOut, err := MarshalReport(reportData, ReportFormatHTML)
+	require.Nil(t, err)
+	require.Contains(t, string(htmlOut), "<th>Synopsis</th>")
+	require.Contains(t, string(htmlOut), "<td>review &lt;a|b&gt;</td>")
+
+	_, err = MarshalReport(reportData, "yaml")
+	require.NotNil(t, err)
+}
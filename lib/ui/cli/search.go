@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/neflyte/timetracker/lib/models"
+)
+
+var (
+	// highlightColor is the color of the matched terms in search results
+	highlightColor = color.New(color.FgYellow, color.Bold)
+)
+
+// Highlight colors the matched terms of a full-text search snippet and removes the highlight markers
+func Highlight(snippet string) string {
+	sb := strings.Builder{}
+	for {
+		start := strings.Index(snippet, models.SearchHighlightStart)
+		if start < 0 {
+			break
+		}
+		end := strings.Index(snippet[start:], models.SearchHighlightEnd)
+		if end < 0 {
+			break
+		}
+		end += start
+		sb.WriteString(snippet[:start])
+		sb.WriteString(highlightColor.Sprint(snippet[start+len(models.SearchHighlightStart) : end]))
+		snippet = snippet[end+len(models.SearchHighlightEnd):]
+	}
+	sb.WriteString(snippet)
+	return strings.NewReplacer(models.SearchHighlightStart, "", models.SearchHighlightEnd, "").Replace(sb.String())
+}
@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/neflyte/timetracker/lib/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_Highlight(t *testing.T) {
+	noColor := color.NoColor
+	defer func() {
+		color.NoColor = noColor
+	}()
+	snippet := "code-" + models.SearchHighlightStart + "review" + models.SearchHighlightEnd + " of the " +
+		models.SearchHighlightStart + "API" + models.SearchHighlightEnd
+
+	color.NoColor = true
+	require.Equal(t, "code-review of the API", Highlight(snippet))
+	require.Equal(t, "no matches", Highlight("no matches"))
+	// A marker without its end is removed
+	require.Equal(t, "broken", Highlight("bro"+models.SearchHighlightStart+"ken"))
+
+	color.NoColor = false
+	highlighted := Highlight(snippet)
+	require.Equal(t, "code-"+highlightColor.Sprint("review")+" of the "+highlightColor.Sprint("API"), highlighted)
+	require.NotEqual(t, "code-review of the API", highlighted)
+}
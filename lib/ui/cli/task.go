@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"errors"
+
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/models"
+	"gorm.io/gorm"
+)
+
+const (
+	// ambiguousTaskCandidates is the number of matching tasks to list when a task name is ambiguous
+	ambiguousTaskCandidates = 5
+)
+
+// ResolveTask loads the task specified by ID or synopsis. If no task has the synopsis, the name is fuzzy-matched
+// against all tasks; a single match is loaded, and more than one match returns ErrAmbiguousTask with the best
+// matches.
+func ResolveTask(arg string) (models.Task, error) {
+	log := logger.GetLogger("ResolveTask")
+	task := models.NewTask()
+	task.Data().ID, task.Data().Synopsis = task.Resolve(arg)
+	err := task.Load(false)
+	if err == nil || task.Data().ID > 0 || !errors.Is(err, gorm.ErrRecordNotFound) {
+		return task, err
+	}
+	matches, searchErr := task.FuzzySearch(arg)
+	if searchErr != nil {
+		return task, searchErr
+	}
+	log.Debug().
+		Str("name", arg).
+		Int("count", len(matches)).
+		Msg("fuzzy-matched task name")
+	switch len(matches) {
+	case 0:
+		return task, err
+	case 1:
+		return models.NewTaskWithData(matches[0]), nil
+	}
+	ambiguousErr := tterrors.ErrAmbiguousTask{
+		Name:       arg,
+		Candidates: make([]string, 0, ambiguousTaskCandidates),
+	}
+	for idx := 0; idx < len(matches) && idx < ambiguousTaskCandidates; idx++ {
+		ambiguousErr.Candidates = append(ambiguousErr.Candidates, matches[idx].String())
+	}
+	return task, ambiguousErr
+}
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/neflyte/timetracker/lib/database"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/models"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestUnit_ResolveTask(t *testing.T) {
+	db := MustOpenTestDB(t)
+	defer CloseTestDB(t, db)
+	database.Set(db)
+
+	for _, synopsis := range []string{"review", "reviews-backlog", "docs"} {
+		task := models.NewTask()
+		task.Data().Synopsis = synopsis
+		require.Nil(t, task.Create())
+	}
+
+	// An exact synopsis or ID is used as is
+	task, err := ResolveTask("review")
+	require.Nil(t, err)
+	require.Equal(t, "review", task.Data().Synopsis)
+	task, err = ResolveTask("3")
+	require.Nil(t, err)
+	require.Equal(t, "docs", task.Data().Synopsis)
+
+	// A single fuzzy match is used
+	task, err = ResolveTask("dcs")
+	require.Nil(t, err)
+	require.Equal(t, "docs", task.Data().Synopsis)
+
+	// More than one fuzzy match lists the best matches
+	_, err = ResolveTask("rvw")
+	ambiguousErr := tterrors.ErrAmbiguousTask{}
+	require.True(t, errors.As(err, &ambiguousErr))
+	require.Equal(t, []string{"review (#1)", "reviews-backlog (#2)"}, ambiguousErr.Candidates)
+
+	_, err = ResolveTask("xyz")
+	require.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+	_, err = ResolveTask("42")
+	require.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+}
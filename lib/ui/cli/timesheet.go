@@ -7,7 +7,7 @@ import (
 	"time"
 
 	"github.com/fatih/color"
-	"github.com/neflyte/timetracker/lib/constants"
+	"github.com/neflyte/timetracker/lib/config"
 	tterrors "github.com/neflyte/timetracker/lib/errors"
 	"github.com/neflyte/timetracker/lib/logger"
 	"github.com/neflyte/timetracker/lib/models"
@@ -27,8 +27,8 @@ func StopRunningTimesheet() error {
 		fmt.Println(
 			color.WhiteString("Task ID %d", stoppedTimesheet.Task.ID),
 			color.YellowString("stopped"),
-			color.WhiteString("at %s", stoppedTimesheet.StopTime.Time.Format(constants.TimestampLayout)),
-			color.BlueString(stoppedTimesheet.StopTime.Time.Sub(stoppedTimesheet.StartTime).Truncate(time.Second).String()),
+			color.WhiteString("at %s", stoppedTimesheet.StopTime.Time.Format(config.TimestampFormat())),
+			color.BlueString(config.FormatDuration(stoppedTimesheet.StopTime.Time.Sub(stoppedTimesheet.StartTime).Truncate(time.Second))),
 		)
 	}
 	return nil
@@ -53,7 +53,7 @@ func StartRunningTimesheet(task models.Task) error {
 		color.CyanString(task.Data().Synopsis),
 		color.MagentaString("(%s) ", task.Data().Description),
 		color.GreenString("started"),
-		color.WhiteString("at %s", timesheetData.StartTime.Format(constants.TimestampLayout)),
+		color.WhiteString("at %s", timesheetData.StartTime.Format(config.TimestampFormat())),
 	)
 	return nil
 }
@@ -2,13 +2,14 @@ package dialogs
 
 import (
 	"fmt"
+	"strconv"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/data/binding"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
-	"github.com/neflyte/timetracker/lib/constants"
+	"github.com/neflyte/timetracker/lib/config"
 	"github.com/neflyte/timetracker/lib/logger"
 	"github.com/neflyte/timetracker/lib/models"
 	"github.com/rs/zerolog"
@@ -34,10 +35,10 @@ type stopTaskDialogData struct {
 }
 
 // NewStopTaskDialog creates a new instance of the Stop Task dialog
-func NewStopTaskDialog(task models.TaskData, prefs fyne.Preferences, cb func(bool), parent fyne.Window) StopTaskDialog {
+func NewStopTaskDialog(task models.TaskData, cb func(bool), parent fyne.Window) StopTaskDialog {
 	newDialog := &stopTaskDialogData{
 		log:                logger.GetStructLogger("stopTaskDialogData"),
-		closeWindowBinding: binding.BindPreferenceBool(constants.PrefKeyCloseWindowStopTask, prefs),
+		closeWindowBinding: binding.NewBool(),
 		parentWindow:       &parent,
 		messageLabel:       widget.NewLabel(fmt.Sprintf("Do you want to stop task %s?", task.Synopsis)), // i18n
 		callbackFunc:       cb,
@@ -53,6 +54,11 @@ func NewStopTaskDialog(task models.TaskData, prefs fyne.Preferences, cb func(boo
 
 // Init initializes the dialog
 func (d *stopTaskDialogData) Init() error {
+	err := d.closeWindowBinding.Set(config.GetBool(config.KeyGUICloseWindowStopTask))
+	if err != nil {
+		return err
+	}
+	d.closeWindowBinding.AddListener(binding.NewDataListener(d.saveCloseWindow))
 	d.closeWindowCheckbox = widget.NewCheckWithData("Close window after stopping task", d.closeWindowBinding) // i18n
 	d.widgetContainer = container.NewVBox(
 		d.messageLabel,
@@ -69,6 +75,25 @@ func (d *stopTaskDialogData) Init() error {
 	return nil
 }
 
+// saveCloseWindow saves the state of the close window checkbox in the configuration file
+func (d *stopTaskDialogData) saveCloseWindow() {
+	log := logger.GetFuncLogger(d.log, "saveCloseWindow")
+	closeWindow, err := d.closeWindowBinding.Get()
+	if err != nil {
+		log.Err(err).
+			Msg("error getting close window checkbox state")
+		return
+	}
+	if closeWindow == config.GetBool(config.KeyGUICloseWindowStopTask) {
+		return
+	}
+	err = config.Set(config.KeyGUICloseWindowStopTask, strconv.FormatBool(closeWindow))
+	if err != nil {
+		log.Err(err).
+			Msg("error saving close window setting")
+	}
+}
+
 func (d *stopTaskDialogData) SetCloseWindowCheckbox(hidden bool) {
 	d.hideCloseWindowCheckbox = hidden
 	defer d.closeWindowCheckbox.Refresh()
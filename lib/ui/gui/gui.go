@@ -1,17 +1,32 @@
 package gui
 
 import (
+	"errors"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
+	"fyne.io/fyne/v2/theme"
+	"github.com/neflyte/timetracker/lib/config"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/ipc"
 	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/profiles"
+	"github.com/neflyte/timetracker/lib/startup"
+	"github.com/neflyte/timetracker/lib/ui/gui/widgets"
 	"github.com/neflyte/timetracker/lib/ui/gui/windows"
 	"github.com/neflyte/timetracker/lib/ui/icons"
 )
 
+const (
+	// legacyPrefKeyCloseWindowStopTask is the Fyne preference that stored the close window setting of the
+	// Stop Running Task dialog before it moved to the configuration file
+	legacyPrefKeyCloseWindowStopTask = "close-window:stop-task"
+)
+
 var (
 	// fyneApp is the main fyne app instance
 	fyneApp fyne.App
@@ -20,6 +35,8 @@ var (
 	mainWindow     windows.TimetrackerWindow
 	guiLogger      = logger.GetPackageLogger("gui")
 	guiStarted     = false
+	profileWatcher profiles.Watcher
+	configWatcher  config.Watcher
 )
 
 // StartGUI starts the GUI app
@@ -41,6 +58,12 @@ func InitGUI(appVersion string) *fyne.App {
 	// Set up fyne
 	fyneApp = app.NewWithID("cc.ethereal.timetracker")
 	fyneApp.SetIcon(icons.IconV2)
+	err := migrateLegacyPreferences()
+	if err != nil {
+		log.Err(err).
+			Msg("error migrating preferences")
+	}
+	applyTheme()
 	// Create the main timetracker window
 	mainWindow = windows.NewTimetrackerWindow(fyneApp, appVersion)
 	guiInitialized = true
@@ -62,6 +85,21 @@ func ShowTimetrackerWindowWithManageWindow() {
 	mainWindow.ShowWithManageWindow()
 }
 
+// ShowTimetrackerWindowWithTimesheetWindow shows the main timetracker window and then shows the timesheet window
+func ShowTimetrackerWindowWithTimesheetWindow() {
+	mainWindow.ShowWithTimesheetWindow()
+}
+
+// ShowTimetrackerWindowWithSettingsWindow shows the main timetracker window and then shows the settings window
+func ShowTimetrackerWindowWithSettingsWindow() {
+	mainWindow.ShowWithSettingsWindow()
+}
+
+// ShowTimetrackerWindowWithDaySummary shows the main timetracker window and then shows the report of today
+func ShowTimetrackerWindowWithDaySummary() {
+	mainWindow.ShowWithDaySummary()
+}
+
 // ShowTimetrackerWindowAndStopRunningTask shows the main timetracker window and then confirms if the running task should be stopped
 func ShowTimetrackerWindowAndStopRunningTask() {
 	mainWindow.ShowAndStopRunningTask()
@@ -72,6 +110,34 @@ func ShowTimetrackerWindowAndShowCreateAndStartDialog() {
 	mainWindow.ShowAndDisplayCreateAndStartDialog()
 }
 
+// HandleCommand performs a command that was requested on the command line or by another app
+func HandleCommand(command ipc.Command) error {
+	if !guiInitialized {
+		return errors.New("the GUI is not initialized")
+	}
+	switch command {
+	case ipc.CommandShow:
+		ShowTimetrackerWindow()
+	case ipc.CommandStopRunningTask:
+		ShowTimetrackerWindowAndStopRunningTask()
+	case ipc.CommandCreateAndStart:
+		ShowTimetrackerWindowAndShowCreateAndStartDialog()
+	case ipc.CommandManage:
+		ShowTimetrackerWindowWithManageWindow()
+	case ipc.CommandTimesheets:
+		ShowTimetrackerWindowWithTimesheetWindow()
+	case ipc.CommandSettings:
+		ShowTimetrackerWindowWithSettingsWindow()
+	case ipc.CommandSummary:
+		ShowTimetrackerWindowWithDaySummary()
+	case ipc.CommandAbout:
+		ShowTimetrackerWindowWithAbout()
+	default:
+		return errors.New(tterrors.UnknownCommandIPCError)
+	}
+	return nil
+}
+
 func guiFunc(appPtr *fyne.App) {
 	log := logger.GetFuncLogger(guiLogger, "guiFunc")
 	if appPtr != nil {
@@ -81,6 +147,11 @@ func guiFunc(appPtr *fyne.App) {
 		// Start Signal catcher
 		signalFuncQuitChan := make(chan bool, 1)
 		go signalFunc(signalFuncQuitChan, appPtr)
+		// Follow the selected profile
+		watchProfiles()
+		defer stopWatchingProfiles()
+		watchConfig()
+		defer stopWatchingConfig()
 		// Set gui started state
 		guiStarted = true
 		defer func() {
@@ -100,6 +171,116 @@ func guiFunc(appPtr *fyne.App) {
 	}
 }
 
+// watchProfiles switches to the database of the selected profile whenever another app selects a different one
+func watchProfiles() {
+	log := logger.GetFuncLogger(guiLogger, "watchProfiles")
+	watcher, err := profiles.NewWatcher()
+	if err != nil {
+		log.Err(err).
+			Msg("unable to watch for profile changes")
+		return
+	}
+	profileWatcher = watcher
+	go func() {
+		for profile := range watcher.Changes() {
+			handleProfileChange(profile)
+		}
+	}()
+}
+
+func stopWatchingProfiles() {
+	log := logger.GetFuncLogger(guiLogger, "stopWatchingProfiles")
+	if profileWatcher == nil {
+		return
+	}
+	err := profileWatcher.Close()
+	if err != nil {
+		log.Err(err).
+			Msg("error closing profile watcher")
+	}
+	profileWatcher = nil
+}
+
+func handleProfileChange(profile profiles.Profile) {
+	log := logger.GetFuncLogger(guiLogger, "handleProfileChange")
+	if !startup.FollowsSelectedProfile() || profile.Name == startup.ActiveProfile() {
+		return
+	}
+	err := startup.SwitchProfile(profile.Name)
+	if err != nil {
+		log.Err(err).
+			Str("profile", profile.Name).
+			Msg(tterrors.SwitchProfileError)
+		return
+	}
+	mainWindow.Reconnect(profile.Name)
+}
+
+// migrateLegacyPreferences copies the settings that were stored in the Fyne preferences into the configuration
+// file, and then removes the preferences so that they are only migrated once
+func migrateLegacyPreferences() error {
+	log := logger.GetFuncLogger(guiLogger, "migrateLegacyPreferences")
+	prefs := fyneApp.Preferences()
+	// A preference that is not set returns the fallback, whichever it is
+	if prefs.BoolWithFallback(legacyPrefKeyCloseWindowStopTask, false) != prefs.BoolWithFallback(legacyPrefKeyCloseWindowStopTask, true) {
+		return nil
+	}
+	if config.Source(config.KeyGUICloseWindowStopTask) == config.SourceDefault {
+		closeWindow := prefs.Bool(legacyPrefKeyCloseWindowStopTask)
+		err := config.Set(config.KeyGUICloseWindowStopTask, strconv.FormatBool(closeWindow))
+		if err != nil {
+			return err
+		}
+	}
+	prefs.RemoveValue(legacyPrefKeyCloseWindowStopTask)
+	log.Info().
+		Str("preference", legacyPrefKeyCloseWindowStopTask).
+		Msg("migrated preference")
+	return nil
+}
+
+// applyTheme sets the theme of the app to the configured one
+func applyTheme() {
+	switch config.GetString(config.KeyGUITheme) {
+	case config.ThemeLight:
+		fyneApp.Settings().SetTheme(widgets.NewVariantTheme(theme.VariantLight))
+	case config.ThemeDark:
+		fyneApp.Settings().SetTheme(widgets.NewVariantTheme(theme.VariantDark))
+	default:
+		fyneApp.Settings().SetTheme(theme.DefaultTheme())
+	}
+}
+
+// watchConfig applies the theme again whenever the settings are saved, either by the Settings window or by another app
+func watchConfig() {
+	log := logger.GetFuncLogger(guiLogger, "watchConfig")
+	watcher, err := config.NewWatcher()
+	if err != nil {
+		log.Err(err).
+			Msg("unable to watch for configuration changes")
+		return
+	}
+	configWatcher = watcher
+	go func() {
+		for range watcher.Changes() {
+			applyTheme()
+		}
+	}()
+}
+
+func stopWatchingConfig() {
+	log := logger.GetFuncLogger(guiLogger, "stopWatchingConfig")
+	if configWatcher == nil {
+		return
+	}
+	err := configWatcher.Close()
+	if err != nil {
+		log.Err(err).
+			Msg("error closing configuration watcher")
+	}
+	configWatcher = nil
+}
+
 func signalFunc(quitChan chan bool, appPtr *fyne.App) {
 	log := logger.GetFuncLogger(guiLogger, "signalFunc")
 	if appPtr != nil {
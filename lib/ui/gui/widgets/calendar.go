@@ -0,0 +1,108 @@
+package widgets
+
+import (
+	"strconv"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/jinzhu/now"
+)
+
+const (
+	// calendarMonthLayout is the layout of the month shown above the days
+	calendarMonthLayout = "January 2006"
+	// calendarDaysPerWeek is the number of columns of days
+	calendarDaysPerWeek = 7
+)
+
+var _ fyne.Widget = (*Calendar)(nil)
+
+// Calendar is a widget that shows the days of a month and calls OnSelected with the day that is tapped. The weeks
+// start on the configured first day of the week. Use NewCalendar() to create a new instance of the widget.
+type Calendar struct {
+	month time.Time
+	// selected is the day that is highlighted
+	selected time.Time
+	// OnSelected is called with the day that is tapped
+	OnSelected func(time.Time)
+	container  *fyne.Container
+	monthLabel *widget.Label
+	daysGrid   *fyne.Container
+	widget.BaseWidget
+}
+
+// NewCalendar returns a pointer to a newly initialized instance of the Calendar widget which shows the month of the
+// selected day
+func NewCalendar(selected time.Time, onSelected func(time.Time)) *Calendar {
+	c := &Calendar{
+		selected:   now.With(selected).BeginningOfDay(),
+		month:      now.With(selected).BeginningOfMonth(),
+		OnSelected: onSelected,
+	}
+	c.ExtendBaseWidget(c)
+	c.initUI()
+	return c
+}
+
+// CreateRenderer returns a new WidgetRenderer for this widget.
+func (c *Calendar) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(c.container)
+}
+
+func (c *Calendar) initUI() {
+	c.monthLabel = widget.NewLabelWithStyle("", fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
+	c.daysGrid = container.NewGridWithColumns(calendarDaysPerWeek)
+	c.container = container.NewBorder(
+		container.NewBorder(
+			nil,
+			nil,
+			widget.NewButtonWithIcon("", theme.NavigateBackIcon(), func() {
+				c.SetMonth(c.month.AddDate(0, -1, 0))
+			}),
+			widget.NewButtonWithIcon("", theme.NavigateNextIcon(), func() {
+				c.SetMonth(c.month.AddDate(0, 1, 0))
+			}),
+			c.monthLabel,
+		),
+		nil,
+		nil,
+		nil,
+		c.daysGrid,
+	)
+	c.SetMonth(c.month)
+}
+
+// SetMonth shows the days of the month of the specified date
+func (c *Calendar) SetMonth(month time.Time) {
+	c.month = now.With(month).BeginningOfMonth()
+	c.monthLabel.SetText(c.month.Format(calendarMonthLayout))
+	objects := make([]fyne.CanvasObject, 0)
+	// The names of the days of the week
+	firstDay := now.With(c.month).BeginningOfWeek()
+	for idx := 0; idx < calendarDaysPerWeek; idx++ {
+		objects = append(objects, widget.NewLabelWithStyle(firstDay.AddDate(0, 0, idx).Format("Mon")[:2], fyne.TextAlignCenter, fyne.TextStyle{}))
+	}
+	// Leave the days of the previous month empty
+	for day := firstDay; day.Before(c.month); day = day.AddDate(0, 0, 1) {
+		objects = append(objects, widget.NewLabel(""))
+	}
+	for day := c.month; day.Month() == c.month.Month(); day = day.AddDate(0, 0, 1) {
+		selectedDay := day
+		dayButton := widget.NewButton(strconv.Itoa(day.Day()), func() {
+			c.selected = selectedDay
+			c.SetMonth(c.month)
+			if c.OnSelected != nil {
+				c.OnSelected(selectedDay)
+			}
+		})
+		if day.Equal(c.selected) {
+			dayButton.Importance = widget.HighImportance
+		}
+		objects = append(objects, dayButton)
+	}
+	c.daysGrid.Objects = objects
+	c.daysGrid.Refresh()
+}
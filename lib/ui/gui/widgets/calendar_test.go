@@ -0,0 +1,39 @@
+package widgets
+
+import (
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+	"github.com/jinzhu/now"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_Calendar(t *testing.T) {
+	previousWeekStart := now.WeekStartDay
+	now.WeekStartDay = time.Monday
+	defer func() {
+		now.WeekStartDay = previousWeekStart
+	}()
+	var selected time.Time
+	calendar := NewCalendar(time.Date(2024, time.March, 6, 15, 30, 0, 0, time.Local), func(date time.Time) {
+		selected = date
+	})
+	require.Equal(t, "March 2024", calendar.monthLabel.Text)
+	// The week starts on Monday and March 1 2024 is a Friday, so four days are left empty
+	objects := calendar.daysGrid.Objects
+	require.Equal(t, "Mo", objects[0].(*widget.Label).Text)
+	require.Len(t, objects, 7+4+31)
+	sixth, ok := objects[7+4+5].(*widget.Button)
+	require.True(t, ok)
+	require.Equal(t, "6", sixth.Text)
+	require.Equal(t, widget.HighImportance, sixth.Importance)
+
+	test.Tap(objects[7+4+19].(*widget.Button))
+	require.Equal(t, time.Date(2024, time.March, 20, 0, 0, 0, 0, time.Local), selected)
+
+	calendar.SetMonth(calendar.month.AddDate(0, -1, 0))
+	require.Equal(t, "February 2024", calendar.monthLabel.Text)
+	require.Len(t, calendar.daysGrid.Objects, 7+3+29)
+}
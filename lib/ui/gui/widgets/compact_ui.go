@@ -49,12 +49,18 @@ func (c *CompactUITaskEvent) ShouldStopTask() bool {
 // CompactUIManageEvent represents an event which opens the Manage window
 type CompactUIManageEvent struct{}
 
+// CompactUITimesheetsEvent represents an event which opens the Timesheets window
+type CompactUITimesheetsEvent struct{}
+
 // CompactUIReportEvent represents an event which opens the Report window
 type CompactUIReportEvent struct{}
 
 // CompactUIQuitEvent represents an event which exits the application
 type CompactUIQuitEvent struct{}
 
+// CompactUISettingsEvent represents an event which opens the Settings window
+type CompactUISettingsEvent struct{}
+
 // CompactUIAboutEvent represents an event which opens the About window
 type CompactUIAboutEvent struct{}
 
@@ -134,9 +140,11 @@ func (c *CompactUI) initUI() {
 		),
 		c.createAndStartButton,
 		container.NewHBox(
-			widget.NewButtonWithIcon("MANAGE", theme.SettingsIcon(), c.manageButtonWasTapped),       // i18n
-			widget.NewButtonWithIcon("REPORT", theme.DocumentCreateIcon(), c.reportButtonWasTapped), // i18n
-			widget.NewButtonWithIcon("QUIT", theme.LogoutIcon(), c.quitButtonWasTapped),             // i18n
+			widget.NewButtonWithIcon("MANAGE", theme.ListIcon(), c.manageButtonWasTapped),            // i18n
+			widget.NewButtonWithIcon("TIMESHEETS", theme.HistoryIcon(), c.timesheetsButtonWasTapped), // i18n
+			widget.NewButtonWithIcon("REPORT", theme.DocumentCreateIcon(), c.reportButtonWasTapped),  // i18n
+			widget.NewButtonWithIcon("QUIT", theme.LogoutIcon(), c.quitButtonWasTapped),              // i18n
+			widget.NewButtonWithIcon("", theme.SettingsIcon(), c.settingsButtonWasTapped),
 			widget.NewButtonWithIcon("", theme.InfoIcon(), c.aboutButtonWasTapped),
 		),
 	)
@@ -399,6 +407,10 @@ func (c *CompactUI) manageButtonWasTapped() {
 	c.commandChan <- rxgo.Of(CompactUIManageEvent{})
 }
 
+func (c *CompactUI) timesheetsButtonWasTapped() {
+	c.commandChan <- rxgo.Of(CompactUITimesheetsEvent{})
+}
+
 func (c *CompactUI) reportButtonWasTapped() {
 	c.commandChan <- rxgo.Of(CompactUIReportEvent{})
 }
@@ -415,6 +427,10 @@ func (c *CompactUI) createAndStartWasTapped() {
 	c.commandChan <- rxgo.Of(CompactUICreateAndStartEvent{})
 }
 
+func (c *CompactUI) settingsButtonWasTapped() {
+	c.commandChan <- rxgo.Of(CompactUISettingsEvent{})
+}
+
 func (c *CompactUI) aboutButtonWasTapped() {
 	c.commandChan <- rxgo.Of(CompactUIAboutEvent{})
 }
@@ -0,0 +1,30 @@
+package widgets
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+var _ fyne.DoubleTappable = (*DoubleTappableLabel)(nil)
+
+// DoubleTappableLabel is a label widget which calls OnDoubleTapped when it is double-tapped
+type DoubleTappableLabel struct {
+	// OnDoubleTapped is called when the label is double-tapped
+	OnDoubleTapped func()
+	widget.Label
+}
+
+// NewDoubleTappableLabel returns a new DoubleTappableLabel widget
+func NewDoubleTappableLabel(text string) *DoubleTappableLabel {
+	newLabel := &DoubleTappableLabel{}
+	newLabel.ExtendBaseWidget(newLabel)
+	newLabel.SetText(text)
+	return newLabel
+}
+
+// DoubleTapped calls OnDoubleTapped
+func (l *DoubleTappableLabel) DoubleTapped(_ *fyne.PointEvent) {
+	if l.OnDoubleTapped != nil {
+		l.OnDoubleTapped()
+	}
+}
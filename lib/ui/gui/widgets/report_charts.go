@@ -0,0 +1,503 @@
+package widgets
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/software"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/jinzhu/now"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/models"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// barChartHeight is the height of a bar chart in pixels
+	barChartHeight = 200.0
+	// barChartMinimumSlotWidth is the minimum width in pixels of the space for each bar
+	barChartMinimumSlotWidth = 28.0
+	// barChartMaximumBarWidth is the maximum width of a bar in pixels
+	barChartMaximumBarWidth = 48.0
+	// barChartBarRatio is the share of its space that a bar is drawn in
+	barChartBarRatio = 0.7
+	// donutChartSize is the width and height of a donut chart in pixels
+	donutChartSize = 200.0
+	// donutChartHoleRatio is the size of the hole of a donut chart relative to its size
+	donutChartHoleRatio = 0.55
+	// donutChartSwatchSize is the width and height of the color swatches in the legend of a donut chart
+	donutChartSwatchSize = 12.0
+	// reportChartsDayLayout is the layout of the labels of the day and week bars
+	reportChartsDayLayout = "Jan 2"
+	// reportChartsDateKeyLayout is the layout used to group the report by day
+	reportChartsDateKeyLayout = "2006-01-02"
+)
+
+// chartSegment is a part of a bar, or a slice of a donut chart
+type chartSegment struct {
+	color    color.Color
+	label    string
+	duration time.Duration
+}
+
+// chartBar is a bar of a bar chart; its segments are stacked from the bottom
+type chartBar struct {
+	label    string
+	segments []chartSegment
+}
+
+// total returns the duration of all segments of the bar
+func (b chartBar) total() time.Duration {
+	total := time.Duration(0)
+	for _, segment := range b.segments {
+		total += segment.duration
+	}
+	return total
+}
+
+/*
+ * Main data struct
+ */
+
+var _ fyne.Widget = (*ReportCharts)(nil)
+
+// ReportCharts is a widget that draws a task report as a bar chart of the time per day, a donut chart of the time
+// per task and a stacked bar chart of the time per week. Use NewReportCharts() to create a new instance of the
+// widget.
+type ReportCharts struct {
+	startDate  time.Time
+	endDate    time.Time
+	log        zerolog.Logger
+	container  *fyne.Container
+	emptyLabel *widget.Label
+	dayChart   *barChart
+	taskChart  *donutChart
+	weekChart  *barChart
+	report     models.TaskReport
+	widget.BaseWidget
+}
+
+// NewReportCharts returns a pointer to a newly initialized instance of the ReportCharts widget
+func NewReportCharts() *ReportCharts {
+	rc := &ReportCharts{
+		log:    logger.GetStructLogger("ReportCharts"),
+		report: make(models.TaskReport, 0),
+	}
+	rc.ExtendBaseWidget(rc)
+	rc.initUI()
+	return rc
+}
+
+// CreateRenderer returns a new WidgetRenderer for this widget.
+func (r *ReportCharts) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(r.container)
+}
+
+func (r *ReportCharts) initUI() {
+	r.emptyLabel = widget.NewLabel("Run a report to draw its charts") // i18n
+	r.emptyLabel.TextStyle = fyne.TextStyle{Italic: true}
+	r.dayChart = newBarChart()
+	r.taskChart = newDonutChart()
+	r.weekChart = newBarChart()
+	r.container = container.NewVBox(
+		r.emptyLabel,
+		widget.NewLabelWithStyle("Time per day", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}), // i18n
+		r.dayChart,
+		widget.NewLabelWithStyle("Time per task", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}), // i18n
+		r.taskChart,
+		widget.NewLabelWithStyle("Time per week", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}), // i18n
+		r.weekChart,
+	)
+	r.showCharts(false)
+}
+
+/*
+ * Public functions
+ */
+
+// SetTaskReport draws the charts of the task report, which covers the days from startDate to endDate
+func (r *ReportCharts) SetTaskReport(report models.TaskReport, startDate time.Time, endDate time.Time) {
+	r.report = report
+	r.startDate = startDate
+	r.endDate = endDate
+	r.dayChart.setBars(reportDayBars(report, startDate, endDate))
+	r.taskChart.setSlices(reportTaskSlices(report))
+	r.weekChart.setBars(reportWeekBars(report, startDate, endDate))
+	r.showCharts(len(report) > 0)
+}
+
+// Image draws the charts to an image, such as for saving them as a PNG file
+func (r *ReportCharts) Image() image.Image {
+	// Draw a copy of the charts so that they are not moved out of the window
+	charts := NewReportCharts()
+	charts.SetTaskReport(r.report, r.startDate, r.endDate)
+	chartsCanvas := software.NewCanvas()
+	chartsCanvas.SetPadded(true)
+	chartsCanvas.SetContent(charts)
+	minSize := charts.MinSize()
+	chartsCanvas.Resize(fyne.NewSize(fyne.Max(r.Size().Width, minSize.Width), minSize.Height).AddWidthHeight(theme.Padding()*2, theme.Padding()*2))
+	return chartsCanvas.Capture()
+}
+
+/*
+ * Private functions
+ */
+
+// showCharts shows the charts, or a label when there is nothing to draw
+func (r *ReportCharts) showCharts(show bool) {
+	for _, object := range r.container.Objects {
+		if object == r.emptyLabel {
+			continue
+		}
+		if show {
+			object.Show()
+		} else {
+			object.Hide()
+		}
+	}
+	if show {
+		r.emptyLabel.Hide()
+	} else {
+		r.emptyLabel.Show()
+	}
+	r.container.Refresh()
+}
+
+// reportDayBars returns a bar with the total duration of each day from startDate to endDate
+func reportDayBars(report models.TaskReport, startDate time.Time, endDate time.Time) []chartBar {
+	durations := make(map[string]time.Duration)
+	for idx := range report {
+		durations[report[idx].StartDate.Time.Format(reportChartsDateKeyLayout)] += report[idx].Duration()
+	}
+	bars := make([]chartBar, 0)
+	for day := now.With(startDate).BeginningOfDay(); !day.After(endDate); day = day.AddDate(0, 0, 1) {
+		bars = append(bars, chartBar{
+			label: day.Format(reportChartsDayLayout),
+			segments: []chartSegment{
+				{
+					color:    theme.PrimaryColor(),
+					duration: durations[day.Format(reportChartsDateKeyLayout)],
+				},
+			},
+		})
+	}
+	return bars
+}
+
+// reportTaskSlices returns the total duration of each task, longest first
+func reportTaskSlices(report models.TaskReport) []chartSegment {
+	slices := make([]chartSegment, 0)
+	sliceIndexes := make(map[uint]int)
+	for idx := range report {
+		sliceIdx, ok := sliceIndexes[report[idx].TaskID]
+		if !ok {
+			sliceIdx = len(slices)
+			sliceIndexes[report[idx].TaskID] = sliceIdx
+			slices = append(slices, chartSegment{
+				color: taskColor(report[idx].TaskID),
+				label: report[idx].TaskSynopsis,
+			})
+		}
+		slices[sliceIdx].duration += report[idx].Duration()
+	}
+	sort.SliceStable(slices, func(i, j int) bool {
+		return slices[i].duration > slices[j].duration
+	})
+	return slices
+}
+
+// reportWeekBars returns a bar for each week from startDate to endDate with the duration of each task stacked
+func reportWeekBars(report models.TaskReport, startDate time.Time, endDate time.Time) []chartBar {
+	bars := make([]chartBar, 0)
+	weekIndexes := make(map[string]int)
+	for week := now.With(startDate).BeginningOfWeek(); !week.After(endDate); week = week.AddDate(0, 0, 7) {
+		weekIndexes[week.Format(reportChartsDateKeyLayout)] = len(bars)
+		bars = append(bars, chartBar{
+			label:    week.Format(reportChartsDayLayout),
+			segments: make([]chartSegment, 0),
+		})
+	}
+	// Stack the tasks in the same order in every bar
+	taskOrder := make(map[string]int)
+	for idx, slice := range reportTaskSlices(report) {
+		taskOrder[slice.label] = idx
+	}
+	for idx := range report {
+		weekIdx, ok := weekIndexes[now.With(report[idx].StartDate.Time).BeginningOfWeek().Format(reportChartsDateKeyLayout)]
+		if !ok {
+			continue
+		}
+		segments := bars[weekIdx].segments
+		found := false
+		for segmentIdx := range segments {
+			if segments[segmentIdx].label == report[idx].TaskSynopsis {
+				segments[segmentIdx].duration += report[idx].Duration()
+				found = true
+				break
+			}
+		}
+		if !found {
+			segments = append(segments, chartSegment{
+				color:    taskColor(report[idx].TaskID),
+				label:    report[idx].TaskSynopsis,
+				duration: report[idx].Duration(),
+			})
+		}
+		bars[weekIdx].segments = segments
+	}
+	for idx := range bars {
+		segments := bars[idx].segments
+		sort.SliceStable(segments, func(i, j int) bool {
+			return taskOrder[segments[i].label] < taskOrder[segments[j].label]
+		})
+	}
+	return bars
+}
+
+// chartDuration formats a duration in hours and minutes
+func chartDuration(duration time.Duration) string {
+	duration = duration.Round(time.Minute)
+	return fmt.Sprintf("%dh%02dm", int(duration.Hours()), int(duration.Minutes())%60) //nolint:gomnd
+}
+
+/*
+ * Bar chart
+ */
+
+// barChart draws bars of stacked segments with a label below and the total above each bar
+type barChart struct {
+	bars []chartBar
+	widget.BaseWidget
+}
+
+func newBarChart() *barChart {
+	chart := &barChart{
+		bars: make([]chartBar, 0),
+	}
+	chart.ExtendBaseWidget(chart)
+	return chart
+}
+
+func (c *barChart) CreateRenderer() fyne.WidgetRenderer {
+	renderer := &barChartRenderer{chart: c}
+	renderer.rebuild()
+	return renderer
+}
+
+// setBars draws the bars
+func (c *barChart) setBars(bars []chartBar) {
+	c.bars = bars
+	c.Refresh()
+}
+
+// barChartRenderer draws a barChart
+type barChartRenderer struct {
+	chart    *barChart
+	baseline *canvas.Line
+	rects    [][]*canvas.Rectangle
+	labels   []*canvas.Text
+	totals   []*canvas.Text
+	objects  []fyne.CanvasObject
+}
+
+// rebuild creates the objects of the bars
+func (r *barChartRenderer) rebuild() {
+	r.baseline = canvas.NewLine(theme.ShadowColor())
+	r.objects = []fyne.CanvasObject{r.baseline}
+	r.rects = make([][]*canvas.Rectangle, len(r.chart.bars))
+	r.labels = make([]*canvas.Text, len(r.chart.bars))
+	r.totals = make([]*canvas.Text, len(r.chart.bars))
+	for idx, bar := range r.chart.bars {
+		r.rects[idx] = make([]*canvas.Rectangle, len(bar.segments))
+		for segmentIdx, segment := range bar.segments {
+			r.rects[idx][segmentIdx] = canvas.NewRectangle(segment.color)
+			r.objects = append(r.objects, r.rects[idx][segmentIdx])
+		}
+		r.labels[idx] = canvas.NewText(bar.label, theme.ForegroundColor())
+		r.labels[idx].TextSize = theme.CaptionTextSize()
+		r.labels[idx].Alignment = fyne.TextAlignCenter
+		r.totals[idx] = canvas.NewText("", theme.ForegroundColor())
+		r.totals[idx].TextSize = theme.CaptionTextSize()
+		r.totals[idx].Alignment = fyne.TextAlignCenter
+		if bar.total() > 0 {
+			r.totals[idx].Text = chartDuration(bar.total())
+		}
+		r.objects = append(r.objects, r.labels[idx], r.totals[idx])
+	}
+}
+
+func (r *barChartRenderer) Layout(size fyne.Size) {
+	if len(r.chart.bars) == 0 {
+		return
+	}
+	textHeight := fyne.MeasureText("0", theme.CaptionTextSize(), fyne.TextStyle{}).Height
+	bottom := size.Height - textHeight
+	top := textHeight
+	r.baseline.Position1 = fyne.NewPos(0, bottom)
+	r.baseline.Position2 = fyne.NewPos(size.Width, bottom)
+	maxTotal := time.Duration(0)
+	for _, bar := range r.chart.bars {
+		if bar.total() > maxTotal {
+			maxTotal = bar.total()
+		}
+	}
+	slotWidth := size.Width / float32(len(r.chart.bars))
+	barWidth := fyne.Min(slotWidth*barChartBarRatio, barChartMaximumBarWidth)
+	// Leave out labels that would overlap
+	labelWidth := float32(0)
+	for _, label := range r.labels {
+		labelWidth = fyne.Max(labelWidth, label.MinSize().Width+theme.Padding())
+	}
+	labelEvery := int(math.Ceil(float64(labelWidth / slotWidth)))
+	for idx, bar := range r.chart.bars {
+		slotX := float32(idx) * slotWidth
+		y := bottom
+		for segmentIdx, segment := range bar.segments {
+			height := float32(0)
+			if maxTotal > 0 {
+				height = float32(float64(segment.duration) / float64(maxTotal) * float64(bottom-top))
+			}
+			y -= height
+			rect := r.rects[idx][segmentIdx]
+			rect.Move(fyne.NewPos(slotX+(slotWidth-barWidth)/2, y)) //nolint:gomnd
+			rect.Resize(fyne.NewSize(barWidth, height))
+		}
+		r.labels[idx].Move(fyne.NewPos(slotX, bottom))
+		r.labels[idx].Resize(fyne.NewSize(slotWidth, textHeight))
+		if labelEvery > 1 && idx%labelEvery != 0 {
+			r.labels[idx].Hide()
+		} else {
+			r.labels[idx].Show()
+		}
+		r.totals[idx].Move(fyne.NewPos(slotX, y-textHeight))
+		r.totals[idx].Resize(fyne.NewSize(slotWidth, textHeight))
+		if r.totals[idx].MinSize().Width > slotWidth {
+			r.totals[idx].Hide()
+		} else {
+			r.totals[idx].Show()
+		}
+	}
+}
+
+func (r *barChartRenderer) MinSize() fyne.Size {
+	return fyne.NewSize(float32(len(r.chart.bars))*barChartMinimumSlotWidth, barChartHeight)
+}
+
+func (r *barChartRenderer) Refresh() {
+	r.rebuild()
+	r.Layout(r.chart.Size())
+	canvas.Refresh(r.chart)
+}
+
+func (r *barChartRenderer) Objects() []fyne.CanvasObject {
+	return r.objects
+}
+
+func (r *barChartRenderer) Destroy() {}
+
+/*
+ * Donut chart
+ */
+
+// donutChart draws slices of a donut with a legend that lists the label, duration and share of each slice
+type donutChart struct {
+	raster    *canvas.Raster
+	legend    *fyne.Container
+	container *fyne.Container
+	slices    []chartSegment
+	widget.BaseWidget
+}
+
+func newDonutChart() *donutChart {
+	chart := &donutChart{
+		slices: make([]chartSegment, 0),
+		legend: container.NewVBox(),
+	}
+	chart.raster = canvas.NewRaster(func(w, h int) image.Image {
+		return donutImage(w, h, chart.slices)
+	})
+	chart.raster.SetMinSize(fyne.NewSize(donutChartSize, donutChartSize))
+	chart.container = container.NewHBox(chart.raster, container.NewCenter(chart.legend))
+	chart.ExtendBaseWidget(chart)
+	return chart
+}
+
+func (c *donutChart) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(c.container)
+}
+
+// setSlices draws the slices and lists them in the legend
+func (c *donutChart) setSlices(slices []chartSegment) {
+	c.slices = slices
+	total := time.Duration(0)
+	for _, slice := range slices {
+		total += slice.duration
+	}
+	rows := make([]fyne.CanvasObject, 0, len(slices))
+	for _, slice := range slices {
+		swatch := canvas.NewRectangle(slice.color)
+		swatch.SetMinSize(fyne.NewSize(donutChartSwatchSize, donutChartSwatchSize))
+		share := float64(0)
+		if total > 0 {
+			share = float64(slice.duration) / float64(total) * 100 //nolint:gomnd
+		}
+		rows = append(rows, container.NewHBox(
+			container.NewCenter(swatch),
+			widget.NewLabel(fmt.Sprintf("%s  %s (%.0f%%)", slice.label, chartDuration(slice.duration), share)),
+		))
+	}
+	c.legend.Objects = rows
+	c.legend.Refresh()
+	c.raster.Refresh()
+}
+
+// donutImage draws the slices clockwise from the top as a donut of the specified width and height
+func donutImage(w int, h int, slices []chartSegment) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	total := time.Duration(0)
+	for _, slice := range slices {
+		total += slice.duration
+	}
+	if total == 0 {
+		return img
+	}
+	centerX, centerY := float64(w)/2, float64(h)/2 //nolint:gomnd
+	outer := math.Min(centerX, centerY)
+	inner := outer * donutChartHoleRatio
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			dx, dy := float64(x)+0.5-centerX, float64(y)+0.5-centerY //nolint:gomnd
+			distance := math.Hypot(dx, dy)
+			if distance > outer || distance < inner {
+				continue
+			}
+			// The share of the circle from the top, clockwise
+			share := math.Atan2(dx, -dy) / (2 * math.Pi) //nolint:gomnd
+			if share < 0 {
+				share++
+			}
+			img.Set(x, y, donutSliceColor(slices, total, share))
+		}
+	}
+	return img
+}
+
+// donutSliceColor returns the color of the slice at the share of the circle
+func donutSliceColor(slices []chartSegment, total time.Duration, share float64) color.Color {
+	cumulative := float64(0)
+	for _, slice := range slices {
+		cumulative += float64(slice.duration) / float64(total)
+		if share < cumulative {
+			return slice.color
+		}
+	}
+	return slices[len(slices)-1].color
+}
@@ -0,0 +1,97 @@
+package widgets
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/now"
+	"github.com/neflyte/timetracker/lib/models"
+	"github.com/stretchr/testify/require"
+)
+
+func reportChartsTestData(taskID uint, synopsis string, startDate time.Time, duration time.Duration) models.TaskReportData {
+	return models.TaskReportData{
+		TaskID:          taskID,
+		TaskSynopsis:    synopsis,
+		StartDate:       sql.NullTime{Time: startDate, Valid: true},
+		DurationSeconds: int(duration.Seconds()),
+	}
+}
+
+func TestUnit_ReportCharts_Totals(t *testing.T) {
+	previousWeekStart := now.WeekStartDay
+	now.WeekStartDay = time.Monday
+	defer func() {
+		now.WeekStartDay = previousWeekStart
+	}()
+	// Sunday, Monday and the next Monday
+	sunday := time.Date(2023, 6, 4, 9, 0, 0, 0, time.Local)
+	monday := sunday.AddDate(0, 0, 1)
+	report := models.TaskReport{
+		reportChartsTestData(1, "review", sunday, time.Hour),
+		reportChartsTestData(2, "write", monday, 3*time.Hour),
+		reportChartsTestData(1, "review", monday, 30*time.Minute),
+		reportChartsTestData(1, "review", monday.AddDate(0, 0, 7), time.Hour),
+	}
+	startDate := now.With(sunday).BeginningOfDay()
+	endDate := now.With(monday.AddDate(0, 0, 7)).EndOfDay()
+
+	dayBars := reportDayBars(report, startDate, endDate)
+	require.Len(t, dayBars, 9)
+	require.Equal(t, "Jun 4", dayBars[0].label)
+	require.Equal(t, time.Hour, dayBars[0].total())
+	require.Equal(t, 3*time.Hour+30*time.Minute, dayBars[1].total())
+	require.Equal(t, time.Duration(0), dayBars[2].total())
+	require.Equal(t, time.Hour, dayBars[8].total())
+
+	slices := reportTaskSlices(report)
+	require.Len(t, slices, 2)
+	require.Equal(t, "write", slices[0].label)
+	require.Equal(t, 3*time.Hour, slices[0].duration)
+	require.Equal(t, "review", slices[1].label)
+	require.Equal(t, 2*time.Hour+30*time.Minute, slices[1].duration)
+	require.Equal(t, taskColor(1), slices[1].color)
+
+	// Sunday is in the week that starts on the Monday before it
+	weekBars := reportWeekBars(report, startDate, endDate)
+	require.Len(t, weekBars, 3)
+	require.Equal(t, "May 29", weekBars[0].label)
+	require.Equal(t, time.Hour, weekBars[0].total())
+	require.Len(t, weekBars[1].segments, 2)
+	// The longest task is stacked at the bottom of every bar
+	require.Equal(t, "write", weekBars[1].segments[0].label)
+	require.Equal(t, "review", weekBars[1].segments[1].label)
+	require.Equal(t, time.Hour, weekBars[2].total())
+	require.Equal(t, "3h30m", chartDuration(weekBars[1].total()))
+}
+
+func TestUnit_ReportCharts_Donut(t *testing.T) {
+	slices := []chartSegment{
+		{color: taskColor(1), duration: 3 * time.Hour},
+		{color: taskColor(2), duration: time.Hour},
+	}
+	img := donutImage(100, 100, slices)
+	// The first slice covers three quarters of the circle clockwise from the top
+	require.Equal(t, taskColor(1), img.At(90, 50))
+	require.Equal(t, taskColor(1), img.At(50, 90))
+	require.Equal(t, taskColor(2), img.At(10, 40))
+	// The hole and the corners are not drawn
+	_, _, _, alpha := img.At(50, 50).RGBA()
+	require.Zero(t, alpha)
+	_, _, _, alpha = img.At(0, 0).RGBA()
+	require.Zero(t, alpha)
+}
+
+func TestUnit_ReportCharts_Image(t *testing.T) {
+	day := time.Date(2023, 6, 5, 0, 0, 0, 0, time.Local)
+	charts := NewReportCharts()
+	require.True(t, charts.emptyLabel.Visible())
+	charts.SetTaskReport(models.TaskReport{
+		reportChartsTestData(1, "review", day.Add(9*time.Hour), time.Hour),
+	}, day, now.With(day).EndOfDay())
+	require.False(t, charts.emptyLabel.Visible())
+	require.True(t, charts.dayChart.Visible())
+	img := charts.Image()
+	require.GreaterOrEqual(t, float32(img.Bounds().Dy()), charts.MinSize().Height)
+}
@@ -0,0 +1,22 @@
+package widgets
+
+import "image/color"
+
+// taskPalette is the colors that tasks are drawn with in the timeline and the report charts
+var taskPalette = []color.NRGBA{
+	{R: 0x4e, G: 0x79, B: 0xa7, A: 0xff},
+	{R: 0xf2, G: 0x8e, B: 0x2b, A: 0xff},
+	{R: 0xe1, G: 0x57, B: 0x59, A: 0xff},
+	{R: 0x76, G: 0xb7, B: 0xb2, A: 0xff},
+	{R: 0x59, G: 0xa1, B: 0x4f, A: 0xff},
+	{R: 0xed, G: 0xc9, B: 0x48, A: 0xff},
+	{R: 0xb0, G: 0x7a, B: 0xa1, A: 0xff},
+	{R: 0xff, G: 0x9d, B: 0xa7, A: 0xff},
+	{R: 0x9c, G: 0x75, B: 0x5f, A: 0xff},
+	{R: 0xba, G: 0xb0, B: 0xac, A: 0xff},
+}
+
+// taskColor returns the color of the task; a task is always drawn with the same color
+func taskColor(taskID uint) color.NRGBA {
+	return taskPalette[taskID%uint(len(taskPalette))]
+}
@@ -18,6 +18,8 @@ const (
 	selectedTaskNone            = widget.ListItemID(-1)
 	taskSelectorCommandChanSize = 2
 	taskSelectorMinimumWidth    = float32(250)
+	// prefKeyTaskSelectorSort is the preferences key of the order in which the task selector lists tasks
+	prefKeyTaskSelectorSort = "task-selector-sort"
 )
 
 // TaskSelectorSelectedEvent contains the task that is sent to the command channel when a selection happens
@@ -37,6 +39,7 @@ var _ fyne.Widget = (*TaskSelector)(nil)
 // TaskSelector is the implementation of the task selector widget
 type TaskSelector struct {
 	filterBinding         binding.String
+	sortBinding           binding.String
 	filterBindingListener binding.DataListener
 	tasksListBinding      binding.UntypedList
 	container             *fyne.Container
@@ -56,6 +59,7 @@ func NewTaskSelector() *TaskSelector {
 		log:              logger.GetStructLogger("TaskSelector"),
 		tasksListBinding: binding.NewUntypedList(),
 		filterBinding:    binding.NewString(),
+		sortBinding:      bindSortPreference(),
 		selectedTask:     selectedTaskNone,
 		commandChan:      make(chan rxgo.Item, taskSelectorCommandChanSize),
 	}
@@ -78,6 +82,15 @@ func (t *TaskSelector) initUI() {
 	t.container = container.NewBorder(t.filterHBox, nil, nil, nil, t.tasksList)
 }
 
+// bindSortPreference returns a binding to the task sort in the app preferences
+func bindSortPreference() binding.String {
+	app := fyne.CurrentApp()
+	if app == nil {
+		return binding.NewString()
+	}
+	return binding.BindPreferenceString(prefKeyTaskSelectorSort, app.Preferences())
+}
+
 // Observable returns an RxGo Observable for the widget's command channel
 func (t *TaskSelector) Observable() rxgo.Observable {
 	return rxgo.FromEventSource(t.commandChan)
@@ -206,11 +219,54 @@ func (t *TaskSelector) list() models.TaskList {
 	return models.TaskListFromSliceIntf(taskListIntf)
 }
 
-// doShowSortMenu displays the task sort menu
+// doShowSortMenu displays the task sort menu below the sort button
 func (t *TaskSelector) doShowSortMenu() {
-	log := logger.GetFuncLogger(t.log, "doShowSortMenu")
-	log.Warn().
-		Msg("implementation missing")
+	currentSort := t.getSort()
+	taskSorts := append([]models.TaskSort{models.TaskSortDefault}, models.TaskSorts...)
+	menuItems := make([]*fyne.MenuItem, len(taskSorts))
+	for idx := range taskSorts {
+		taskSort := taskSorts[idx]
+		menuItems[idx] = fyne.NewMenuItem(models.TaskSortNames[taskSort], func() {
+			t.SetSort(taskSort)
+		})
+		menuItems[idx].Checked = taskSort == currentSort
+	}
+	driver := fyne.CurrentApp().Driver()
+	position := driver.AbsolutePositionForObject(t.sortButton).
+		Add(fyne.NewPos(0, t.sortButton.Size().Height))
+	widget.ShowPopUpMenuAtPosition(fyne.NewMenu("", menuItems...), driver.CanvasForObject(t.sortButton), position)
+}
+
+// SetSort sets the order of the tasks, remembers it in the app preferences, and re-filters the tasks
+func (t *TaskSelector) SetSort(taskSort models.TaskSort) {
+	log := logger.GetFuncLogger(t.log, "SetSort")
+	err := t.sortBinding.Set(string(taskSort))
+	if err != nil {
+		log.Err(err).
+			Str("sort", string(taskSort)).
+			Msg("unable to set sort binding")
+		return
+	}
+	t.FilterTasks()
+}
+
+// getSort returns the order of the tasks; an unknown order in the preferences is ignored
+func (t *TaskSelector) getSort() models.TaskSort {
+	log := logger.GetFuncLogger(t.log, "getSort")
+	sortName, err := t.sortBinding.Get()
+	if err != nil {
+		log.Err(err).
+			Msg("unable to get sort from binding")
+		return models.TaskSortDefault
+	}
+	taskSort, err := models.ParseTaskSort(sortName)
+	if err != nil {
+		log.Warn().
+			Err(err).
+			Msg("ignoring unknown sort")
+		return models.TaskSortDefault
+	}
+	return taskSort
 }
 
 // FilterTasks loads and filters tasks from the database
@@ -225,8 +281,14 @@ func (t *TaskSelector) FilterTasks() {
 	// Search (filter) tasks
 	if filterText == "" {
 		filteredTaskDatas, err = models.NewTask().LoadAll(false)
+		// Show the latest tasks first unless they are sorted
+		slices.Reverse(filteredTaskDatas)
 	} else {
-		filteredTaskDatas, err = models.NewTask().Search(filterText)
+		// Search results are ranked best match first unless they are sorted
+		filteredTaskDatas, err = models.NewTask().FuzzySearch(filterText)
+	}
+	if err == nil {
+		err = models.SortTasks(filteredTaskDatas, t.getSort())
 	}
 	if err != nil {
 		log.Err(err).
@@ -241,7 +303,6 @@ func (t *TaskSelector) FilterTasks() {
 		Msg("task filter results")
 	// Update list binding with results of search
 	taskList := models.TaskDatas(filteredTaskDatas).AsTaskList()
-	slices.Reverse(taskList)
 	err = t.tasksListBinding.Set(taskList.ToSliceIntf())
 	if err != nil {
 		log.Err(err).
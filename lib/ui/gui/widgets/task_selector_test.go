@@ -0,0 +1,53 @@
+package widgets
+
+import (
+	"testing"
+	"time"
+
+	"github.com/neflyte/timetracker/lib/database"
+	"github.com/neflyte/timetracker/lib/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_TaskSelector_SetSort(t *testing.T) {
+	db := MustOpenTestDB(t)
+	defer CloseTestDB(t, db)
+	database.Set(db)
+	defer testApp.Preferences().RemoveValue(prefKeyTaskSelectorSort)
+
+	for _, synopsis := range []string{"beta", "alpha", "gamma"} {
+		task := models.NewTask()
+		task.Data().Synopsis = synopsis
+		require.Nil(t, task.Create())
+	}
+	timesheet := models.NewTimesheet()
+	timesheet.Data().Task.ID = 3
+	timesheet.Data().StartTime = time.Now()
+	require.Nil(t, timesheet.Create())
+
+	taskSelector := NewTaskSelector()
+	taskSelector.FilterTasks()
+	// Latest first by default
+	require.Equal(t, []string{"gamma", "alpha", "beta"}, taskSelectorSynopses(taskSelector))
+
+	taskSelector.SetSort(models.TaskSortSynopsis)
+	require.Equal(t, []string{"alpha", "beta", "gamma"}, taskSelectorSynopses(taskSelector))
+	require.Equal(t, string(models.TaskSortSynopsis), testApp.Preferences().String(prefKeyTaskSelectorSort))
+
+	// The sort is remembered and applied to filtered tasks
+	taskSelector = NewTaskSelector()
+	require.Nil(t, taskSelector.filterBinding.Set("a"))
+	taskSelector.FilterTasks()
+	require.Equal(t, []string{"alpha", "beta", "gamma"}, taskSelectorSynopses(taskSelector))
+	taskSelector.SetSort(models.TaskSortRecentlyStarted)
+	require.Equal(t, []string{"gamma", "alpha", "beta"}, taskSelectorSynopses(taskSelector))
+}
+
+func taskSelectorSynopses(taskSelector *TaskSelector) []string {
+	taskList := taskSelector.list()
+	synopses := make([]string, len(taskList))
+	for idx := range taskList {
+		synopses[idx] = taskList[idx].Data().Synopsis
+	}
+	return synopses
+}
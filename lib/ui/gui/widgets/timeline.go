@@ -0,0 +1,583 @@
+package widgets
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/models"
+	"github.com/reactivex/rxgo/v2"
+	"github.com/rs/zerolog"
+)
+
+const (
+	timelineCommandChanSize = 2
+	// timelineHourHeight is the height of an hour in pixels
+	timelineHourHeight = 40.0
+	// timelineAxisWidth is the width of the hour axis in pixels
+	timelineAxisWidth = 48.0
+	// timelineHeaderHeight is the height of the day headers in pixels
+	timelineHeaderHeight = 24.0
+	// timelineMinimumColumnWidth is the minimum width of a day column in pixels
+	timelineMinimumColumnWidth = 80.0
+	// timelineResizeHandleHeight is the height in pixels of the edges of a block that are dragged to resize it
+	timelineResizeHandleHeight = 8.0
+	// timelineBlockPadding is the space in pixels between a block and the edges of its column
+	timelineBlockPadding = 2.0
+	// timelineSnap is the duration that dragged times snap to
+	timelineSnap = 5 * time.Minute
+	// timelineMinimumGap is the shortest gap between two timesheets that is shown
+	timelineMinimumGap = 5 * time.Minute
+	// timelineTimeLayout is the layout of the times shown in a block
+	timelineTimeLayout = "15:04"
+	// timelineDayLayout is the layout of the day headers
+	timelineDayLayout = "Mon 2"
+)
+
+const (
+	timelineDragNone = iota
+	timelineDragMove
+	timelineDragStart
+	timelineDragStop
+)
+
+var (
+	timelineBlockTextColor = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	timelineGapFillColor   = color.NRGBA{R: 0x80, G: 0x80, B: 0x80, A: 0x20}
+)
+
+/*
+ * Event data structs
+ */
+
+// TimelineBlockChangedEvent represents an event which changes the start and stop times of a timesheet
+type TimelineBlockChangedEvent struct {
+	// Timesheet is the timesheet with its new start and stop times
+	Timesheet models.TimesheetData
+}
+
+// TimelineGapTappedEvent represents an event which fills a gap between two timesheets
+type TimelineGapTappedEvent struct {
+	// Start is the time that the gap starts at
+	Start time.Time
+	// Stop is the time that the gap stops at
+	Stop time.Time
+}
+
+/*
+ * Main data struct
+ */
+
+var _ fyne.Widget = (*Timeline)(nil)
+
+// Timeline is a widget that draws the timesheets of one or more days as blocks on an hour axis, with a column for
+// each day. Blocks are dragged to change their start and stop times, and the gaps between them are tapped to fill
+// them. Use NewTimeline() to create a new instance of the widget.
+type Timeline struct {
+	start       time.Time
+	currentTime time.Time
+	log         zerolog.Logger
+	commandChan chan rxgo.Item
+	timesheets  []models.TimesheetData
+	widget.BaseWidget
+	days int
+}
+
+// timelineSegment is the part of a timesheet that is on a single day
+type timelineSegment struct {
+	start     time.Time
+	stop      time.Time
+	timesheet models.TimesheetData
+	day       int
+	// clippedStart is set when the timesheet starts before the day
+	clippedStart bool
+	// clippedStop is set when the timesheet stops after the day
+	clippedStop bool
+	// running is set when the timesheet has not stopped yet
+	running bool
+}
+
+// timelineGap is the time between two timesheets on a single day
+type timelineGap struct {
+	start time.Time
+	stop  time.Time
+	day   int
+}
+
+// NewTimeline returns a pointer to a newly initialized instance of the Timeline widget
+func NewTimeline() *Timeline {
+	tl := &Timeline{
+		log:         logger.GetStructLogger("Timeline"),
+		commandChan: make(chan rxgo.Item, timelineCommandChanSize),
+		timesheets:  make([]models.TimesheetData, 0),
+		days:        1,
+	}
+	tl.ExtendBaseWidget(tl)
+	return tl
+}
+
+// CreateRenderer returns a new WidgetRenderer for this widget.
+func (t *Timeline) CreateRenderer() fyne.WidgetRenderer {
+	renderer := &timelineRenderer{
+		timeline: t,
+	}
+	renderer.rebuild()
+	return renderer
+}
+
+/*
+ * Public functions
+ */
+
+// Observable returns an RxGo Observable for the widget's command channel
+func (t *Timeline) Observable() rxgo.Observable {
+	return rxgo.FromEventSource(t.commandChan)
+}
+
+// SetTimesheets draws the timesheets on the specified number of days from the start day. Running timesheets are
+// drawn until currentTime.
+func (t *Timeline) SetTimesheets(start time.Time, days int, timesheets []models.TimesheetData, currentTime time.Time) {
+	t.start = start
+	t.days = days
+	t.timesheets = timesheets
+	t.currentTime = currentTime
+	t.Refresh()
+}
+
+// SetCurrentTime moves the end of the running timesheet to currentTime
+func (t *Timeline) SetCurrentTime(currentTime time.Time) {
+	t.currentTime = currentTime
+	t.Refresh()
+}
+
+// OffsetOf returns the vertical position of the specified time of day, such as for scrolling to it
+func (t *Timeline) OffsetOf(hours int) float32 {
+	return timelineHeaderHeight + float32(hours)*timelineHourHeight
+}
+
+/*
+ * Private functions
+ */
+
+// dayStart returns the start of the day in the specified column
+func (t *Timeline) dayStart(day int) time.Time {
+	return t.start.AddDate(0, 0, day)
+}
+
+// columnWidth returns the width of a day column when the widget has the specified width
+func (t *Timeline) columnWidth(width float32) float32 {
+	return (width - timelineAxisWidth) / float32(t.days)
+}
+
+// yOf returns the vertical position of the time in the specified column
+func (t *Timeline) yOf(day int, timestamp time.Time) float32 {
+	return timelineHeaderHeight + float32(timestamp.Sub(t.dayStart(day)).Hours())*timelineHourHeight
+}
+
+// blockGeometry returns the position and size of a block from start to stop in the specified column
+func (t *Timeline) blockGeometry(day int, start time.Time, stop time.Time) (fyne.Position, fyne.Size) {
+	columnWidth := t.columnWidth(t.Size().Width)
+	top := t.yOf(day, start)
+	return fyne.NewPos(timelineAxisWidth+float32(day)*columnWidth+timelineBlockPadding, top),
+		fyne.NewSize(columnWidth-2*timelineBlockPadding, t.yOf(day, stop)-top)
+}
+
+// timelineSegments splits the timesheets into the parts that are on each of the days from the start day
+func timelineSegments(start time.Time, days int, timesheets []models.TimesheetData, currentTime time.Time) []timelineSegment {
+	segments := make([]timelineSegment, 0, len(timesheets))
+	for _, timesheet := range timesheets {
+		stop := currentTime
+		if timesheet.StopTime.Valid {
+			stop = timesheet.StopTime.Time
+		}
+		for day := 0; day < days; day++ {
+			dayStart := start.AddDate(0, 0, day)
+			dayEnd := start.AddDate(0, 0, day+1)
+			segment := timelineSegment{
+				timesheet:    timesheet,
+				day:          day,
+				start:        timesheet.StartTime,
+				stop:         stop,
+				clippedStart: timesheet.StartTime.Before(dayStart),
+				clippedStop:  stop.After(dayEnd),
+				running:      !timesheet.StopTime.Valid,
+			}
+			if segment.clippedStart {
+				segment.start = dayStart
+			}
+			if segment.clippedStop {
+				segment.stop = dayEnd
+			}
+			if segment.stop.After(segment.start) {
+				segments = append(segments, segment)
+			}
+		}
+	}
+	sort.SliceStable(segments, func(i, j int) bool {
+		if segments[i].day != segments[j].day {
+			return segments[i].day < segments[j].day
+		}
+		return segments[i].start.Before(segments[j].start)
+	})
+	return segments
+}
+
+// timelineGaps returns the gaps between the segments of each day; segments must be sorted by day and start time
+func timelineGaps(segments []timelineSegment) []timelineGap {
+	gaps := make([]timelineGap, 0)
+	for idx := 1; idx < len(segments); idx++ {
+		if segments[idx-1].day != segments[idx].day {
+			continue
+		}
+		// A segment may be covered by an earlier, longer segment, so the gap starts at the latest stop
+		latestStop := segments[idx-1].stop
+		for previous := idx - 2; previous >= 0 && segments[previous].day == segments[idx].day; previous-- {
+			if segments[previous].stop.After(latestStop) {
+				latestStop = segments[previous].stop
+			}
+		}
+		if segments[idx].start.Sub(latestStop) >= timelineMinimumGap {
+			gaps = append(gaps, timelineGap{start: latestStop, stop: segments[idx].start, day: segments[idx].day})
+		}
+	}
+	return gaps
+}
+
+// dragMode returns how a drag that starts at the vertical position within the block changes the segment
+func (s *timelineSegment) dragMode(y float32, height float32) int {
+	switch {
+	case y <= timelineResizeHandleHeight && !s.clippedStart:
+		return timelineDragStart
+	case y >= height-timelineResizeHandleHeight && !s.clippedStop && !s.running:
+		return timelineDragStop
+	case !s.clippedStart && !s.clippedStop && !s.running:
+		return timelineDragMove
+	default:
+		return timelineDragNone
+	}
+}
+
+// dragged returns the start and stop times of the segment after it is dragged by the offset, snapped to
+// timelineSnap. A segment is never resized to less than timelineSnap.
+func (s *timelineSegment) dragged(mode int, offset time.Duration) (time.Time, time.Time) {
+	offset = offset.Round(timelineSnap)
+	start, stop := s.start, s.stop
+	switch mode {
+	case timelineDragMove:
+		start, stop = start.Add(offset), stop.Add(offset)
+	case timelineDragStart:
+		start = start.Add(offset)
+		if stop.Sub(start) < timelineSnap {
+			start = stop.Add(-timelineSnap)
+		}
+	case timelineDragStop:
+		stop = stop.Add(offset)
+		if stop.Sub(start) < timelineSnap {
+			stop = start.Add(timelineSnap)
+		}
+	}
+	return start, stop
+}
+
+// draggedTimesheet returns the timesheet of the segment with the times that the drag changed, and whether they
+// changed. Only the times that the drag mode changes are written, so that a timesheet that crosses midnight keeps
+// its start or stop on the other day.
+func (s *timelineSegment) draggedTimesheet(mode int, offset time.Duration) (models.TimesheetData, bool) {
+	timesheet := s.timesheet
+	start, stop := s.dragged(mode, offset)
+	if start.Equal(s.start) && stop.Equal(s.stop) {
+		return timesheet, false
+	}
+	if mode == timelineDragMove || mode == timelineDragStart {
+		timesheet.StartTime = start
+	}
+	if (mode == timelineDragMove || mode == timelineDragStop) && !s.running {
+		timesheet.StopTime.Time = stop
+	}
+	return timesheet, true
+}
+
+/*
+ * Renderer
+ */
+
+// timelineRenderer draws the hour axis, the day headers, the blocks and the gaps of a Timeline
+type timelineRenderer struct {
+	timeline   *Timeline
+	nowLine    *canvas.Line
+	objects    []fyne.CanvasObject
+	hourLines  []*canvas.Line
+	hourLabels []*canvas.Text
+	dayLabels  []*canvas.Text
+	dayLines   []*canvas.Line
+	blocks     []*timelineBlock
+	gaps       []*timelineGapArea
+}
+
+// rebuild creates the objects that draw the timeline
+func (r *timelineRenderer) rebuild() {
+	t := r.timeline
+	r.objects = make([]fyne.CanvasObject, 0)
+	r.hourLines = make([]*canvas.Line, 0, 24)  //nolint:gomnd
+	r.hourLabels = make([]*canvas.Text, 0, 24) //nolint:gomnd
+	for hour := 0; hour < 24; hour++ {
+		line := canvas.NewLine(theme.SeparatorColor())
+		label := canvas.NewText(fmt.Sprintf("%02d:00", hour), theme.PlaceHolderColor())
+		label.TextSize = theme.CaptionTextSize()
+		r.hourLines = append(r.hourLines, line)
+		r.hourLabels = append(r.hourLabels, label)
+		r.objects = append(r.objects, line, label)
+	}
+	r.dayLabels = make([]*canvas.Text, 0, t.days)
+	r.dayLines = make([]*canvas.Line, 0, t.days)
+	for day := 0; day < t.days; day++ {
+		label := canvas.NewText(t.dayStart(day).Format(timelineDayLayout), theme.ForegroundColor())
+		label.TextStyle = fyne.TextStyle{Bold: true}
+		label.Alignment = fyne.TextAlignCenter
+		line := canvas.NewLine(theme.SeparatorColor())
+		r.dayLabels = append(r.dayLabels, label)
+		r.dayLines = append(r.dayLines, line)
+		r.objects = append(r.objects, label, line)
+	}
+	segments := timelineSegments(t.start, t.days, t.timesheets, t.currentTime)
+	r.gaps = make([]*timelineGapArea, 0)
+	for _, gap := range timelineGaps(segments) {
+		gapArea := newTimelineGapArea(t, gap)
+		r.gaps = append(r.gaps, gapArea)
+		r.objects = append(r.objects, gapArea)
+	}
+	r.blocks = make([]*timelineBlock, 0, len(segments))
+	for _, segment := range segments {
+		block := newTimelineBlock(t, segment)
+		r.blocks = append(r.blocks, block)
+		r.objects = append(r.objects, block)
+	}
+	r.nowLine = canvas.NewLine(theme.ErrorColor())
+	r.nowLine.StrokeWidth = 2
+	r.objects = append(r.objects, r.nowLine)
+}
+
+func (r *timelineRenderer) Layout(size fyne.Size) {
+	t := r.timeline
+	columnWidth := t.columnWidth(size.Width)
+	for hour, line := range r.hourLines {
+		y := t.OffsetOf(hour)
+		line.Position1 = fyne.NewPos(timelineAxisWidth, y)
+		line.Position2 = fyne.NewPos(size.Width, y)
+		r.hourLabels[hour].Move(fyne.NewPos(theme.Padding(), y))
+	}
+	for day, label := range r.dayLabels {
+		x := timelineAxisWidth + float32(day)*columnWidth
+		label.Move(fyne.NewPos(x, 0))
+		label.Resize(fyne.NewSize(columnWidth, timelineHeaderHeight))
+		r.dayLines[day].Position1 = fyne.NewPos(x, 0)
+		r.dayLines[day].Position2 = fyne.NewPos(x, size.Height)
+	}
+	for _, gapArea := range r.gaps {
+		pos, gapSize := t.blockGeometry(gapArea.gap.day, gapArea.gap.start, gapArea.gap.stop)
+		gapArea.Move(pos)
+		gapArea.Resize(gapSize)
+	}
+	for _, block := range r.blocks {
+		block.layout()
+	}
+	r.nowLine.Hide()
+	for day := 0; day < t.days; day++ {
+		if !t.currentTime.Before(t.dayStart(day)) && t.currentTime.Before(t.dayStart(day+1)) {
+			y := t.yOf(day, t.currentTime)
+			x := timelineAxisWidth + float32(day)*columnWidth
+			r.nowLine.Position1 = fyne.NewPos(x, y)
+			r.nowLine.Position2 = fyne.NewPos(x+columnWidth, y)
+			r.nowLine.Show()
+		}
+	}
+}
+
+func (r *timelineRenderer) MinSize() fyne.Size {
+	return fyne.NewSize(
+		timelineAxisWidth+float32(r.timeline.days)*timelineMinimumColumnWidth,
+		r.timeline.OffsetOf(24), //nolint:gomnd
+	)
+}
+
+func (r *timelineRenderer) Refresh() {
+	r.rebuild()
+	r.Layout(r.timeline.Size())
+	canvas.Refresh(r.timeline)
+}
+
+func (r *timelineRenderer) Objects() []fyne.CanvasObject {
+	return r.objects
+}
+
+func (r *timelineRenderer) Destroy() {}
+
+/*
+ * Blocks
+ */
+
+var _ fyne.Draggable = (*timelineBlock)(nil)
+
+// timelineBlock draws a segment of a timesheet and changes its start and stop times when it is dragged
+type timelineBlock struct {
+	dragStart time.Time
+	dragStop  time.Time
+	timeline  *Timeline
+	rect      *canvas.Rectangle
+	label     *canvas.Text
+	widget.BaseWidget
+	segment    timelineSegment
+	dragMode   int
+	dragOffset float32
+}
+
+func newTimelineBlock(timeline *Timeline, segment timelineSegment) *timelineBlock {
+	block := &timelineBlock{
+		timeline: timeline,
+		segment:  segment,
+		rect:     canvas.NewRectangle(taskColor(segment.timesheet.TaskID)),
+		label:    canvas.NewText("", timelineBlockTextColor),
+	}
+	block.rect.CornerRadius = theme.InputRadiusSize()
+	block.label.TextSize = theme.CaptionTextSize()
+	block.ExtendBaseWidget(block)
+	return block
+}
+
+func (b *timelineBlock) CreateRenderer() fyne.WidgetRenderer {
+	return &timelineBlockRenderer{block: b}
+}
+
+// layout moves the block to the times of its segment, or to the times it is being dragged to
+func (b *timelineBlock) layout() {
+	start, stop := b.segment.start, b.segment.stop
+	if b.dragMode != timelineDragNone {
+		start, stop = b.dragStart, b.dragStop
+	}
+	pos, size := b.timeline.blockGeometry(b.segment.day, start, stop)
+	b.Move(pos)
+	b.Resize(size)
+	b.label.Text = fitText(
+		fmt.Sprintf("%s %s–%s", b.segment.timesheet.Task.Synopsis, start.Format(timelineTimeLayout), stop.Format(timelineTimeLayout)),
+		size.Width-2*theme.Padding(),
+		b.label.TextSize,
+	)
+	b.label.Refresh()
+}
+
+// Dragged moves or resizes the block
+func (b *timelineBlock) Dragged(event *fyne.DragEvent) {
+	if b.dragMode == timelineDragNone {
+		b.dragMode = b.segment.dragMode(event.Position.Y-event.Dragged.DY, b.Size().Height)
+		if b.dragMode == timelineDragNone {
+			return
+		}
+		b.dragOffset = 0
+	}
+	b.dragOffset += event.Dragged.DY
+	b.dragStart, b.dragStop = b.segment.dragged(b.dragMode, b.offsetDuration())
+	b.layout()
+}
+
+// DragEnd sends an event to change the times of the timesheet to the times the block was dragged to
+func (b *timelineBlock) DragEnd() {
+	mode := b.dragMode
+	b.dragMode = timelineDragNone
+	if mode == timelineDragNone {
+		return
+	}
+	timesheet, changed := b.segment.draggedTimesheet(mode, b.offsetDuration())
+	if !changed {
+		b.layout()
+		return
+	}
+	b.timeline.commandChan <- rxgo.Of(TimelineBlockChangedEvent{Timesheet: timesheet})
+}
+
+// offsetDuration returns the time that the block has been dragged by
+func (b *timelineBlock) offsetDuration() time.Duration {
+	return time.Duration(float64(b.dragOffset) / timelineHourHeight * float64(time.Hour))
+}
+
+// timelineBlockRenderer draws a block
+type timelineBlockRenderer struct {
+	block *timelineBlock
+}
+
+func (r *timelineBlockRenderer) Layout(size fyne.Size) {
+	r.block.rect.Resize(size)
+	r.block.label.Move(fyne.NewPos(theme.Padding(), 0))
+}
+
+func (r *timelineBlockRenderer) MinSize() fyne.Size {
+	return fyne.NewSize(0, 0)
+}
+
+func (r *timelineBlockRenderer) Refresh() {
+	r.Layout(r.block.Size())
+	canvas.Refresh(r.block)
+}
+
+func (r *timelineBlockRenderer) Objects() []fyne.CanvasObject {
+	return []fyne.CanvasObject{r.block.rect, r.block.label}
+}
+
+func (r *timelineBlockRenderer) Destroy() {}
+
+/*
+ * Gaps
+ */
+
+var _ fyne.Tappable = (*timelineGapArea)(nil)
+
+// timelineGapArea draws a gap between two timesheets and sends an event to fill it when it is tapped
+type timelineGapArea struct {
+	timeline *Timeline
+	rect     *canvas.Rectangle
+	gap      timelineGap
+	widget.BaseWidget
+}
+
+func newTimelineGapArea(timeline *Timeline, gap timelineGap) *timelineGapArea {
+	gapArea := &timelineGapArea{
+		timeline: timeline,
+		gap:      gap,
+		rect:     canvas.NewRectangle(timelineGapFillColor),
+	}
+	gapArea.rect.StrokeColor = theme.DisabledColor()
+	gapArea.rect.StrokeWidth = 1
+	gapArea.rect.CornerRadius = theme.InputRadiusSize()
+	gapArea.ExtendBaseWidget(gapArea)
+	return gapArea
+}
+
+func (g *timelineGapArea) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(g.rect)
+}
+
+// Tapped sends an event to fill the gap
+func (g *timelineGapArea) Tapped(_ *fyne.PointEvent) {
+	g.timeline.commandChan <- rxgo.Of(TimelineGapTappedEvent{Start: g.gap.start, Stop: g.gap.stop})
+}
+
+// fitText shortens the text with an ellipsis until it fits in the width
+func fitText(text string, width float32, textSize float32) string {
+	if fyne.MeasureText(text, textSize, fyne.TextStyle{}).Width <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		shortened := string(runes) + "…"
+		if fyne.MeasureText(shortened, textSize, fyne.TextStyle{}).Width <= width {
+			return shortened
+		}
+	}
+	return ""
+}
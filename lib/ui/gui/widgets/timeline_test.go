@@ -0,0 +1,134 @@
+package widgets
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"github.com/neflyte/timetracker/lib/models"
+	"github.com/stretchr/testify/require"
+)
+
+func timelineTestTimesheet(id uint, start time.Time, stop time.Time) models.TimesheetData {
+	timesheet := models.NewTimesheetData()
+	timesheet.ID = id
+	timesheet.TaskID = id
+	timesheet.StartTime = start
+	if !stop.IsZero() {
+		timesheet.StopTime = sql.NullTime{Time: stop, Valid: true}
+	}
+	return timesheet
+}
+
+func TestUnit_TimelineSegments(t *testing.T) {
+	day := time.Date(2023, 6, 5, 0, 0, 0, 0, time.Local)
+	currentTime := day.AddDate(0, 0, 1).Add(9 * time.Hour)
+	timesheets := []models.TimesheetData{
+		timelineTestTimesheet(1, day.Add(22*time.Hour), day.Add(26*time.Hour)),
+		timelineTestTimesheet(2, day.Add(9*time.Hour), day.Add(10*time.Hour)),
+		timelineTestTimesheet(3, day.AddDate(0, 0, 1).Add(8*time.Hour), time.Time{}),
+	}
+	segments := timelineSegments(day, 2, timesheets, currentTime)
+	require.Len(t, segments, 4)
+	// The segments are sorted by day and start time
+	require.Equal(t, uint(2), segments[0].timesheet.ID)
+	// A timesheet that stops after midnight is split between the days
+	require.Equal(t, uint(1), segments[1].timesheet.ID)
+	require.Equal(t, 0, segments[1].day)
+	require.True(t, segments[1].clippedStop)
+	require.Equal(t, day.AddDate(0, 0, 1), segments[1].stop)
+	require.Equal(t, uint(1), segments[2].timesheet.ID)
+	require.Equal(t, 1, segments[2].day)
+	require.True(t, segments[2].clippedStart)
+	require.Equal(t, day.AddDate(0, 0, 1), segments[2].start)
+	// A running timesheet is drawn until the current time
+	require.Equal(t, uint(3), segments[3].timesheet.ID)
+	require.True(t, segments[3].running)
+	require.Equal(t, currentTime, segments[3].stop)
+
+	gaps := timelineGaps(segments)
+	require.Len(t, gaps, 2)
+	require.Equal(t, timelineGap{start: day.Add(10 * time.Hour), stop: day.Add(22 * time.Hour), day: 0}, gaps[0])
+	require.Equal(t, timelineGap{start: day.Add(26 * time.Hour), stop: day.Add(32 * time.Hour), day: 1}, gaps[1])
+}
+
+func TestUnit_TimelineSegment_Dragged(t *testing.T) {
+	day := time.Date(2023, 6, 5, 0, 0, 0, 0, time.Local)
+	segment := timelineSegment{start: day.Add(9 * time.Hour), stop: day.Add(10 * time.Hour)}
+	require.Equal(t, timelineDragStart, segment.dragMode(2, 40))
+	require.Equal(t, timelineDragStop, segment.dragMode(38, 40))
+	require.Equal(t, timelineDragMove, segment.dragMode(20, 40))
+
+	// Moves are snapped
+	start, stop := segment.dragged(timelineDragMove, 17*time.Minute)
+	require.Equal(t, day.Add(9*time.Hour+15*time.Minute), start)
+	require.Equal(t, day.Add(10*time.Hour+15*time.Minute), stop)
+	// A segment is never resized to nothing
+	start, stop = segment.dragged(timelineDragStop, -2*time.Hour)
+	require.Equal(t, day.Add(9*time.Hour), start)
+	require.Equal(t, day.Add(9*time.Hour+timelineSnap), stop)
+
+	// Only the start of a running segment can be dragged
+	segment.running = true
+	require.Equal(t, timelineDragStart, segment.dragMode(2, 40))
+	require.Equal(t, timelineDragNone, segment.dragMode(38, 40))
+	require.Equal(t, timelineDragNone, segment.dragMode(20, 40))
+}
+
+func TestUnit_TimelineSegment_DraggedTimesheet(t *testing.T) {
+	day := time.Date(2023, 6, 5, 0, 0, 0, 0, time.Local)
+	// A timesheet from 22:00 until 02:00 the next day
+	timesheets := []models.TimesheetData{timelineTestTimesheet(1, day.Add(22*time.Hour), day.Add(26*time.Hour))}
+	segments := timelineSegments(day, 2, timesheets, day.AddDate(0, 0, 1).Add(9*time.Hour))
+	require.Len(t, segments, 2)
+
+	// Dragging the start of the first day's block keeps the stop on the next day
+	timesheet, changed := segments[0].draggedTimesheet(timelineDragStart, -time.Hour)
+	require.True(t, changed)
+	require.Equal(t, day.Add(21*time.Hour), timesheet.StartTime)
+	require.Equal(t, day.Add(26*time.Hour), timesheet.StopTime.Time)
+
+	// Dragging the stop of the next day's block keeps the start on the first day
+	timesheet, changed = segments[1].draggedTimesheet(timelineDragStop, time.Hour)
+	require.True(t, changed)
+	require.Equal(t, day.Add(22*time.Hour), timesheet.StartTime)
+	require.Equal(t, day.Add(27*time.Hour), timesheet.StopTime.Time)
+
+	_, changed = segments[1].draggedTimesheet(timelineDragStop, time.Minute)
+	require.False(t, changed)
+}
+
+func TestUnit_Timeline_Events(t *testing.T) {
+	day := time.Date(2023, 6, 5, 0, 0, 0, 0, time.Local)
+	timeline := NewTimeline()
+	timeline.SetTimesheets(day, 1, []models.TimesheetData{
+		timelineTestTimesheet(1, day.Add(9*time.Hour), day.Add(10*time.Hour)),
+		timelineTestTimesheet(2, day.Add(11*time.Hour), day.Add(12*time.Hour)),
+	}, day.Add(13*time.Hour))
+	timeline.Resize(fyne.NewSize(400, timeline.OffsetOf(24)))
+	segments := timelineSegments(day, 1, timeline.timesheets, timeline.currentTime)
+
+	gapArea := newTimelineGapArea(timeline, timelineGaps(segments)[0])
+	gapArea.Tapped(&fyne.PointEvent{})
+	item := <-timeline.commandChan
+	gapEvent, ok := item.V.(TimelineGapTappedEvent)
+	require.True(t, ok)
+	require.Equal(t, day.Add(10*time.Hour), gapEvent.Start)
+	require.Equal(t, day.Add(11*time.Hour), gapEvent.Stop)
+
+	// Dragging the middle of a block down by half an hour moves its timesheet
+	block := newTimelineBlock(timeline, segments[0])
+	block.layout()
+	block.Dragged(&fyne.DragEvent{
+		PointEvent: fyne.PointEvent{Position: fyne.NewPos(10, 20+timelineHourHeight/2)},
+		Dragged:    fyne.NewDelta(0, timelineHourHeight/2),
+	})
+	block.DragEnd()
+	item = <-timeline.commandChan
+	changedEvent, ok := item.V.(TimelineBlockChangedEvent)
+	require.True(t, ok)
+	require.Equal(t, uint(1), changedEvent.Timesheet.ID)
+	require.Equal(t, day.Add(9*time.Hour+30*time.Minute), changedEvent.Timesheet.StartTime)
+	require.Equal(t, day.Add(10*time.Hour+30*time.Minute), changedEvent.Timesheet.StopTime.Time)
+}
@@ -0,0 +1,298 @@
+package widgets
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/models"
+	"github.com/neflyte/timetracker/lib/utils"
+	"github.com/reactivex/rxgo/v2"
+	"github.com/rs/zerolog"
+)
+
+const (
+	timesheetEditorCommandChanSize = 2
+	// timesheetEditorTimeWidth is the minimum width of the start and stop time entries
+	timesheetEditorTimeWidth = 110.0
+)
+
+/*
+ * Event data structs
+ */
+
+// TimesheetEditorSaveEvent represents an event which creates or updates a timesheet
+type TimesheetEditorSaveEvent struct {
+	// Timesheet has the values that were entered; a timesheet without an ID is created
+	Timesheet models.TimesheetData
+	// Row is the index of the row that the timesheet was entered in
+	Row int
+}
+
+// TimesheetEditorSplitEvent represents an event which splits a timesheet in two
+type TimesheetEditorSplitEvent struct {
+	// Timesheet is the timesheet to split
+	Timesheet models.TimesheetData
+}
+
+// TimesheetEditorDeleteEvent represents an event which deletes a timesheet
+type TimesheetEditorDeleteEvent struct {
+	// Timesheet is the timesheet to delete
+	Timesheet models.TimesheetData
+}
+
+/*
+ * Main data struct
+ */
+
+var _ fyne.Widget = (*TimesheetEditor)(nil)
+
+// TimesheetEditor is a widget that lists the timesheets of a day and edits their task, start time and stop time
+// inline. Use NewTimesheetEditor() to create a new instance of the widget.
+type TimesheetEditor struct {
+	day          time.Time
+	log          zerolog.Logger
+	container    *fyne.Container
+	emptyLabel   *widget.Label
+	commandChan  chan rxgo.Item
+	rows         []*timesheetEditorRow
+	taskSynopses []string
+	widget.BaseWidget
+}
+
+// timesheetEditorRow is the row of inputs that edits a single timesheet
+type timesheetEditorRow struct {
+	taskEntry    *widget.SelectEntry
+	startEntry   *MinWidthEntry
+	stopEntry    *MinWidthEntry
+	saveButton   *widget.Button
+	splitButton  *widget.Button
+	deleteButton *widget.Button
+	messageLabel *widget.Label
+	container    *fyne.Container
+	timesheet    models.TimesheetData
+}
+
+// NewTimesheetEditor returns a pointer to a newly initialized instance of the TimesheetEditor widget
+func NewTimesheetEditor() *TimesheetEditor {
+	te := &TimesheetEditor{
+		log:          logger.GetStructLogger("TimesheetEditor"),
+		commandChan:  make(chan rxgo.Item, timesheetEditorCommandChanSize),
+		rows:         make([]*timesheetEditorRow, 0),
+		taskSynopses: make([]string, 0),
+	}
+	te.ExtendBaseWidget(te)
+	te.initUI()
+	return te
+}
+
+// CreateRenderer returns a new WidgetRenderer for this widget.
+func (t *TimesheetEditor) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(t.container)
+}
+
+func (t *TimesheetEditor) initUI() {
+	t.emptyLabel = widget.NewLabel("There are no timesheets on this day") // i18n
+	t.emptyLabel.TextStyle = fyne.TextStyle{Italic: true}
+	t.container = container.NewVBox(t.emptyLabel)
+}
+
+/*
+ * Public functions
+ */
+
+// Observable returns an RxGo Observable for the widget's command channel
+func (t *TimesheetEditor) Observable() rxgo.Observable {
+	return rxgo.FromEventSource(t.commandChan)
+}
+
+// SetTimesheets replaces the rows with the timesheets of the specified day. The task entries suggest the
+// synopses in taskSynopses.
+func (t *TimesheetEditor) SetTimesheets(day time.Time, timesheets []models.TimesheetData, taskSynopses []string) {
+	t.day = day
+	t.taskSynopses = taskSynopses
+	t.rows = make([]*timesheetEditorRow, 0, len(timesheets))
+	for _, timesheet := range timesheets {
+		t.rows = append(t.rows, t.newRow(timesheet))
+	}
+	t.refreshRows()
+}
+
+// AddTimesheet adds a row for a new timesheet which starts when the last timesheet of the day stops
+func (t *TimesheetEditor) AddTimesheet() {
+	timesheet := models.NewTimesheetData()
+	if len(t.rows) > 0 {
+		last := t.rows[len(t.rows)-1].timesheet
+		if last.StopTime.Valid {
+			timesheet.StartTime = last.StopTime.Time
+		}
+	}
+	t.rows = append(t.rows, t.newRow(timesheet))
+	t.refreshRows()
+}
+
+// SetRowError shows the error below the specified row; a nil error clears it
+func (t *TimesheetEditor) SetRowError(row int, err error) {
+	if row < 0 || row >= len(t.rows) {
+		return
+	}
+	messageLabel := t.rows[row].messageLabel
+	if err == nil {
+		messageLabel.SetText("")
+		messageLabel.Hide()
+		return
+	}
+	messageLabel.SetText(err.Error())
+	messageLabel.Show()
+}
+
+/*
+ * Private functions
+ */
+
+// newRow returns a row that edits the timesheet
+func (t *TimesheetEditor) newRow(timesheet models.TimesheetData) *timesheetEditorRow {
+	row := &timesheetEditorRow{
+		timesheet: timesheet,
+	}
+	row.taskEntry = widget.NewSelectEntry(t.taskSynopses)
+	row.taskEntry.SetPlaceHolder("task id or synopsis")                   // i18n
+	row.startEntry = NewMinWidthEntry(timesheetEditorTimeWidth, "HH:MM")  // i18n
+	row.stopEntry = NewMinWidthEntry(timesheetEditorTimeWidth, "running") // i18n
+	if timesheet.Task.ID > 0 {
+		row.taskEntry.SetText(timesheet.Task.Synopsis)
+	}
+	if !timesheet.StartTime.IsZero() {
+		row.startEntry.SetText(utils.FormatTimeOfDay(timesheet.StartTime, t.day))
+	}
+	if timesheet.StopTime.Valid {
+		row.stopEntry.SetText(utils.FormatTimeOfDay(timesheet.StopTime.Time, t.day))
+	}
+	row.saveButton = widget.NewButtonWithIcon("", theme.DocumentSaveIcon(), func() {
+		t.saveRow(row)
+	})
+	row.splitButton = widget.NewButtonWithIcon("", theme.ContentCutIcon(), func() {
+		t.commandChan <- rxgo.Of(TimesheetEditorSplitEvent{Timesheet: row.timesheet})
+	})
+	row.deleteButton = widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+		t.deleteRow(row)
+	})
+	if timesheet.ID == 0 {
+		// A new timesheet has to be saved before it can be split
+		row.splitButton.Disable()
+	} else {
+		// An existing timesheet is saved once it is changed
+		row.saveButton.Disable()
+	}
+	changed := func(string) {
+		row.saveButton.Enable()
+	}
+	submitted := func(string) {
+		t.saveRow(row)
+	}
+	row.taskEntry.OnChanged = changed
+	row.taskEntry.OnSubmitted = submitted
+	row.startEntry.OnChanged = changed
+	row.startEntry.OnSubmitted = submitted
+	row.stopEntry.OnChanged = changed
+	row.stopEntry.OnSubmitted = submitted
+	row.messageLabel = widget.NewLabel("")
+	row.messageLabel.Importance = widget.DangerImportance
+	row.messageLabel.Wrapping = fyne.TextWrapWord
+	row.messageLabel.Hide()
+	row.container = container.NewVBox(
+		container.NewBorder(
+			nil,
+			nil,
+			nil,
+			container.NewHBox(row.startEntry, row.stopEntry, row.saveButton, row.splitButton, row.deleteButton),
+			row.taskEntry,
+		),
+		row.messageLabel,
+	)
+	return row
+}
+
+// refreshRows shows the rows in the container
+func (t *TimesheetEditor) refreshRows() {
+	objects := make([]fyne.CanvasObject, 0, len(t.rows))
+	for _, row := range t.rows {
+		objects = append(objects, row.container)
+	}
+	if len(objects) == 0 {
+		objects = append(objects, t.emptyLabel)
+	}
+	t.container.Objects = objects
+	t.container.Refresh()
+}
+
+// rowIndex returns the index of the row, or -1 if the row was removed
+func (t *TimesheetEditor) rowIndex(row *timesheetEditorRow) int {
+	for idx := range t.rows {
+		if t.rows[idx] == row {
+			return idx
+		}
+	}
+	return -1
+}
+
+// saveRow sends an event to save the values of the row, or shows why they cannot be saved
+func (t *TimesheetEditor) saveRow(row *timesheetEditorRow) {
+	rowIdx := t.rowIndex(row)
+	if rowIdx == -1 {
+		return
+	}
+	timesheet, err := row.timesheetData(t.day)
+	t.SetRowError(rowIdx, err)
+	if err != nil {
+		return
+	}
+	t.commandChan <- rxgo.Of(TimesheetEditorSaveEvent{Timesheet: timesheet, Row: rowIdx})
+}
+
+// deleteRow sends an event to delete the timesheet of the row; the row of a new timesheet is removed
+func (t *TimesheetEditor) deleteRow(row *timesheetEditorRow) {
+	if row.timesheet.ID > 0 {
+		t.commandChan <- rxgo.Of(TimesheetEditorDeleteEvent{Timesheet: row.timesheet})
+		return
+	}
+	rowIdx := t.rowIndex(row)
+	if rowIdx == -1 {
+		return
+	}
+	t.rows = append(t.rows[:rowIdx], t.rows[rowIdx+1:]...)
+	t.refreshRows()
+}
+
+// timesheetData returns the timesheet with the values of the row, or an error if they cannot be parsed
+func (r *timesheetEditorRow) timesheetData(day time.Time) (models.TimesheetData, error) {
+	timesheet := r.timesheet
+	task := models.NewTask()
+	task.Data().ID, task.Data().Synopsis = task.Resolve(strings.TrimSpace(r.taskEntry.Text))
+	err := task.Load(false)
+	if err != nil {
+		return timesheet, fmt.Errorf("%s: %w", tterrors.LoadTaskError, err)
+	}
+	timesheet.Task = *task.Data()
+	timesheet.TaskID = task.Data().ID
+	timesheet.StartTime, err = utils.ParseTimeOfDay(r.startEntry.Text, day)
+	if err != nil {
+		return timesheet, err
+	}
+	timesheet.StopTime = sql.NullTime{}
+	if strings.TrimSpace(r.stopEntry.Text) != "" {
+		timesheet.StopTime.Time, err = utils.ParseTimeOfDay(r.stopEntry.Text, day)
+		if err != nil {
+			return timesheet, err
+		}
+		timesheet.StopTime.Valid = true
+	}
+	return timesheet, nil
+}
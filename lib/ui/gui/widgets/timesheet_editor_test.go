@@ -0,0 +1,67 @@
+package widgets
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/neflyte/timetracker/lib/database"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_TimesheetEditor_Rows(t *testing.T) {
+	db := MustOpenTestDB(t)
+	defer CloseTestDB(t, db)
+	database.Set(db)
+
+	task := models.NewTask()
+	task.Data().Synopsis = "review"
+	require.Nil(t, task.Create())
+	day := time.Date(2023, 6, 5, 0, 0, 0, 0, time.Local)
+	timesheet := models.NewTimesheet()
+	timesheet.Data().Task = *task.Data()
+	timesheet.Data().StartTime = day.Add(9 * time.Hour)
+	timesheet.Data().StopTime = sql.NullTime{Time: day.Add(10 * time.Hour), Valid: true}
+	require.Nil(t, timesheet.Create())
+
+	editor := NewTimesheetEditor()
+	editor.SetTimesheets(day, []models.TimesheetData{*timesheet.Data()}, []string{"review"})
+	require.Len(t, editor.rows, 1)
+	row := editor.rows[0]
+	require.Equal(t, "review", row.taskEntry.Text)
+	require.Equal(t, "09:00:00", row.startEntry.Text)
+	require.Equal(t, "10:00:00", row.stopEntry.Text)
+	require.True(t, row.saveButton.Disabled())
+	require.False(t, row.splitButton.Disabled())
+
+	// A changed row can be saved once its values can be parsed
+	row.stopEntry.SetText("half past ten")
+	require.False(t, row.saveButton.Disabled())
+	editor.saveRow(row)
+	require.True(t, row.messageLabel.Visible())
+	require.Equal(t, tterrors.InvalidTimeOfDayError, row.messageLabel.Text)
+	row.stopEntry.SetText("10:30")
+	editor.saveRow(row)
+	require.False(t, row.messageLabel.Visible())
+	item := <-editor.commandChan
+	saveEvent, ok := item.V.(TimesheetEditorSaveEvent)
+	require.True(t, ok)
+	require.Equal(t, 0, saveEvent.Row)
+	require.Equal(t, timesheet.Data().ID, saveEvent.Timesheet.ID)
+	require.Equal(t, day.Add(10*time.Hour+30*time.Minute), saveEvent.Timesheet.StopTime.Time)
+
+	// A new timesheet starts when the last one stops, and its row is removed without a confirmation
+	editor.AddTimesheet()
+	require.Len(t, editor.rows, 2)
+	require.Equal(t, "10:00:00", editor.rows[1].startEntry.Text)
+	require.True(t, editor.rows[1].splitButton.Disabled())
+	editor.deleteRow(editor.rows[1])
+	require.Len(t, editor.rows, 1)
+	editor.deleteRow(row)
+	item = <-editor.commandChan
+	deleteEvent, ok := item.V.(TimesheetEditorDeleteEvent)
+	require.True(t, ok)
+	require.Equal(t, timesheet.Data().ID, deleteEvent.Timesheet.ID)
+}
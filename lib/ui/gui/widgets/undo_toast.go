@@ -0,0 +1,92 @@
+package widgets
+
+import (
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	// undoToastDuration is how long the toast is shown before it hides itself
+	undoToastDuration = 6 * time.Second
+)
+
+var _ fyne.Widget = (*UndoToast)(nil)
+
+// UndoToast is a pop-up at the bottom of a window which describes the last action, with a button to undo it. It hides
+// itself after a few seconds.
+type UndoToast struct {
+	// OnUndo is called when the UNDO button is tapped
+	OnUndo     func()
+	label      *widget.Label
+	undoButton *widget.Button
+	popUp      *widget.PopUp
+	hideTimer  *time.Timer
+	widget.BaseWidget
+	mtx sync.Mutex
+}
+
+// NewUndoToast returns a new UndoToast widget which calls onUndo when the UNDO button is tapped
+func NewUndoToast(onUndo func()) *UndoToast {
+	toast := &UndoToast{
+		OnUndo: onUndo,
+		label:  widget.NewLabel(""),
+	}
+	toast.undoButton = widget.NewButtonWithIcon("UNDO", theme.ContentUndoIcon(), toast.handleUndo) // i18n
+	toast.ExtendBaseWidget(toast)
+	return toast
+}
+
+// CreateRenderer returns a new WidgetRenderer for this widget
+func (u *UndoToast) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(container.NewHBox(u.label, u.undoButton))
+}
+
+// ShowMessage shows the toast with the message at the bottom of the canvas, and hides it after a few seconds
+func (u *UndoToast) ShowMessage(canvas fyne.Canvas, message string) {
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+	u.label.SetText(message)
+	if u.popUp == nil || u.popUp.Canvas != canvas {
+		u.popUp = widget.NewPopUp(u, canvas)
+	}
+	toastSize := u.popUp.MinSize()
+	canvasSize := canvas.Size()
+	u.popUp.ShowAtPosition(fyne.NewPos(
+		(canvasSize.Width-toastSize.Width)/2,
+		canvasSize.Height-toastSize.Height-theme.Padding(),
+	))
+	if u.hideTimer != nil {
+		u.hideTimer.Stop()
+	}
+	u.hideTimer = time.AfterFunc(undoToastDuration, u.Dismiss)
+}
+
+// Message returns the message that the toast shows
+func (u *UndoToast) Message() string {
+	return u.label.Text
+}
+
+// Dismiss hides the toast
+func (u *UndoToast) Dismiss() {
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+	if u.hideTimer != nil {
+		u.hideTimer.Stop()
+		u.hideTimer = nil
+	}
+	if u.popUp != nil {
+		u.popUp.Hide()
+	}
+}
+
+func (u *UndoToast) handleUndo() {
+	u.Dismiss()
+	if u.OnUndo != nil {
+		u.OnUndo()
+	}
+}
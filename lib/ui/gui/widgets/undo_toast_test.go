@@ -0,0 +1,23 @@
+package widgets
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnit_UndoToast(t *testing.T) {
+	undone := 0
+	toast := NewUndoToast(func() { undone++ })
+	testWindow := testApp.NewWindow("UndoToast Test")
+	testWindow.Show()
+	defer testWindow.Hide()
+
+	toast.ShowMessage(testWindow.Canvas(), "Start task review")
+	assert.Equal(t, "Start task review", toast.Message())
+	assert.True(t, toast.popUp.Visible())
+	test.Tap(toast.undoButton)
+	assert.Equal(t, 1, undone)
+	assert.False(t, toast.popUp.Visible())
+}
@@ -0,0 +1,30 @@
+package widgets
+
+import (
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+)
+
+var _ fyne.Theme = (*VariantTheme)(nil)
+
+// VariantTheme is the default theme with its colours fixed to the light or dark variant, whatever the preference of
+// the operating system is
+type VariantTheme struct {
+	fyne.Theme
+	variant fyne.ThemeVariant
+}
+
+// NewVariantTheme returns the default theme with the colours of the specified variant
+func NewVariantTheme(variant fyne.ThemeVariant) *VariantTheme {
+	return &VariantTheme{
+		Theme:   theme.DefaultTheme(),
+		variant: variant,
+	}
+}
+
+// Color returns the colour of the fixed variant
+func (v *VariantTheme) Color(name fyne.ThemeColorName, _ fyne.ThemeVariant) color.Color {
+	return v.Theme.Color(name, v.variant)
+}
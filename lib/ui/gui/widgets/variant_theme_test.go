@@ -0,0 +1,16 @@
+package widgets
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2/theme"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_VariantTheme(t *testing.T) {
+	darkTheme := NewVariantTheme(theme.VariantDark)
+	defaultTheme := theme.DefaultTheme()
+	require.Equal(t, defaultTheme.Color(theme.ColorNameBackground, theme.VariantDark), darkTheme.Color(theme.ColorNameBackground, theme.VariantLight))
+	require.NotEqual(t, defaultTheme.Color(theme.ColorNameBackground, theme.VariantLight), darkTheme.Color(theme.ColorNameBackground, theme.VariantLight))
+	require.Equal(t, defaultTheme.Size(theme.SizeNamePadding), darkTheme.Size(theme.SizeNamePadding))
+}
@@ -11,6 +11,13 @@ import (
 	"github.com/gofrs/uuid"
 	"github.com/neflyte/timetracker/lib/logger"
 	"github.com/neflyte/timetracker/lib/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+const (
+	TestDSN = "file:test.db?cache=shared&mode=memory"
 )
 
 var (
@@ -46,3 +53,31 @@ func TestMain(m *testing.M) {
 		)
 	}
 }
+
+func MustOpenTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(TestDSN), &gorm.Config{
+		Logger: gormLogger.Default.LogMode(gormLogger.Warn),
+	})
+	if err != nil {
+		t.Fatalf("error opening test db: %s", err)
+	}
+	err = models.Migrate(db)
+	if err != nil {
+		t.Fatalf("error automigrating test db schema: %s", err)
+	}
+	return db
+}
+
+func CloseTestDB(t *testing.T, db *gorm.DB) {
+	if db != nil {
+		sqldb, err := db.DB()
+		if err != nil {
+			t.Logf("error getting sql.DB handle: %s\n", err)
+		} else {
+			err = sqldb.Close()
+			if err != nil {
+				t.Logf("error closing DB handle: %s\n", err)
+			}
+		}
+	}
+}
@@ -8,7 +8,9 @@ import (
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
 	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/models"
 	"github.com/neflyte/timetracker/lib/ui/gui/dialogs"
 	"github.com/neflyte/timetracker/lib/ui/gui/widgets"
 	"github.com/neflyte/timetracker/lib/ui/icons"
@@ -29,6 +31,7 @@ type manageWindowV2 interface {
 	Hide()
 	Close()
 	Observable() rxgo.Observable
+	RefreshTasks()
 }
 
 var _ fyne.Window = (*manageWindowV2Impl)(nil)
@@ -40,6 +43,7 @@ type manageWindowV2Impl struct {
 	createButton *widget.Button
 	editButton   *widget.Button
 	deleteButton *widget.Button
+	pinButton    *widget.Button
 	taskSelector *widgets.TaskSelector
 	taskEditor   *widgets.TaskEditorV2
 	eventChan    chan rxgo.Item
@@ -65,11 +69,12 @@ func (m *manageWindowV2Impl) Init() error {
 	m.createButton = widget.NewButtonWithIcon("New", theme.ContentAddIcon(), m.doCreateTask)
 	m.editButton = widget.NewButtonWithIcon("Edit", theme.DocumentCreateIcon(), m.doEditTask)
 	m.deleteButton = widget.NewButtonWithIcon("Delete", theme.DeleteIcon(), m.doDeleteTask)
+	m.pinButton = widget.NewButtonWithIcon("Pin", theme.ConfirmIcon(), m.doTogglePinned) // i18n
 	m.buttonHBox = container.NewBorder(
 		nil,
 		nil,
 		m.createButton,
-		container.NewHBox(m.editButton, m.deleteButton),
+		container.NewHBox(m.pinButton, m.editButton, m.deleteButton),
 	)
 	m.taskSelector = widgets.NewTaskSelector()
 	m.taskSelector.Observable().ForEach(
@@ -104,6 +109,11 @@ func (m *manageWindowV2Impl) Observable() rxgo.Observable {
 	return rxgo.FromEventSource(m.eventChan)
 }
 
+// RefreshTasks reloads the task list, such as after switching to a different database
+func (m *manageWindowV2Impl) RefreshTasks() {
+	go m.taskSelector.FilterTasks()
+}
+
 func (m *manageWindowV2Impl) handleTaskSelectorEvent(item interface{}) {
 	log := logger.GetFuncLogger(m.log, "handleTaskSelectorEvent")
 	if event, ok := item.(widgets.TaskSelectorSelectedEvent); ok {
@@ -112,7 +122,43 @@ func (m *manageWindowV2Impl) handleTaskSelectorEvent(item interface{}) {
 				Str("selected", event.SelectedTask.String()).
 				Msg("got selected task")
 		}
+		m.updatePinButton(event.SelectedTask)
+	}
+}
+
+// updatePinButton shows whether the button pins or unpins the selected task
+func (m *manageWindowV2Impl) updatePinButton(selectedTask models.Task) {
+	if selectedTask != nil && selectedTask.Data().Pinned {
+		m.pinButton.SetText("Unpin") // i18n
+		m.pinButton.SetIcon(theme.CancelIcon())
+		return
+	}
+	m.pinButton.SetText("Pin") // i18n
+	m.pinButton.SetIcon(theme.ConfirmIcon())
+}
+
+// doTogglePinned pins the selected task to the tray's Favorites menu, or unpins it
+func (m *manageWindowV2Impl) doTogglePinned() {
+	log := logger.GetFuncLogger(m.log, "doTogglePinned")
+	selectedTask := m.taskSelector.Selected()
+	if selectedTask == nil {
+		return
 	}
+	pinned := !selectedTask.Data().Pinned
+	err := selectedTask.SetPinned(pinned)
+	if err != nil {
+		log.Err(err).
+			Str("task", selectedTask.String()).
+			Msg(tterrors.PinTaskError)
+		dialog.NewError(err, m.Window).Show()
+		return
+	}
+	selectedTask.Data().Pinned = pinned
+	m.updatePinButton(selectedTask)
+	// re-filter task list
+	go m.taskSelector.FilterTasks()
+	// send a refresh event
+	m.eventChan <- rxgo.Of(ManageWindowV2TasksChangedEvent{})
 }
 
 func (m *manageWindowV2Impl) doEditTask() {
@@ -1,8 +1,8 @@
 package windows
 
 import (
-	"encoding/csv"
 	"fmt"
+	"image/png"
 	"reflect"
 	"time"
 
@@ -12,12 +12,17 @@ import (
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+	"github.com/jinzhu/now"
+	"github.com/neflyte/timetracker/lib/config"
 	"github.com/neflyte/timetracker/lib/constants"
 	tterrors "github.com/neflyte/timetracker/lib/errors"
 	"github.com/neflyte/timetracker/lib/logger"
 	"github.com/neflyte/timetracker/lib/models"
+	"github.com/neflyte/timetracker/lib/ui/cli"
+	"github.com/neflyte/timetracker/lib/ui/gui/dialogs"
 	"github.com/neflyte/timetracker/lib/ui/gui/widgets"
 	"github.com/neflyte/timetracker/lib/ui/icons"
+	"github.com/neflyte/timetracker/lib/utils"
 	"github.com/rs/zerolog"
 )
 
@@ -32,13 +37,32 @@ const (
 var (
 	tableColumnWidths = []float32{75, 250, 100, 100}
 	tableHeader       = []string{"Task ID", "Synopsis", "Started On", "Duration"}
-	csvTableHeader    = []string{"task_id", "synopsis", "started_on", "duration"}
+	timesheetsHeader  = []string{"Started", "Stopped", "Duration", "Note"} // i18n
+	// reportExportFormats is the list of formats that the report is exported in
+	reportExportFormats = []reportExportFormat{
+		{name: "CSV", format: cli.ReportFormatCSV, extension: "csv"},
+		{name: "JSON", format: cli.ReportFormatJSON, extension: "json"},
+		{name: "XML", format: cli.ReportFormatXML, extension: "xml"},
+		{name: "Markdown", format: cli.ReportFormatMarkdown, extension: "md"},
+		{name: "HTML", format: cli.ReportFormatHTML, extension: "html"},
+	}
 )
 
+// reportExportFormat is a format that the report is exported in
+type reportExportFormat struct {
+	// name is the name of the format in the format selector
+	name string
+	// format is the serialization format of the report
+	format string
+	// extension is the file name extension of the exported file
+	extension string
+}
+
 type reportWindow interface {
 	windowBase
 	Hide()
 	Show()
+	ShowToday()
 }
 
 type reportWindowData struct {
@@ -49,11 +73,16 @@ type reportWindowData struct {
 	container       *fyne.Container
 	endDateLabel    *widget.Label
 	startDateEntry  *widgets.MinWidthEntry
+	startDateButton *widget.Button
+	endDateButton   *widget.Button
 	headerContainer *fyne.Container
 	startDateLabel  *widget.Label
 	runReportButton *widget.Button
 	exportButton    *widget.Button
+	exportSelect    *widget.Select
+	exportPNGButton *widget.Button
 	resultTable     *widget.Table
+	charts          *widgets.ReportCharts
 	endDateEntry    *widgets.MinWidthEntry
 	taskReport      models.TaskReport
 	tableColumns    int
@@ -88,20 +117,44 @@ func (w *reportWindowData) Init() error {
 	w.endDateEntry = widgets.NewMinWidthEntry(dateEntryMinWidth, "YYYY-MM-DD") // l10n
 	w.endDateEntry.Bind(w.endDateBinding)
 	w.endDateEntry.Validator = w.dateValidator
+	w.startDateButton = widget.NewButtonWithIcon("", theme.MenuDropDownIcon(), func() {
+		w.showDatePicker(w.startDateBinding, w.startDateButton)
+	})
+	w.endDateButton = widget.NewButtonWithIcon("", theme.MenuDropDownIcon(), func() {
+		w.showDatePicker(w.endDateBinding, w.endDateButton)
+	})
 	w.startDateLabel = widget.NewLabel("Start date:")                                         // i18n
 	w.endDateLabel = widget.NewLabel("End date:")                                             // i18n
 	w.runReportButton = widget.NewButtonWithIcon("RUN", theme.MediaPlayIcon(), w.doRunReport) // i18n
 	w.exportButton = widget.NewButtonWithIcon("EXPORT", theme.DownloadIcon(), w.doExport)     // i18n
 	w.exportButton.Disable()                                                                  // Initialize the export button in a disabled state
-	w.headerContainer = container.NewBorder(
-		nil, nil,
-		container.NewHBox(
-			w.startDateLabel, w.startDateEntry,
-			w.endDateLabel, w.endDateEntry,
-		),
-		container.NewHBox(
-			w.runReportButton, w.exportButton,
+	exportFormatNames := make([]string, 0, len(reportExportFormats))
+	for _, exportFormat := range reportExportFormats {
+		exportFormatNames = append(exportFormatNames, exportFormat.name)
+	}
+	w.exportSelect = widget.NewSelect(exportFormatNames, nil)
+	w.exportSelect.SetSelectedIndex(0)
+	w.exportPNGButton = widget.NewButtonWithIcon("SAVE PNG", theme.FileImageIcon(), w.doExportPNG) // i18n
+	w.exportPNGButton.Disable()
+	presetButtons := make([]fyne.CanvasObject, 0, len(cli.DateRangePresets))
+	for _, preset := range cli.DateRangePresets {
+		selectedPreset := preset
+		presetButtons = append(presetButtons, widget.NewButton(preset.Name, func() {
+			w.doPreset(selectedPreset)
+		}))
+	}
+	w.headerContainer = container.NewVBox(
+		container.NewBorder(
+			nil, nil,
+			container.NewHBox(
+				w.startDateLabel, w.startDateEntry, w.startDateButton,
+				w.endDateLabel, w.endDateEntry, w.endDateButton,
+			),
+			container.NewHBox(
+				w.runReportButton, w.exportSelect, w.exportButton, w.exportPNGButton,
+			),
 		),
+		container.NewHBox(presetButtons...),
 	)
 	// Result table
 	w.resultTable = widget.NewTable(w.resultTableLength, w.resultTableCreate, w.resultTableUpdate)
@@ -109,10 +162,15 @@ func (w *reportWindowData) Init() error {
 	for idx, colWidth := range tableColumnWidths {
 		w.resultTable.SetColumnWidth(idx, colWidth)
 	}
+	// Charts of the same report
+	w.charts = widgets.NewReportCharts()
 	w.container = container.NewPadded(
 		container.NewBorder(
 			w.headerContainer, nil, nil, nil,
-			w.resultTable,
+			container.NewAppTabs(
+				container.NewTabItemWithIcon("Table", theme.ListIcon(), w.resultTable),                       // i18n
+				container.NewTabItemWithIcon("Charts", theme.FileImageIcon(), container.NewScroll(w.charts)), // i18n
+			),
 		),
 	)
 	w.Window.SetContent(w.container)
@@ -129,6 +187,12 @@ func (w *reportWindowData) Show() {
 	w.Window.Show()
 }
 
+// ShowToday displays the window and runs the report for today
+func (w *reportWindowData) ShowToday() {
+	w.Show()
+	w.doPreset(cli.TodayPreset)
+}
+
 func (w *reportWindowData) Hide() {
 	w.Window.Hide()
 }
@@ -138,7 +202,7 @@ func (w *reportWindowData) resultTableLength() (int, int) {
 }
 
 func (w *reportWindowData) resultTableCreate() fyne.CanvasObject {
-	return widget.NewLabel("")
+	return widgets.NewDoubleTappableLabel("")
 }
 
 func (w *reportWindowData) resultTableUpdate(cell widget.TableCellID, object fyne.CanvasObject) {
@@ -146,27 +210,32 @@ func (w *reportWindowData) resultTableUpdate(cell widget.TableCellID, object fyn
 		log       = logger.GetFuncLogger(w.log, "resultTableUpdate")
 		labelText string
 	)
-	label, isLabel := object.(*widget.Label)
+	label, isLabel := object.(*widgets.DoubleTappableLabel)
 	if !isLabel {
 		log.Error().
 			Str("unexpectedType", reflect.TypeOf(object).String()).
-			Msg("expected *widget.Label but got unexpected type")
+			Msg("expected *widgets.DoubleTappableLabel but got unexpected type")
 		return
 	}
 	if cell.Row == 0 {
 		labelText = tableHeader[cell.Col]
 		label.TextStyle.Bold = true
+		label.OnDoubleTapped = nil
 	} else {
 		taskReportData := w.taskReport[cell.Row-1]
+		// Double-tapping a row shows the timesheets that it adds up
+		label.OnDoubleTapped = func() {
+			w.showTimesheets(taskReportData)
+		}
 		switch cell.Col {
 		case columnTaskID:
 			labelText = fmt.Sprintf("%d", taskReportData.TaskID)
 		case columnTaskSynopsis:
 			labelText = taskReportData.TaskSynopsis
 		case columnStartDate:
-			labelText = taskReportData.StartDate.Time.Format(constants.TimestampDateLayout)
+			labelText = taskReportData.StartDate.Time.Format(config.DateFormat())
 		case columnDuration:
-			labelText = taskReportData.Duration().String()
+			labelText = config.FormatDuration(taskReportData.Duration())
 		default:
 			labelText = ""
 		}
@@ -196,12 +265,15 @@ func (w *reportWindowData) doRunReport() {
 	}
 	// Disable run button and enable when this function is done
 	w.exportButton.Disable()
+	w.exportPNGButton.Disable()
 	w.runReportButton.Disable()
 	defer func() {
 		w.runReportButton.Enable()
 		if len(w.taskReport) > 0 {
 			w.exportButton.Enable()
+			w.exportPNGButton.Enable()
 		}
+		w.charts.SetTaskReport(w.taskReport, dStart, dEnd)
 		w.Window.Content().Refresh()
 	}()
 	// Clear table
@@ -240,48 +312,171 @@ func (w *reportWindowData) doExport() {
 		).Show()
 		return
 	}
+	exportFormat := reportExportFormats[w.exportSelect.SelectedIndex()]
 	// Show file save dialog
-	dialog.ShowFileSave(w.exportReportAsCSV, w)
+	saveDialog := dialog.NewFileSave(func(writeCloser fyne.URIWriteCloser, dialogErr error) {
+		w.exportReport(exportFormat, writeCloser, dialogErr)
+	}, w)
+	saveDialog.SetFileName("report." + exportFormat.extension)
+	saveDialog.Show()
 }
 
-func (w *reportWindowData) exportReportAsCSV(writeCloser fyne.URIWriteCloser, dialogErr error) {
-	log := logger.GetFuncLogger(w.log, "exportReportAsCSV")
+func (w *reportWindowData) exportReport(exportFormat reportExportFormat, writeCloser fyne.URIWriteCloser, dialogErr error) {
+	log := logger.GetFuncLogger(w.log, "exportReport").
+		With().
+		Str("format", exportFormat.format).
+		Logger()
 	if dialogErr != nil {
 		log.Err(dialogErr).
-			Msg("error opening CSV file for writing")
+			Msg("error opening file for writing")
+		return
+	}
+	if writeCloser == nil {
 		return
 	}
-	// Write data to file
-	if writeCloser != nil {
-		// Collect data
-		csvData := make([][]string, 0)
-		csvData = append(csvData, csvTableHeader)
-		for _, taskReportData := range w.taskReport {
-			csvData = append(csvData, []string{
-				fmt.Sprintf("%d", taskReportData.TaskID),
-				taskReportData.TaskSynopsis,
-				taskReportData.StartDate.Time.Format(constants.TimestampDateLayout),
-				taskReportData.Duration().String(),
-			})
+	defer func() {
+		err := writeCloser.Close()
+		if err != nil {
+			log.Err(err).
+				Msg("error closing file")
 		}
-		csvOut := csv.NewWriter(writeCloser)
-		defer func() {
-			csvOut.Flush()
-			err := writeCloser.Close()
-			if err != nil {
-				log.Err(err).
-					Msg("error closing CSV file")
-			}
-		}()
-		err := csvOut.WriteAll(csvData)
+	}()
+	reportOut, err := cli.MarshalReport(w.taskReport, exportFormat.format)
+	if err == nil {
+		_, err = writeCloser.Write(reportOut)
+	}
+	if err != nil {
+		log.Err(err).
+			Msg("error exporting report")
+		dialog.ShowError(err, w)
+		return
+	}
+	dialog.ShowInformation("Export Successful", "The report data was exported successfully.", w)
+}
+
+func (w *reportWindowData) doExportPNG() {
+	// Sanity check that there is data to export
+	if len(w.taskReport) == 0 {
+		dialog.NewError(
+			fmt.Errorf("there is no data to export"),
+			w,
+		).Show()
+		return
+	}
+	// Show file save dialog
+	saveDialog := dialog.NewFileSave(w.exportChartsAsPNG, w)
+	saveDialog.SetFileName("report.png")
+	saveDialog.Show()
+}
+
+func (w *reportWindowData) exportChartsAsPNG(writeCloser fyne.URIWriteCloser, dialogErr error) {
+	log := logger.GetFuncLogger(w.log, "exportChartsAsPNG")
+	if dialogErr != nil {
+		log.Err(dialogErr).
+			Msg("error opening PNG file for writing")
+		return
+	}
+	if writeCloser == nil {
+		return
+	}
+	defer func() {
+		err := writeCloser.Close()
 		if err != nil {
 			log.Err(err).
-				Msg("error exporting data to CSV")
-			dialog.ShowError(err, w)
-			return
+				Msg("error closing PNG file")
+		}
+	}()
+	err := png.Encode(writeCloser, w.charts.Image())
+	if err != nil {
+		log.Err(err).
+			Msg("error exporting charts to PNG")
+		dialog.ShowError(err, w)
+		return
+	}
+	dialog.ShowInformation("Export Successful", "The report charts were exported successfully.", w) // i18n
+}
+
+// doPreset runs the report for a range of dates such as this week
+func (w *reportWindowData) doPreset(preset cli.DateRangePreset) {
+	log := logger.GetFuncLogger(w.log, "doPreset")
+	startDate, endDate, err := preset.Dates()
+	if err != nil {
+		log.Err(err).
+			Str("preset", preset.Name).
+			Msg("error getting the dates of the preset")
+		dialog.NewError(err, w).Show()
+		return
+	}
+	err = w.startDateBinding.Set(startDate.Format(constants.TimestampDateLayout))
+	if err == nil {
+		err = w.endDateBinding.Set(endDate.Format(constants.TimestampDateLayout))
+	}
+	if err != nil {
+		log.Err(err).
+			Msg("error setting date bindings")
+		return
+	}
+	w.doRunReport()
+}
+
+// showDatePicker shows a calendar below the button which sets the date of the binding
+func (w *reportWindowData) showDatePicker(dateBinding binding.String, button *widget.Button) {
+	log := logger.GetFuncLogger(w.log, "showDatePicker")
+	selected := time.Now()
+	dateString, err := dateBinding.Get()
+	if err == nil {
+		date, parseErr := time.Parse(constants.TimestampDateLayout, dateString)
+		if parseErr == nil {
+			selected = date
 		}
-		dialog.ShowInformation("Export Successful", "The report data was exported successfully.", w)
 	}
+	var popUp *widget.PopUp
+	calendar := widgets.NewCalendar(selected, func(date time.Time) {
+		popUp.Hide()
+		setErr := dateBinding.Set(date.Format(constants.TimestampDateLayout))
+		if setErr != nil {
+			log.Err(setErr).
+				Msg("error setting date binding")
+		}
+	})
+	popUp = widget.NewPopUp(calendar, w.Window.Canvas())
+	buttonPosition := fyne.CurrentApp().Driver().AbsolutePositionForObject(button)
+	popUp.ShowAtPosition(buttonPosition.AddXY(0, button.Size().Height))
+}
+
+// showTimesheets shows the timesheets that a row of the report adds up
+func (w *reportWindowData) showTimesheets(taskReportData models.TaskReportData) {
+	log := logger.GetFuncLogger(w.log, "showTimesheets")
+	day := now.With(taskReportData.StartDate.Time).BeginningOfDay()
+	timesheets, err := models.NewTimesheet().SearchStarted(day, now.With(day).EndOfDay())
+	if err != nil {
+		log.Err(err).
+			Uint("taskID", taskReportData.TaskID).
+			Msg(tterrors.ListTimesheetError)
+		dialog.NewError(err, w).Show()
+		return
+	}
+	grid := container.NewGridWithColumns(len(timesheetsHeader))
+	for _, header := range timesheetsHeader {
+		grid.Add(widget.NewLabelWithStyle(header, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+	}
+	for _, timesheet := range timesheets {
+		if timesheet.TaskID != taskReportData.TaskID || !timesheet.StopTime.Valid {
+			continue
+		}
+		grid.Add(widget.NewLabel(utils.FormatTimeOfDay(timesheet.StartTime, day)))
+		grid.Add(widget.NewLabel(utils.FormatTimeOfDay(timesheet.StopTime.Time, day)))
+		grid.Add(widget.NewLabel(config.FormatDuration(timesheet.StopTime.Time.Sub(timesheet.StartTime))))
+		grid.Add(widget.NewLabel(timesheet.Note))
+	}
+	timesheetsDialog := dialog.NewCustom(
+		fmt.Sprintf("%s on %s", taskReportData.TaskSynopsis, day.Format(config.DateFormat())), // i18n
+		"Close", // i18n
+		container.NewVScroll(grid),
+		w,
+	)
+	dialogs.ResizeDialogToWindowWithPadding(timesheetsDialog, w, dialogSizeOffset)
+	timesheetsDialog.Show()
 }
 
 func (w *reportWindowData) validateDateRange() (startDate time.Time, endDate time.Time, err error) {
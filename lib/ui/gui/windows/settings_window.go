@@ -0,0 +1,316 @@
+package windows
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/neflyte/timetracker/lib/config"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/profiles"
+	"github.com/neflyte/timetracker/lib/ui/icons"
+	"github.com/reactivex/rxgo/v2"
+	"github.com/rs/zerolog"
+)
+
+const (
+	settingsWindowEventChannelSize = 1
+	// settingsWindowMinimumWidth is the minimum width of the window in pixels; the descriptions of the settings are long
+	settingsWindowMinimumWidth = 560.0
+	// settingsWindowMinimumHeight is the minimum height of the window in pixels
+	settingsWindowMinimumHeight = 600.0
+)
+
+// SettingsWindowSettingsChangedEvent is sent after the settings are saved
+type SettingsWindowSettingsChangedEvent struct{}
+
+type settingsWindow interface {
+	windowBase
+	Show()
+	Hide()
+	Close()
+	Observable() rxgo.Observable
+}
+
+var _ fyne.Window = (*settingsWindowImpl)(nil)
+
+// settingsField is a form widget that edits one configuration setting
+type settingsField struct {
+	// widget is the widget that is shown in the form
+	widget fyne.Disableable
+	// value returns the value of the widget as it is saved in the configuration file
+	value func() string
+	// setValue shows the value of the setting in the widget
+	setValue func(value string)
+	// formItem is the row of the form that holds the widget
+	formItem *widget.FormItem
+	key      string
+	// hint is the text that is shown below the widget
+	hint string
+	// restart is true if the setting only takes effect after the app is started again
+	restart bool
+}
+
+type settingsWindowImpl struct {
+	fyne.Window
+	log           zerolog.Logger
+	container     *fyne.Container
+	profileSelect *widget.Select
+	eventChan     chan rxgo.Item
+	fields        []*settingsField
+	forms         []*widget.Form
+}
+
+func newSettingsWindow(app fyne.App) settingsWindow {
+	sw := &settingsWindowImpl{
+		log:       logger.GetStructLogger("settingsWindowImpl"),
+		eventChan: make(chan rxgo.Item, settingsWindowEventChannelSize),
+		Window:    app.NewWindow("Settings"), // i18n
+		fields:    make([]*settingsField, 0),
+		forms:     make([]*widget.Form, 0),
+	}
+	err := sw.Init()
+	if err != nil {
+		sw.log.
+			Err(err).
+			Msg("error initializing window")
+	}
+	return sw
+}
+
+func (s *settingsWindowImpl) Init() error {
+	s.profileSelect = widget.NewSelect([]string{}, nil)
+	databaseForm := widget.NewForm(
+		widget.NewFormItem("Profile", s.profileSelect),                      // i18n
+		s.newEntryField(config.KeyDatabase, "Database file", true).formItem, // i18n
+	)
+	tasksForm := widget.NewForm(
+		s.newCheckField(config.KeyTrayStopTaskConfirm, "Confirm when the tray stops a task", false).formItem,        // i18n
+		s.newEntryField(config.KeyTrayRecentTasks, "Recent tasks in the tray", false).formItem,                      // i18n
+		s.newEntryField(config.KeyDailyGoal, "Daily goal", false).formItem,                                          // i18n
+		s.newCheckField(config.KeyGUICloseWindowStopTask, "Close the window after stopping a task", false).formItem, // i18n
+	)
+	displayForm := widget.NewForm(
+		s.newEntryField(config.KeyTimestampFormat, "Timestamp format", false).formItem, // i18n
+		s.newEntryField(config.KeyDateFormat, "Date format", false).formItem,           // i18n
+		s.newSelectField(config.KeyDurationFormat, "Duration format", false).formItem,  // i18n
+		s.newSelectField(config.KeyWeekStart, "First day of the week", false).formItem, // i18n
+		s.newSelectField(config.KeyGUITheme, "Theme", false).formItem,                  // i18n
+		s.newSelectField(config.KeyTrayIcon, "Tray icon", false).formItem,              // i18n
+	)
+	notificationsForm := widget.NewForm(
+		s.newCheckField(config.KeyNotificationsEnabled, "Show notifications", false).formItem,        // i18n
+		s.newEntryField(config.KeyNotificationsQuietHoursStart, "Quiet hours start", false).formItem, // i18n
+		s.newEntryField(config.KeyNotificationsQuietHoursEnd, "Quiet hours end", false).formItem,     // i18n
+	)
+	loggingForm := widget.NewForm(
+		s.newSelectField(config.KeyLogLevel, "Log level", true).formItem,           // i18n
+		s.newCheckField(config.KeyLogConsole, "Log to the console", true).formItem, // i18n
+	)
+	s.forms = append(s.forms, databaseForm, tasksForm, displayForm, notificationsForm, loggingForm)
+	s.container = container.NewBorder(
+		nil,
+		container.NewHBox(
+			widget.NewButtonWithIcon("SAVE", theme.DocumentSaveIcon(), s.doSave), // i18n
+			widget.NewButtonWithIcon("CLOSE", theme.CancelIcon(), s.Hide),        // i18n
+		),
+		nil,
+		nil,
+		container.NewVScroll(container.NewVBox(
+			widget.NewCard("Database", "", databaseForm),           // i18n
+			widget.NewCard("Tasks", "", tasksForm),                 // i18n
+			widget.NewCard("Display", "", displayForm),             // i18n
+			widget.NewCard("Notifications", "", notificationsForm), // i18n
+			widget.NewCard("Logging", "", loggingForm),             // i18n
+		)),
+	)
+	s.Window.SetCloseIntercept(s.Hide)
+	s.Window.SetContent(s.container)
+	s.Window.SetIcon(icons.IconV2)
+	// resize the window to fit the content
+	resizeToMinimum(s.Window, settingsWindowMinimumWidth, settingsWindowMinimumHeight)
+	return nil
+}
+
+func (s *settingsWindowImpl) Hide() {
+	s.Window.Hide()
+}
+
+func (s *settingsWindowImpl) Close() {
+	s.Window.Close()
+}
+
+// Show shows the current settings
+func (s *settingsWindowImpl) Show() {
+	s.loadSettings()
+	s.Window.Show()
+}
+
+func (s *settingsWindowImpl) Observable() rxgo.Observable {
+	return rxgo.FromEventSource(s.eventChan)
+}
+
+// newEntryField adds a field that edits a setting with free text
+func (s *settingsWindowImpl) newEntryField(key string, label string, restart bool) *settingsField {
+	entry := widget.NewEntry()
+	return s.addField(&settingsField{
+		key:      key,
+		widget:   entry,
+		value:    func() string { return entry.Text },
+		setValue: entry.SetText,
+		formItem: widget.NewFormItem(label, entry),
+		restart:  restart,
+	})
+}
+
+// newCheckField adds a field that turns a setting on or off
+func (s *settingsWindowImpl) newCheckField(key string, label string, restart bool) *settingsField {
+	check := widget.NewCheck("", nil)
+	return s.addField(&settingsField{
+		key:    key,
+		widget: check,
+		value:  func() string { return strconv.FormatBool(check.Checked) },
+		setValue: func(value string) {
+			checked, err := strconv.ParseBool(value)
+			check.SetChecked(err == nil && checked)
+		},
+		formItem: widget.NewFormItem(label, check),
+		restart:  restart,
+	})
+}
+
+// newSelectField adds a field that chooses one of the allowed values of a setting
+func (s *settingsWindowImpl) newSelectField(key string, label string, restart bool) *settingsField {
+	options := make([]string, 0)
+	if k, ok := config.Lookup(key); ok {
+		options = k.Values
+	}
+	if key == config.KeyWeekStart {
+		options = weekdayOptions()
+	}
+	selectWidget := widget.NewSelect(options, nil)
+	return s.addField(&settingsField{
+		key:    key,
+		widget: selectWidget,
+		value:  func() string { return selectWidget.Selected },
+		setValue: func(value string) {
+			selectWidget.SetSelected(strings.ToLower(value))
+		},
+		formItem: widget.NewFormItem(label, selectWidget),
+		restart:  restart,
+	})
+}
+
+func (s *settingsWindowImpl) addField(field *settingsField) *settingsField {
+	if k, ok := config.Lookup(field.key); ok {
+		field.hint = k.Description
+	}
+	if field.restart {
+		field.hint += " (applied after restarting)" // i18n
+	}
+	s.fields = append(s.fields, field)
+	return field
+}
+
+// loadSettings shows the current value of each setting. A setting that is set in the environment cannot be changed
+// here since the environment takes precedence over the configuration file.
+func (s *settingsWindowImpl) loadSettings() {
+	log := logger.GetFuncLogger(s.log, "loadSettings")
+	for _, field := range s.fields {
+		field.setValue(config.GetString(field.key))
+		if config.Source(field.key) == config.SourceEnvironment {
+			field.widget.Disable()
+			field.formItem.HintText = fmt.Sprintf("Set by the %s environment variable", config.EnvironmentVariable(field.key)) // i18n
+			continue
+		}
+		field.widget.Enable()
+		field.formItem.HintText = field.hint
+	}
+	for _, form := range s.forms {
+		form.Refresh()
+	}
+	profileList, err := profiles.List()
+	if err != nil {
+		log.Err(err).
+			Msg(tterrors.ListProfileError)
+		dialog.NewError(err, s.Window).Show()
+		return
+	}
+	profileNames := make([]string, 0, len(profileList))
+	for _, profile := range profileList {
+		profileNames = append(profileNames, profile.Name)
+	}
+	s.profileSelect.Options = profileNames
+	current, err := profiles.Current()
+	if err != nil {
+		log.Err(err).
+			Msg(tterrors.ListProfileError)
+		return
+	}
+	s.profileSelect.SetSelected(current.Name)
+}
+
+// doSave saves the settings that were changed. The selected profile is changed last since the app switches to the
+// database of the profile as soon as it is selected.
+func (s *settingsWindowImpl) doSave() {
+	log := logger.GetFuncLogger(s.log, "doSave")
+	needsRestart := false
+	for _, field := range s.fields {
+		value := field.value()
+		if config.Source(field.key) == config.SourceEnvironment || value == config.GetString(field.key) {
+			continue
+		}
+		err := config.Set(field.key, value)
+		if err != nil {
+			log.Err(err).
+				Str("key", field.key).
+				Msg(tterrors.SetConfigError)
+			dialog.NewError(fmt.Errorf("%s: %w", field.formItem.Text, err), s.Window).Show()
+			return
+		}
+		needsRestart = needsRestart || field.restart
+	}
+	current, err := profiles.Current()
+	if err != nil {
+		log.Err(err).
+			Msg(tterrors.ListProfileError)
+		dialog.NewError(err, s.Window).Show()
+		return
+	}
+	if s.profileSelect.Selected != "" && s.profileSelect.Selected != current.Name {
+		err = profiles.Use(s.profileSelect.Selected)
+	}
+	if err != nil {
+		log.Err(err).
+			Str("profile", s.profileSelect.Selected).
+			Msg(tterrors.UseProfileError)
+		dialog.NewError(err, s.Window).Show()
+		return
+	}
+	s.eventChan <- rxgo.Of(SettingsWindowSettingsChangedEvent{})
+	if needsRestart {
+		dialog.NewInformation(
+			"Settings saved", // i18n
+			"Some settings are applied after restarting Timetracker", // i18n
+			s.Window,
+		).Show()
+		return
+	}
+	s.Hide()
+}
+
+// weekdayOptions returns the values of the first day of the week setting
+func weekdayOptions() []string {
+	options := make([]string, 0)
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		options = append(options, strings.ToLower(weekday.String()))
+	}
+	return options
+}
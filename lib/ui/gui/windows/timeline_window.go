@@ -0,0 +1,340 @@
+package windows
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/jinzhu/now"
+	"github.com/neflyte/timetracker/lib/config"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/models"
+	ttmonitor "github.com/neflyte/timetracker/lib/monitor"
+	"github.com/neflyte/timetracker/lib/ui/gui/dialogs"
+	"github.com/neflyte/timetracker/lib/ui/gui/widgets"
+	"github.com/neflyte/timetracker/lib/ui/icons"
+	"github.com/neflyte/timetracker/lib/utils"
+	"github.com/reactivex/rxgo/v2"
+	"github.com/rs/zerolog"
+)
+
+const (
+	timelineWindowEventChannelSize = 2
+	// timelineWindowMinimumWidth is the minimum width of the window in pixels; the week view has seven columns
+	timelineWindowMinimumWidth = 800.0
+	// timelineWindowMinimumHeight is the minimum height of the window in pixels
+	timelineWindowMinimumHeight = 600.0
+	// timelineWindowScrollHour is the hour that the timeline is scrolled to when the window is shown
+	timelineWindowScrollHour = 7
+	// timelineWindowRefreshInterval is how often the running timesheet grows while the window is shown
+	timelineWindowRefreshInterval = 30 * time.Second
+)
+
+var (
+	timelineWindowViewDay  = "Day"  // i18n
+	timelineWindowViewWeek = "Week" // i18n
+)
+
+// TimelineWindowTimesheetsChangedEvent is sent after a timesheet is created or changed in the timeline
+type TimelineWindowTimesheetsChangedEvent struct{}
+
+type timelineWindow interface {
+	windowBase
+	Show()
+	ShowDay(day time.Time)
+	Hide()
+	Close()
+	Observable() rxgo.Observable
+	RefreshTimesheets()
+}
+
+var _ fyne.Window = (*timelineWindowImpl)(nil)
+
+type timelineWindowImpl struct {
+	day time.Time
+	fyne.Window
+	log                zerolog.Logger
+	monitor            ttmonitor.Service
+	container          *fyne.Container
+	rangeLabel         *widget.Label
+	viewRadio          *widget.RadioGroup
+	timeline           *widgets.Timeline
+	scroll             *container.Scroll
+	taskSelector       *widgets.TaskSelector
+	eventChan          chan rxgo.Item
+	refreshQuitChan    chan bool
+	refreshTicker      *time.Ticker
+	unsubscribeMonitor func()
+}
+
+func newTimelineWindow(app fyne.App, monitor ttmonitor.Service) timelineWindow {
+	tw := &timelineWindowImpl{
+		log:       logger.GetStructLogger("timelineWindowImpl"),
+		eventChan: make(chan rxgo.Item, timelineWindowEventChannelSize),
+		Window:    app.NewWindow("Timeline"), // i18n
+		day:       now.BeginningOfDay(),
+		monitor:   monitor,
+	}
+	err := tw.Init()
+	if err != nil {
+		tw.log.
+			Err(err).
+			Msg("error initializing window")
+	}
+	return tw
+}
+
+func (t *timelineWindowImpl) Init() error {
+	t.rangeLabel = widget.NewLabel("")
+	t.rangeLabel.TextStyle = fyne.TextStyle{Bold: true}
+	t.viewRadio = widget.NewRadioGroup([]string{timelineWindowViewDay, timelineWindowViewWeek}, func(string) {
+		t.loadTimesheets()
+	})
+	t.viewRadio.Horizontal = true
+	t.viewRadio.Required = true
+	t.viewRadio.Selected = timelineWindowViewDay
+	t.timeline = widgets.NewTimeline()
+	t.timeline.Observable().ForEach(
+		t.handleTimelineEvent,
+		utils.ObservableErrorHandler("timeline", t.log),
+		utils.ObservableCloseHandler("timeline", t.log),
+	)
+	t.taskSelector = widgets.NewTaskSelector()
+	t.scroll = container.NewVScroll(t.timeline)
+	t.container = container.NewBorder(
+		container.NewBorder(
+			nil,
+			nil,
+			container.NewHBox(
+				widget.NewButtonWithIcon("", theme.NavigateBackIcon(), t.doPrevious),
+				t.rangeLabel,
+				widget.NewButtonWithIcon("", theme.NavigateNextIcon(), t.doNext),
+				widget.NewButton("Today", t.doToday), // i18n
+			),
+			t.viewRadio,
+		),
+		widget.NewLabel("Drag a block or its edges to change its times; tap a gap to fill it with a task"), // i18n
+		nil,
+		nil,
+		t.scroll,
+	)
+	if t.monitor != nil {
+		observable, unsubscribe := t.monitor.Subscribe()
+		t.unsubscribeMonitor = unsubscribe
+		observable.ForEach(
+			t.handleMonitorServiceEvent,
+			utils.ObservableErrorHandler("monitor", t.log),
+			utils.ObservableCloseHandler("monitor", t.log),
+		)
+	}
+	t.Window.SetCloseIntercept(t.Hide)
+	t.Window.SetContent(t.container)
+	t.Window.SetIcon(icons.IconV2)
+	resizeToMinimum(t.Window, timelineWindowMinimumWidth, timelineWindowMinimumHeight)
+	return nil
+}
+
+// Hide hides the window and stops growing the running timesheet
+func (t *timelineWindowImpl) Hide() {
+	t.stopRefreshLoop()
+	t.Window.Hide()
+}
+
+func (t *timelineWindowImpl) Close() {
+	t.stopRefreshLoop()
+	if t.unsubscribeMonitor != nil {
+		t.unsubscribeMonitor()
+		t.unsubscribeMonitor = nil
+	}
+	t.Window.Close()
+}
+
+// Show shows the timeline of today
+func (t *timelineWindowImpl) Show() {
+	t.ShowDay(now.BeginningOfDay())
+}
+
+// ShowDay shows the timeline of the specified day, or of its week in the week view
+func (t *timelineWindowImpl) ShowDay(day time.Time) {
+	t.day = now.With(day).BeginningOfDay()
+	t.loadTimesheets()
+	t.Window.Show()
+	t.scroll.Offset = fyne.NewPos(0, t.timeline.OffsetOf(timelineWindowScrollHour))
+	t.scroll.Refresh()
+	t.startRefreshLoop()
+}
+
+func (t *timelineWindowImpl) Observable() rxgo.Observable {
+	return rxgo.FromEventSource(t.eventChan)
+}
+
+// RefreshTimesheets reloads the timesheets, such as after switching to a different database
+func (t *timelineWindowImpl) RefreshTimesheets() {
+	t.loadTimesheets()
+}
+
+// shownRange returns the first day that is shown and the number of days
+func (t *timelineWindowImpl) shownRange() (time.Time, int) {
+	if t.viewRadio.Selected == timelineWindowViewWeek {
+		return now.With(t.day).BeginningOfWeek(), 7 //nolint:gomnd
+	}
+	return t.day, 1
+}
+
+// loadTimesheets draws the timesheets that started on the shown days
+func (t *timelineWindowImpl) loadTimesheets() {
+	log := logger.GetFuncLogger(t.log, "loadTimesheets")
+	start, days := t.shownRange()
+	end := start.AddDate(0, 0, days)
+	if days == 1 {
+		t.rangeLabel.SetText(start.Format(config.DateFormat()))
+	} else {
+		t.rangeLabel.SetText(fmt.Sprintf("%s - %s", start.Format(config.DateFormat()), end.AddDate(0, 0, -1).Format(config.DateFormat())))
+	}
+	search := models.NewTimesheet()
+	search.Data().StartTime = start
+	timesheets, err := search.SearchDateRange(false)
+	if err != nil {
+		log.Err(err).
+			Msg(tterrors.ListTimesheetError)
+		dialog.NewError(err, t.Window).Show()
+		return
+	}
+	shown := make([]models.TimesheetData, 0, len(timesheets))
+	for _, timesheet := range timesheets {
+		if timesheet.StartTime.Before(end) {
+			shown = append(shown, timesheet)
+		}
+	}
+	t.timeline.SetTimesheets(start, days, shown, time.Now())
+}
+
+func (t *timelineWindowImpl) doPrevious() {
+	_, days := t.shownRange()
+	t.day = t.day.AddDate(0, 0, -days)
+	t.loadTimesheets()
+}
+
+func (t *timelineWindowImpl) doNext() {
+	_, days := t.shownRange()
+	t.day = t.day.AddDate(0, 0, days)
+	t.loadTimesheets()
+}
+
+func (t *timelineWindowImpl) doToday() {
+	t.day = now.BeginningOfDay()
+	t.loadTimesheets()
+}
+
+// startRefreshLoop starts growing the running timesheet
+func (t *timelineWindowImpl) startRefreshLoop() {
+	if t.refreshTicker != nil {
+		return
+	}
+	t.refreshTicker = time.NewTicker(timelineWindowRefreshInterval)
+	t.refreshQuitChan = make(chan bool, 1)
+	go t.refreshLoop(t.refreshTicker, t.refreshQuitChan)
+}
+
+// stopRefreshLoop stops growing the running timesheet
+func (t *timelineWindowImpl) stopRefreshLoop() {
+	if t.refreshTicker == nil {
+		return
+	}
+	t.refreshTicker.Stop()
+	t.refreshQuitChan <- true
+	t.refreshTicker = nil
+}
+
+// refreshLoop moves the end of the running timesheet to the current time
+func (t *timelineWindowImpl) refreshLoop(ticker *time.Ticker, quitChan chan bool) {
+	for {
+		select {
+		case <-ticker.C:
+			t.timeline.SetCurrentTime(time.Now())
+		case <-quitChan:
+			return
+		}
+	}
+}
+
+func (t *timelineWindowImpl) handleMonitorServiceEvent(item interface{}) {
+	switch item.(type) {
+	case ttmonitor.TaskStartedEvent,
+		ttmonitor.TaskStoppedEvent,
+		ttmonitor.TaskSwitchedEvent,
+		ttmonitor.ServiceUpdateEvent:
+		t.loadTimesheets()
+	}
+}
+
+func (t *timelineWindowImpl) handleTimelineEvent(item interface{}) {
+	switch event := item.(type) {
+	case widgets.TimelineBlockChangedEvent:
+		t.saveTimesheet(event.Timesheet)
+	case widgets.TimelineGapTappedEvent:
+		t.doFillGap(event)
+	}
+}
+
+// doFillGap asks for the task that fills the gap
+func (t *timelineWindowImpl) doFillGap(event widgets.TimelineGapTappedEvent) {
+	t.taskSelector.Reset()
+	t.taskSelector.FilterTasks()
+	selectTaskDialog := dialog.NewCustomConfirm(
+		fmt.Sprintf("Fill %s - %s with a task", event.Start.Format("15:04"), event.Stop.Format("15:04")), // i18n
+		"FILL",   // i18n
+		"CANCEL", // i18n
+		t.taskSelector,
+		func(selected bool) {
+			if selected {
+				t.handleFillGapResult(event)
+			}
+		},
+		t.Window,
+	)
+	dialogs.ResizeDialogToWindowWithPadding(selectTaskDialog, t.Window, dialogSizeOffset)
+	selectTaskDialog.Show()
+}
+
+func (t *timelineWindowImpl) handleFillGapResult(event widgets.TimelineGapTappedEvent) {
+	selectedTask := t.taskSelector.Selected()
+	if selectedTask == nil {
+		return
+	}
+	timesheet := models.NewTimesheetData()
+	timesheet.Task = *selectedTask.Data()
+	timesheet.TaskID = selectedTask.Data().ID
+	timesheet.StartTime = event.Start
+	timesheet.StopTime.Time = event.Stop
+	timesheet.StopTime.Valid = true
+	t.saveTimesheet(timesheet)
+}
+
+// saveTimesheet validates and saves the timesheet, and draws the timesheets again
+func (t *timelineWindowImpl) saveTimesheet(timesheet models.TimesheetData) {
+	log := logger.GetFuncLogger(t.log, "saveTimesheet")
+	err := timesheet.Validate(time.Now())
+	if err == nil {
+		if timesheet.ID == 0 {
+			err = timesheet.Create()
+		} else {
+			err = timesheet.Update()
+		}
+	}
+	// Draw the timesheets again either way so that a block which could not be moved goes back
+	t.loadTimesheets()
+	if err != nil {
+		log.Err(err).
+			Str("timesheet", timesheet.String()).
+			Msg(tterrors.SaveTimesheetError)
+		dialog.NewError(err, t.Window).Show()
+		return
+	}
+	t.eventChan <- rxgo.Of(TimelineWindowTimesheetsChangedEvent{})
+}
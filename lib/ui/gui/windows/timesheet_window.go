@@ -0,0 +1,305 @@
+package windows
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/jinzhu/now"
+	"github.com/neflyte/timetracker/lib/config"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/models"
+	"github.com/neflyte/timetracker/lib/ui/gui/widgets"
+	"github.com/neflyte/timetracker/lib/ui/icons"
+	"github.com/neflyte/timetracker/lib/utils"
+	"github.com/reactivex/rxgo/v2"
+	"github.com/rs/zerolog"
+)
+
+const (
+	timesheetWindowEventChannelSize = 2
+	// timesheetWindowMinimumWidth is the minimum width of the window in pixels; the rows of timesheets are wide
+	timesheetWindowMinimumWidth = 640.0
+)
+
+// TimesheetWindowTimesheetsChangedEvent is sent after a timesheet is created, changed, split or deleted
+type TimesheetWindowTimesheetsChangedEvent struct{}
+
+// TimesheetWindowShowTimelineEvent is sent to show the timeline of the day that is shown in the window
+type TimesheetWindowShowTimelineEvent struct {
+	Day time.Time
+}
+
+type timesheetWindow interface {
+	windowBase
+	Show()
+	Hide()
+	Close()
+	Observable() rxgo.Observable
+	RefreshTimesheets()
+}
+
+var _ fyne.Window = (*timesheetWindowImpl)(nil)
+
+type timesheetWindowImpl struct {
+	day time.Time
+	fyne.Window
+	log             zerolog.Logger
+	container       *fyne.Container
+	dayLabel        *widget.Label
+	previousButton  *widget.Button
+	nextButton      *widget.Button
+	todayButton     *widget.Button
+	addButton       *widget.Button
+	timelineButton  *widget.Button
+	timesheetEditor *widgets.TimesheetEditor
+	eventChan       chan rxgo.Item
+}
+
+func newTimesheetWindow(app fyne.App) timesheetWindow {
+	tw := &timesheetWindowImpl{
+		log:       logger.GetStructLogger("timesheetWindowImpl"),
+		eventChan: make(chan rxgo.Item, timesheetWindowEventChannelSize),
+		Window:    app.NewWindow("Timesheets"), // i18n
+		day:       now.BeginningOfDay(),
+	}
+	err := tw.Init()
+	if err != nil {
+		tw.log.
+			Err(err).
+			Msg("error initializing window")
+	}
+	return tw
+}
+
+func (t *timesheetWindowImpl) Init() error {
+	t.previousButton = widget.NewButtonWithIcon("", theme.NavigateBackIcon(), t.doPreviousDay)
+	t.nextButton = widget.NewButtonWithIcon("", theme.NavigateNextIcon(), t.doNextDay)
+	t.todayButton = widget.NewButton("Today", t.doToday)                                               // i18n
+	t.addButton = widget.NewButtonWithIcon("Add", theme.ContentAddIcon(), t.doAddTimesheet)            // i18n
+	t.timelineButton = widget.NewButtonWithIcon("Timeline", theme.ViewRestoreIcon(), t.doShowTimeline) // i18n
+	t.dayLabel = widget.NewLabel("")
+	t.dayLabel.TextStyle = fyne.TextStyle{Bold: true}
+	t.timesheetEditor = widgets.NewTimesheetEditor()
+	t.timesheetEditor.Observable().ForEach(
+		t.handleTimesheetEditorEvent,
+		utils.ObservableErrorHandler("timesheetEditor", t.log),
+		utils.ObservableCloseHandler("timesheetEditor", t.log),
+	)
+	t.container = container.NewBorder(
+		container.NewBorder(
+			nil,
+			nil,
+			container.NewHBox(t.previousButton, t.dayLabel, t.nextButton, t.todayButton),
+			container.NewHBox(t.timelineButton, t.addButton),
+		),
+		nil,
+		nil,
+		nil,
+		container.NewVScroll(t.timesheetEditor),
+	)
+	t.Window.SetCloseIntercept(t.Hide)
+	t.Window.SetContent(t.container)
+	t.Window.SetIcon(icons.IconV2)
+	// resize the window to fit the content
+	resizeToMinimum(t.Window, timesheetWindowMinimumWidth, minimumWindowHeight)
+	return nil
+}
+
+func (t *timesheetWindowImpl) Hide() {
+	t.Window.Hide()
+}
+
+func (t *timesheetWindowImpl) Close() {
+	t.Window.Close()
+}
+
+// Show shows the timesheets of today
+func (t *timesheetWindowImpl) Show() {
+	t.day = now.BeginningOfDay()
+	t.loadDay()
+	t.Window.Show()
+}
+
+func (t *timesheetWindowImpl) Observable() rxgo.Observable {
+	return rxgo.FromEventSource(t.eventChan)
+}
+
+// RefreshTimesheets reloads the timesheets, such as after switching to a different database
+func (t *timesheetWindowImpl) RefreshTimesheets() {
+	t.loadDay()
+}
+
+// loadDay shows the timesheets that started on the selected day along with any problems they have
+func (t *timesheetWindowImpl) loadDay() {
+	log := logger.GetFuncLogger(t.log, "loadDay")
+	t.dayLabel.SetText(t.day.Format(config.DateFormat()))
+	timesheets, err := models.NewTimesheet().SearchStarted(t.day, now.With(t.day).EndOfDay())
+	if err != nil {
+		log.Err(err).
+			Msg(tterrors.ListTimesheetError)
+		dialog.NewError(err, t.Window).Show()
+		return
+	}
+	tasks, err := models.NewTask().LoadAll(false)
+	if err != nil {
+		log.Err(err).
+			Msg(tterrors.ListTaskError)
+		dialog.NewError(err, t.Window).Show()
+		return
+	}
+	taskSynopses := make([]string, len(tasks))
+	for idx := range tasks {
+		taskSynopses[idx] = tasks[idx].Synopsis
+	}
+	t.timesheetEditor.SetTimesheets(t.day, timesheets, taskSynopses)
+	currentTime := time.Now()
+	for idx := range timesheets {
+		t.timesheetEditor.SetRowError(idx, timesheets[idx].Validate(currentTime))
+	}
+}
+
+func (t *timesheetWindowImpl) doPreviousDay() {
+	t.day = now.With(t.day.AddDate(0, 0, -1)).BeginningOfDay()
+	t.loadDay()
+}
+
+func (t *timesheetWindowImpl) doNextDay() {
+	t.day = now.With(t.day.AddDate(0, 0, 1)).BeginningOfDay()
+	t.loadDay()
+}
+
+func (t *timesheetWindowImpl) doToday() {
+	t.day = now.BeginningOfDay()
+	t.loadDay()
+}
+
+func (t *timesheetWindowImpl) doAddTimesheet() {
+	t.timesheetEditor.AddTimesheet()
+}
+
+func (t *timesheetWindowImpl) doShowTimeline() {
+	t.eventChan <- rxgo.Of(TimesheetWindowShowTimelineEvent{Day: t.day})
+}
+
+func (t *timesheetWindowImpl) handleTimesheetEditorEvent(item interface{}) {
+	switch event := item.(type) {
+	case widgets.TimesheetEditorSaveEvent:
+		t.doSaveTimesheet(event)
+	case widgets.TimesheetEditorSplitEvent:
+		t.doSplitTimesheet(event.Timesheet)
+	case widgets.TimesheetEditorDeleteEvent:
+		t.doDeleteTimesheet(event.Timesheet)
+	}
+}
+
+// doSaveTimesheet validates and saves the timesheet; if it cannot be saved, the reason is shown below its row
+func (t *timesheetWindowImpl) doSaveTimesheet(event widgets.TimesheetEditorSaveEvent) {
+	log := logger.GetFuncLogger(t.log, "doSaveTimesheet")
+	timesheet := event.Timesheet
+	err := timesheet.Validate(time.Now())
+	if err != nil {
+		t.timesheetEditor.SetRowError(event.Row, err)
+		return
+	}
+	if timesheet.ID == 0 {
+		err = timesheet.Create()
+	} else {
+		err = timesheet.Update()
+	}
+	if err != nil {
+		log.Err(err).
+			Str("timesheet", timesheet.String()).
+			Msg(tterrors.SaveTimesheetError)
+		t.timesheetEditor.SetRowError(event.Row, fmt.Errorf("%s: %w", tterrors.SaveTimesheetError, err))
+		return
+	}
+	t.timesheetsChanged()
+}
+
+// doSplitTimesheet asks for the time at which to split the timesheet, suggesting the middle of it
+func (t *timesheetWindowImpl) doSplitTimesheet(timesheet models.TimesheetData) {
+	stopTime := time.Now()
+	if timesheet.StopTime.Valid {
+		stopTime = timesheet.StopTime.Time
+	}
+	splitAt := timesheet.StartTime.Add(stopTime.Sub(timesheet.StartTime) / 2).Truncate(time.Minute) //nolint:gomnd
+	splitEntry := widget.NewEntry()
+	splitEntry.SetText(utils.FormatTimeOfDay(splitAt, t.day))
+	dialog.NewForm(
+		"Split timesheet", // i18n
+		"SPLIT",           // i18n
+		"CANCEL",          // i18n
+		[]*widget.FormItem{
+			widget.NewFormItem("Split at", splitEntry), // i18n
+		},
+		func(split bool) {
+			if split {
+				t.handleSplitTimesheetResult(timesheet, splitEntry.Text)
+			}
+		},
+		t.Window,
+	).Show()
+}
+
+func (t *timesheetWindowImpl) handleSplitTimesheetResult(timesheet models.TimesheetData, splitAt string) {
+	log := logger.GetFuncLogger(t.log, "handleSplitTimesheetResult")
+	at, err := utils.ParseTimeOfDay(splitAt, t.day)
+	if err == nil {
+		_, err = timesheet.Split(at)
+	}
+	if err != nil {
+		log.Err(err).
+			Str("timesheet", timesheet.String()).
+			Msg(tterrors.SplitTimesheetError)
+		dialog.NewError(err, t.Window).Show()
+		return
+	}
+	t.timesheetsChanged()
+}
+
+func (t *timesheetWindowImpl) doDeleteTimesheet(timesheet models.TimesheetData) {
+	stopTime := "running" // i18n
+	if timesheet.StopTime.Valid {
+		stopTime = utils.FormatTimeOfDay(timesheet.StopTime.Time, t.day)
+	}
+	dialog.NewConfirm(
+		"Delete timesheet", // i18n
+		fmt.Sprintf(
+			"Are you sure you want to delete this timesheet?\n\n%s: %s - %s", // i18n
+			timesheet.Task.Synopsis,
+			utils.FormatTimeOfDay(timesheet.StartTime, t.day),
+			stopTime,
+		),
+		func(deleted bool) {
+			if deleted {
+				t.handleDeleteTimesheetResult(timesheet)
+			}
+		},
+		t.Window,
+	).Show()
+}
+
+func (t *timesheetWindowImpl) handleDeleteTimesheetResult(timesheet models.TimesheetData) {
+	log := logger.GetFuncLogger(t.log, "handleDeleteTimesheetResult")
+	err := timesheet.Delete()
+	if err != nil {
+		log.Err(err).
+			Str("timesheet", timesheet.String()).
+			Msg(tterrors.DeleteTimesheetError)
+		dialog.NewError(err, t.Window).Show()
+		return
+	}
+	t.timesheetsChanged()
+}
+
+// timesheetsChanged reloads the timesheets and sends a refresh event
+func (t *timesheetWindowImpl) timesheetsChanged() {
+	t.loadDay()
+	t.eventChan <- rxgo.Of(TimesheetWindowTimesheetsChangedEvent{})
+}
@@ -9,11 +9,13 @@ import (
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"github.com/neflyte/timetracker/lib/config"
 	"github.com/neflyte/timetracker/lib/constants"
 	tterrors "github.com/neflyte/timetracker/lib/errors"
 	"github.com/neflyte/timetracker/lib/logger"
 	"github.com/neflyte/timetracker/lib/models"
 	ttmonitor "github.com/neflyte/timetracker/lib/monitor"
+	"github.com/neflyte/timetracker/lib/startup"
 	"github.com/neflyte/timetracker/lib/ui/gui/dialogs"
 	"github.com/neflyte/timetracker/lib/ui/gui/widgets"
 	"github.com/neflyte/timetracker/lib/ui/icons"
@@ -33,8 +35,12 @@ type TimetrackerWindow interface {
 	ShowAbout()
 	ShowWithError(err error)
 	ShowWithManageWindow()
+	ShowWithTimesheetWindow()
+	ShowWithSettingsWindow()
+	ShowWithDaySummary()
 	ShowAndStopRunningTask()
 	ShowAndDisplayCreateAndStartDialog()
+	Reconnect(profileName string)
 }
 
 // timetrackerWindowData is the struct underlying the TimetrackerWindow interface
@@ -45,9 +51,13 @@ type timetrackerWindowData struct {
 	fyne.Window
 	selectedTask        models.Task
 	mngWindowV2         manageWindowV2
+	tsWindow            timesheetWindow
+	tlWindow            timelineWindow
+	stWindow            settingsWindow
 	toast               tttoast.Toast
 	monitor             ttmonitor.Service
 	monitorQuitChan     chan bool
+	unsubscribeMonitor  func()
 	runningTimesheet    *models.TimesheetData
 	container           *fyne.Container
 	elapsedTimeQuitChan chan bool
@@ -55,6 +65,7 @@ type timetrackerWindowData struct {
 	taskSelector        *widgets.TaskSelector
 	app                 *fyne.App
 	compactUI           *widgets.CompactUI
+	undoToast           *widgets.UndoToast
 	appVersion          string
 	selectedTaskMtx     sync.RWMutex
 	elapsedTimeRunning  bool
@@ -91,6 +102,10 @@ func (t *timetrackerWindowData) Init() error {
 	}
 	// Initialize monitor service
 	t.monitor = ttmonitor.NewService(t.monitorQuitChan)
+	t.monitor.EnableAutomaticBackups()
+	// Set up the timeline window, which follows the running task through the monitor service, and hide it
+	t.tlWindow = newTimelineWindow(*t.app, t.monitor)
+	t.tlWindow.Hide()
 	// Initialize observables
 	t.initObservables()
 	// Initialize window display data
@@ -109,9 +124,11 @@ func (t *timetrackerWindowData) initUI() error {
 	t.compactUI = widgets.NewCompactUI()
 	t.createNewTaskAndStartDialog = dialogs.NewCreateAndStartTaskDialog((*t.app).Preferences(), t.createAndStartTaskDialogCallback, t.Window)
 	t.taskSelector = widgets.NewTaskSelector()
+	t.undoToast = widgets.NewUndoToast(t.doUndo)
 	t.container = container.NewPadded(t.compactUI)
 	t.Window.SetContent(t.container)
 	t.Window.SetIcon(icons.IconV2)
+	t.setTitle(startup.ActiveProfile())
 	// get the size of the content with everything visible
 	siz := t.Window.Content().Size()
 	// Resize the window to the minimum size^H^H^H^H^H height
@@ -130,9 +147,15 @@ func (t *timetrackerWindowData) initUI() error {
 	// Set up the manage window and hide it
 	t.mngWindowV2 = newManageWindowV2(*t.app)
 	t.mngWindowV2.Hide()
+	// Set up the timesheet window and hide it
+	t.tsWindow = newTimesheetWindow(*t.app)
+	t.tsWindow.Hide()
 	// Also set up the report window and hide it
 	t.rptWindow = newReportWindow(*t.app)
 	t.rptWindow.Hide()
+	// Set up the settings window and hide it
+	t.stWindow = newSettingsWindow(*t.app)
+	t.stWindow.Hide()
 	return nil
 }
 
@@ -147,7 +170,24 @@ func (t *timetrackerWindowData) initObservables() {
 		utils.ObservableErrorHandler("taskSelector", t.log),
 		utils.ObservableCloseHandler("taskSelector", t.log),
 	)
-	t.monitor.Observable().ForEach(
+	t.tsWindow.Observable().ForEach(
+		t.handleTimesheetWindowEvent,
+		utils.ObservableErrorHandler("tsWindow", t.log),
+		utils.ObservableCloseHandler("tsWindow", t.log),
+	)
+	t.tlWindow.Observable().ForEach(
+		t.handleTimelineWindowEvent,
+		utils.ObservableErrorHandler("tlWindow", t.log),
+		utils.ObservableCloseHandler("tlWindow", t.log),
+	)
+	t.stWindow.Observable().ForEach(
+		t.handleSettingsWindowEvent,
+		utils.ObservableErrorHandler("stWindow", t.log),
+		utils.ObservableCloseHandler("stWindow", t.log),
+	)
+	monitorEvents, unsubscribe := t.monitor.Subscribe()
+	t.unsubscribeMonitor = unsubscribe
+	monitorEvents.ForEach(
 		t.handleMonitorServiceEvent,
 		utils.ObservableErrorHandler("monitor", t.log),
 		utils.ObservableCloseHandler("monitor", t.log),
@@ -265,7 +305,6 @@ func (t *timetrackerWindowData) doStartSelectedTask() {
 			Msg("a timesheet is running; ask the user if it should stop")
 		stopTaskDialog := dialogs.NewStopTaskDialog(
 			runningTS.Data().Task,
-			(*t.app).Preferences(),
 			t.handleStopTaskDialogResult,
 			t.Window,
 		)
@@ -319,6 +358,7 @@ func (t *timetrackerWindowData) doStartTask() {
 	t.monitor.SetRunningTimesheet(timesheet)
 	// Refresh task list
 	t.refreshTaskList()
+	t.showUndoToast()
 }
 
 // doStopTask attempts to stop the running task, if there is any.
@@ -347,6 +387,39 @@ func (t *timetrackerWindowData) doStopTask() {
 	)
 	t.setRunningTimesheet(nil)
 	t.monitor.SetRunningTimesheet(nil)
+	t.showUndoToast()
+}
+
+// showUndoToast shows the last action with a button to undo it
+func (t *timetrackerWindowData) showUndoToast() {
+	log := logger.GetFuncLogger(t.log, "showUndoToast")
+	last, err := models.NewJournal().Last()
+	if err != nil {
+		log.Err(err).
+			Msg("unable to load the last action")
+		return
+	}
+	t.undoToast.ShowMessage(t.Window.Canvas(), last.Description)
+}
+
+// doUndo undoes the last action and shows its result
+func (t *timetrackerWindowData) doUndo() {
+	log := logger.GetFuncLogger(t.log, "doUndo")
+	undone, err := models.NewJournal().Undo()
+	if err != nil {
+		log.Err(err).
+			Msg(tterrors.UndoError)
+		dialog.NewError(err, t.Window).Show()
+		return
+	}
+	t.notify("Action undone", undone.Description) // i18n
+	// The running task and the timesheets may have changed
+	err = t.initWindowData()
+	if err != nil {
+		dialog.NewError(err, t.Window).Show()
+	}
+	t.tsWindow.RefreshTimesheets()
+	t.tlWindow.RefreshTimesheets()
 }
 
 func (t *timetrackerWindowData) doStopAndStartTask() {
@@ -358,6 +431,43 @@ func (t *timetrackerWindowData) doManageTasksV2() {
 	t.mngWindowV2.Show()
 }
 
+func (t *timetrackerWindowData) doTimesheets() {
+	t.tsWindow.Show()
+}
+
+// handleTimesheetWindowEvent refreshes the recently started tasks and the timeline after the timesheets are
+// changed, and shows the timeline of a day
+func (t *timetrackerWindowData) handleTimesheetWindowEvent(item interface{}) {
+	switch event := item.(type) {
+	case TimesheetWindowTimesheetsChangedEvent:
+		t.refreshTaskList()
+		t.tlWindow.RefreshTimesheets()
+	case TimesheetWindowShowTimelineEvent:
+		t.tlWindow.ShowDay(event.Day)
+	}
+}
+
+// handleTimelineWindowEvent refreshes the recently started tasks and the timesheets after the timeline is changed
+func (t *timetrackerWindowData) handleTimelineWindowEvent(item interface{}) {
+	if _, ok := item.(TimelineWindowTimesheetsChangedEvent); ok {
+		t.refreshTaskList()
+		t.tsWindow.RefreshTimesheets()
+	}
+}
+
+func (t *timetrackerWindowData) doSettings() {
+	t.stWindow.Show()
+}
+
+// handleSettingsWindowEvent shows the timesheets again after the settings are saved since the formats of times and
+// durations may have changed
+func (t *timetrackerWindowData) handleSettingsWindowEvent(item interface{}) {
+	if _, ok := item.(SettingsWindowSettingsChangedEvent); ok {
+		t.tsWindow.RefreshTimesheets()
+		t.tlWindow.RefreshTimesheets()
+	}
+}
+
 func (t *timetrackerWindowData) doSelectTask() {
 	t.taskSelector.Reset()
 	t.taskSelector.FilterTasks()
@@ -420,8 +530,12 @@ func (t *timetrackerWindowData) handleCompactUIEvent(item interface{}) {
 		t.doCreateAndStartTask()
 	case widgets.CompactUIManageEvent:
 		t.doManageTasksV2()
+	case widgets.CompactUITimesheetsEvent:
+		t.doTimesheets()
 	case widgets.CompactUIReportEvent:
 		t.doReport()
+	case widgets.CompactUISettingsEvent:
+		t.doSettings()
 	case widgets.CompactUIAboutEvent:
 		t.doAbout()
 	case widgets.CompactUIQuitEvent:
@@ -451,7 +565,24 @@ func (t *timetrackerWindowData) handleCompactUITaskEvent(event widgets.CompactUI
 
 func (t *timetrackerWindowData) handleMonitorServiceEvent(item interface{}) {
 	log := logger.GetFuncLogger(t.log, "handleMonitorServiceEvent")
-	if _, ok := item.(ttmonitor.ServiceUpdateEvent); ok {
+	switch event := item.(type) {
+	case ttmonitor.TaskStartedEvent:
+		t.setRunningTimesheet(&event.After)
+	case ttmonitor.TaskSwitchedEvent:
+		t.setRunningTimesheet(&event.After)
+	case ttmonitor.TaskStoppedEvent:
+		if t.runningTimesheet != nil {
+			t.setRunningTimesheet(nil)
+		}
+	case ttmonitor.ErrorRaisedEvent:
+		log.Err(event.Error).
+			Msg("error from monitor service")
+		// TODO: should we do anything else here?
+	case ttmonitor.ServiceUpdateEvent:
+		// The database may have been replaced, so reload everything
+		t.refreshTaskList()
+		t.mngWindowV2.RefreshTasks()
+		t.tsWindow.RefreshTimesheets()
 		switch t.monitor.TimesheetStatus() {
 		case constants.TimesheetStatusError:
 			tsErr := t.monitor.TimesheetError()
@@ -459,7 +590,6 @@ func (t *timetrackerWindowData) handleMonitorServiceEvent(item interface{}) {
 				log.Err(tsErr).
 					Msg("error from TimesheetStatus")
 			}
-			// TODO: should we do anything else here?
 		case constants.TimesheetStatusIdle:
 			// if we're running, stop.
 			if t.runningTimesheet != nil {
@@ -503,7 +633,7 @@ func (t *timetrackerWindowData) maybeStopRunningTask(stopTask bool) {
 	// Stop the task
 	t.doStopTask()
 	// Check if we should close the main window
-	shouldCloseMainWindow := (*t.app).Preferences().BoolWithFallback(constants.PrefKeyCloseWindowStopTask, false)
+	shouldCloseMainWindow := config.GetBool(config.KeyGUICloseWindowStopTask)
 	if shouldCloseMainWindow {
 		t.Close()
 	}
@@ -625,7 +755,7 @@ func (t *timetrackerWindowData) notify(title string, contents string) {
 }
 
 func (t *timetrackerWindowData) elapsedTime(since time.Time) string {
-	return time.Since(since).Truncate(time.Second).String()
+	return config.FormatDuration(time.Since(since).Truncate(time.Second))
 }
 
 func (t *timetrackerWindowData) isTimesheetOpen() bool {
@@ -663,7 +793,6 @@ func (t *timetrackerWindowData) ShowAndStopRunningTask() {
 	t.Show()
 	dialogs.NewStopTaskDialog(
 		runningTS.Data().Task,
-		(*t.app).Preferences(),
 		t.maybeStopRunningTask,
 		t.Window,
 	).Show()
@@ -675,6 +804,24 @@ func (t *timetrackerWindowData) ShowWithManageWindow() {
 	t.doManageTasksV2()
 }
 
+// ShowWithTimesheetWindow shows the main window followed by the Timesheets window
+func (t *timetrackerWindowData) ShowWithTimesheetWindow() {
+	t.Show()
+	t.doTimesheets()
+}
+
+// ShowWithSettingsWindow shows the main window followed by the Settings window
+func (t *timetrackerWindowData) ShowWithSettingsWindow() {
+	t.Show()
+	t.doSettings()
+}
+
+// ShowWithDaySummary shows the main window followed by the Report window with the report of today
+func (t *timetrackerWindowData) ShowWithDaySummary() {
+	t.Show()
+	t.rptWindow.ShowToday()
+}
+
 // ShowWithError shows the main window and then shows an error dialog
 func (t *timetrackerWindowData) ShowWithError(err error) {
 	t.Show()
@@ -693,11 +840,48 @@ func (t *timetrackerWindowData) ShowAndDisplayCreateAndStartDialog() {
 	t.createNewTaskAndStartDialog.Show()
 }
 
-// Hide hides the main window and the manage window
+// Reconnect shows the data from the database of the profile after it has been opened
+func (t *timetrackerWindowData) Reconnect(profileName string) {
+	log := logger.GetFuncLogger(t.log, "Reconnect")
+	t.setTitle(profileName)
+	if t.monitor.IsRunning() {
+		// The monitor sends a ServiceUpdateEvent once it has reconnected, which reloads the window
+		t.monitor.Reconnect()
+		return
+	}
+	t.mngWindowV2.RefreshTasks()
+	t.tsWindow.RefreshTimesheets()
+	t.tlWindow.RefreshTimesheets()
+	err := t.initWindowData()
+	if err != nil {
+		log.Err(err).
+			Msg("error loading window data")
+	}
+}
+
+// setTitle sets the window title to include the name of the profile
+func (t *timetrackerWindowData) setTitle(profileName string) {
+	if profileName == "" {
+		t.Window.SetTitle("Timetracker") // i18n
+		return
+	}
+	t.Window.SetTitle(fmt.Sprintf("Timetracker - %s", profileName)) // i18n
+}
+
+// Hide hides the main window, the manage window, the timesheet window, the timeline window and the settings window
 func (t *timetrackerWindowData) Hide() {
 	if t.mngWindowV2 != nil {
 		t.mngWindowV2.Hide()
 	}
+	if t.tsWindow != nil {
+		t.tsWindow.Hide()
+	}
+	if t.tlWindow != nil {
+		t.tlWindow.Hide()
+	}
+	if t.stWindow != nil {
+		t.stWindow.Hide()
+	}
 	t.Window.Hide()
 }
 
@@ -713,6 +897,12 @@ func (t *timetrackerWindowData) Close() {
 	if t.monitor.IsRunning() {
 		t.monitor.Stop()
 	}
+	// Stop receiving its events
+	if t.unsubscribeMonitor != nil {
+		t.unsubscribeMonitor()
+	}
+	// The timeline window has its own subscription to the monitor service
+	t.tlWindow.Close()
 	// Close the window
 	t.Window.Close()
 	// Quit
@@ -0,0 +1,270 @@
+package icons
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	// dynamicIconSize is the width and height of a dynamic icon in pixels
+	dynamicIconSize = 64
+	// progressSteps is the number of steps that the progress ring fills in; each step is rendered once
+	progressSteps = 40
+	// ringWidth is the width of the progress ring in pixels
+	ringWidth = 7
+	// ringGap is the space between the progress ring and the icon in pixels
+	ringGap = 2
+	// badgeHeight is the height of the background of the elapsed minutes in pixels
+	badgeHeight = 30
+	// badgePadding is the space on each side of the elapsed minutes in pixels
+	badgePadding = 2
+	// maxElapsedMinutes is the most minutes that are shown; longer times are shown in hours
+	maxElapsedMinutes = 99
+	// fontDPI is the resolution that the font sizes are in
+	fontDPI = 72
+
+	icnsHeaderSize = 8
+	icoHeaderSize  = 6
+	icoEntrySize   = 16
+)
+
+var (
+	pngSignature = []byte("\x89PNG\r\n\x1a\n")
+	icnsMagic    = []byte("icns")
+
+	ringColor      = color.NRGBA{R: 0x4c, G: 0xaf, B: 0x50, A: 0xff}
+	ringTrackColor = color.NRGBA{R: 0x80, G: 0x80, B: 0x80, A: 0x60}
+	badgeColor     = color.NRGBA{R: 0x20, G: 0x20, B: 0x20, A: 0xd0}
+	badgeTextColor = color.White
+)
+
+// DynamicIcons renders icons that show the progress toward the daily goal, or the minutes elapsed since a task was
+// started, on top of one of the static icons. Each rendered icon is cached, since the same icon is shown for a
+// while and is rendered again whenever it is shown.
+type DynamicIcons interface {
+	Progress(base *fyne.StaticResource, progress float64) ([]byte, error)
+	Elapsed(base *fyne.StaticResource, elapsed time.Duration) ([]byte, error)
+}
+
+type dynamicIconsImpl struct {
+	bases    map[string]image.Image
+	rendered map[string][]byte
+	font     *opentype.Font
+	mtx      sync.Mutex
+}
+
+// NewDynamicIcons creates a new DynamicIcons with an empty cache
+func NewDynamicIcons() DynamicIcons {
+	return &dynamicIconsImpl{
+		bases:    make(map[string]image.Image),
+		rendered: make(map[string][]byte),
+		mtx:      sync.Mutex{},
+	}
+}
+
+// Progress returns the base icon inside a ring that is filled by the specified fraction; the fraction is rounded
+// to one of a fixed number of steps
+func (d *dynamicIconsImpl) Progress(base *fyne.StaticResource, progress float64) ([]byte, error) {
+	step := int(math.Round(math.Max(0, math.Min(1, progress)) * progressSteps))
+	key := fmt.Sprintf("%s:progress:%d", base.StaticName, step)
+	return d.render(key, base, func(baseImage image.Image) (image.Image, error) {
+		return renderProgress(baseImage, float64(step)/progressSteps), nil
+	})
+}
+
+// Elapsed returns the base icon with the elapsed time drawn on it in minutes, or in hours after 99 minutes
+func (d *dynamicIconsImpl) Elapsed(base *fyne.StaticResource, elapsed time.Duration) ([]byte, error) {
+	text := ElapsedText(elapsed)
+	key := fmt.Sprintf("%s:elapsed:%s", base.StaticName, text)
+	return d.render(key, base, func(baseImage image.Image) (image.Image, error) {
+		if d.font == nil {
+			parsedFont, err := opentype.Parse(gobold.TTF)
+			if err != nil {
+				return nil, err
+			}
+			d.font = parsedFont
+		}
+		return renderText(baseImage, d.font, text)
+	})
+}
+
+// render returns the cached icon, or renders and caches it
+func (d *dynamicIconsImpl) render(key string, base *fyne.StaticResource, renderFunc func(image.Image) (image.Image, error)) ([]byte, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	if content, ok := d.rendered[key]; ok {
+		return content, nil
+	}
+	baseImage, ok := d.bases[base.StaticName]
+	if !ok {
+		decoded, err := decodeIcon(base.StaticContent)
+		if err != nil {
+			return nil, err
+		}
+		baseImage = decoded
+		d.bases[base.StaticName] = baseImage
+	}
+	renderedImage, err := renderFunc(baseImage)
+	if err != nil {
+		return nil, err
+	}
+	content, err := encodeIcon(renderedImage)
+	if err != nil {
+		return nil, err
+	}
+	d.rendered[key] = content
+	return content, nil
+}
+
+// ElapsedText returns the elapsed time as it is drawn on an icon: the number of minutes, or the number of hours
+// followed by h after 99 minutes
+func ElapsedText(elapsed time.Duration) string {
+	minutes := int(elapsed / time.Minute)
+	if minutes < 0 {
+		minutes = 0
+	}
+	if minutes <= maxElapsedMinutes {
+		return strconv.Itoa(minutes)
+	}
+	return strconv.Itoa(int(elapsed/time.Hour)) + "h"
+}
+
+// renderProgress draws the base icon inside a ring that is filled clockwise from the top
+func renderProgress(base image.Image, progress float64) image.Image {
+	icon := image.NewNRGBA(image.Rect(0, 0, dynamicIconSize, dynamicIconSize))
+	inset := ringWidth + ringGap
+	draw.CatmullRom.Scale(icon, image.Rect(inset, inset, dynamicIconSize-inset, dynamicIconSize-inset), base, base.Bounds(), draw.Over, nil)
+	center := float64(dynamicIconSize) / 2
+	outerRadius := center
+	innerRadius := center - ringWidth
+	for y := 0; y < dynamicIconSize; y++ {
+		for x := 0; x < dynamicIconSize; x++ {
+			dx := float64(x) + 0.5 - center
+			dy := float64(y) + 0.5 - center
+			distance := math.Hypot(dx, dy)
+			// Anti-alias the edges of the ring by the fraction of the pixel that is inside it
+			coverage := math.Min(clamp(outerRadius-distance+0.5), clamp(distance-innerRadius+0.5))
+			if coverage <= 0 {
+				continue
+			}
+			// The angle is measured clockwise from the top of the icon
+			angle := math.Atan2(dx, -dy)
+			if angle < 0 {
+				angle += 2 * math.Pi
+			}
+			ringPixelColor := ringTrackColor
+			if angle/(2*math.Pi) < progress {
+				ringPixelColor = ringColor
+			}
+			ringPixelColor.A = uint8(float64(ringPixelColor.A) * coverage)
+			draw.Draw(icon, image.Rect(x, y, x+1, y+1), image.NewUniform(ringPixelColor), image.Point{}, draw.Over)
+		}
+	}
+	return icon
+}
+
+// renderText draws the base icon with the text on a dark background across the bottom of it. The font size is
+// reduced until the text fits.
+func renderText(base image.Image, textFont *opentype.Font, text string) (image.Image, error) {
+	icon := image.NewNRGBA(image.Rect(0, 0, dynamicIconSize, dynamicIconSize))
+	draw.CatmullRom.Scale(icon, icon.Bounds(), base, base.Bounds(), draw.Over, nil)
+	badge := image.Rect(0, dynamicIconSize-badgeHeight, dynamicIconSize, dynamicIconSize)
+	draw.Draw(icon, badge, image.NewUniform(badgeColor), image.Point{}, draw.Over)
+	var face font.Face
+	for size := float64(badgeHeight); size > 1; size-- {
+		sizedFace, err := opentype.NewFace(textFont, &opentype.FaceOptions{Size: size, DPI: fontDPI, Hinting: font.HintingFull})
+		if err != nil {
+			return nil, err
+		}
+		if font.MeasureString(sizedFace, text).Ceil() <= dynamicIconSize-2*badgePadding {
+			face = sizedFace
+			break
+		}
+	}
+	if face == nil {
+		return nil, fmt.Errorf("%s does not fit on the icon", text)
+	}
+	metrics := face.Metrics()
+	textHeight := metrics.Ascent + metrics.Descent
+	drawer := font.Drawer{
+		Dst:  icon,
+		Src:  image.NewUniform(badgeTextColor),
+		Face: face,
+		Dot: fixed.Point26_6{
+			X: (fixed.I(dynamicIconSize) - font.MeasureString(face, text)) / 2,
+			Y: fixed.I(badge.Min.Y) + (fixed.I(badgeHeight)-textHeight)/2 + metrics.Ascent,
+		},
+	}
+	drawer.DrawString(text)
+	return icon, nil
+}
+
+func clamp(value float64) float64 {
+	return math.Max(0, math.Min(1, value))
+}
+
+// decodeIcon decodes a PNG image, or the first PNG image of an ICO or ICNS file
+func decodeIcon(content []byte) (image.Image, error) {
+	pngContent, err := findPNG(content)
+	if err != nil {
+		return nil, err
+	}
+	return png.Decode(bytes.NewReader(pngContent))
+}
+
+// findPNG returns the PNG image of an icon. The ICO and ICNS icons of the app each contain their images as PNG.
+func findPNG(content []byte) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(content, pngSignature):
+		return content, nil
+	case bytes.HasPrefix(content, icnsMagic):
+		// An ICNS file is a header followed by entries of a 4-byte type, a 4-byte big-endian length and the data
+		for offset := icnsHeaderSize; offset+icnsHeaderSize <= len(content); {
+			length := int(binary.BigEndian.Uint32(content[offset+4 : offset+icnsHeaderSize]))
+			if length < icnsHeaderSize || offset+length > len(content) {
+				break
+			}
+			data := content[offset+icnsHeaderSize : offset+length]
+			if bytes.HasPrefix(data, pngSignature) {
+				return data, nil
+			}
+			offset += length
+		}
+	case len(content) >= icoHeaderSize && binary.LittleEndian.Uint16(content[0:2]) == 0 && binary.LittleEndian.Uint16(content[2:4]) == 1:
+		// An ICO file is a header, a directory of 16-byte entries that each end with the size and offset of an
+		// image, and the images
+		count := int(binary.LittleEndian.Uint16(content[4:icoHeaderSize]))
+		for idx := 0; idx < count; idx++ {
+			entry := icoHeaderSize + idx*icoEntrySize
+			if entry+icoEntrySize > len(content) {
+				break
+			}
+			size := int(binary.LittleEndian.Uint32(content[entry+8 : entry+12]))
+			offset := int(binary.LittleEndian.Uint32(content[entry+12 : entry+icoEntrySize]))
+			if offset+size > len(content) {
+				continue
+			}
+			data := content[offset : offset+size]
+			if bytes.HasPrefix(data, pngSignature) {
+				return data, nil
+			}
+		}
+	}
+	return nil, errors.New("the icon does not contain a PNG image")
+}
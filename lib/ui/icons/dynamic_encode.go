@@ -0,0 +1,19 @@
+//go:build !windows
+
+package icons
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+)
+
+// encodeIcon encodes a rendered icon as a PNG image
+func encodeIcon(icon image.Image) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	err := png.Encode(buf, icon)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,33 @@
+package icons
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/png"
+)
+
+const (
+	icoTypeIcon     = 1
+	icoColorPlanes  = 1
+	icoBitsPerPixel = 32
+)
+
+// encodeIcon encodes a rendered icon as an ICO file that contains the icon as a PNG image, since the tray only
+// loads icons from ICO files on Windows
+func encodeIcon(icon image.Image) ([]byte, error) {
+	pngBuf := new(bytes.Buffer)
+	err := png.Encode(pngBuf, icon)
+	if err != nil {
+		return nil, err
+	}
+	bounds := icon.Bounds()
+	buf := new(bytes.Buffer)
+	// The header and directory entry are written to a buffer, so they cannot fail
+	_ = binary.Write(buf, binary.LittleEndian, []uint16{0, icoTypeIcon, 1})
+	_ = binary.Write(buf, binary.LittleEndian, []uint8{uint8(bounds.Dx()), uint8(bounds.Dy()), 0, 0})
+	_ = binary.Write(buf, binary.LittleEndian, []uint16{icoColorPlanes, icoBitsPerPixel})
+	_ = binary.Write(buf, binary.LittleEndian, []uint32{uint32(pngBuf.Len()), icoHeaderSize + icoEntrySize})
+	buf.Write(pngBuf.Bytes())
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,73 @@
+package icons
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/color"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_ElapsedText(t *testing.T) {
+	require.Equal(t, "0", ElapsedText(30*time.Second))
+	require.Equal(t, "25", ElapsedText(25*time.Minute+59*time.Second))
+	require.Equal(t, "99", ElapsedText(99*time.Minute))
+	require.Equal(t, "1h", ElapsedText(100*time.Minute))
+	require.Equal(t, "12h", ElapsedText(12*time.Hour+30*time.Minute))
+}
+
+func TestUnit_DynamicIcons_Progress(t *testing.T) {
+	dynamicIcons := NewDynamicIcons()
+	empty, err := dynamicIcons.Progress(IconV2, 0)
+	require.Nil(t, err)
+	half, err := dynamicIcons.Progress(IconV2, 0.5)
+	require.Nil(t, err)
+	require.NotEqual(t, empty, half)
+	// Nearby progress is rounded to the same step and comes from the cache
+	almostHalf, err := dynamicIcons.Progress(IconV2, 0.501)
+	require.Nil(t, err)
+	require.Equal(t, &half[0], &almostHalf[0])
+	halfImage, err := decodeIcon(half)
+	require.Nil(t, err)
+	require.Equal(t, dynamicIconSize, halfImage.Bounds().Dx())
+	// The top right of the ring is filled and the bottom left is not
+	right := color.NRGBAModel.Convert(halfImage.At(dynamicIconSize-ringWidth/2-1, dynamicIconSize/2-1)).(color.NRGBA)
+	left := color.NRGBAModel.Convert(halfImage.At(ringWidth/2, dynamicIconSize/2)).(color.NRGBA)
+	require.Equal(t, ringColor, right)
+	require.NotEqual(t, ringColor, left)
+}
+
+func TestUnit_DynamicIcons_Elapsed(t *testing.T) {
+	dynamicIcons := NewDynamicIcons()
+	icon, err := dynamicIcons.Elapsed(IconV2, 42*time.Minute)
+	require.Nil(t, err)
+	iconImage, err := decodeIcon(icon)
+	require.Nil(t, err)
+	require.Equal(t, dynamicIconSize, iconImage.Bounds().Dy())
+	_, err = dynamicIcons.Elapsed(&fyne.StaticResource{StaticName: "broken", StaticContent: []byte("not an icon")}, time.Minute)
+	require.NotNil(t, err)
+}
+
+func TestUnit_FindPNG_ICO(t *testing.T) {
+	pngContent, err := findPNG(IconV2.StaticContent)
+	require.Nil(t, err)
+	ico := new(bytes.Buffer)
+	require.Nil(t, binary.Write(ico, binary.LittleEndian, []uint16{0, 1, 1, 0, 0, 1, 32}))
+	require.Nil(t, binary.Write(ico, binary.LittleEndian, []uint32{uint32(len(pngContent)), icoHeaderSize + icoEntrySize}))
+	ico.Write(pngContent)
+	found, err := findPNG(ico.Bytes())
+	require.Nil(t, err)
+	require.Equal(t, pngContent, found)
+	icns := new(bytes.Buffer)
+	icns.WriteString("icns")
+	require.Nil(t, binary.Write(icns, binary.BigEndian, uint32(2*icnsHeaderSize+len(pngContent))))
+	icns.WriteString("ic09")
+	require.Nil(t, binary.Write(icns, binary.BigEndian, uint32(icnsHeaderSize+len(pngContent))))
+	icns.Write(pngContent)
+	found, err = findPNG(icns.Bytes())
+	require.Nil(t, err)
+	require.Equal(t, pngContent, found)
+}
@@ -5,8 +5,10 @@ package toast
 import (
 	"os"
 	"path"
+	"time"
 
 	"github.com/gen2brain/beeep"
+	"github.com/neflyte/timetracker/lib/config"
 	"github.com/neflyte/timetracker/lib/logger"
 	"github.com/neflyte/timetracker/lib/ui/icons"
 	"github.com/rs/zerolog"
@@ -32,6 +34,12 @@ func NewToast() Toast {
 
 func (t *impl) Notify(title string, description string) error {
 	log := logger.GetFuncLogger(t.logger, "Notify")
+	if !config.NotificationsAllowed(time.Now()) {
+		log.Debug().
+			Str("title", title).
+			Msg("notifications are disabled or it is quiet hours; not sending notification")
+		return nil
+	}
 	err := t.ensureIcon()
 	if err != nil {
 		log.Err(err).
@@ -7,7 +7,9 @@ import (
 	"path"
 	"strings"
 	"syscall"
+	"time"
 
+	"github.com/neflyte/timetracker/lib/config"
 	"github.com/neflyte/timetracker/lib/logger"
 	"github.com/neflyte/timetracker/lib/ui/icons"
 	"github.com/rs/zerolog"
@@ -37,6 +39,12 @@ func NewToast() Toast {
 
 func (t *impl) Notify(title string, description string) error {
 	log := logger.GetFuncLogger(t.logger, "Notify")
+	if !config.NotificationsAllowed(time.Now()) {
+		log.Debug().
+			Str("title", title).
+			Msg("notifications are disabled or it is quiet hours; not sending notification")
+		return nil
+	}
 	toastArgs := []string{
 		"-NoLogo", "-NoProfile", "-NonInteractive", "-WindowStyle", "Hidden", "-File", t.scriptPath,
 		"-AppId", "Timetracker",
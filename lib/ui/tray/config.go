@@ -2,71 +2,113 @@ package tray
 
 import (
 	"errors"
-	"fmt"
 	"os"
 	"path"
+	"strconv"
 
-	"github.com/neflyte/timetracker/lib/constants"
+	"github.com/neflyte/timetracker/lib/config"
 	"github.com/neflyte/timetracker/lib/logger"
 	"github.com/spf13/viper"
 )
 
 const (
-	keyStopTaskConfirm = "stop-task-confirm"
+	legacyConfigFileName     = "timetracker-tray.yaml"
+	legacyConfigSuffix       = ".migrated"
+	legacyKeyStopTaskConfirm = "stop-task-confirm"
 )
 
-func init() {
-	// Set up config file parameters
-	viper.SetConfigName("timetracker-tray")
-	viper.SetConfigType("yaml")
-	// Set config file paths:
-	// 1. User's config directory
-	userConfDir, err := os.UserConfigDir()
+var (
+	configWatcher config.Watcher
+)
+
+// readConfig reads the app configuration after moving any settings from the old tray configuration file into it
+func readConfig() error {
+	log := logger.GetFuncLogger(trayLogger, "readConfig")
+	err := migrateLegacyConfig()
 	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "error reading user config directory: %s\n", err.Error())
-	} else {
-		userTimetrackerConfDir := path.Join(userConfDir, "timetracker")
-		// Make sure the directory exists before we add it
-		mkdirErr := os.MkdirAll(userTimetrackerConfDir, constants.ConfigDirectoryMode)
-		if mkdirErr != nil {
-			_, _ = fmt.Fprintf(os.Stderr, "error creating configuration directory '%s': %s\n", userTimetrackerConfDir, mkdirErr.Error())
-		} else {
-			viper.AddConfigPath(userTimetrackerConfDir)
-		}
+		log.Err(err).
+			Msg("error migrating tray configuration file")
 	}
-	// 2. Current directory
-	viper.AddConfigPath(".")
-	// Set default config values
-	viper.SetDefault(keyStopTaskConfirm, true) // Confirm when stopping an active task
-	// Ensure the config file exists
-	err = viper.SafeWriteConfig()
+	err = config.Load()
 	if err != nil {
-		var viperAlreadyExistsError viper.ConfigFileAlreadyExistsError
-		errOk := errors.As(err, &viperAlreadyExistsError)
-		if !errOk {
-			_, _ = fmt.Fprintf(os.Stderr, "error creating config file: %s\n", err.Error())
-		}
+		log.Err(err).
+			Msg("error reading configuration file")
+		return err
 	}
+	return nil
 }
 
-func readConfig() error {
-	log := logger.GetFuncLogger(trayLogger, "readConfig")
-	err := viper.ReadInConfig()
+// watchConfig starts watching for settings that are saved by another app, such as the GUI's Settings window
+func watchConfig() {
+	log := logger.GetFuncLogger(trayLogger, "watchConfig")
+	watcher, err := config.NewWatcher()
 	if err != nil {
-		if !errors.Is(err, viper.ConfigFileNotFoundError{}) {
-			log.Err(err).Msg("error reading configuration file")
-			return err
-		}
+		log.Err(err).
+			Msg("unable to watch for configuration changes")
+		return
 	}
-	return nil
+	configWatcher = watcher
+}
+
+// configChanges returns the channel that receives a value after the configuration has been reloaded
+func configChanges() <-chan bool {
+	if configWatcher == nil {
+		return nil
+	}
+	return configWatcher.Changes()
 }
 
-func writeConfig() error {
-	log := logger.GetFuncLogger(trayLogger, "writeConfig")
-	err := viper.WriteConfig()
+// cleanupConfigWatcher stops watching for configuration changes
+func cleanupConfigWatcher() {
+	log := logger.GetFuncLogger(trayLogger, "cleanupConfigWatcher")
+	if configWatcher == nil {
+		return
+	}
+	err := configWatcher.Close()
+	if err != nil {
+		log.Err(err).
+			Msg("error closing configuration watcher")
+	}
+}
+
+// handleConfigChange shows the reloaded settings in the menu
+func handleConfigChange() {
+	if config.GetBool(config.KeyTrayStopTaskConfirm) {
+		mTrayOptionConfirmStopTask.Check()
+	} else {
+		mTrayOptionConfirmStopTask.Uncheck()
+	}
+	updateElapsedTime()
+	updateTaskMenus()
+	updateSummary()
+}
+
+// migrateLegacyConfig copies the settings in timetracker-tray.yaml into the shared configuration file, and then
+// renames the old file so that it is only migrated once
+func migrateLegacyConfig() error {
+	log := logger.GetFuncLogger(trayLogger, "migrateLegacyConfig")
+	userConfigDir, err := os.UserConfigDir()
 	if err != nil {
-		log.Err(err).Msg("error writing configuration file")
 		return err
 	}
-	return nil
+	legacyConfigFile := path.Join(userConfigDir, "timetracker", legacyConfigFileName)
+	legacyConfig := viper.New()
+	legacyConfig.SetConfigFile(legacyConfigFile)
+	err = legacyConfig.ReadInConfig()
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if legacyConfig.IsSet(legacyKeyStopTaskConfirm) && config.Source(config.KeyTrayStopTaskConfirm) == config.SourceDefault {
+		err = config.Set(config.KeyTrayStopTaskConfirm, strconv.FormatBool(legacyConfig.GetBool(legacyKeyStopTaskConfirm)))
+		if err != nil {
+			return err
+		}
+	}
+	log.Info().
+		Str("file", legacyConfigFile).
+		Msg("migrated tray configuration file")
+	return os.Rename(legacyConfigFile, legacyConfigFile+legacyConfigSuffix)
 }
@@ -0,0 +1,70 @@
+package tray
+
+import (
+	"bytes"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/systray"
+	"github.com/neflyte/timetracker/lib/config"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/ui/icons"
+)
+
+var (
+	dynamicIcons = icons.NewDynamicIcons()
+	// statusIcon is the static icon of the timesheet status
+	statusIcon *fyne.StaticResource
+	// runningSince is the start time of the running task; it is zero when a task is not running
+	runningSince time.Time
+	// shownIcon is the content of the icon that the tray shows
+	shownIcon []byte
+	iconMtx   sync.Mutex
+)
+
+// setStatusIcon shows the icon of the timesheet status. The start time of the running task is zero when a task
+// is not running.
+func setStatusIcon(icon *fyne.StaticResource, startTime time.Time) {
+	iconMtx.Lock()
+	statusIcon = icon
+	runningSince = startTime
+	iconMtx.Unlock()
+	refreshIcon()
+}
+
+// refreshIcon shows the static icon of the timesheet status, or draws the progress toward the daily goal or the
+// elapsed time of the running task on it, as set by the tray icon setting. The static icon is shown if the icon
+// cannot be drawn.
+func refreshIcon() {
+	log := logger.GetFuncLogger(trayLogger, "refreshIcon")
+	iconMtx.Lock()
+	defer iconMtx.Unlock()
+	if statusIcon == nil {
+		return
+	}
+	content := statusIcon.StaticContent
+	var err error
+	switch config.GetString(config.KeyTrayIcon) {
+	case config.TrayIconProgress:
+		if statusIcon != icons.IconV2Error && config.DailyGoal() > 0 {
+			content, err = dynamicIcons.Progress(statusIcon, float64(trackedToday())/float64(config.DailyGoal()))
+		}
+	case config.TrayIconElapsed:
+		if !runningSince.IsZero() {
+			content, err = dynamicIcons.Elapsed(statusIcon, time.Since(runningSince))
+		}
+	}
+	if err != nil {
+		log.Err(err).
+			Str("icon", statusIcon.StaticName).
+			Msg("error drawing the tray icon; showing the static icon")
+		content = statusIcon.StaticContent
+	}
+	// Setting the same icon again makes some trays flicker
+	if bytes.Equal(content, shownIcon) {
+		return
+	}
+	systray.SetIcon(content)
+	shownIcon = content
+}
@@ -0,0 +1,102 @@
+package tray
+
+import (
+	"fmt"
+	"sync"
+
+	"fyne.io/systray"
+	"github.com/neflyte/timetracker/lib/models"
+)
+
+const (
+	menuClickChanSize = 1
+)
+
+// menuDispatcher runs the handler of a menu item in mainLoop whenever the item is clicked, so that mainLoop does
+// not need a case for every menu item
+type menuDispatcher struct {
+	clicks chan func()
+}
+
+func newMenuDispatcher() *menuDispatcher {
+	return &menuDispatcher{
+		clicks: make(chan func(), menuClickChanSize),
+	}
+}
+
+// Handle calls the handler whenever the menu item is clicked
+func (d *menuDispatcher) Handle(item *systray.MenuItem, handler func()) {
+	go func() {
+		for range item.ClickedCh {
+			d.clicks <- handler
+		}
+	}()
+}
+
+// Clicks returns the channel that receives the handler of each menu item that is clicked
+func (d *menuDispatcher) Clicks() <-chan func() {
+	return d.clicks
+}
+
+// taskMenu is a submenu that lists tasks and starts the task that is clicked. Menu items can't be removed, so
+// they are added as they are needed and hidden when there are fewer tasks.
+type taskMenu struct {
+	parent     *systray.MenuItem
+	dispatcher *menuDispatcher
+	onClick    func(synopsis string)
+	items      []*systray.MenuItem
+	synopses   []string
+	mtx        sync.Mutex
+}
+
+func newTaskMenu(parent *systray.MenuItem, dispatcher *menuDispatcher, onClick func(synopsis string)) *taskMenu {
+	return &taskMenu{
+		parent:     parent,
+		dispatcher: dispatcher,
+		onClick:    onClick,
+		items:      make([]*systray.MenuItem, 0),
+		synopses:   make([]string, 0),
+		mtx:        sync.Mutex{},
+	}
+}
+
+// SetTasks lists the tasks in the submenu; the submenu is disabled when there are no tasks
+func (m *taskMenu) SetTasks(tasks []models.TaskData) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	for len(m.items) < len(tasks) {
+		index := len(m.items)
+		item := m.parent.AddSubMenuItem("-", "")
+		m.items = append(m.items, item)
+		m.synopses = append(m.synopses, "")
+		m.dispatcher.Handle(item, func() {
+			m.clicked(index)
+		})
+	}
+	for idx, item := range m.items {
+		if idx >= len(tasks) {
+			item.Hide()
+			m.synopses[idx] = ""
+			continue
+		}
+		item.SetTitle(tasks[idx].Synopsis)
+		item.SetTooltip(fmt.Sprintf("Start task %s", tasks[idx].Synopsis)) // i18n
+		item.Show()
+		m.synopses[idx] = tasks[idx].Synopsis
+	}
+	if len(tasks) == 0 {
+		m.parent.Disable()
+		return
+	}
+	m.parent.Enable()
+}
+
+// clicked calls onClick with the synopsis of the task that the menu item shows
+func (m *taskMenu) clicked(index int) {
+	m.mtx.Lock()
+	synopsis := m.synopses[index]
+	m.mtx.Unlock()
+	if synopsis != "" {
+		m.onClick(synopsis)
+	}
+}
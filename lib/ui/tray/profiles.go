@@ -0,0 +1,144 @@
+package tray
+
+import (
+	"fmt"
+
+	"fyne.io/systray"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/profiles"
+	"github.com/neflyte/timetracker/lib/startup"
+)
+
+var (
+	mProfiles      *systray.MenuItem
+	profileItems   = make(map[string]*systray.MenuItem)
+	profileWatcher profiles.Watcher
+)
+
+// initProfileMenu adds the profile switcher to the menu and starts watching for profile changes
+func initProfileMenu() {
+	log := logger.GetFuncLogger(trayLogger, "initProfileMenu")
+	mProfiles = systray.AddMenuItem("Profile", "Select the profile whose database is used") // i18n
+	refreshProfileMenu()
+	watcher, err := profiles.NewWatcher()
+	if err != nil {
+		log.Err(err).
+			Msg("unable to watch for profile changes")
+		return
+	}
+	profileWatcher = watcher
+}
+
+// profileChanges returns the channel that receives the selected profile when the profiles change
+func profileChanges() <-chan profiles.Profile {
+	if profileWatcher == nil {
+		return nil
+	}
+	return profileWatcher.Changes()
+}
+
+// cleanupProfileMenu stops watching for profile changes
+func cleanupProfileMenu() {
+	log := logger.GetFuncLogger(trayLogger, "cleanupProfileMenu")
+	if profileWatcher == nil {
+		return
+	}
+	err := profileWatcher.Close()
+	if err != nil {
+		log.Err(err).
+			Msg("error closing profile watcher")
+	}
+}
+
+// refreshProfileMenu adds any new profiles to the menu and checks the active profile
+func refreshProfileMenu() {
+	log := logger.GetFuncLogger(trayLogger, "refreshProfileMenu")
+	profileList, err := profiles.List()
+	if err != nil {
+		log.Err(err).
+			Msg(tterrors.ListProfileError)
+		return
+	}
+	activeProfile := startup.ActiveProfile()
+	for _, profile := range profileList {
+		item, ok := profileItems[profile.Name]
+		if !ok {
+			tooltip := profile.Database
+			if profile.IsDefault() {
+				tooltip = "Use the default database" // i18n
+			}
+			item = mProfiles.AddSubMenuItemCheckbox(profile.Name, tooltip, false)
+			profileItems[profile.Name] = item
+			profileName := profile.Name
+			menuClicks.Handle(item, func() {
+				handleProfileClick(profileName)
+			})
+		}
+		if profile.Name == activeProfile {
+			item.Check()
+		} else {
+			item.Uncheck()
+		}
+	}
+	if activeProfile == "" {
+		mProfiles.SetTitle("Profile") // i18n
+		return
+	}
+	mProfiles.SetTitle(fmt.Sprintf("Profile: %s", activeProfile)) // i18n
+}
+
+// handleProfileClick selects the profile and switches to its database
+func handleProfileClick(name string) {
+	log := logger.GetFuncLogger(trayLogger, "handleProfileClick")
+	err := profiles.Use(name)
+	if err != nil {
+		log.Err(err).
+			Str("profile", name).
+			Msg(tterrors.UseProfileError)
+		notifyProfileError(err)
+		return
+	}
+	switchProfile(name)
+}
+
+// handleProfileChange follows a profile that was selected by another app
+func handleProfileChange(profile profiles.Profile) {
+	if startup.FollowsSelectedProfile() && profile.Name != startup.ActiveProfile() {
+		switchProfile(profile.Name)
+		return
+	}
+	refreshProfileMenu()
+}
+
+// switchProfile opens the database of the profile and reconnects the monitor to it
+func switchProfile(name string) {
+	log := logger.GetFuncLogger(trayLogger, "switchProfile")
+	if name == startup.ActiveProfile() {
+		refreshProfileMenu()
+		return
+	}
+	err := startup.SwitchProfile(name)
+	if err != nil {
+		log.Err(err).
+			Str("profile", name).
+			Msg(tterrors.SwitchProfileError)
+		notifyProfileError(err)
+		refreshProfileMenu()
+		return
+	}
+	refreshProfileMenu()
+	monitor.Reconnect()
+}
+
+func notifyProfileError(err error) {
+	log := logger.GetFuncLogger(trayLogger, "notifyProfileError")
+	notifyErr := toast.Notify(
+		"Error Switching Profile",                               // i18n
+		fmt.Sprintf("Error switching profile: %s", err.Error()), // i18n
+	)
+	if notifyErr != nil {
+		log.Err(notifyErr).
+			Msg("error sending notification for switch profile error")
+	}
+}
@@ -0,0 +1,122 @@
+package tray
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/systray"
+	"github.com/jinzhu/now"
+	"github.com/neflyte/timetracker/lib/config"
+	"github.com/neflyte/timetracker/lib/ipc"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/models"
+)
+
+const (
+	// summaryTopTasks is the number of tasks of the day that the summary lists
+	summaryTopTasks = 3
+)
+
+var (
+	mSummaryToday  *systray.MenuItem
+	mSummaryWeek   *systray.MenuItem
+	mSummaryTasks  []*systray.MenuItem
+	mDaySummary    *systray.MenuItem
+	weekTimesheets []models.TimesheetData
+	weekStart      time.Time
+	todayTotal     time.Duration
+	weekMtx        sync.Mutex
+)
+
+// initSummaryMenu adds the time spent today and this week to the menu. The summary is only informational, so its
+// menu items are disabled; the Day summary item shows the report of today in the GUI.
+func initSummaryMenu() {
+	mSummaryToday = systray.AddMenuItem("", "Time tracked today") // i18n
+	mSummaryToday.Disable()
+	mSummaryTasks = make([]*systray.MenuItem, 0, summaryTopTasks)
+	for idx := 0; idx < summaryTopTasks; idx++ {
+		mSummaryTask := systray.AddMenuItem("", "One of the tasks with the most time tracked today") // i18n
+		mSummaryTask.Disable()
+		mSummaryTask.Hide()
+		mSummaryTasks = append(mSummaryTasks, mSummaryTask)
+	}
+	mSummaryWeek = systray.AddMenuItem("", "Time tracked this week") // i18n
+	mSummaryWeek.Disable()
+	mDaySummary = systray.AddMenuItem("Day summary", "Display the report of today's tasks") // i18n
+	menuClicks.Handle(mDaySummary, func() { showGUI(ipc.CommandSummary) })
+	showSummary()
+}
+
+// updateSummary loads the timesheets of this week and shows the summary of them
+func updateSummary() {
+	log := logger.GetFuncLogger(trayLogger, "updateSummary")
+	currentWeekStart := now.BeginningOfWeek()
+	// A timesheet that started before the week and ran into it counts towards it too
+	timesheets, err := models.NewTimesheet().SearchOverlapping(currentWeekStart, now.EndOfWeek())
+	if err != nil {
+		log.Err(err).
+			Msg("error loading the timesheets of this week")
+		return
+	}
+	weekMtx.Lock()
+	weekTimesheets = timesheets
+	weekStart = currentWeekStart
+	weekMtx.Unlock()
+	showSummary()
+}
+
+// refreshSummary adds the time spent on the running task since the summary was last shown, and refreshes the icon.
+// The timesheets are loaded again when a new week has started.
+func refreshSummary() {
+	weekMtx.Lock()
+	newWeek := !weekStart.Equal(now.BeginningOfWeek())
+	weekMtx.Unlock()
+	if newWeek {
+		updateSummary()
+		return
+	}
+	showSummary()
+}
+
+// showSummary shows the time spent today and this week, and the tasks with the most time today, in the menu and
+// the tooltip
+func showSummary() {
+	currentTime := time.Now()
+	weekMtx.Lock()
+	today := models.Summarize(weekTimesheets, now.With(currentTime).BeginningOfDay(), now.With(currentTime).EndOfDay(), currentTime)
+	week := models.Summarize(weekTimesheets, now.With(currentTime).BeginningOfWeek(), now.With(currentTime).EndOfWeek(), currentTime)
+	todayTotal = models.TotalDuration(today)
+	weekMtx.Unlock()
+	todayTitle := fmt.Sprintf("Today: %s", formatSummaryDuration(models.TotalDuration(today)))   // i18n
+	weekTitle := fmt.Sprintf("This week: %s", formatSummaryDuration(models.TotalDuration(week))) // i18n
+	tooltip := []string{"Timetracker", todayTitle}
+	mSummaryToday.SetTitle(todayTitle)
+	for idx, mSummaryTask := range mSummaryTasks {
+		if idx >= len(today) {
+			mSummaryTask.Hide()
+			continue
+		}
+		taskTitle := fmt.Sprintf("    %s: %s", today[idx].Synopsis, formatSummaryDuration(today[idx].Duration))
+		tooltip = append(tooltip, taskTitle)
+		mSummaryTask.SetTitle(taskTitle)
+		mSummaryTask.Show()
+	}
+	mSummaryWeek.SetTitle(weekTitle)
+	systray.SetTooltip(strings.Join(append(tooltip, weekTitle), "\n"))
+	// The progress icon follows the time tracked today
+	refreshIcon()
+}
+
+// trackedToday returns the time tracked today as of the last time the summary was shown
+func trackedToday() time.Duration {
+	weekMtx.Lock()
+	defer weekMtx.Unlock()
+	return todayTotal
+}
+
+// formatSummaryDuration formats a duration of the summary to the minute
+func formatSummaryDuration(duration time.Duration) string {
+	return config.FormatDuration(duration.Truncate(time.Minute))
+}
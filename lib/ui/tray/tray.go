@@ -4,47 +4,46 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"os/signal"
-	"path"
 	"runtime"
+	"strconv"
 	"syscall"
 	"time"
 
 	"fyne.io/systray"
+	"github.com/neflyte/timetracker/lib/config"
 	"github.com/neflyte/timetracker/lib/constants"
 	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/ipc"
 	"github.com/neflyte/timetracker/lib/logger"
 	"github.com/neflyte/timetracker/lib/models"
 	ttmonitor "github.com/neflyte/timetracker/lib/monitor"
 	"github.com/neflyte/timetracker/lib/ui/icons"
 	tttoast "github.com/neflyte/timetracker/lib/ui/toast"
 	"github.com/neflyte/timetracker/lib/utils"
-	"github.com/spf13/viper"
 )
 
 const (
-	guiOptionStopRunningTask    = "-stop-running-task"
-	guiOptionCreateAndStartTask = "-create-and-start"
-	guiOptionShowManageWindow   = "-manage"
-	guiOptionShowAboutWindow    = "-about"
-
 	statusStartTaskTitle       = "Start new task"
 	statusStartTaskDescription = "Display a task selector and start a task"
 	statusStopTaskDescription  = "Stop the running task"
 
-	recentlyStartedTasks = 5
+	elapsedTimeDelay = constants.ActionLoopDelaySeconds * time.Second
 )
 
 var (
 	mStatus                    *systray.MenuItem
 	mManage                    *systray.MenuItem
+	mTimesheets                *systray.MenuItem
 	mCreateAndStart            *systray.MenuItem
 	mTrayOptions               *systray.MenuItem
 	mTrayOptionConfirmStopTask *systray.MenuItem
+	mSettings                  *systray.MenuItem
 	mLastStarted               *systray.MenuItem
-	lastStartedItems           [recentlyStartedTasks]*systray.MenuItem
-	lastStartedItemSynopses    [recentlyStartedTasks]string
+	mFavorites                 *systray.MenuItem
+	recentTasksMenu            *taskMenu
+	favoriteTasksMenu          *taskMenu
+	menuClicks                 *menuDispatcher
 	mAbout                     *systray.MenuItem
 	mQuit                      *systray.MenuItem
 	trayQuitChan               chan bool
@@ -53,6 +52,7 @@ var (
 	cleanupFunc                func()
 	toast                      tttoast.Toast
 	monitor                    ttmonitor.Service
+	unsubscribeMonitor         func()
 )
 
 // Run starts the systray app
@@ -73,29 +73,44 @@ func onReady() {
 		return
 	}
 	toast = tttoast.NewToast()
+	menuClicks = newMenuDispatcher()
 	setTrayTitle("Timetracker")
 	systray.SetTooltip("Timetracker")
-	systray.SetIcon(icons.IconV2NotRunning.StaticContent)
+	setStatusIcon(icons.IconV2NotRunning, time.Time{})
 	mStatus = systray.AddMenuItem(statusStartTaskTitle, statusStartTaskDescription)
 	mCreateAndStart = systray.AddMenuItem("Create and Start new task", "Display a dialog to input new task details and then start the task") // i18n
 	mManage = systray.AddMenuItem("Manage tasks", "Display the Manage Tasks window to add, change, or remove tasks")                         // i18n
-	// List the top 5 last-started tasks as easy-start options
+	mTimesheets = systray.AddMenuItem("Edit timesheets", "Display the Timesheets window to add, change, split, or remove timesheets")        // i18n
+	menuClicks.Handle(mStatus, handleStatusClick)
+	menuClicks.Handle(mCreateAndStart, func() { showGUI(ipc.CommandCreateAndStart) })
+	menuClicks.Handle(mManage, func() { showGUI(ipc.CommandManage) })
+	menuClicks.Handle(mTimesheets, func() { showGUI(ipc.CommandTimesheets) })
+	initUndoMenu()
+	// Show the time tracked today and this week
+	systray.AddSeparator()
+	initSummaryMenu()
+	// List the last-started tasks and the pinned tasks as easy-start options
 	systray.AddSeparator()
 	mLastStarted = systray.AddMenuItem("Recent tasks", "Select a recently started task to start it again") // i18n
-	for x := range lastStartedItems {
-		lastStartedItems[x] = mLastStarted.AddSubMenuItem("-", "")
-		lastStartedItemSynopses[x] = ""
-	}
+	recentTasksMenu = newTaskMenu(mLastStarted, menuClicks, handleTaskClick)
+	mFavorites = systray.AddMenuItem("Favorites", "Select a pinned task to start it") // i18n
+	favoriteTasksMenu = newTaskMenu(mFavorites, menuClicks, handleTaskClick)
 	systray.AddSeparator()
+	initProfileMenu()
 	mTrayOptions = systray.AddMenuItem("Tray options", "Set system tray icon options") // i18n
 	mTrayOptionConfirmStopTask = mTrayOptions.AddSubMenuItemCheckbox(
 		"Confirm when stopping a task",                         // i18n
 		"Prompt for confirmation when stopping a running task", // i18n
-		viper.GetBool(keyStopTaskConfirm),
+		config.GetBool(config.KeyTrayStopTaskConfirm),
 	)
+	mSettings = systray.AddMenuItem("Settings", "Display the Settings window to change the app settings") // i18n
+	menuClicks.Handle(mTrayOptionConfirmStopTask, toggleConfirmStopTask)
+	menuClicks.Handle(mSettings, func() { showGUI(ipc.CommandSettings) })
+	watchConfig()
 	systray.AddSeparator()
 	mAbout = systray.AddMenuItem("About Timetracker", "About the Timetracker app") // i18n
 	mQuit = systray.AddMenuItem("Quit", "Quit the Timetracker tray app")           // i18n
+	menuClicks.Handle(mAbout, func() { showGUI(ipc.CommandAbout) })
 	// Start mainLoop
 	trayQuitChan = make(chan bool, 1)
 	log.Debug().
@@ -108,11 +123,24 @@ func onReady() {
 		actionLoopStartChan <- true
 	}()
 	monitor = ttmonitor.NewService(actionLoopQuitChan)
-	monitor.Observable().ForEach(
+	monitor.EnableAutomaticBackups()
+	monitorEvents, unsubscribe := monitor.Subscribe()
+	unsubscribeMonitor = unsubscribe
+	monitorEvents.ForEach(
 		func(item interface{}) {
 			switch item.(type) {
-			case ttmonitor.ServiceUpdateEvent:
+			case ttmonitor.ServiceUpdateEvent,
+				ttmonitor.TaskStartedEvent,
+				ttmonitor.TaskStoppedEvent,
+				ttmonitor.TaskSwitchedEvent,
+				ttmonitor.ErrorRaisedEvent,
+				ttmonitor.ErrorClearedEvent:
 				updateStatus()
+			case ttmonitor.DatabaseChangedEvent:
+				// Tasks may have been pinned or unpinned, or timesheets changed, by another app
+				updateTaskMenus()
+				updateSummary()
+				updateUndoMenu()
 			default:
 				log.Warn().
 					Type("event", item).
@@ -131,19 +159,20 @@ func onReady() {
 }
 
 func onExit() {
-	log := logger.GetFuncLogger(trayLogger, "onExit")
-	// Save config
-	err := writeConfig()
-	if err != nil {
-		log.Err(err).
-			Msg("error saving app config")
-	}
 	// Clean up toast
 	toast.Cleanup()
+	// Stop watching for profile changes
+	cleanupProfileMenu()
+	// Stop watching for configuration changes
+	cleanupConfigWatcher()
 	// Shut down mainLoop
 	trayQuitChan <- true
 	// Shut down ActionLoop
 	actionLoopQuitChan <- true
+	// Stop receiving monitor events
+	if unsubscribeMonitor != nil {
+		unsubscribeMonitor()
+	}
 	// Run cleanup function if it is defined
 	if cleanupFunc != nil {
 		cleanupFunc()
@@ -153,8 +182,10 @@ func onExit() {
 func updateStatus() {
 	log := logger.GetFuncLogger(trayLogger, "updateStatus")
 	defer log.Debug().Msg("finished updating status")
-	// Update the last 5 started tasks regardless of what happens in this function
-	defer updateLastStartedTasks()
+	// Update the recent and pinned tasks, the summary and the undo item regardless of what happens in this function
+	defer updateTaskMenus()
+	defer updateSummary()
+	defer updateUndoMenu()
 	// Check if last status was error and show the error icon
 	if monitor.TimesheetStatus() == constants.TimesheetStatusError {
 		log.Debug().
@@ -166,7 +197,7 @@ func updateStatus() {
 				Err(timesheetError).
 				Msg("got last error")
 		}
-		systray.SetIcon(icons.IconV2Error.StaticContent)
+		setStatusIcon(icons.IconV2Error, time.Time{})
 		mStatus.SetTitle("Error (click for details)")                   // i18n
 		mStatus.SetTooltip("An error occurred; click for more details") // i18n
 		return
@@ -177,7 +208,7 @@ func updateStatus() {
 		// No running timesheet
 		log.Debug().
 			Msg("got nil running timesheet item")
-		systray.SetIcon(icons.IconV2NotRunning.StaticContent)
+		setStatusIcon(icons.IconV2NotRunning, time.Time{})
 		mStatus.SetTitle(statusStartTaskTitle)
 		mStatus.SetTooltip(statusStartTaskDescription)
 		return
@@ -185,96 +216,82 @@ func updateStatus() {
 	log.Debug().
 		Str("object", runningTS.String()).
 		Msg("got running timesheet")
-	systray.SetIcon(icons.IconV2Running.StaticContent)
-	statusText := fmt.Sprintf(
+	setStatusIcon(icons.IconV2Running, runningTS.Data().StartTime)
+	mStatus.SetTitle(runningStatusTitle(runningTS))
+	mStatus.SetTooltip(statusStopTaskDescription)
+}
+
+// updateElapsedTime refreshes the elapsed time of the running task in the status menu item
+func updateElapsedTime() {
+	if monitor == nil || monitor.TimesheetStatus() != constants.TimesheetStatusRunning {
+		return
+	}
+	runningTS := monitor.RunningTimesheet()
+	if runningTS == nil || runningTS.Data() == nil {
+		return
+	}
+	mStatus.SetTitle(runningStatusTitle(runningTS))
+}
+
+func runningStatusTitle(runningTS models.Timesheet) string {
+	return fmt.Sprintf(
 		"Stop task %s (%s)", // i18n
 		runningTS.Data().Task.Synopsis,
-		time.Since(runningTS.Data().StartTime).Truncate(time.Second).String(),
+		config.FormatDuration(time.Since(runningTS.Data().StartTime).Truncate(time.Second)),
 	)
-	mStatus.SetTitle(statusText)
-	mStatus.SetTooltip(statusStopTaskDescription)
 }
 
-func updateLastStartedTasks() {
-	log := logger.GetFuncLogger(trayLogger, "updateLastStartedTasks")
-	lastStartedTasks, err := models.NewTimesheet().LastStartedTasks(recentlyStartedTasks)
+// updateTaskMenus lists the configured number of recently started tasks and the pinned tasks in their menus
+func updateTaskMenus() {
+	log := logger.GetFuncLogger(trayLogger, "updateTaskMenus")
+	lastStartedTasks := make([]models.TaskData, 0)
+	recentTaskCount := config.GetInt(config.KeyTrayRecentTasks)
+	if recentTaskCount > 0 {
+		var err error
+		lastStartedTasks, err = models.NewTimesheet().LastStartedTasks(uint(recentTaskCount))
+		if err != nil {
+			log.Err(err).
+				Msg("error loading last started tasks")
+			return
+		}
+	}
+	pinnedTasks, err := models.NewTask().LoadPinned()
 	if err != nil {
 		log.Err(err).
-			Msg("error loading last started tasks")
+			Msg("error loading pinned tasks")
 		return
 	}
 	log.Debug().
-		Int("length", len(lastStartedTasks)).
-		Msg("loaded last started tasks")
-	for idx := range lastStartedTasks {
-		log.Trace().
-			Int("index", idx).
-			Str("task", lastStartedTasks[idx].String()).
-			Msg("lastStartedTask")
-	}
-	// Hide entries we don't need
-	if len(lastStartedTasks) < recentlyStartedTasks {
-		log.Debug().
-			Msgf("len(lastStartedTasks) < recentlyStartedTasks; %d < %d", len(lastStartedTasks), recentlyStartedTasks)
-		for x := recentlyStartedTasks - 1; x > len(lastStartedItems)-1; x-- {
-			log.Trace().
-				Int("index", x).
-				Str("item", lastStartedItems[x].String()).
-				Msg("hiding item")
-			lastStartedItems[x].Disable()
-			lastStartedItems[x].SetTitle("-")
-			lastStartedItems[x].SetTooltip("")
-			lastStartedItemSynopses[x] = ""
-		}
-	}
-	// Fill in the entries we have
-	for x := range lastStartedTasks {
-		log.Trace().
-			Int("index", x).
-			Str("synopsis", lastStartedTasks[x].Synopsis).
-			Msg("showing item")
-		lastStartedItems[x].SetTitle(lastStartedTasks[x].Synopsis)
-		lastStartedItems[x].SetTooltip(fmt.Sprintf("Start task %s", lastStartedTasks[x].Synopsis)) // i18n
-		lastStartedItems[x].Enable()
-		lastStartedItemSynopses[x] = lastStartedTasks[x].Synopsis
-	}
+		Int("recent", len(lastStartedTasks)).
+		Int("pinned", len(pinnedTasks)).
+		Msg("loaded recent and pinned tasks")
+	recentTasksMenu.SetTasks(lastStartedTasks)
+	favoriteTasksMenu.SetTasks(pinnedTasks)
 }
 
-// FIXME: figure out if there is a way to safely reduce complexity in mainLoop below to remove the nolint directive
-
-func mainLoop(quitChan chan bool) { //nolint:cyclop
+func mainLoop(quitChan chan bool) {
 	log := logger.GetFuncLogger(trayLogger, "mainLoop")
 	// Create a channel to catch OS signals
 	signalChan := make(chan os.Signal, 1)
 	// Catch OS interrupt and SIGTERM signals
 	signal.Notify(signalChan, os.Interrupt, syscall.SIGTERM)
+	// The monitor only reports changes, so the elapsed time of the running task and the summary are refreshed here
+	elapsedTimeTicker := time.NewTicker(elapsedTimeDelay)
+	defer elapsedTimeTicker.Stop()
 	// Start main loop
 	log.Debug().
 		Msg("starting")
 	for {
 		select {
-		case <-mStatus.ClickedCh:
-			handleStatusClick()
-		case <-mManage.ClickedCh:
-			launchGUI(guiOptionShowManageWindow)
-		case <-mTrayOptionConfirmStopTask.ClickedCh:
-			toggleConfirmStopTask()
-		// BEGIN Last started tasks
-		case <-lastStartedItems[0].ClickedCh:
-			handleLastStartedClick(0)
-		case <-lastStartedItems[1].ClickedCh:
-			handleLastStartedClick(1)
-		case <-lastStartedItems[2].ClickedCh:
-			handleLastStartedClick(2) //nolint:gomnd
-		case <-lastStartedItems[3].ClickedCh:
-			handleLastStartedClick(3) //nolint:gomnd
-		case <-lastStartedItems[4].ClickedCh:
-			handleLastStartedClick(4) //nolint:gomnd
-		// END Last started tasks
-		case <-mAbout.ClickedCh:
-			launchGUI(guiOptionShowAboutWindow)
-		case <-mCreateAndStart.ClickedCh:
-			launchGUI(guiOptionCreateAndStartTask)
+		case handler := <-menuClicks.Clicks():
+			handler()
+		case <-elapsedTimeTicker.C:
+			updateElapsedTime()
+			refreshSummary()
+		case profile := <-profileChanges():
+			handleProfileChange(profile)
+		case <-configChanges():
+			handleConfigChange()
 		case <-signalChan:
 			log.Trace().
 				Msg("caught interrupt or SIGTERM signal; calling systray.Quit() and exiting function")
@@ -293,45 +310,29 @@ func mainLoop(quitChan chan bool) { //nolint:cyclop
 	}
 }
 
-// launchGUI launches the gui with specific parameters
-func launchGUI(guiOptions ...string) {
-	log := logger.GetFuncLogger(trayLogger, "launchGUI")
+// showGUI asks the running GUI to perform a command, launching the GUI if it is not running
+func showGUI(command ipc.Command) {
+	log := logger.GetFuncLogger(trayLogger, "showGUI")
 	log.Debug().
-		Strs("guiOptions", guiOptions).
+		Str("command", string(command)).
 		Msg("function options")
-	timetrackerExecutable, err := os.Executable()
-	if err != nil {
-		log.Err(err).
-			Msg("error getting path and name of this program")
-		return
-	}
-	timetrackerDir := path.Dir(timetrackerExecutable)
-	guiExecutable := path.Join(timetrackerDir, "timetracker-gui")
-	if runtime.GOOS == "windows" {
-		guiExecutable += ".exe"
-	}
-	log.Debug().
-		Str("guiExecutable", guiExecutable).
-		Strs("guiOptions", guiOptions).
-		Msg("command to launch gui")
-	guiCmd := exec.Command(guiExecutable, guiOptions...)
-	err = guiCmd.Start()
+	err := ipc.Show(command)
 	if err != nil {
 		log.Err(err).
-			Str("command", guiCmd.String()).
-			Msg("error launching gui")
+			Str("command", string(command)).
+			Msg(tterrors.LaunchGUIError)
 		return
 	}
-	log.Debug().Msg("gui launched successfully")
+	log.Debug().Msg("gui command sent successfully")
 }
 
 func handleStatusClick() {
 	log := logger.GetFuncLogger(trayLogger, "handleStatusClick")
 	switch monitor.TimesheetStatus() {
 	case constants.TimesheetStatusRunning:
-		shouldConfirmStopTask := viper.GetBool(keyStopTaskConfirm)
+		shouldConfirmStopTask := config.GetBool(config.KeyTrayStopTaskConfirm)
 		if shouldConfirmStopTask {
-			launchGUI(guiOptionStopRunningTask)
+			showGUI(ipc.CommandStopRunningTask)
 			return
 		}
 		stopRunningTask()
@@ -348,7 +349,7 @@ func handleStatusClick() {
 				Msg("error sending notification about status error")
 		}
 	case constants.TimesheetStatusIdle:
-		launchGUI()
+		showGUI(ipc.CommandShow)
 	}
 }
 
@@ -359,24 +360,17 @@ func setTrayTitle(title string) {
 	}
 }
 
-func handleLastStartedClick(index uint) {
-	log := logger.GetFuncLogger(trayLogger, "handleLastStartedClick").
-		With().
-		Uint("index", index).
-		Logger()
-	// Request to handle an index beyond what we have; do nothing
-	if index >= recentlyStartedTasks {
-		return
-	}
-	// Get the synopsis from the menu item
-	taskSyn := lastStartedItemSynopses[index]
+// handleTaskClick stops the running task, if any, and starts the task that was clicked in the Recent tasks or
+// Favorites menu
+func handleTaskClick(synopsis string) {
+	log := logger.GetFuncLogger(trayLogger, "handleTaskClick")
 	// Ensure the task exists
 	task := models.NewTask()
-	task.Data().Synopsis = taskSyn
+	task.Data().Synopsis = synopsis
 	err := task.Load(false)
 	if err != nil {
 		log.Err(err).
-			Str("synopsis", taskSyn).
+			Str("synopsis", synopsis).
 			Msg("error loading task")
 		return
 	}
@@ -460,9 +454,15 @@ func startTask(taskData *models.TaskData) (err error) {
 }
 
 func toggleConfirmStopTask() {
-	shouldConfirmStopTask := viper.GetBool(keyStopTaskConfirm)
+	shouldConfirmStopTask := config.GetBool(config.KeyTrayStopTaskConfirm)
 	newConfirmValue := !shouldConfirmStopTask
-	viper.Set(keyStopTaskConfirm, newConfirmValue)
+	err := config.Set(config.KeyTrayStopTaskConfirm, strconv.FormatBool(newConfirmValue))
+	if err != nil {
+		log := logger.GetFuncLogger(trayLogger, "toggleConfirmStopTask")
+		log.Err(err).
+			Msg("error saving app config")
+		return
+	}
 	if newConfirmValue {
 		mTrayOptionConfirmStopTask.Check()
 	} else {
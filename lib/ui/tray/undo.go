@@ -0,0 +1,71 @@
+package tray
+
+import (
+	"errors"
+	"fmt"
+
+	"fyne.io/systray"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/models"
+)
+
+const (
+	undoTitle       = "Undo last action"                                                          // i18n
+	undoDescription = "Undo the last action that changed a task or timesheet, such as a misclick" // i18n
+)
+
+var (
+	mUndo *systray.MenuItem
+)
+
+// initUndoMenu adds the menu item that undoes the last action in the journal
+func initUndoMenu() {
+	mUndo = systray.AddMenuItem(undoTitle, undoDescription)
+	menuClicks.Handle(mUndo, handleUndoClick)
+	updateUndoMenu()
+}
+
+// updateUndoMenu names the action that the undo menu item undoes, and disables the menu item when there is
+// nothing to undo
+func updateUndoMenu() {
+	log := logger.GetFuncLogger(trayLogger, "updateUndoMenu")
+	last, err := models.NewJournal().Last()
+	if err != nil {
+		if !errors.Is(err, tterrors.ErrNothingToUndo{}) {
+			log.Err(err).
+				Msg("error loading the last action")
+		}
+		mUndo.SetTitle(undoTitle)
+		mUndo.Disable()
+		return
+	}
+	mUndo.SetTitle(fmt.Sprintf("Undo: %s", last.Description)) // i18n
+	mUndo.Enable()
+}
+
+// handleUndoClick undoes the last action. The monitor notices the change to the database and updates the status.
+func handleUndoClick() {
+	log := logger.GetFuncLogger(trayLogger, "handleUndoClick")
+	undone, err := models.NewJournal().Undo()
+	if err != nil {
+		log.Err(err).
+			Msg("error undoing the last action")
+		err = toast.Notify(
+			"Error Undoing Action", // i18n
+			fmt.Sprintf("Error undoing the last action: %s", err.Error()), // i18n
+		)
+		if err != nil {
+			log.Err(err).
+				Msg("error sending notification for undo error")
+		}
+		updateUndoMenu()
+		return
+	}
+	err = toast.Notify("Action undone", undone.Description) // i18n
+	if err != nil {
+		log.Err(err).
+			Msg("error sending notification about undone action")
+	}
+	updateUndoMenu()
+}
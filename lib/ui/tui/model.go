@@ -0,0 +1,639 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jinzhu/now"
+	"github.com/neflyte/timetracker/lib/config"
+	"github.com/neflyte/timetracker/lib/constants"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/models"
+	"github.com/neflyte/timetracker/lib/monitor"
+	"github.com/rs/zerolog"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	paneTasks = iota
+	paneTimesheets
+	paneCount
+
+	defaultWidth     = 80
+	defaultHeight    = 24
+	durationWidth    = 9
+	minimumNameWidth = 8
+	// paneBorderSize is the number of rows or columns taken by a pane's border
+	paneBorderSize = 2
+	// paneChromeWidth is the width of a pane's border and padding
+	paneChromeWidth = paneBorderSize + 2
+	tickInterval    = time.Second
+	rowTimeLayout   = "15:04:05"
+)
+
+// tickMsg updates the elapsed time of the running task
+type tickMsg time.Time
+
+// monitorEventMsg is an event sent by the monitor service
+type monitorEventMsg struct {
+	event interface{}
+}
+
+// modelData is the state of the terminal UI
+type modelData struct {
+	currentTime     time.Time
+	day             time.Time
+	monitor         monitor.Service
+	editor          *timesheetEditor
+	log             zerolog.Logger
+	message         string
+	tasks           []models.TaskData
+	timesheets      []models.TimesheetData
+	weekTimesheets  []models.TimesheetData
+	filterInput     textinput.Model
+	focus           int
+	taskCursor      int
+	timesheetCursor int
+	width           int
+	height          int
+	messageIsError  bool
+	filtering       bool
+	confirmDelete   bool
+}
+
+// newModel returns the terminal UI state with the tasks and timesheets loaded
+func newModel(monitorService monitor.Service) *modelData {
+	filterInput := textinput.New()
+	filterInput.Prompt = "/ "
+	filterInput.Placeholder = "Filter tasks" // i18n
+	m := &modelData{
+		log:         logger.GetStructLogger("tui.modelData"),
+		monitor:     monitorService,
+		filterInput: filterInput,
+		currentTime: time.Now(),
+		day:         now.BeginningOfDay(),
+	}
+	m.reload()
+	return m
+}
+
+// Init starts the clock that updates the elapsed time
+func (m *modelData) Init() tea.Cmd {
+	return tick()
+}
+
+func tick() tea.Cmd {
+	return tea.Tick(tickInterval, func(t time.Time) tea.Msg {
+		return tickMsg(t)
+	})
+}
+
+// Update handles a message and returns the next command to run
+func (m *modelData) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch typedMsg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = typedMsg.Width
+		m.height = typedMsg.Height
+	case tickMsg:
+		m.currentTime = time.Time(typedMsg)
+		return m, tick()
+	case monitorEventMsg:
+		m.handleMonitorEvent(typedMsg.event)
+	case tea.KeyMsg:
+		return m, m.handleKey(typedMsg)
+	}
+	return m, nil
+}
+
+// handleMonitorEvent reloads the tasks and timesheets after another app has changed them
+func (m *modelData) handleMonitorEvent(event interface{}) {
+	log := logger.GetFuncLogger(m.log, "handleMonitorEvent")
+	log.Trace().
+		Type("event", event).
+		Msg("received monitor event")
+	switch typedEvent := event.(type) {
+	case monitor.ErrorRaisedEvent:
+		m.setError(typedEvent.Error)
+	case monitor.ErrorClearedEvent:
+		m.message = ""
+	}
+	m.reload()
+}
+
+func (m *modelData) handleKey(msg tea.KeyMsg) tea.Cmd {
+	if msg.String() == "ctrl+c" {
+		return tea.Quit
+	}
+	switch {
+	case m.editor != nil:
+		return m.handleEditorKey(msg)
+	case m.confirmDelete:
+		m.handleConfirmDeleteKey(msg)
+		return nil
+	case m.filtering:
+		return m.handleFilterKey(msg)
+	}
+	m.message = ""
+	switch msg.String() {
+	case "q":
+		return tea.Quit
+	case "tab":
+		m.focus = (m.focus + 1) % paneCount
+	case "x":
+		m.stopRunningTask()
+	case "r":
+		m.reload()
+	case "up", "k":
+		m.moveCursor(-1)
+	case "down", "j":
+		m.moveCursor(1)
+	default:
+		if m.focus == paneTasks {
+			return m.handleTasksKey(msg)
+		}
+		m.handleTimesheetsKey(msg)
+	}
+	return nil
+}
+
+func (m *modelData) handleTasksKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "/":
+		m.filtering = true
+		return m.filterInput.Focus()
+	case "esc":
+		m.filterInput.SetValue("")
+		m.loadTasks()
+	case "enter", "s":
+		m.startSelectedTask()
+	}
+	return nil
+}
+
+func (m *modelData) handleTimesheetsKey(msg tea.KeyMsg) {
+	switch msg.String() {
+	case "left", "h", "[":
+		m.setDay(m.day.AddDate(0, 0, -1))
+	case "right", "l", "]":
+		if m.day.Before(now.With(m.currentTime).BeginningOfDay()) {
+			m.setDay(m.day.AddDate(0, 0, 1))
+		}
+	case "t":
+		m.setDay(now.With(m.currentTime).BeginningOfDay())
+	case "enter", "e":
+		if timesheet := m.selectedTimesheet(); timesheet != nil {
+			m.editor = newTimesheetEditor(*timesheet, m.day)
+		}
+	case "n":
+		timesheet := models.NewTimesheetData()
+		if task := m.selectedTask(); task != nil {
+			timesheet.Task = *task
+		}
+		m.editor = newTimesheetEditor(timesheet, m.day)
+	case "d":
+		if m.selectedTimesheet() != nil {
+			m.confirmDelete = true
+		}
+	}
+}
+
+func (m *modelData) handleFilterKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "enter":
+		m.filtering = false
+		m.filterInput.Blur()
+		return nil
+	case "esc":
+		m.filtering = false
+		m.filterInput.Blur()
+		m.filterInput.SetValue("")
+		m.loadTasks()
+		return nil
+	case "up":
+		m.moveCursor(-1)
+		return nil
+	case "down":
+		m.moveCursor(1)
+		return nil
+	}
+	var cmd tea.Cmd
+	m.filterInput, cmd = m.filterInput.Update(msg)
+	m.loadTasks()
+	return cmd
+}
+
+func (m *modelData) handleEditorKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.editor = nil
+		return nil
+	case "enter":
+		timesheet, err := m.editor.save(time.Now())
+		if err != nil {
+			m.editor.err = err
+			return nil
+		}
+		m.editor = nil
+		m.setMessage("Saved the timesheet for %s", timesheet.Task.Synopsis) // i18n
+		m.reload()
+		return nil
+	}
+	return m.editor.update(msg)
+}
+
+func (m *modelData) handleConfirmDeleteKey(msg tea.KeyMsg) {
+	m.confirmDelete = false
+	if msg.String() != "y" {
+		return
+	}
+	timesheet := m.selectedTimesheet()
+	if timesheet == nil {
+		return
+	}
+	err := models.Timesheet(timesheet).Delete()
+	if err != nil {
+		m.setError(fmt.Errorf("%s: %w", tterrors.DeleteTimesheetError, err))
+		return
+	}
+	m.setMessage("Deleted the timesheet for %s", timesheet.Task.Synopsis) // i18n
+	m.reload()
+}
+
+// moveCursor moves the cursor of the focused list
+func (m *modelData) moveCursor(delta int) {
+	if m.focus == paneTasks || m.filtering {
+		m.taskCursor = clamp(m.taskCursor+delta, len(m.tasks))
+		return
+	}
+	m.timesheetCursor = clamp(m.timesheetCursor+delta, len(m.timesheets))
+}
+
+func (m *modelData) setDay(day time.Time) {
+	m.day = day
+	m.timesheetCursor = 0
+	m.loadTimesheets()
+}
+
+func (m *modelData) selectedTask() *models.TaskData {
+	if m.taskCursor >= len(m.tasks) {
+		return nil
+	}
+	return &m.tasks[m.taskCursor]
+}
+
+func (m *modelData) selectedTimesheet() *models.TimesheetData {
+	if m.timesheetCursor >= len(m.timesheets) {
+		return nil
+	}
+	return &m.timesheets[m.timesheetCursor]
+}
+
+// startSelectedTask starts the selected task, stopping the running task first
+func (m *modelData) startSelectedTask() {
+	task := m.selectedTask()
+	if task == nil {
+		return
+	}
+	runningTS := m.monitor.RunningTimesheet()
+	if runningTS != nil && runningTS.Data() != nil && runningTS.Data().Task.ID == task.ID {
+		m.setMessage("%s is already running", task.Synopsis) // i18n
+		return
+	}
+	_, err := models.NewTask().StopRunningTask()
+	if err != nil && !errors.Is(err, tterrors.ErrNoRunningTask{}) {
+		m.setError(fmt.Errorf("%s: %w", tterrors.StopRunningTaskError, err))
+		return
+	}
+	timesheet := models.NewTimesheet()
+	timesheet.Data().Task = *task
+	timesheet.Data().StartTime = time.Now()
+	err = timesheet.Create()
+	if err != nil {
+		m.setError(fmt.Errorf("%s: %w", tterrors.CreateTimesheetError, err))
+		return
+	}
+	// Update the Service
+	m.monitor.SetRunningTimesheet(timesheet)
+	m.monitor.SetTimesheetStatus(constants.TimesheetStatusRunning)
+	m.monitor.SetTimesheetError(nil)
+	m.setMessage("Started %s", task.Synopsis) // i18n
+	m.reload()
+}
+
+// stopRunningTask stops the running task, if any
+func (m *modelData) stopRunningTask() {
+	stoppedTimesheet, err := models.NewTask().StopRunningTask()
+	if errors.Is(err, tterrors.ErrNoRunningTask{}) {
+		m.setMessage("No task is running") // i18n
+		return
+	}
+	if err != nil {
+		m.setError(fmt.Errorf("%s: %w", tterrors.StopRunningTaskError, err))
+		return
+	}
+	// Update the Service
+	m.monitor.SetRunningTimesheet(nil)
+	m.monitor.SetTimesheetStatus(constants.TimesheetStatusIdle)
+	m.monitor.SetTimesheetError(nil)
+	m.setMessage("Stopped %s", stoppedTimesheet.Task.Synopsis) // i18n
+	m.reload()
+}
+
+func (m *modelData) setMessage(format string, args ...interface{}) {
+	m.message = fmt.Sprintf(format, args...)
+	m.messageIsError = false
+}
+
+func (m *modelData) setError(err error) {
+	log := logger.GetFuncLogger(m.log, "setError")
+	log.Err(err).
+		Msg("error in terminal UI")
+	m.message = err.Error()
+	m.messageIsError = true
+}
+
+// reload loads the tasks, the timesheets of the selected day and the timesheets of the current week
+func (m *modelData) reload() {
+	m.loadTasks()
+	m.loadTimesheets()
+	weekTimesheets, err := models.NewTimesheet().SearchStarted(now.With(m.currentTime).BeginningOfWeek(), now.With(m.currentTime).EndOfWeek())
+	if err != nil {
+		m.setError(fmt.Errorf("%s: %w", tterrors.ListTimesheetError, err))
+		return
+	}
+	m.weekTimesheets = weekTimesheets
+}
+
+// loadTasks loads the tasks that match the filter; all tasks are shown latest first
+func (m *modelData) loadTasks() {
+	var (
+		tasks []models.TaskData
+		err   error
+	)
+	filterText := strings.TrimSpace(m.filterInput.Value())
+	if filterText == "" {
+		tasks, err = models.NewTask().LoadAll(false)
+		slices.Reverse(tasks)
+	} else {
+		// Search results are ranked best match first
+		tasks, err = models.NewTask().FuzzySearch(filterText)
+	}
+	if err != nil {
+		m.setError(fmt.Errorf("%s: %w", tterrors.LoadTaskError, err))
+		return
+	}
+	m.tasks = tasks
+	m.taskCursor = clamp(m.taskCursor, len(m.tasks))
+}
+
+// loadTimesheets loads the timesheets of the selected day
+func (m *modelData) loadTimesheets() {
+	timesheets, err := models.NewTimesheet().SearchStarted(m.day, now.With(m.day).EndOfDay())
+	if err != nil {
+		m.setError(fmt.Errorf("%s: %w", tterrors.ListTimesheetError, err))
+		return
+	}
+	m.timesheets = timesheets
+	m.timesheetCursor = clamp(m.timesheetCursor, len(m.timesheets))
+}
+
+// View renders the terminal UI
+func (m *modelData) View() string {
+	width, height := m.size()
+	header := m.viewHeader(width)
+	footer := m.viewFooter(width)
+	bodyHeight := height - lipgloss.Height(header) - lipgloss.Height(footer)
+	topHeight := bodyHeight / 2
+	tasksWidth := width * 3 / 5
+	top := lipgloss.JoinHorizontal(
+		lipgloss.Top,
+		m.viewTasks(tasksWidth, topHeight),
+		m.viewSummary(width-tasksWidth, topHeight),
+	)
+	bottom := m.viewTimesheets(width, bodyHeight-topHeight)
+	return lipgloss.JoinVertical(lipgloss.Left, header, top, bottom, footer)
+}
+
+func (m *modelData) size() (int, int) {
+	if m.width == 0 || m.height == 0 {
+		return defaultWidth, defaultHeight
+	}
+	return m.width, m.height
+}
+
+// viewHeader renders the status of the running task and its elapsed time
+func (m *modelData) viewHeader(width int) string {
+	status := idleStyle.Render("Idle") // i18n
+	switch m.monitor.TimesheetStatus() {
+	case constants.TimesheetStatusRunning:
+		runningTS := m.monitor.RunningTimesheet()
+		if runningTS != nil && runningTS.Data() != nil {
+			status = runningStyle.Render(fmt.Sprintf(
+				"▶ %s  %s",
+				runningTS.Data().Task.Synopsis,
+				formatDuration(m.currentTime.Sub(runningTS.Data().StartTime)),
+			))
+		}
+	case constants.TimesheetStatusError:
+		status = errorStyle.Render("Error: " + errorText(m.monitor.TimesheetError())) // i18n
+	}
+	title := "Timetracker" // i18n
+	if profileName := activeProfile(); profileName != "" {
+		title = fmt.Sprintf("%s - %s", title, profileName)
+	}
+	gap := width - lipgloss.Width(title) - lipgloss.Width(status) - 2
+	if gap < 1 {
+		gap = 1
+	}
+	return headerStyle.Render(title + strings.Repeat(" ", gap) + status)
+}
+
+// viewFooter renders the latest message and the keys that can be pressed
+func (m *modelData) viewFooter(width int) string {
+	message := m.message
+	if m.messageIsError {
+		message = errorStyle.Render(message)
+	}
+	var help string
+	switch {
+	case m.editor != nil:
+		help = "tab next field • enter save • esc cancel" // i18n
+	case m.confirmDelete:
+		help = "delete this timesheet? y yes • any other key no" // i18n
+	case m.filtering:
+		help = "type to filter • ↑/↓ move • enter done • esc clear" // i18n
+	case m.focus == paneTasks:
+		help = "enter start/switch • x stop • / filter • tab timesheets • q quit" // i18n
+	default:
+		help = "←/→ day • t today • e edit • n new • d delete • x stop • tab tasks • q quit" // i18n
+	}
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		" "+truncate(message, width-1),
+		helpStyle.Render(truncate(help, width-2)),
+	)
+}
+
+// viewTasks renders the filterable task list
+func (m *modelData) viewTasks(width int, height int) string {
+	innerWidth := width - paneChromeWidth
+	rows := height - paneBorderSize - 2
+	var runningTaskID uint
+	if runningTS := m.monitor.RunningTimesheet(); runningTS != nil && runningTS.Data() != nil {
+		runningTaskID = runningTS.Data().Task.ID
+	}
+	lines := []string{
+		titleStyle.Render(fmt.Sprintf("Tasks (%d)", len(m.tasks))), // i18n
+		m.viewFilter(innerWidth),
+	}
+	start, end := visibleRange(m.taskCursor, len(m.tasks), rows)
+	for idx := start; idx < end; idx++ {
+		task := m.tasks[idx]
+		marker := "  "
+		if task.ID == runningTaskID {
+			marker = "▶ "
+		}
+		line := truncate(marker+task.Synopsis, innerWidth)
+		if task.Description != "" && lipgloss.Width(line) < innerWidth-2 {
+			line += "  " + mutedStyle.Render(truncate(task.Description, innerWidth-lipgloss.Width(line)-2))
+		}
+		if idx == m.taskCursor && !m.filtering {
+			line = selectedStyle.Render(truncate(marker+task.Synopsis, innerWidth))
+		}
+		lines = append(lines, line)
+	}
+	return m.pane(paneTasks, width, height, lines)
+}
+
+func (m *modelData) viewFilter(width int) string {
+	if m.filtering {
+		m.filterInput.Width = width - lipgloss.Width(m.filterInput.Prompt) - 1
+		return m.filterInput.View()
+	}
+	if m.filterInput.Value() != "" {
+		return mutedStyle.Render(truncate(m.filterInput.Prompt+m.filterInput.Value(), width))
+	}
+	return mutedStyle.Render("/ to filter") // i18n
+}
+
+// viewSummary renders the time spent today and this week
+func (m *modelData) viewSummary(width int, height int) string {
+	innerWidth := width - paneChromeWidth
+	// Each period has a title and a line for the remaining tasks
+	maxTasks := (height - paneBorderSize - 4) / 2
+	if maxTasks < 0 {
+		maxTasks = 0
+	}
+	todayStart := now.With(m.currentTime).BeginningOfDay()
+	weekStart := now.With(m.currentTime).BeginningOfWeek()
+	today := models.Summarize(m.weekTimesheets, todayStart, now.With(m.currentTime).EndOfDay(), m.currentTime)
+	week := models.Summarize(m.weekTimesheets, weekStart, now.With(m.currentTime).EndOfWeek(), m.currentTime)
+	lines := []string{
+		renderSummary("Today", today, innerWidth, maxTasks),                                                         // i18n
+		renderSummary(fmt.Sprintf("Week of %s", weekStart.Format(config.DateFormat())), week, innerWidth, maxTasks), // i18n
+	}
+	return m.pane(-1, width, height, lines)
+}
+
+// viewTimesheets renders the timesheets of the selected day, or the timesheet editor
+func (m *modelData) viewTimesheets(width int, height int) string {
+	if m.editor != nil {
+		return m.pane(paneTimesheets, width, height, []string{m.editor.view()})
+	}
+	innerWidth := width - paneChromeWidth
+	rows := height - paneBorderSize - 1
+	lines := []string{
+		titleStyle.Render(fmt.Sprintf("Timesheets for %s", m.day.Format(config.DateFormat()))), // i18n
+	}
+	if len(m.timesheets) == 0 {
+		lines = append(lines, mutedStyle.Render("No timesheets; press n to add one")) // i18n
+	}
+	start, end := visibleRange(m.timesheetCursor, len(m.timesheets), rows)
+	for idx := start; idx < end; idx++ {
+		timesheet := m.timesheets[idx]
+		stop := "running" // i18n
+		stopTime := m.currentTime
+		if timesheet.StopTime.Valid {
+			stopTime = timesheet.StopTime.Time
+			stop = stopTime.Format(rowTimeLayout)
+		}
+		line := truncate(fmt.Sprintf(
+			"%s - %-8s %*s  %s",
+			timesheet.StartTime.Format(rowTimeLayout),
+			stop,
+			durationWidth,
+			formatDuration(stopTime.Sub(timesheet.StartTime)),
+			timesheet.Task.Synopsis,
+		), innerWidth)
+		if idx == m.timesheetCursor && m.focus == paneTimesheets {
+			line = selectedStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	return m.pane(paneTimesheets, width, height, lines)
+}
+
+// pane renders the lines in a bordered box that is highlighted when it has the focus
+func (m *modelData) pane(pane int, width int, height int, lines []string) string {
+	style := paneStyle
+	if pane == m.focus {
+		style = focusedPaneStyle
+	}
+	content := strings.Join(lines, "\n")
+	return style.
+		Width(width - paneBorderSize).
+		Height(height - paneBorderSize).
+		Render(content)
+}
+
+// visibleRange returns the range of list items to show so that the cursor is always visible
+func visibleRange(cursor int, count int, rows int) (int, int) {
+	if rows <= 0 {
+		return 0, 0
+	}
+	start := 0
+	if cursor >= rows {
+		start = cursor - rows + 1
+	}
+	end := start + rows
+	if end > count {
+		end = count
+	}
+	return start, end
+}
+
+// clamp keeps an index within a list of the specified length
+func clamp(index int, length int) int {
+	if index >= length {
+		index = length - 1
+	}
+	if index < 0 {
+		return 0
+	}
+	return index
+}
+
+// truncate shortens text to fit in the specified width
+func truncate(text string, width int) string {
+	if width < minimumNameWidth {
+		width = minimumNameWidth
+	}
+	runes := []rune(text)
+	if len(runes) <= width {
+		return text
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+func errorText(err error) string {
+	if err == nil {
+		return "unknown error" // i18n
+	}
+	return err.Error()
+}
@@ -0,0 +1,23 @@
+package tui
+
+import "github.com/charmbracelet/lipgloss"
+
+var (
+	colorMuted   = lipgloss.AdaptiveColor{Light: "244", Dark: "241"}
+	colorAccent  = lipgloss.AdaptiveColor{Light: "25", Dark: "39"}
+	colorRunning = lipgloss.AdaptiveColor{Light: "28", Dark: "42"}
+	colorError   = lipgloss.AdaptiveColor{Light: "160", Dark: "203"}
+
+	headerStyle       = lipgloss.NewStyle().Bold(true).Padding(0, 1)
+	runningStyle      = lipgloss.NewStyle().Foreground(colorRunning).Bold(true)
+	idleStyle         = lipgloss.NewStyle().Foreground(colorMuted)
+	errorStyle        = lipgloss.NewStyle().Foreground(colorError)
+	mutedStyle        = lipgloss.NewStyle().Foreground(colorMuted)
+	titleStyle        = lipgloss.NewStyle().Bold(true)
+	selectedStyle     = lipgloss.NewStyle().Reverse(true)
+	helpStyle         = lipgloss.NewStyle().Foreground(colorMuted).Padding(0, 1)
+	paneStyle         = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(colorMuted).Padding(0, 1)
+	focusedPaneStyle  = paneStyle.Copy().BorderForeground(colorAccent)
+	editorLabelStyle  = lipgloss.NewStyle().Width(editorLabelWidth)
+	focusedLabelStyle = editorLabelStyle.Copy().Foreground(colorAccent).Bold(true)
+)
@@ -0,0 +1,43 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/neflyte/timetracker/lib/models"
+)
+
+// renderSummary renders the totals of a period, limited to the specified number of tasks
+func renderSummary(title string, summary []models.TaskTotal, width int, maxTasks int) string {
+	total := models.TotalDuration(summary)
+	sb := strings.Builder{}
+	sb.WriteString(titleStyle.Render(fmt.Sprintf("%-*s %s", summaryNameWidth(width), title, formatDuration(total))))
+	for idx, taskTotal := range summary {
+		if idx >= maxTasks {
+			sb.WriteString("\n" + mutedStyle.Render(fmt.Sprintf("  and %d more", len(summary)-maxTasks))) // i18n
+			break
+		}
+		synopsis := truncate(taskTotal.Synopsis, summaryNameWidth(width)-2)
+		sb.WriteString(fmt.Sprintf("\n  %-*s %s", summaryNameWidth(width)-2, synopsis, formatDuration(taskTotal.Duration)))
+	}
+	return sb.String()
+}
+
+// summaryNameWidth returns the width of the task name column of a summary
+func summaryNameWidth(width int) int {
+	nameWidth := width - durationWidth - 1
+	if nameWidth < minimumNameWidth {
+		return minimumNameWidth
+	}
+	return nameWidth
+}
+
+// formatDuration formats a duration as hours, minutes and seconds, such as 1:02:03
+func formatDuration(duration time.Duration) string {
+	duration = duration.Truncate(time.Second)
+	hours := duration / time.Hour
+	minutes := (duration % time.Hour) / time.Minute
+	seconds := (duration % time.Minute) / time.Second
+	return fmt.Sprintf("%d:%02d:%02d", hours, minutes, seconds)
+}
@@ -0,0 +1,150 @@
+package tui
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/neflyte/timetracker/lib/config"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/models"
+	"github.com/neflyte/timetracker/lib/utils"
+)
+
+const (
+	editorFieldTask = iota
+	editorFieldStart
+	editorFieldStop
+	editorFieldCount
+
+	editorLabelWidth = 8
+)
+
+var (
+	editorFieldLabels = [editorFieldCount]string{"Task", "Start", "Stop"} // i18n
+)
+
+// timesheetEditor is a form that edits the task, start time and stop time of a new or existing timesheet
+type timesheetEditor struct {
+	err       error
+	day       time.Time
+	timesheet models.TimesheetData
+	inputs    [editorFieldCount]textinput.Model
+	focused   int
+}
+
+// newTimesheetEditor returns an editor for the timesheet; a timesheet without an ID is created when it is saved
+func newTimesheetEditor(timesheet models.TimesheetData, day time.Time) *timesheetEditor {
+	e := &timesheetEditor{
+		timesheet: timesheet,
+		day:       day,
+	}
+	for idx := range e.inputs {
+		e.inputs[idx] = textinput.New()
+		e.inputs[idx].Prompt = ""
+	}
+	e.inputs[editorFieldTask].Placeholder = "task id or synopsis"                // i18n
+	e.inputs[editorFieldStart].Placeholder = "HH:MM"                             // i18n
+	e.inputs[editorFieldStop].Placeholder = "HH:MM; leave empty to keep running" // i18n
+	if timesheet.Task.ID > 0 {
+		e.inputs[editorFieldTask].SetValue(timesheet.Task.Synopsis)
+	}
+	if !timesheet.StartTime.IsZero() {
+		e.inputs[editorFieldStart].SetValue(utils.FormatTimeOfDay(timesheet.StartTime, day))
+	}
+	if timesheet.StopTime.Valid {
+		e.inputs[editorFieldStop].SetValue(utils.FormatTimeOfDay(timesheet.StopTime.Time, day))
+	}
+	e.focus(editorFieldTask)
+	return e
+}
+
+// focus moves the cursor to the specified field
+func (e *timesheetEditor) focus(field int) {
+	e.inputs[e.focused].Blur()
+	e.focused = (field + editorFieldCount) % editorFieldCount
+	e.inputs[e.focused].Focus()
+}
+
+// update handles a key press in the focused field
+func (e *timesheetEditor) update(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "tab", "down":
+		e.focus(e.focused + 1)
+		return nil
+	case "shift+tab", "up":
+		e.focus(e.focused - 1)
+		return nil
+	}
+	var cmd tea.Cmd
+	e.inputs[e.focused], cmd = e.inputs[e.focused].Update(msg)
+	return cmd
+}
+
+// save validates the form and creates or updates the timesheet
+func (e *timesheetEditor) save(currentTime time.Time) (models.TimesheetData, error) {
+	timesheet, err := e.timesheetData(currentTime)
+	if err != nil {
+		return timesheet, err
+	}
+	if timesheet.ID == 0 {
+		err = models.Timesheet(&timesheet).Create()
+	} else {
+		err = models.Timesheet(&timesheet).Update()
+	}
+	if err != nil {
+		return timesheet, fmt.Errorf("%s: %w", tterrors.SaveTimesheetError, err)
+	}
+	return timesheet, nil
+}
+
+// timesheetData returns the timesheet with the values in the form, or an error if they are not valid
+func (e *timesheetEditor) timesheetData(currentTime time.Time) (models.TimesheetData, error) {
+	timesheet := e.timesheet
+	task := models.NewTask()
+	task.Data().ID, task.Data().Synopsis = task.Resolve(strings.TrimSpace(e.inputs[editorFieldTask].Value()))
+	err := task.Load(false)
+	if err != nil {
+		return timesheet, fmt.Errorf("%s: %w", tterrors.LoadTaskError, err)
+	}
+	timesheet.Task = *task.Data()
+	timesheet.TaskID = task.Data().ID
+	timesheet.StartTime, err = utils.ParseTimeOfDay(e.inputs[editorFieldStart].Value(), e.day)
+	if err != nil {
+		return timesheet, err
+	}
+	timesheet.StopTime = sql.NullTime{}
+	stopValue := e.inputs[editorFieldStop].Value()
+	if strings.TrimSpace(stopValue) != "" {
+		timesheet.StopTime.Time, err = utils.ParseTimeOfDay(stopValue, e.day)
+		if err != nil {
+			return timesheet, err
+		}
+		timesheet.StopTime.Valid = true
+	}
+	return timesheet, timesheet.Validate(currentTime)
+}
+
+// view renders the form
+func (e *timesheetEditor) view() string {
+	title := "Edit timesheet" // i18n
+	if e.timesheet.ID == 0 {
+		title = "New timesheet" // i18n
+	}
+	sb := strings.Builder{}
+	sb.WriteString(titleStyle.Render(fmt.Sprintf("%s for %s", title, e.day.Format(config.DateFormat())))) // i18n
+	for idx, input := range e.inputs {
+		labelStyle := editorLabelStyle
+		if idx == e.focused {
+			labelStyle = focusedLabelStyle
+		}
+		sb.WriteString("\n" + labelStyle.Render(editorFieldLabels[idx]) + input.View())
+	}
+	if e.err != nil {
+		sb.WriteString("\n" + errorStyle.Render(e.err.Error()))
+	}
+	return sb.String()
+}
@@ -0,0 +1,46 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/monitor"
+	"github.com/neflyte/timetracker/lib/profiles"
+	"github.com/neflyte/timetracker/lib/startup"
+	"github.com/neflyte/timetracker/lib/utils"
+)
+
+var (
+	tuiLogger = logger.GetPackageLogger("tui")
+)
+
+// Run shows the full-screen terminal UI until the user quits. The monitor service keeps it up to date with
+// changes made by the other apps.
+func Run() error {
+	log := logger.GetFuncLogger(tuiLogger, "Run")
+	monitorService := monitor.NewService(make(chan bool, 1))
+	observable, unsubscribe := monitorService.Subscribe()
+	defer unsubscribe()
+	program := tea.NewProgram(newModel(monitorService), tea.WithAltScreen())
+	observable.ForEach(
+		func(event interface{}) {
+			program.Send(monitorEventMsg{event: event})
+		},
+		utils.ObservableErrorHandler("monitor", tuiLogger),
+		utils.ObservableCloseHandler("monitor", tuiLogger),
+	)
+	monitorService.Start(nil)
+	defer monitorService.Stop()
+	log.Debug().
+		Msg("starting terminal UI")
+	_, err := program.Run()
+	return err
+}
+
+// activeProfile returns the name of the profile whose database is open, if it is not the default profile
+func activeProfile() string {
+	profileName := startup.ActiveProfile()
+	if profileName == profiles.DefaultProfileName {
+		return ""
+	}
+	return profileName
+}
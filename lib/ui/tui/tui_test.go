@@ -0,0 +1,155 @@
+package tui
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/neflyte/timetracker/lib/constants"
+	"github.com/neflyte/timetracker/lib/database"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/models"
+	"github.com/neflyte/timetracker/lib/monitor"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	TestDSN = "file:test.db?cache=shared&mode=memory"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(m.Run())
+}
+
+func MustOpenTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(TestDSN), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Warn),
+	})
+	if err != nil {
+		t.Fatalf("error opening test db: %s", err)
+	}
+	err = models.Migrate(db)
+	if err != nil {
+		t.Fatalf("error automigrating test db schema: %s", err)
+	}
+	return db
+}
+
+func CloseTestDB(t *testing.T, db *gorm.DB) {
+	if db != nil {
+		sqldb, err := db.DB()
+		if err != nil {
+			t.Logf("error getting sql.DB handle: %s\n", err)
+		} else {
+			err = sqldb.Close()
+			if err != nil {
+				t.Logf("error closing DB handle: %s\n", err)
+			}
+		}
+	}
+}
+
+func mustCreateTask(t *testing.T, synopsis string) *models.TaskData {
+	task := models.NewTask()
+	task.Data().Synopsis = synopsis
+	require.Nil(t, task.Create())
+	return task.Data()
+}
+
+func TestUnit_FormatDuration(t *testing.T) {
+	require.Equal(t, "1:30:00", formatDuration(90*time.Minute))
+	require.Equal(t, "26:00:05", formatDuration(26*time.Hour+5*time.Second+time.Millisecond))
+}
+
+func TestUnit_TimesheetEditor_Validation(t *testing.T) {
+	db := MustOpenTestDB(t)
+	defer CloseTestDB(t, db)
+	database.Set(db)
+
+	task := mustCreateTask(t, "editor")
+	day := time.Date(2023, 6, 5, 0, 0, 0, 0, time.Local)
+	currentTime := day.Add(12 * time.Hour)
+	editor := newTimesheetEditor(models.TimesheetData{}, day)
+	editor.inputs[editorFieldTask].SetValue("editor")
+	editor.inputs[editorFieldStart].SetValue("13:00")
+	_, err := editor.save(currentTime)
+	require.EqualError(t, err, tterrors.StartInFutureTimesheetError)
+	editor.inputs[editorFieldStart].SetValue("09:00")
+	editor.inputs[editorFieldStop].SetValue("08:00")
+	_, err = editor.save(currentTime)
+	require.EqualError(t, err, tterrors.StopBeforeStartTimesheetError)
+	editor.inputs[editorFieldStop].SetValue("12:30")
+	_, err = editor.save(currentTime)
+	require.EqualError(t, err, tterrors.StopInFutureTimesheetError)
+	editor.inputs[editorFieldStop].SetValue("10:00")
+	saved, err := editor.save(currentTime)
+	require.Nil(t, err)
+	require.NotZero(t, saved.ID)
+	require.Equal(t, task.ID, saved.Task.ID)
+	require.Equal(t, day.Add(10*time.Hour), saved.StopTime.Time)
+
+	// Only one timesheet can be running
+	running := models.NewTimesheet()
+	running.Data().Task = *task
+	running.Data().StartTime = currentTime.Add(-time.Minute)
+	require.Nil(t, running.Create())
+	editor = newTimesheetEditor(saved, day)
+	require.Equal(t, "10:00:00", editor.inputs[editorFieldStop].Value())
+	editor.inputs[editorFieldStop].SetValue("")
+	_, err = editor.save(currentTime)
+	require.EqualError(t, err, tterrors.AlreadyRunningTimesheetError)
+}
+
+func TestUnit_Model_StartAndStop(t *testing.T) {
+	db := MustOpenTestDB(t)
+	defer CloseTestDB(t, db)
+	database.Set(db)
+
+	mustCreateTask(t, "first")
+	mustCreateTask(t, "second")
+	monitorService := monitor.NewService(make(chan bool, 1))
+	m := newModel(monitorService)
+	m.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	// Latest task first
+	require.Len(t, m.tasks, 2)
+	require.Equal(t, "second", m.tasks[0].Synopsis)
+
+	m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	require.Equal(t, constants.TimesheetStatusRunning, monitorService.TimesheetStatus())
+	require.Equal(t, "first", monitorService.RunningTimesheet().Data().Task.Synopsis)
+	require.Len(t, m.timesheets, 1)
+	require.True(t, strings.Contains(m.View(), "first"))
+
+	// Switch to the other task
+	m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	require.Equal(t, "second", monitorService.RunningTimesheet().Data().Task.Synopsis)
+	openTimesheets, err := models.NewTimesheet().SearchOpen()
+	require.Nil(t, err)
+	require.Len(t, openTimesheets, 1)
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	require.Equal(t, constants.TimesheetStatusIdle, monitorService.TimesheetStatus())
+	require.Nil(t, monitorService.RunningTimesheet())
+	require.Equal(t, "Stopped second", m.message)
+}
+
+func TestUnit_VisibleRange(t *testing.T) {
+	start, end := visibleRange(0, 10, 4)
+	require.Equal(t, []int{0, 4}, []int{start, end})
+	start, end = visibleRange(6, 10, 4)
+	require.Equal(t, []int{3, 7}, []int{start, end})
+	start, end = visibleRange(1, 2, 4)
+	require.Equal(t, []int{0, 2}, []int{start, end})
+	start, end = visibleRange(1, 2, 0)
+	require.Equal(t, []int{0, 0}, []int{start, end})
+	require.Equal(t, 0, clamp(3, 0))
+	require.Equal(t, 2, clamp(5, 3))
+	require.Equal(t, "abcdefg…", truncate("abcdefghij", 8))
+}
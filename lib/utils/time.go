@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+)
+
+const (
+	// timeOfDayLayout is the layout that FormatTimeOfDay uses for a time on the day that is being edited
+	timeOfDayLayout = "15:04:05"
+)
+
+var (
+	// timeOfDayLayouts are the layouts of a time on the day that is being edited
+	timeOfDayLayouts = []string{"15:04", "15:04:05"}
+	// dateTimeLayouts are the layouts of a date and time
+	dateTimeLayouts = []string{"2006-01-02 15:04", "2006-01-02 15:04:05"}
+)
+
+// ParseTimeOfDay parses a time of day on the specified day, or a date and time
+func ParseTimeOfDay(value string, day time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range timeOfDayLayouts {
+		timeOfDay, err := time.ParseInLocation(layout, value, day.Location())
+		if err == nil {
+			return time.Date(
+				day.Year(), day.Month(), day.Day(),
+				timeOfDay.Hour(), timeOfDay.Minute(), timeOfDay.Second(), 0,
+				day.Location(),
+			), nil
+		}
+	}
+	for _, layout := range dateTimeLayouts {
+		dateTime, err := time.ParseInLocation(layout, value, day.Location())
+		if err == nil {
+			return dateTime, nil
+		}
+	}
+	return time.Time{}, errors.New(tterrors.InvalidTimeOfDayError)
+}
+
+// FormatTimeOfDay formats a time for editing; only the time of day is shown if it is on the specified day
+func FormatTimeOfDay(timestamp time.Time, day time.Time) string {
+	year, month, dayOfMonth := timestamp.Date()
+	if year == day.Year() && month == day.Month() && dayOfMonth == day.Day() {
+		return timestamp.Format(timeOfDayLayout)
+	}
+	return timestamp.Format(dateTimeLayouts[1])
+}
@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_ParseTimeOfDay(t *testing.T) {
+	day := time.Date(2023, 6, 5, 0, 0, 0, 0, time.Local)
+	parsed, err := ParseTimeOfDay("9:30", day)
+	require.Nil(t, err)
+	require.Equal(t, day.Add(9*time.Hour+30*time.Minute), parsed)
+	parsed, err = ParseTimeOfDay(" 17:45:10 ", day)
+	require.Nil(t, err)
+	require.Equal(t, day.Add(17*time.Hour+45*time.Minute+10*time.Second), parsed)
+	parsed, err = ParseTimeOfDay("2023-06-06 01:15", day)
+	require.Nil(t, err)
+	require.Equal(t, day.Add(25*time.Hour+15*time.Minute), parsed)
+	_, err = ParseTimeOfDay("noon", day)
+	require.EqualError(t, err, tterrors.InvalidTimeOfDayError)
+}
+
+func TestUnit_FormatTimeOfDay(t *testing.T) {
+	day := time.Date(2023, 6, 5, 0, 0, 0, 0, time.Local)
+	require.Equal(t, "09:30:00", FormatTimeOfDay(day.Add(9*time.Hour+30*time.Minute), day))
+	require.Equal(t, "2023-06-06 01:15:00", FormatTimeOfDay(day.Add(25*time.Hour+15*time.Minute), day))
+}
@@ -0,0 +1,347 @@
+package webhooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/neflyte/timetracker/lib/database"
+	tterrors "github.com/neflyte/timetracker/lib/errors"
+	"github.com/neflyte/timetracker/lib/logger"
+	"github.com/neflyte/timetracker/lib/models"
+	"gorm.io/gorm"
+)
+
+const (
+	// SignatureHeader is the HTTP header containing the HMAC-SHA256 signature of the request body
+	SignatureHeader = "X-Timetracker-Signature"
+	// EventHeader is the HTTP header containing the event type of the payload
+	EventHeader = "X-Timetracker-Event"
+	// DeliveryHeader is the HTTP header containing the ID of the queued delivery
+	DeliveryHeader = "X-Timetracker-Delivery"
+	// EventWebhookTest is the event type of the payload sent by Test
+	EventWebhookTest = "webhook.test"
+	// MaxDeliveryAttempts is the number of times delivery of a payload is attempted before giving up
+	MaxDeliveryAttempts = 10
+
+	signaturePrefix  = "sha256="
+	contentType      = "application/json"
+	userAgent        = "timetracker-webhooks"
+	testDeliveryID   = "test"
+	deliveryTimeout  = 5 * time.Second
+	retryBaseDelay   = 30 * time.Second
+	retryMaxDelay    = 6 * time.Hour
+	successStatusMin = 200
+	successStatusMax = 299
+)
+
+var (
+	packageLogger = logger.GetPackageLogger("webhooks")
+	httpClient    = &http.Client{Timeout: deliveryTimeout}
+	// deliverMtx ensures only one delivery pass runs at a time in this process
+	deliverMtx = sync.Mutex{}
+	// inFlight tracks background delivery passes so that they can finish before the app exits
+	inFlight       = sync.WaitGroup{}
+	initialized    = false
+	initializedMtx = sync.Mutex{}
+)
+
+// PayloadTask is the representation of a task inside an event payload
+type PayloadTask struct {
+	Synopsis    string `json:"synopsis"`
+	Description string `json:"description"`
+	ID          uint   `json:"id"`
+}
+
+// PayloadTimesheet is the representation of a timesheet inside an event payload
+type PayloadTimesheet struct {
+	StartTime       time.Time  `json:"start_time"`
+	StopTime        *time.Time `json:"stop_time,omitempty"`
+	ID              uint       `json:"id"`
+	TaskID          uint       `json:"task_id"`
+	DurationSeconds int64      `json:"duration_seconds,omitempty"`
+}
+
+// Payload is the JSON document sent to webhook endpoints
+type Payload struct {
+	Timestamp time.Time         `json:"timestamp"`
+	Task      *PayloadTask      `json:"task,omitempty"`
+	Timesheet *PayloadTimesheet `json:"timesheet,omitempty"`
+	Event     string            `json:"event"`
+}
+
+// NewPayload creates a Payload that describes the supplied event
+func NewPayload(event models.Event) Payload {
+	payload := Payload{
+		Event:     string(event.Type),
+		Timestamp: event.Time.UTC(),
+		Task: &PayloadTask{
+			ID:          event.Task.ID,
+			Synopsis:    event.Task.Synopsis,
+			Description: event.Task.Description,
+		},
+	}
+	if event.Timesheet != nil {
+		payload.Timesheet = &PayloadTimesheet{
+			ID:        event.Timesheet.ID,
+			TaskID:    event.Timesheet.Task.ID,
+			StartTime: event.Timesheet.StartTime.UTC(),
+		}
+		if event.Timesheet.StopTime.Valid {
+			stopTime := event.Timesheet.StopTime.Time.UTC()
+			payload.Timesheet.StopTime = &stopTime
+			payload.Timesheet.DurationSeconds = int64(stopTime.Sub(event.Timesheet.StartTime).Truncate(time.Second).Seconds())
+		}
+	}
+	return payload
+}
+
+// Init subscribes the webhook system to task and timesheet events. It is safe to call more than once.
+func Init() {
+	initializedMtx.Lock()
+	defer initializedMtx.Unlock()
+	if initialized {
+		return
+	}
+	models.AddEventListener(handleEvent)
+	initialized = true
+}
+
+// Wait blocks until any background delivery passes have finished
+func Wait() {
+	inFlight.Wait()
+}
+
+// Sign returns the signature of the body using the supplied secret, in the form `sha256=<hex digest>`
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body) // hash.Hash never returns an error
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks that the signature matches the body signed with the supplied secret
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// Enqueue stores a payload in the delivery queue of every endpoint that accepts the event type. The number of
+// queued deliveries is returned.
+func Enqueue(eventType models.EventType, payload Payload) (int, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return 0, err
+	}
+	webhookList, err := models.NewWebhook().LoadAll()
+	if err != nil {
+		return 0, err
+	}
+	queued := 0
+	tx := database.Get().Begin()
+	for idx := range webhookList {
+		if !webhookList[idx].Accepts(eventType) {
+			continue
+		}
+		delivery := &models.WebhookDeliveryData{
+			WebhookID:     webhookList[idx].ID,
+			Event:         string(eventType),
+			Payload:       string(body),
+			NextAttemptAt: time.Now(),
+		}
+		err = tx.Create(delivery).Error
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		queued++
+	}
+	tx.Commit()
+	return queued, nil
+}
+
+// DeliverPending attempts to deliver every queued payload that is due. The number of payloads that were
+// delivered successfully is returned along with the number of attempts that failed.
+func DeliverPending() (delivered int, failed int, err error) {
+	log := logger.GetFuncLogger(packageLogger, "DeliverPending")
+	deliverMtx.Lock()
+	defer deliverMtx.Unlock()
+	db := database.Get()
+	if db == nil {
+		return 0, 0, errors.New(tterrors.DatabaseNotOpenWebhookError)
+	}
+	deliveries := make([]models.WebhookDeliveryData, 0)
+	err = db.Where("delivered_at IS NULL AND attempts < ? AND next_attempt_at <= ?", MaxDeliveryAttempts, time.Now()).
+		Order("id").
+		Find(&deliveries).
+		Error
+	if err != nil {
+		return 0, 0, err
+	}
+	webhookCache := make(map[uint]*models.WebhookData)
+	for idx := range deliveries {
+		delivery := &deliveries[idx]
+		webhook, cached := webhookCache[delivery.WebhookID]
+		if !cached {
+			loaded := models.NewWebhook()
+			loaded.Data().ID = delivery.WebhookID
+			loadErr := loaded.Load()
+			if loadErr != nil {
+				log.Err(loadErr).
+					Uint("webhookID", delivery.WebhookID).
+					Msg("unable to load webhook for queued delivery")
+				continue
+			}
+			webhook = loaded.Data()
+			webhookCache[delivery.WebhookID] = webhook
+		}
+		// Claim the delivery so that another process does not attempt it at the same time
+		claim := db.Model(delivery).
+			Where("attempts = ?", delivery.Attempts).
+			Update("attempts", gorm.Expr("attempts + 1"))
+		if claim.Error != nil {
+			log.Err(claim.Error).
+				Uint("deliveryID", delivery.ID).
+				Msg("unable to claim queued delivery")
+			continue
+		}
+		if claim.RowsAffected == 0 {
+			continue
+		}
+		delivery.Attempts++
+		sendErr := send(webhook, delivery.Event, strconv.Itoa(int(delivery.ID)), []byte(delivery.Payload))
+		if sendErr != nil {
+			log.Warn().
+				Err(sendErr).
+				Uint("deliveryID", delivery.ID).
+				Int("attempts", delivery.Attempts).
+				Msg("webhook delivery failed")
+			failed++
+			err = db.Model(delivery).Updates(map[string]interface{}{
+				"last_error":      sendErr.Error(),
+				"next_attempt_at": time.Now().Add(retryDelay(delivery.Attempts)),
+			}).Error
+		} else {
+			delivered++
+			err = db.Model(delivery).Updates(map[string]interface{}{
+				"last_error":   "",
+				"delivered_at": time.Now(),
+			}).Error
+		}
+		if err != nil {
+			log.Err(err).
+				Uint("deliveryID", delivery.ID).
+				Msg("unable to update queued delivery")
+			return delivered, failed, err
+		}
+	}
+	return delivered, failed, nil
+}
+
+// DeliverPendingInBackground runs DeliverPending in a new goroutine
+func DeliverPendingInBackground() {
+	inFlight.Add(1)
+	go func() {
+		defer inFlight.Done()
+		_, _, err := DeliverPending()
+		if err != nil {
+			log := logger.GetFuncLogger(packageLogger, "DeliverPendingInBackground")
+			log.Err(err).
+				Msg(tterrors.DeliverWebhookError)
+		}
+	}()
+}
+
+// Pending returns the number of payloads that are still waiting to be delivered to the endpoint
+func Pending(webhookID uint) (int64, error) {
+	var count int64
+	err := database.Get().
+		Model(new(models.WebhookDeliveryData)).
+		Where("webhook_id = ? AND delivered_at IS NULL AND attempts < ?", webhookID, MaxDeliveryAttempts).
+		Count(&count).
+		Error
+	return count, err
+}
+
+// Test sends a test payload to the endpoint immediately without using the delivery queue
+func Test(webhook models.Webhook) error {
+	if webhook == nil || webhook.Data() == nil {
+		return tterrors.ErrInvalidWebhookState{Details: tterrors.LoadInvalidWebhookError}
+	}
+	body, err := json.Marshal(Payload{
+		Event:     EventWebhookTest,
+		Timestamp: time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+	return send(webhook.Data(), EventWebhookTest, testDeliveryID, body)
+}
+
+// handleEvent queues a published event for delivery and starts a delivery pass
+func handleEvent(event models.Event) {
+	log := logger.GetFuncLogger(packageLogger, "handleEvent")
+	queued, err := Enqueue(event.Type, NewPayload(event))
+	if err != nil {
+		log.Err(err).
+			Str("event", string(event.Type)).
+			Msg("unable to queue event for delivery")
+		return
+	}
+	if queued > 0 {
+		DeliverPendingInBackground()
+	}
+}
+
+// send POSTs a signed payload to the endpoint
+func send(webhook *models.WebhookData, event string, deliveryID string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(EventHeader, event)
+	req.Header.Set(DeliveryHeader, deliveryID)
+	if webhook.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(webhook.Secret, body))
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		closeErr := resp.Body.Close()
+		if closeErr != nil {
+			log := logger.GetFuncLogger(packageLogger, "send")
+			log.Err(closeErr).
+				Msg("error closing response body")
+		}
+	}()
+	if resp.StatusCode < successStatusMin || resp.StatusCode > successStatusMax {
+		return tterrors.ErrWebhookResponse{
+			URL:        webhook.URL,
+			StatusCode: resp.StatusCode,
+		}
+	}
+	return nil
+}
+
+// retryDelay returns how long to wait before the next delivery attempt, doubling with each attempt
+func retryDelay(attempts int) time.Duration {
+	delay := retryBaseDelay
+	for x := 1; x < attempts; x++ {
+		delay *= 2
+		if delay >= retryMaxDelay {
+			return retryMaxDelay
+		}
+	}
+	return delay
+}
@@ -0,0 +1,232 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/neflyte/timetracker/lib/database"
+	"github.com/neflyte/timetracker/lib/models"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	TestDSN = "file:webhooks_test.db?cache=shared&mode=memory"
+
+	testSecret = "s3cr3t"
+)
+
+// receivedRequest is a request captured by the test endpoint
+type receivedRequest struct {
+	Signature string
+	Event     string
+	Body      []byte
+}
+
+// testEndpoint is an httptest server that records the requests it receives
+type testEndpoint struct {
+	server   *httptest.Server
+	requests []receivedRequest
+	status   int
+	mtx      sync.Mutex
+}
+
+func newTestEndpoint(t *testing.T) *testEndpoint {
+	endpoint := &testEndpoint{
+		status:   http.StatusOK,
+		requests: make([]receivedRequest, 0),
+	}
+	endpoint.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("error reading request body: %s", err)
+		}
+		endpoint.mtx.Lock()
+		defer endpoint.mtx.Unlock()
+		endpoint.requests = append(endpoint.requests, receivedRequest{
+			Signature: r.Header.Get(SignatureHeader),
+			Event:     r.Header.Get(EventHeader),
+			Body:      body,
+		})
+		w.WriteHeader(endpoint.status)
+	}))
+	return endpoint
+}
+
+func (e *testEndpoint) setStatus(status int) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	e.status = status
+}
+
+func (e *testEndpoint) received() []receivedRequest {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	requests := make([]receivedRequest, len(e.requests))
+	copy(requests, e.requests)
+	return requests
+}
+
+func MustOpenTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(TestDSN), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Warn),
+	})
+	if err != nil {
+		t.Fatalf("error opening test db: %s", err)
+	}
+	err = models.Migrate(db)
+	if err != nil {
+		t.Fatalf("error automigrating test db schema: %s", err)
+	}
+	return db
+}
+
+func CloseTestDB(t *testing.T, db *gorm.DB) {
+	if db != nil {
+		sqldb, err := db.DB()
+		if err != nil {
+			t.Logf("error getting sql.DB handle: %s\n", err)
+		} else {
+			err = sqldb.Close()
+			if err != nil {
+				t.Logf("error closing DB handle: %s\n", err)
+			}
+		}
+	}
+}
+
+func mustCreateWebhook(t *testing.T, url string, events string) models.Webhook {
+	webhook := models.NewWebhook()
+	webhook.Data().URL = url
+	webhook.Data().Secret = testSecret
+	webhook.Data().Events = events
+	require.Nil(t, webhook.Create())
+	return webhook
+}
+
+func TestUnit_Sign_Verify(t *testing.T) {
+	body := []byte(`{"event":"task.started"}`)
+	signature := Sign(testSecret, body)
+	require.Equal(t, "sha256=", signature[:7])
+	require.True(t, Verify(testSecret, body, signature))
+	require.False(t, Verify("wrong", body, signature))
+	require.False(t, Verify(testSecret, []byte(`{"event":"task.stopped"}`), signature))
+}
+
+func TestUnit_Webhooks_EventDelivered(t *testing.T) {
+	db := MustOpenTestDB(t)
+	defer CloseTestDB(t, db)
+	database.Set(db)
+	models.AddEventListener(handleEvent)
+	defer models.ClearEventListeners()
+
+	endpoint := newTestEndpoint(t)
+	defer endpoint.server.Close()
+	mustCreateWebhook(t, endpoint.server.URL, string(models.EventTaskCreated))
+
+	task := models.NewTask()
+	task.Data().Synopsis = "webhook-task"
+	task.Data().Description = "a task that is sent to a webhook"
+	require.Nil(t, task.Create())
+	Wait()
+
+	requests := endpoint.received()
+	require.Len(t, requests, 1)
+	require.Equal(t, string(models.EventTaskCreated), requests[0].Event)
+	require.True(t, Verify(testSecret, requests[0].Body, requests[0].Signature))
+	payload := Payload{}
+	require.Nil(t, json.Unmarshal(requests[0].Body, &payload))
+	require.Equal(t, string(models.EventTaskCreated), payload.Event)
+	require.NotNil(t, payload.Task)
+	require.Equal(t, task.Data().ID, payload.Task.ID)
+	require.Equal(t, "webhook-task", payload.Task.Synopsis)
+	require.Nil(t, payload.Timesheet)
+}
+
+func TestUnit_Webhooks_EventFiltered(t *testing.T) {
+	db := MustOpenTestDB(t)
+	defer CloseTestDB(t, db)
+	database.Set(db)
+
+	endpoint := newTestEndpoint(t)
+	defer endpoint.server.Close()
+	mustCreateWebhook(t, endpoint.server.URL, string(models.EventTaskStopped))
+
+	queued, err := Enqueue(models.EventTaskCreated, Payload{Event: string(models.EventTaskCreated)})
+	require.Nil(t, err)
+	require.Equal(t, 0, queued)
+}
+
+func TestUnit_Webhooks_DeliveryRetried(t *testing.T) {
+	db := MustOpenTestDB(t)
+	defer CloseTestDB(t, db)
+	database.Set(db)
+
+	endpoint := newTestEndpoint(t)
+	defer endpoint.server.Close()
+	endpoint.setStatus(http.StatusInternalServerError)
+	webhook := mustCreateWebhook(t, endpoint.server.URL, "")
+
+	queued, err := Enqueue(models.EventTaskStarted, Payload{Event: string(models.EventTaskStarted), Timestamp: time.Now()})
+	require.Nil(t, err)
+	require.Equal(t, 1, queued)
+
+	// The first attempt fails and is rescheduled
+	delivered, failed, err := DeliverPending()
+	require.Nil(t, err)
+	require.Equal(t, 0, delivered)
+	require.Equal(t, 1, failed)
+	delivery := models.WebhookDeliveryData{}
+	require.Nil(t, db.Where("webhook_id = ?", webhook.Data().ID).First(&delivery).Error)
+	require.Equal(t, 1, delivery.Attempts)
+	require.NotEmpty(t, delivery.LastError)
+	require.True(t, delivery.NextAttemptAt.After(time.Now()))
+
+	// Nothing is due yet
+	delivered, failed, err = DeliverPending()
+	require.Nil(t, err)
+	require.Equal(t, 0, delivered+failed)
+
+	// Make the retry due and let the endpoint succeed
+	require.Nil(t, db.Model(&delivery).Update("next_attempt_at", time.Now().Add(-time.Second)).Error)
+	endpoint.setStatus(http.StatusNoContent)
+	delivered, failed, err = DeliverPending()
+	require.Nil(t, err)
+	require.Equal(t, 1, delivered)
+	require.Equal(t, 0, failed)
+	pending, err := Pending(webhook.Data().ID)
+	require.Nil(t, err)
+	require.Equal(t, int64(0), pending)
+	require.Len(t, endpoint.received(), 2)
+}
+
+func TestUnit_Webhooks_Test(t *testing.T) {
+	endpoint := newTestEndpoint(t)
+	defer endpoint.server.Close()
+	webhook := models.NewWebhook()
+	webhook.Data().URL = endpoint.server.URL
+	webhook.Data().Secret = testSecret
+
+	require.Nil(t, Test(webhook))
+	requests := endpoint.received()
+	require.Len(t, requests, 1)
+	require.Equal(t, EventWebhookTest, requests[0].Event)
+	require.True(t, Verify(testSecret, requests[0].Body, requests[0].Signature))
+
+	endpoint.setStatus(http.StatusNotFound)
+	require.NotNil(t, Test(webhook))
+}
+
+func TestUnit_RetryDelay(t *testing.T) {
+	require.Equal(t, retryBaseDelay, retryDelay(1))
+	require.Equal(t, 2*retryBaseDelay, retryDelay(2))
+	require.Equal(t, 4*retryBaseDelay, retryDelay(3))
+	require.Equal(t, retryMaxDelay, retryDelay(MaxDeliveryAttempts*2))
+}